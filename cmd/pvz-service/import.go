@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ST359/pvz-service/internal/api"
+	"github.com/ST359/pvz-service/internal/config"
+	"github.com/ST359/pvz-service/internal/repository"
+	"github.com/ST359/pvz-service/internal/service"
+)
+
+const importPVZCmd = "import-pvz"
+
+// runImportPVZ implements `pvz-service import-pvz -file <path> [-format csv|ndjson] [-dry-run]`.
+// The report is printed to stdout as JSON, exit code is non-zero if the file could not be imported.
+func runImportPVZ(cfg *config.Config, args []string) int {
+	fs := flag.NewFlagSet(importPVZCmd, flag.ContinueOnError)
+	file := fs.String("file", "", "path to a CSV or NDJSON file with PVZs")
+	format := fs.String("format", "", "file format: csv or ndjson, detected by extension if omitted")
+	dryRun := fs.Bool("dry-run", false, "only validate the file without creating PVZs")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *file == "" {
+		fmt.Fprintln(os.Stderr, "-file is required")
+		fs.Usage()
+		return 2
+	}
+	if *format == "" {
+		switch strings.ToLower(filepath.Ext(*file)) {
+		case ".csv":
+			*format = string(api.PVZImportCSV)
+		case ".ndjson", ".jsonl":
+			*format = string(api.PVZImportNDJSON)
+		}
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to open file: %s\n", err)
+		return 1
+	}
+	defer f.Close()
+
+	rows, err := service.ParsePVZImport(f, api.PVZImportFormat(*format))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to parse file: %s\n", err)
+		return 1
+	}
+
+	db, err := repository.NewPostgresDB(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error during db initializing: %s\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	pvzService := service.NewPVZService(repository.NewPVZPostgres(db), nil)
+	report, err := pvzService.Import(context.Background(), rows, *dryRun)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to import pvz: %s\n", err)
+		return 1
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write report: %s\n", err)
+		return 1
+	}
+	if report.Invalid > 0 {
+		return 1
+	}
+	return 0
+}
@@ -2,19 +2,25 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/ST359/pvz-service/internal/blobstore"
 	"github.com/ST359/pvz-service/internal/config"
 	"github.com/ST359/pvz-service/internal/handler"
+	"github.com/ST359/pvz-service/internal/metrics"
 	"github.com/ST359/pvz-service/internal/repository"
 	"github.com/ST359/pvz-service/internal/service"
+	"github.com/ST359/pvz-service/internal/tracing"
+	"github.com/ST359/pvz-service/internal/worker"
 )
 
 type Server struct {
@@ -37,22 +43,83 @@ func (s *Server) Shutdown(ctx context.Context) error {
 }
 func main() {
 	cfg := config.MustLoad()
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	if len(os.Args) > 1 && os.Args[1] == importPVZCmd {
+		os.Exit(runImportPVZ(cfg, os.Args[2:]))
+	}
+	logger := slog.New(tracing.NewLogHandler(slog.NewJSONHandler(os.Stdout, nil)))
+	pickupCfg := config.MustLoadPickupCode()
+	sender, err := service.NewPickupCodeSender(pickupCfg, logger)
+	if err != nil {
+		log.Fatalf("error during pickup code sender initializing: %s", err.Error())
+	}
+	if pickupCfg.Sender == service.PickupCodeSenderLog {
+		logger.Warn("pickup codes are not delivered to customers, PICKUP_CODE_SENDER=log is for local development only")
+	}
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg)
+	if err != nil {
+		log.Fatalf("error during tracing initializing: %s", err.Error())
+	}
 
 	db, err := repository.NewPostgresDB(cfg)
 	if err != nil {
 		log.Fatalf("error during db initializing: %s", err.Error())
 	}
 	repos := repository.NewRepository(db)
-	services := service.NewService(repos)
-	handlers := handler.NewHandler(services, logger)
+	blobs, err := blobstore.New(cfg)
+	if err != nil {
+		log.Fatalf("error during blob store initializing: %s", err.Error())
+	}
+	var (
+		businessMetrics service.MetricsRecorder
+		httpMetrics     *metrics.HTTP
+		metricsSrv      *Server
+	)
+	if cfg.MetricsPort > 0 {
+		registry := metrics.NewRegistry()
+		httpMetrics = metrics.NewHTTP(registry)
+		businessMetrics = metrics.NewBusiness(registry)
+		metrics.RegisterDBStats(registry, db, cfg.DbName)
+
+		mux := http.NewServeMux()
+		mux.Handle("GET /metrics", metrics.Handler(registry))
+		metricsSrv = new(Server)
+		go func() {
+			if err := metricsSrv.Run(strconv.Itoa(cfg.MetricsPort), mux); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatalf("error while running metrics server: %s", err.Error())
+			}
+		}()
+	}
+	services := service.NewService(repos, cfg, pickupCfg, sender, blobs, businessMetrics, logger)
+	handlers := handler.NewHandler(services, logger, httpMetrics)
 	srv := new(Server)
 	go func() {
-		if err := srv.Run(strconv.Itoa(cfg.Port), handlers.InitRoutes()); err != nil {
+		if err := srv.Run(strconv.Itoa(cfg.Port), handlers.InitRoutes()); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatalf("error while running server: %s", err.Error())
 		}
 	}()
 
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	if cfg.StaleReceptionTimeout > 0 && cfg.StaleReceptionCheckInterval > 0 {
+		if cfg.StaleReceptionAction != service.StaleActionClose && cfg.StaleReceptionAction != service.StaleActionFlag {
+			log.Fatalf("unknown STALE_RECEPTION_ACTION %q, expected %s or %s", cfg.StaleReceptionAction, service.StaleActionClose, service.StaleActionFlag)
+		}
+		staleReceptions := worker.NewStaleReceptions(services.Reception, cfg.StaleReceptionCheckInterval, logger)
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			staleReceptions.Run(workerCtx)
+		}()
+	}
+	if cfg.IdempotencyCleanupInterval > 0 {
+		idempotencyKeys := worker.NewIdempotencyKeys(services.Idempotency, cfg.IdempotencyCleanupInterval, logger)
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			idempotencyKeys.Run(workerCtx)
+		}()
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
 	<-quit
@@ -61,6 +128,19 @@ func main() {
 	if err := srv.Shutdown(context.Background()); err != nil {
 		log.Printf("error occured on server shutting down: %s", err.Error())
 	}
+	if metricsSrv != nil {
+		if err := metricsSrv.Shutdown(context.Background()); err != nil {
+			log.Printf("error occured on metrics server shutting down: %s", err.Error())
+		}
+	}
+	stopWorkers()
+	workers.Wait()
+
+	tracingCtx, cancelTracing := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelTracing()
+	if err := shutdownTracing(tracingCtx); err != nil {
+		log.Printf("error occured on flushing traces: %s", err.Error())
+	}
 
 	if err := db.Close(); err != nil {
 		log.Printf("error occured on db connection close: %s", err.Error())
@@ -13,6 +13,32 @@ const (
 	BearerAuthScopes = "bearerAuth.Scopes"
 )
 
+// Defines values for DamageReportDecisionDecision.
+const (
+	DecisionAccepted DamageReportDecisionDecision = "accepted"
+	DecisionRejected DamageReportDecisionDecision = "rejected"
+)
+
+// Defines values for DamageReportStatus.
+const (
+	DamageReportAccepted DamageReportStatus = "accepted"
+	DamageReportPending  DamageReportStatus = "pending"
+	DamageReportRejected DamageReportStatus = "rejected"
+)
+
+// Defines values for DiscrepancyKind.
+const (
+	DiscrepancyExtra     DiscrepancyKind = "extra"
+	DiscrepancyMissing   DiscrepancyKind = "missing"
+	DiscrepancyWrongType DiscrepancyKind = "wrong_type"
+)
+
+// Defines values for ExportFormat.
+const (
+	ExportCSV  ExportFormat = "csv"
+	ExportXLSX ExportFormat = "xlsx"
+)
+
 // Defines values for PVZCity.
 const (
 	Kazan           PVZCity = "Казань"
@@ -20,11 +46,56 @@ const (
 	SaintPetersburg PVZCity = "Санкт-Петербург"
 )
 
-// Defines values for ProductType.
+// Defines values for PVZImportFormat.
+const (
+	PVZImportCSV    PVZImportFormat = "csv"
+	PVZImportNDJSON PVZImportFormat = "ndjson"
+)
+
+// Defines values for PVZImportRowResultStatus.
+const (
+	PVZImportRowCreated PVZImportRowResultStatus = "created"
+	PVZImportRowInvalid PVZImportRowResultStatus = "invalid"
+	PVZImportRowValid   PVZImportRowResultStatus = "valid"
+)
+
+// Defines values for ProductCondition.
+const (
+	ConditionDamaged ProductCondition = "damaged"
+	ConditionOk      ProductCondition = "ok"
+	ConditionOpened  ProductCondition = "opened"
+	ConditionWet     ProductCondition = "wet"
+)
+
+// Defines values for ProductDeleteReason.
+const (
+	DeleteReasonDamaged       ProductDeleteReason = "damaged"
+	DeleteReasonDuplicateScan ProductDeleteReason = "duplicate_scan"
+	DeleteReasonMistakenScan  ProductDeleteReason = "mistaken_scan"
+	DeleteReasonOther         ProductDeleteReason = "other"
+	DeleteReasonUndoLast      ProductDeleteReason = "undo_last"
+	DeleteReasonWrongPVZ      ProductDeleteReason = "wrong_pvz"
+)
+
+// Defines values for ProductState.
 const (
-	ProductTypeShoes       ProductType = "обувь"
-	ProductTypeClothes     ProductType = "одежда"
-	ProductTypeElectronics ProductType = "электроника"
+	ProductStateInTransit        ProductState = "in_transit"
+	ProductStateIssued           ProductState = "issued"
+	ProductStateReceived         ProductState = "received"
+	ProductStateReturnedToSender ProductState = "returned_to_sender"
+	ProductStateStored           ProductState = "stored"
+)
+
+// Defines values for ReceptionCloseReason.
+const (
+	CloseReasonAutoClosed ReceptionCloseReason = "auto_closed"
+	CloseReasonManual     ReceptionCloseReason = "manual"
+)
+
+// Defines values for ReceptionKind.
+const (
+	ReceptionKindCustomerReturn ReceptionKind = "customer_return"
+	ReceptionKindInbound        ReceptionKind = "inbound"
 )
 
 // Defines values for ReceptionStatus.
@@ -33,23 +104,44 @@ const (
 	InProgress ReceptionStatus = "in_progress"
 )
 
+// Defines values for ReturnCondition.
+const (
+	ReturnConditionDefective ReturnCondition = "defective"
+	ReturnConditionOpened    ReturnCondition = "opened"
+	ReturnConditionUnopened  ReturnCondition = "unopened"
+)
+
+// Defines values for TransferStatus.
+const (
+	TransferStatusArrived    TransferStatus = "arrived"
+	TransferStatusCreated    TransferStatus = "created"
+	TransferStatusDispatched TransferStatus = "dispatched"
+)
+
 // Defines values for UserRole.
 const (
 	UserRoleEmployee  UserRole = "employee"
 	UserRoleModerator UserRole = "moderator"
 )
 
-// Defines values for PostDummyLoginJSONBodyRole.
+// Defines values for VolumeGroup.
 const (
-	PostDummyLoginJSONBodyRoleEmployee  PostDummyLoginJSONBodyRole = "employee"
-	PostDummyLoginJSONBodyRoleModerator PostDummyLoginJSONBodyRole = "moderator"
+	VolumeGroupCity VolumeGroup = "city"
+	VolumeGroupPVZ  VolumeGroup = "pvz"
+	VolumeGroupType VolumeGroup = "type"
 )
 
-// Defines values for PostProductsJSONBodyType.
+// Defines values for VolumePeriod.
+const (
+	VolumePeriodDay   VolumePeriod = "day"
+	VolumePeriodMonth VolumePeriod = "month"
+	VolumePeriodWeek  VolumePeriod = "week"
+)
+
+// Defines values for PostDummyLoginJSONBodyRole.
 const (
-	PostProductsJSONBodyTypeShoes       PostProductsJSONBodyType = "обувь"
-	PostProductsJSONBodyTypeClothes     PostProductsJSONBodyType = "одежда"
-	PostProductsJSONBodyTypeElectronics PostProductsJSONBodyType = "электроника"
+	PostDummyLoginJSONBodyRoleEmployee  PostDummyLoginJSONBodyRole = "employee"
+	PostDummyLoginJSONBodyRoleModerator PostDummyLoginJSONBodyRole = "moderator"
 )
 
 // Defines values for PostRegisterJSONBodyRole.
@@ -58,13 +150,134 @@ const (
 	Moderator PostRegisterJSONBodyRole = "moderator"
 )
 
+// Attachment Фото или документ, приложенный к приемке или товару
+type Attachment struct {
+	// ContentType Тип содержимого, определенный по самому файлу
+	ContentType string `json:"contentType"`
+
+	// DownloadUrl Ссылка на скачивание файла
+	DownloadUrl string              `json:"downloadUrl"`
+	FileName    string              `json:"fileName"`
+	Id          openapi_types.UUID  `json:"id"`
+	ProductId   *openapi_types.UUID `json:"productId,omitempty"`
+	ReceptionId *openapi_types.UUID `json:"receptionId,omitempty"`
+
+	// Sha256 Контрольная сумма SHA-256 файла в hex
+	Sha256 string `json:"sha256"`
+
+	// Size Размер файла в байтах
+	Size       int64               `json:"size"`
+	UploadedAt time.Time           `json:"uploadedAt"`
+	UploadedBy *openapi_types.UUID `json:"uploadedBy,omitempty"`
+}
+
+// AttachmentUpload defines model for AttachmentUpload.
+type AttachmentUpload struct {
+	// File Изображение JPEG, PNG, WebP или документ PDF
+	File openapi_types.File `json:"file"`
+}
+
+// DamageReport Отметка о повреждении товара, ожидающая решения модератора или уже рассмотренная
+type DamageReport struct {
+	Comment string `json:"comment"`
+
+	// Condition Состояние товара при приемке, отличное от ok состояние проверяет модератор
+	Condition   ProductCondition   `json:"condition"`
+	Id          openapi_types.UUID `json:"id"`
+	ProductId   openapi_types.UUID `json:"productId"`
+	PvzId       openapi_types.UUID `json:"pvzId"`
+	ReceptionId openapi_types.UUID `json:"receptionId"`
+	ReportedAt  time.Time          `json:"reportedAt"`
+
+	// ReportedBy Сотрудник, отметивший товар, отсутствует для токенов /dummyLogin
+	ReportedBy    *openapi_types.UUID `json:"reportedBy,omitempty"`
+	ReviewComment *string             `json:"reviewComment,omitempty"`
+	ReviewedAt    *time.Time          `json:"reviewedAt,omitempty"`
+	ReviewedBy    *openapi_types.UUID `json:"reviewedBy,omitempty"`
+	Status        DamageReportStatus  `json:"status"`
+}
+
+// DamageReportDecision defines model for DamageReportDecision.
+type DamageReportDecision struct {
+	Comment *string `json:"comment,omitempty"`
+
+	// Decision accepted подтверждает повреждение, rejected возвращает товару состояние ok
+	Decision DamageReportDecisionDecision `json:"decision"`
+}
+
+// DamageReportDecisionDecision accepted подтверждает повреждение, rejected возвращает товару состояние ok
+type DamageReportDecisionDecision string
+
+// DamageReportStatus defines model for DamageReportStatus.
+type DamageReportStatus string
+
+// DiscrepancyKind defines model for DiscrepancyKind.
+type DiscrepancyKind string
+
+// EmployeeProductivityRow Сотруднику засчитываются открытые им приемки и все отсканированные в них товары, включая удаленные
+type EmployeeProductivityRow struct {
+	// AvgReceptionDurationSeconds Среднее время от открытия до закрытия по приемкам, закрытым вручную. Не заполняется, если таких приемок нет
+	AvgReceptionDurationSeconds *int64 `json:"avgReceptionDurationSeconds,omitempty"`
+	ClosedReceptions            int64  `json:"closedReceptions"`
+
+	// DeletionRate Доля удаленных товаров среди отсканированных, от 0 до 1
+	DeletionRate    float64 `json:"deletionRate"`
+	Email           string  `json:"email"`
+	ProductsDeleted int64   `json:"productsDeleted"`
+
+	// ProductsPerHour Товаров в час по приемкам, закрытым вручную. Не заполняется, если таких приемок нет
+	ProductsPerHour *float64           `json:"productsPerHour,omitempty"`
+	ProductsScanned int64              `json:"productsScanned"`
+	PvzId           openapi_types.UUID `json:"pvzId"`
+
+	// Receptions Открыто приемок
+	Receptions int64              `json:"receptions"`
+	UserId     openapi_types.UUID `json:"userId"`
+}
+
 // Error defines model for Error.
 type Error struct {
 	Message string `json:"message"`
 }
 
+// ExportFormat defines model for ExportFormat.
+type ExportFormat string
+
+// ManifestItem defines model for ManifestItem.
+type ManifestItem struct {
+	Barcode string `json:"barcode"`
+
+	// Type Код типа товара из справочника /product_types. При добавлении товара также принимается прежнее название (электроника, одежда, обувь)
+	Type ProductType `json:"type"`
+}
+
+// ManifestProgress Сверка отсканированных товаров приемки с ожидаемыми по манифесту
+type ManifestProgress struct {
+	// Expected Позиций в манифесте
+	Expected int `json:"expected"`
+
+	// Extra Отсканированных товаров, которых нет в манифесте
+	Extra int `json:"extra"`
+
+	// Missing Позиций манифеста, которые еще не отсканированы
+	Missing     int                `json:"missing"`
+	ReceptionId openapi_types.UUID `json:"receptionId"`
+
+	// Scanned Позиций манифеста, по штрихкоду которых отсканирован товар
+	Scanned int `json:"scanned"`
+
+	// WrongType Отсканированных позиций с типом, отличным от манифеста
+	WrongType int `json:"wrongType"`
+}
+
+// ManifestUpload defines model for ManifestUpload.
+type ManifestUpload struct {
+	Items []ManifestItem `json:"items"`
+}
+
 // PVZ defines model for PVZ.
 type PVZ struct {
+	Address          *string             `json:"address,omitempty"`
 	City             PVZCity             `json:"city"`
 	Id               *openapi_types.UUID `json:"id,omitempty"`
 	RegistrationDate *time.Time          `json:"registrationDate,omitempty"`
@@ -73,6 +286,32 @@ type PVZ struct {
 // PVZCity defines model for PVZ.City.
 type PVZCity string
 
+// PVZImportFormat defines model for PVZImportFormat.
+type PVZImportFormat string
+
+// PVZImportReport defines model for PVZImportReport.
+type PVZImportReport struct {
+	Created int                  `json:"created"`
+	DryRun  bool                 `json:"dryRun"`
+	Invalid int                  `json:"invalid"`
+	Rows    []PVZImportRowResult `json:"rows"`
+	Total   int                  `json:"total"`
+	Valid   int                  `json:"valid"`
+}
+
+// PVZImportRowResult defines model for PVZImportRowResult.
+type PVZImportRowResult struct {
+	Address string                   `json:"address"`
+	City    string                   `json:"city"`
+	Errors  *[]string                `json:"errors,omitempty"`
+	Line    int                      `json:"line"`
+	Pvz     *PVZ                     `json:"pvz,omitempty"`
+	Status  PVZImportRowResultStatus `json:"status"`
+}
+
+// PVZImportRowResultStatus defines model for PVZImportRowResult.Status.
+type PVZImportRowResultStatus string
+
 // PVZInfo defines model for PVZInfo.
 type PVZInfo struct {
 	Pvz        *PVZ             `json:"pvz,omitempty"`
@@ -82,27 +321,213 @@ type PVZInfo struct {
 // PVZResponse defines model for PVZResponse.
 type PVZResponse = []PVZInfo
 
+// PickupCode Сам код не возвращается, он хранится только в виде хеша и отправляется клиенту
+type PickupCode struct {
+	CreatedAt       time.Time            `json:"createdAt"`
+	ExternalOrderId *string              `json:"externalOrderId,omitempty"`
+	Id              openapi_types.UUID   `json:"id"`
+	ProductIds      []openapi_types.UUID `json:"productIds"`
+	PvzId           openapi_types.UUID   `json:"pvzId"`
+	ReceptionId     openapi_types.UUID   `json:"receptionId"`
+}
+
+// PickupCodeRef Код ищется по товару или по номеру заказа, нужно указать ровно одно поле
+type PickupCodeRef struct {
+	ExternalOrderId *string             `json:"externalOrderId,omitempty"`
+	ProductId       *openapi_types.UUID `json:"productId,omitempty"`
+}
+
+// PickupRequest defines model for PickupRequest.
+type PickupRequest struct {
+	Code            string              `json:"code"`
+	ExternalOrderId *string             `json:"externalOrderId,omitempty"`
+	ProductId       *openapi_types.UUID `json:"productId,omitempty"`
+}
+
 // Product defines model for Product.
 type Product struct {
-	DateTime    *time.Time          `json:"dateTime,omitempty"`
-	Id          *openapi_types.UUID `json:"id,omitempty"`
-	ReceptionId openapi_types.UUID  `json:"receptionId"`
-	Type        ProductType         `json:"type"`
+	Barcode *string `json:"barcode,omitempty"`
+
+	// CellId Ячейка хранения, в которую положили товар
+	CellId *openapi_types.UUID `json:"cellId,omitempty"`
+
+	// Condition Состояние товара при приемке, отличное от ok состояние проверяет модератор
+	Condition       *ProductCondition   `json:"condition,omitempty"`
+	DateTime        *time.Time          `json:"dateTime,omitempty"`
+	ExternalOrderId *string             `json:"externalOrderId,omitempty"`
+	Id              *openapi_types.UUID `json:"id,omitempty"`
+
+	// OriginalOrderId Заказ, по которому товар был выдан клиенту, заполняется для товаров из приемки возвратов
+	OriginalOrderId *string            `json:"originalOrderId,omitempty"`
+	ReceptionId     openapi_types.UUID `json:"receptionId"`
+
+	// ReturnCondition Состояние возвращенного клиентом товара
+	ReturnCondition *ReturnCondition `json:"returnCondition,omitempty"`
+
+	// ScanSeq Порядковый номер сканирования в приемке, растет с каждым добавленным товаром
+	ScanSeq *int64 `json:"scanSeq,omitempty"`
+
+	// State received - товар в открытой приемке, stored - приемка закрыта и товар хранится в ПВЗ, issued - выдан клиенту, returned_to_sender - возвращен отправителю, in_transit - отправлен в другой ПВЗ по перемещению
+	State          ProductState `json:"state"`
+	StateChangedAt *time.Time   `json:"stateChangedAt,omitempty"`
+
+	// Type Код типа товара из справочника /product_types. При добавлении товара также принимается прежнее название (электроника, одежда, обувь)
+	Type ProductType `json:"type"`
+}
+
+// ProductBatchResponse defines model for ProductBatchResponse.
+type ProductBatchResponse struct {
+	Products []Product `json:"products"`
 }
 
-// ProductType defines model for Product.Type.
-type ProductType string
+// ProductCondition Состояние товара при приемке, отличное от ok состояние проверяет модератор
+type ProductCondition string
+
+// ProductConditionInput defines model for ProductConditionInput.
+type ProductConditionInput struct {
+	// Comment Обязателен для состояния, отличного от ok
+	Comment *string `json:"comment,omitempty"`
+
+	// Condition Состояние товара при приемке, отличное от ok состояние проверяет модератор
+	Condition ProductCondition `json:"condition"`
+}
+
+// ProductDeleteReason Причина удаления товара из приемки, undo_last проставляется при удалении последнего товара
+type ProductDeleteReason string
+
+// ProductDeletion defines model for ProductDeletion.
+type ProductDeletion struct {
+	Comment   *string   `json:"comment,omitempty"`
+	DeletedAt time.Time `json:"deletedAt"`
+	Product   Product   `json:"product"`
+
+	// Reason Причина удаления товара из приемки, undo_last проставляется при удалении последнего товара
+	Reason ProductDeleteReason `json:"reason"`
+}
+
+// ProductIDList defines model for ProductIDList.
+type ProductIDList struct {
+	ProductIds []openapi_types.UUID `json:"productIds"`
+}
+
+// ProductInput defines model for ProductInput.
+type ProductInput struct {
+	// Barcode Штрихкод посылки, уникален среди открытых приемок и товаров на складе ПВЗ
+	Barcode *string `json:"barcode,omitempty"`
+
+	// CellId Ячейка хранения ПВЗ, в которую товар кладут сразу при сканировании
+	CellId          *openapi_types.UUID `json:"cellId,omitempty"`
+	ExternalOrderId *string             `json:"externalOrderId,omitempty"`
+
+	// OriginalOrderId Обязателен вместе с returnCondition для приемки возвратов и запрещен для поставки
+	OriginalOrderId *string `json:"originalOrderId,omitempty"`
+
+	// ReturnCondition Состояние возвращенного клиентом товара
+	ReturnCondition *ReturnCondition `json:"returnCondition,omitempty"`
+
+	// Type Код типа товара из справочника /product_types. При добавлении товара также принимается прежнее название (электроника, одежда, обувь)
+	Type ProductType `json:"type"`
+}
+
+// ProductLocation defines model for ProductLocation.
+type ProductLocation struct {
+	// CellCode Код ячейки хранения товара, если товар размещен
+	CellCode        *string            `json:"cellCode,omitempty"`
+	Product         Product            `json:"product"`
+	PvzId           openapi_types.UUID `json:"pvzId"`
+	ReceptionStatus ReceptionStatus    `json:"receptionStatus"`
+}
+
+// ProductState received - товар в открытой приемке, stored - приемка закрыта и товар хранится в ПВЗ, issued - выдан клиенту, returned_to_sender - возвращен отправителю, in_transit - отправлен в другой ПВЗ по перемещению
+type ProductState string
+
+// ProductType Код типа товара из справочника /product_types. При добавлении товара также принимается прежнее название (электроника, одежда, обувь)
+type ProductType = string
+
+// ProductTypeInfo defines model for ProductTypeInfo.
+type ProductTypeInfo struct {
+	Active    bool      `json:"active"`
+	Code      string    `json:"code"`
+	CreatedAt time.Time `json:"createdAt"`
+
+	// LegacyName Название типа, которое использовалось до появления справочника
+	LegacyName *string `json:"legacyName,omitempty"`
+
+	// Names Отображаемые названия по коду языка
+	Names map[string]string `json:"names"`
+
+	// RequiresSignature Выдача товара требует подписи клиента
+	RequiresSignature bool      `json:"requiresSignature"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+}
+
+// ProductTypeInput defines model for ProductTypeInput.
+type ProductTypeInput struct {
+	// Active По умолчанию true
+	Active *bool `json:"active,omitempty"`
+
+	// Code Латинские строчные буквы, цифры и подчеркивание, начинается с буквы, не длиннее 32 символов
+	Code       string            `json:"code"`
+	LegacyName *string           `json:"legacyName,omitempty"`
+	Names      map[string]string `json:"names"`
+
+	// RequiresSignature По умолчанию false
+	RequiresSignature *bool `json:"requiresSignature,omitempty"`
+}
+
+// ProductTypeUpdate defines model for ProductTypeUpdate.
+type ProductTypeUpdate struct {
+	Active            bool              `json:"active"`
+	Names             map[string]string `json:"names"`
+	RequiresSignature bool              `json:"requiresSignature"`
+}
 
 // Reception defines model for Reception.
 type Reception struct {
-	DateTime time.Time           `json:"dateTime"`
-	Id       *openapi_types.UUID `json:"id,omitempty"`
-	PvzId    openapi_types.UUID  `json:"pvzId"`
-	Status   ReceptionStatus     `json:"status"`
+	CloseReason *ReceptionCloseReason `json:"closeReason,omitempty"`
+	ClosedAt    *time.Time            `json:"closedAt,omitempty"`
+
+	// CreatedBy Пользователь, открывший приемку, не заполняется для токенов /dummyLogin
+	CreatedBy *openapi_types.UUID `json:"createdBy,omitempty"`
+	DateTime  time.Time           `json:"dateTime"`
+	Id        *openapi_types.UUID `json:"id,omitempty"`
+
+	// Kind inbound - поставка от отправителя, customer_return - возврат товаров клиентами. В ПВЗ может быть открыто по одной приемке каждого вида
+	Kind  ReceptionKind      `json:"kind"`
+	PvzId openapi_types.UUID `json:"pvzId"`
+
+	// StaleFlaggedAt Время, когда фоновая проверка пометила приемку как зависшую
+	StaleFlaggedAt *time.Time      `json:"staleFlaggedAt,omitempty"`
+	Status         ReceptionStatus `json:"status"`
+
+	// Summary Итоги приемки, считаются при закрытии и удаляются при повторном открытии
+	Summary *ReceptionSummary `json:"summary,omitempty"`
 }
 
-// ReceptionStatus defines model for Reception.Status.
-type ReceptionStatus string
+// ReceptionCloseReason defines model for ReceptionCloseReason.
+type ReceptionCloseReason string
+
+// ReceptionDetails defines model for ReceptionDetails.
+type ReceptionDetails struct {
+	// Products Страница не удаленных товаров приемки в порядке сканирования
+	Products []Product `json:"products"`
+
+	// ProductsTotal Всего не удаленных товаров в приемке
+	ProductsTotal int       `json:"productsTotal"`
+	Reception     Reception `json:"reception"`
+}
+
+// ReceptionDiscrepancy defines model for ReceptionDiscrepancy.
+type ReceptionDiscrepancy struct {
+	// ActualType Код типа товара из справочника /product_types. При добавлении товара также принимается прежнее название (электроника, одежда, обувь)
+	ActualType *ProductType `json:"actualType,omitempty"`
+	Barcode    *string      `json:"barcode,omitempty"`
+
+	// ExpectedType Код типа товара из справочника /product_types. При добавлении товара также принимается прежнее название (электроника, одежда, обувь)
+	ExpectedType *ProductType        `json:"expectedType,omitempty"`
+	Kind         DiscrepancyKind     `json:"kind"`
+	ProductId    *openapi_types.UUID `json:"productId,omitempty"`
+}
 
 // ReceptionInfo defines model for ReceptionInfo.
 type ReceptionInfo struct {
@@ -110,9 +535,121 @@ type ReceptionInfo struct {
 	Reception *Reception `json:"reception,omitempty"`
 }
 
+// ReceptionKind inbound - поставка от отправителя, customer_return - возврат товаров клиентами. В ПВЗ может быть открыто по одной приемке каждого вида
+type ReceptionKind string
+
+// ReceptionReopen defines model for ReceptionReopen.
+type ReceptionReopen struct {
+	PreviousClosedAt time.Time `json:"previousClosedAt"`
+	Reason           string    `json:"reason"`
+	Reception        Reception `json:"reception"`
+	ReopenedAt       time.Time `json:"reopenedAt"`
+
+	// ReopenedBy Идентификатор модератора, отсутствует для токенов /dummyLogin
+	ReopenedBy *openapi_types.UUID `json:"reopenedBy,omitempty"`
+}
+
+// ReceptionStatus defines model for ReceptionStatus.
+type ReceptionStatus string
+
+// ReceptionSummary Итоги приемки, считаются при закрытии и удаляются при повторном открытии
+type ReceptionSummary struct {
+	// DamagedByCondition Количество не удаленных товаров в состоянии, отличном от ok, по состояниям. Отклоненные модератором отметки не учитываются
+	DamagedByCondition *map[string]int `json:"damagedByCondition,omitempty"`
+	DamagedTotal       *int            `json:"damagedTotal,omitempty"`
+
+	// Deletions Сколько товаров было удалено из приемки
+	Deletions int `json:"deletions"`
+
+	// DurationSeconds Время от открытия до закрытия приемки
+	DurationSeconds int64 `json:"durationSeconds"`
+
+	// ProductsByType Количество не удаленных товаров по кодам типов
+	ProductsByType map[string]int `json:"productsByType"`
+	ProductsTotal  int            `json:"productsTotal"`
+}
+
+// ReturnCondition Состояние возвращенного клиентом товара
+type ReturnCondition string
+
+// ReturnsReportRow defines model for ReturnsReportRow.
+type ReturnsReportRow struct {
+	// Condition Состояние возвращенного клиентом товара
+	Condition ReturnCondition    `json:"condition"`
+	Products  int                `json:"products"`
+	PvzId     openapi_types.UUID `json:"pvzId"`
+
+	// Receptions Количество приемок возвратов, в которых есть товары в этом состоянии
+	Receptions int `json:"receptions"`
+}
+
+// StorageCell defines model for StorageCell.
+type StorageCell struct {
+	// Active В отключенную ячейку нельзя класть товары
+	Active bool `json:"active"`
+
+	// Capacity Сколько товаров помещается в ячейку
+	Capacity int `json:"capacity"`
+
+	// Code Код ячейки на стеллаже, например A-01-03
+	Code      string             `json:"code"`
+	CreatedAt time.Time          `json:"createdAt"`
+	Id        openapi_types.UUID `json:"id"`
+
+	// Occupied Сколько товаров в состояниях received и stored лежит в ячейке
+	Occupied int                `json:"occupied"`
+	PvzId    openapi_types.UUID `json:"pvzId"`
+}
+
+// StorageCellInput defines model for StorageCellInput.
+type StorageCellInput struct {
+	Capacity int `json:"capacity"`
+
+	// Code Латинские буквы, цифры, точки и дефисы, не длиннее 32 символов
+	Code string `json:"code"`
+}
+
+// StorageCellUpdate defines model for StorageCellUpdate.
+type StorageCellUpdate struct {
+	Active bool `json:"active"`
+
+	// Capacity Не может быть меньше числа товаров в ячейке
+	Capacity int `json:"capacity"`
+}
+
 // Token defines model for Token.
 type Token = string
 
+// Transfer defines model for Transfer.
+type Transfer struct {
+	ArrivedAt        *time.Time           `json:"arrivedAt,omitempty"`
+	ArrivedBy        *openapi_types.UUID  `json:"arrivedBy,omitempty"`
+	CreatedAt        time.Time            `json:"createdAt"`
+	CreatedBy        *openapi_types.UUID  `json:"createdBy,omitempty"`
+	DestinationPvzId openapi_types.UUID   `json:"destinationPvzId"`
+	DispatchedAt     *time.Time           `json:"dispatchedAt,omitempty"`
+	DispatchedBy     *openapi_types.UUID  `json:"dispatchedBy,omitempty"`
+	Id               openapi_types.UUID   `json:"id"`
+	ProductIds       []openapi_types.UUID `json:"productIds"`
+
+	// ReceptionId Приемка ПВЗ назначения, под которой хранятся прибывшие товары, receptionId самих товаров остается исходным
+	ReceptionId *openapi_types.UUID `json:"receptionId,omitempty"`
+	SourcePvzId openapi_types.UUID  `json:"sourcePvzId"`
+
+	// Status created - перемещение создано, dispatched - товары отправлены из исходного ПВЗ, arrived - товары приняты в ПВЗ назначения
+	Status TransferStatus `json:"status"`
+}
+
+// TransferInput defines model for TransferInput.
+type TransferInput struct {
+	DestinationPvzId openapi_types.UUID   `json:"destinationPvzId"`
+	ProductIds       []openapi_types.UUID `json:"productIds"`
+	SourcePvzId      openapi_types.UUID   `json:"sourcePvzId"`
+}
+
+// TransferStatus created - перемещение создано, dispatched - товары отправлены из исходного ПВЗ, arrived - товары приняты в ПВЗ назначения
+type TransferStatus string
+
 // User defines model for User.
 type User struct {
 	Email openapi_types.Email `json:"email"`
@@ -123,6 +660,42 @@ type User struct {
 // UserRole defines model for User.Role.
 type UserRole string
 
+// VolumeGroup defines model for VolumeGroup.
+type VolumeGroup string
+
+// VolumePeriod defines model for VolumePeriod.
+type VolumePeriod string
+
+// VolumeReportRow Поля группировки заполняются только для запрошенных группировок
+type VolumeReportRow struct {
+	City *string `json:"city,omitempty"`
+
+	// PeriodStart Первый день периода по UTC, неделя начинается с понедельника
+	PeriodStart *openapi_types.Date `json:"periodStart,omitempty"`
+	Products    int64               `json:"products"`
+	PvzId       *openapi_types.UUID `json:"pvzId,omitempty"`
+
+	// Type Код типа товара
+	Type *string `json:"type,omitempty"`
+}
+
+// IdempotencyKey defines model for IdempotencyKey.
+type IdempotencyKey = string
+
+// GetDamageReportsParams defines parameters for GetDamageReports.
+type GetDamageReportsParams struct {
+	Status *DamageReportStatus `form:"status,omitempty" json:"status,omitempty"`
+	PvzId  *openapi_types.UUID `form:"pvzId,omitempty" json:"pvzId,omitempty"`
+	Page   *int                `form:"page,omitempty" json:"page,omitempty"`
+	Limit  *int                `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// PostDamageReportsReportIdDecisionParams defines parameters for PostDamageReportsReportIdDecision.
+type PostDamageReportsReportIdDecisionParams struct {
+	// IdempotencyKey Ключ повтора запроса. Повтор с тем же ключом и телом в течение IDEMPOTENCY_KEY_TTL возвращает сохраненный ответ с заголовком Idempotent-Replayed, повтор с другим телом или пока первый запрос еще выполняется возвращает 409. Тело запроса с ключом больше допустимого для вложений и импорта возвращает 413
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
 // PostDummyLoginJSONBody defines parameters for PostDummyLogin.
 type PostDummyLoginJSONBody struct {
 	Role PostDummyLoginJSONBodyRole `json:"role"`
@@ -131,20 +704,107 @@ type PostDummyLoginJSONBody struct {
 // PostDummyLoginJSONBodyRole defines parameters for PostDummyLogin.
 type PostDummyLoginJSONBodyRole string
 
+// GetExportsProductsParams defines parameters for GetExportsProducts.
+type GetExportsProductsParams struct {
+	// Format По умолчанию csv
+	Format *ExportFormat `form:"format,omitempty" json:"format,omitempty"`
+
+	// Columns Колонки через запятую в нужном порядке, по умолчанию все: productId, dateTime, type, barcode, externalOrderId, state, condition, returnCondition, originalOrderId, receptionId, receptionDateTime, receptionKind, receptionStatus, pvzId, city, address
+	Columns *[]string `form:"columns,omitempty" json:"columns,omitempty"`
+
+	// StartDate Начало периода по дате приемки
+	StartDate *time.Time `form:"startDate,omitempty" json:"startDate,omitempty"`
+
+	// EndDate Конец периода по дате приемки
+	EndDate *time.Time          `form:"endDate,omitempty" json:"endDate,omitempty"`
+	PvzId   *openapi_types.UUID `form:"pvzId,omitempty" json:"pvzId,omitempty"`
+	City    *string             `form:"city,omitempty" json:"city,omitempty"`
+}
+
+// GetExportsReceptionsParams defines parameters for GetExportsReceptions.
+type GetExportsReceptionsParams struct {
+	// Format По умолчанию csv
+	Format *ExportFormat `form:"format,omitempty" json:"format,omitempty"`
+
+	// Columns Колонки через запятую в нужном порядке, по умолчанию все: receptionId, dateTime, pvzId, city, address, kind, status, closedAt, closeReason, createdBy, products
+	Columns *[]string `form:"columns,omitempty" json:"columns,omitempty"`
+
+	// StartDate Начало периода по дате приемки
+	StartDate *time.Time `form:"startDate,omitempty" json:"startDate,omitempty"`
+
+	// EndDate Конец периода по дате приемки
+	EndDate *time.Time          `form:"endDate,omitempty" json:"endDate,omitempty"`
+	PvzId   *openapi_types.UUID `form:"pvzId,omitempty" json:"pvzId,omitempty"`
+	City    *string             `form:"city,omitempty" json:"city,omitempty"`
+}
+
 // PostLoginJSONBody defines parameters for PostLogin.
 type PostLoginJSONBody struct {
 	Email    openapi_types.Email `json:"email"`
 	Password string              `json:"password"`
 }
 
+// GetProductTypesParams defines parameters for GetProductTypes.
+type GetProductTypesParams struct {
+	// IncludeInactive Вернуть также отключенные типы, по умолчанию false
+	IncludeInactive *bool `form:"includeInactive,omitempty" json:"includeInactive,omitempty"`
+}
+
+// PostProductTypesParams defines parameters for PostProductTypes.
+type PostProductTypesParams struct {
+	// IdempotencyKey Ключ повтора запроса. Повтор с тем же ключом и телом в течение IDEMPOTENCY_KEY_TTL возвращает сохраненный ответ с заголовком Idempotent-Replayed, повтор с другим телом или пока первый запрос еще выполняется возвращает 409. Тело запроса с ключом больше допустимого для вложений и импорта возвращает 413
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// GetProductsParams defines parameters for GetProducts.
+type GetProductsParams struct {
+	Barcode string `form:"barcode" json:"barcode"`
+}
+
 // PostProductsJSONBody defines parameters for PostProducts.
 type PostProductsJSONBody struct {
-	PvzId openapi_types.UUID       `json:"pvzId"`
-	Type  PostProductsJSONBodyType `json:"type"`
+	Barcode         *string             `json:"barcode,omitempty"`
+	CellId          *openapi_types.UUID `json:"cellId,omitempty"`
+	ExternalOrderId *string             `json:"externalOrderId,omitempty"`
+	OriginalOrderId *string             `json:"originalOrderId,omitempty"`
+	PvzId           openapi_types.UUID  `json:"pvzId"`
+
+	// ReceptionKind inbound - поставка от отправителя, customer_return - возврат товаров клиентами. В ПВЗ может быть открыто по одной приемке каждого вида
+	ReceptionKind *ReceptionKind `json:"receptionKind,omitempty"`
+
+	// ReturnCondition Состояние возвращенного клиентом товара
+	ReturnCondition *ReturnCondition `json:"returnCondition,omitempty"`
+
+	// Type Код типа товара из справочника /product_types. При добавлении товара также принимается прежнее название (электроника, одежда, обувь)
+	Type ProductType `json:"type"`
 }
 
-// PostProductsJSONBodyType defines parameters for PostProducts.
-type PostProductsJSONBodyType string
+// PostProductsParams defines parameters for PostProducts.
+type PostProductsParams struct {
+	// IdempotencyKey Ключ повтора запроса. Повтор с тем же ключом и телом в течение IDEMPOTENCY_KEY_TTL возвращает сохраненный ответ с заголовком Idempotent-Replayed, повтор с другим телом или пока первый запрос еще выполняется возвращает 409. Тело запроса с ключом больше допустимого для вложений и импорта возвращает 413
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// PostProductsBatchJSONBody defines parameters for PostProductsBatch.
+type PostProductsBatchJSONBody struct {
+	Products []ProductInput     `json:"products"`
+	PvzId    openapi_types.UUID `json:"pvzId"`
+
+	// ReceptionKind inbound - поставка от отправителя, customer_return - возврат товаров клиентами. В ПВЗ может быть открыто по одной приемке каждого вида
+	ReceptionKind *ReceptionKind `json:"receptionKind,omitempty"`
+}
+
+// PostProductsBatchParams defines parameters for PostProductsBatch.
+type PostProductsBatchParams struct {
+	// IdempotencyKey Ключ повтора запроса. Повтор с тем же ключом и телом в течение IDEMPOTENCY_KEY_TTL возвращает сохраненный ответ с заголовком Idempotent-Replayed, повтор с другим телом или пока первый запрос еще выполняется возвращает 409. Тело запроса с ключом больше допустимого для вложений и импорта возвращает 413
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// PostProductsProductIdAttachmentsParams defines parameters for PostProductsProductIdAttachments.
+type PostProductsProductIdAttachmentsParams struct {
+	// IdempotencyKey Ключ повтора запроса. Повтор с тем же ключом и телом в течение IDEMPOTENCY_KEY_TTL возвращает сохраненный ответ с заголовком Idempotent-Replayed, повтор с другим телом или пока первый запрос еще выполняется возвращает 409. Тело запроса с ключом больше допустимого для вложений и импорта возвращает 413
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
 
 // GetPvzParams defines parameters for GetPvz.
 type GetPvzParams struct {
@@ -161,11 +821,148 @@ type GetPvzParams struct {
 	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
 }
 
+// PostPvzParams defines parameters for PostPvz.
+type PostPvzParams struct {
+	// IdempotencyKey Ключ повтора запроса. Повтор с тем же ключом и телом в течение IDEMPOTENCY_KEY_TTL возвращает сохраненный ответ с заголовком Idempotent-Replayed, повтор с другим телом или пока первый запрос еще выполняется возвращает 409. Тело запроса с ключом больше допустимого для вложений и импорта возвращает 413
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// PostPvzImportParams defines parameters for PostPvzImport.
+type PostPvzImportParams struct {
+	// DryRun Только проверить файл, ничего не сохраняя
+	DryRun *bool `form:"dryRun,omitempty" json:"dryRun,omitempty"`
+
+	// Format Формат файла, по умолчанию определяется по Content-Type
+	Format *PVZImportFormat `form:"format,omitempty" json:"format,omitempty"`
+
+	// IdempotencyKey Ключ повтора запроса. Повтор с тем же ключом и телом в течение IDEMPOTENCY_KEY_TTL возвращает сохраненный ответ с заголовком Idempotent-Replayed, повтор с другим телом или пока первый запрос еще выполняется возвращает 409. Тело запроса с ключом больше допустимого для вложений и импорта возвращает 413
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// PostPvzPvzIdCellsParams defines parameters for PostPvzPvzIdCells.
+type PostPvzPvzIdCellsParams struct {
+	// IdempotencyKey Ключ повтора запроса. Повтор с тем же ключом и телом в течение IDEMPOTENCY_KEY_TTL возвращает сохраненный ответ с заголовком Idempotent-Replayed, повтор с другим телом или пока первый запрос еще выполняется возвращает 409. Тело запроса с ключом больше допустимого для вложений и импорта возвращает 413
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// GetPvzPvzIdCellsSuggestParams defines parameters for GetPvzPvzIdCellsSuggest.
+type GetPvzPvzIdCellsSuggestParams struct {
+	// Count Сколько товаров нужно разместить, по умолчанию 1
+	Count *int `form:"count,omitempty" json:"count,omitempty"`
+}
+
+// PostPvzPvzIdCellsCellIdProductsParams defines parameters for PostPvzPvzIdCellsCellIdProducts.
+type PostPvzPvzIdCellsCellIdProductsParams struct {
+	// IdempotencyKey Ключ повтора запроса. Повтор с тем же ключом и телом в течение IDEMPOTENCY_KEY_TTL возвращает сохраненный ответ с заголовком Idempotent-Replayed, повтор с другим телом или пока первый запрос еще выполняется возвращает 409. Тело запроса с ключом больше допустимого для вложений и импорта возвращает 413
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// PostPvzPvzIdCloseLastReceptionParams defines parameters for PostPvzPvzIdCloseLastReception.
+type PostPvzPvzIdCloseLastReceptionParams struct {
+	// Kind Вид приемки, по умолчанию inbound
+	Kind *ReceptionKind `form:"kind,omitempty" json:"kind,omitempty"`
+
+	// IdempotencyKey Ключ повтора запроса. Повтор с тем же ключом и телом в течение IDEMPOTENCY_KEY_TTL возвращает сохраненный ответ с заголовком Idempotent-Replayed, повтор с другим телом или пока первый запрос еще выполняется возвращает 409. Тело запроса с ключом больше допустимого для вложений и импорта возвращает 413
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// PostPvzPvzIdDeleteLastProductParams defines parameters for PostPvzPvzIdDeleteLastProduct.
+type PostPvzPvzIdDeleteLastProductParams struct {
+	// Kind Вид приемки, по умолчанию inbound
+	Kind *ReceptionKind `form:"kind,omitempty" json:"kind,omitempty"`
+
+	// IdempotencyKey Ключ повтора запроса. Повтор с тем же ключом и телом в течение IDEMPOTENCY_KEY_TTL возвращает сохраненный ответ с заголовком Idempotent-Replayed, повтор с другим телом или пока первый запрос еще выполняется возвращает 409. Тело запроса с ключом больше допустимого для вложений и импорта возвращает 413
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// GetPvzPvzIdInventoryParams defines parameters for GetPvzPvzIdInventory.
+type GetPvzPvzIdInventoryParams struct {
+	State *ProductState `form:"state,omitempty" json:"state,omitempty"`
+	Page  *int          `form:"page,omitempty" json:"page,omitempty"`
+	Limit *int          `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// PostPvzPvzIdPickupParams defines parameters for PostPvzPvzIdPickup.
+type PostPvzPvzIdPickupParams struct {
+	// IdempotencyKey Ключ повтора запроса. Повтор с тем же ключом и телом в течение IDEMPOTENCY_KEY_TTL возвращает сохраненный ответ с заголовком Idempotent-Replayed, повтор с другим телом или пока первый запрос еще выполняется возвращает 409. Тело запроса с ключом больше допустимого для вложений и импорта возвращает 413
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// PostPvzPvzIdPickupCodesParams defines parameters for PostPvzPvzIdPickupCodes.
+type PostPvzPvzIdPickupCodesParams struct {
+	// IdempotencyKey Ключ повтора запроса. Повтор с тем же ключом и телом в течение IDEMPOTENCY_KEY_TTL возвращает сохраненный ответ с заголовком Idempotent-Replayed, повтор с другим телом или пока первый запрос еще выполняется возвращает 409. Тело запроса с ключом больше допустимого для вложений и импорта возвращает 413
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// PostPvzPvzIdReturnProductsParams defines parameters for PostPvzPvzIdReturnProducts.
+type PostPvzPvzIdReturnProductsParams struct {
+	// IdempotencyKey Ключ повтора запроса. Повтор с тем же ключом и телом в течение IDEMPOTENCY_KEY_TTL возвращает сохраненный ответ с заголовком Idempotent-Replayed, повтор с другим телом или пока первый запрос еще выполняется возвращает 409. Тело запроса с ключом больше допустимого для вложений и импорта возвращает 413
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// GetReceptionsParams defines parameters for GetReceptions.
+type GetReceptionsParams struct {
+	PvzId     *openapi_types.UUID `form:"pvzId,omitempty" json:"pvzId,omitempty"`
+	Status    *ReceptionStatus    `form:"status,omitempty" json:"status,omitempty"`
+	Kind      *ReceptionKind      `form:"kind,omitempty" json:"kind,omitempty"`
+	CreatedBy *openapi_types.UUID `form:"createdBy,omitempty" json:"createdBy,omitempty"`
+
+	// StartDate Приемки, открытые не раньше
+	StartDate *time.Time `form:"startDate,omitempty" json:"startDate,omitempty"`
+
+	// EndDate Приемки, открытые не позже
+	EndDate *time.Time `form:"endDate,omitempty" json:"endDate,omitempty"`
+	Page    *int       `form:"page,omitempty" json:"page,omitempty"`
+	Limit   *int       `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
 // PostReceptionsJSONBody defines parameters for PostReceptions.
 type PostReceptionsJSONBody struct {
+	// Kind inbound - поставка от отправителя, customer_return - возврат товаров клиентами. В ПВЗ может быть открыто по одной приемке каждого вида
+	Kind  *ReceptionKind     `json:"kind,omitempty"`
 	PvzId openapi_types.UUID `json:"pvzId"`
 }
 
+// PostReceptionsParams defines parameters for PostReceptions.
+type PostReceptionsParams struct {
+	// IdempotencyKey Ключ повтора запроса. Повтор с тем же ключом и телом в течение IDEMPOTENCY_KEY_TTL возвращает сохраненный ответ с заголовком Idempotent-Replayed, повтор с другим телом или пока первый запрос еще выполняется возвращает 409. Тело запроса с ключом больше допустимого для вложений и импорта возвращает 413
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// GetReceptionsReceptionIdParams defines parameters for GetReceptionsReceptionId.
+type GetReceptionsReceptionIdParams struct {
+	Page  *int `form:"page,omitempty" json:"page,omitempty"`
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// AfterSeq Вернуть только товары с номером сканирования больше указанного, позволяет сканеру продолжить с последнего полученного товара
+	AfterSeq *int64 `form:"afterSeq,omitempty" json:"afterSeq,omitempty"`
+}
+
+// PostReceptionsReceptionIdAttachmentsParams defines parameters for PostReceptionsReceptionIdAttachments.
+type PostReceptionsReceptionIdAttachmentsParams struct {
+	// IdempotencyKey Ключ повтора запроса. Повтор с тем же ключом и телом в течение IDEMPOTENCY_KEY_TTL возвращает сохраненный ответ с заголовком Idempotent-Replayed, повтор с другим телом или пока первый запрос еще выполняется возвращает 409. Тело запроса с ключом больше допустимого для вложений и импорта возвращает 413
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// DeleteReceptionsReceptionIdProductsProductIdJSONBody defines parameters for DeleteReceptionsReceptionIdProductsProductId.
+type DeleteReceptionsReceptionIdProductsProductIdJSONBody struct {
+	Comment *string `json:"comment,omitempty"`
+
+	// Reason Причина удаления товара из приемки, undo_last проставляется при удалении последнего товара
+	Reason ProductDeleteReason `json:"reason"`
+}
+
+// PostReceptionsReceptionIdReopenJSONBody defines parameters for PostReceptionsReceptionIdReopen.
+type PostReceptionsReceptionIdReopenJSONBody struct {
+	Reason string `json:"reason"`
+}
+
+// PostReceptionsReceptionIdReopenParams defines parameters for PostReceptionsReceptionIdReopen.
+type PostReceptionsReceptionIdReopenParams struct {
+	// IdempotencyKey Ключ повтора запроса. Повтор с тем же ключом и телом в течение IDEMPOTENCY_KEY_TTL возвращает сохраненный ответ с заголовком Idempotent-Replayed, повтор с другим телом или пока первый запрос еще выполняется возвращает 409. Тело запроса с ключом больше допустимого для вложений и импорта возвращает 413
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
 // PostRegisterJSONBody defines parameters for PostRegister.
 type PostRegisterJSONBody struct {
 	Email    openapi_types.Email      `json:"email"`
@@ -176,20 +973,141 @@ type PostRegisterJSONBody struct {
 // PostRegisterJSONBodyRole defines parameters for PostRegister.
 type PostRegisterJSONBodyRole string
 
+// GetReportsEmployeesParams defines parameters for GetReportsEmployees.
+type GetReportsEmployeesParams struct {
+	// StartDate Начало периода по дате открытия приемки
+	StartDate *time.Time `form:"startDate,omitempty" json:"startDate,omitempty"`
+
+	// EndDate Конец периода по дате открытия приемки
+	EndDate *time.Time          `form:"endDate,omitempty" json:"endDate,omitempty"`
+	PvzId   *openapi_types.UUID `form:"pvzId,omitempty" json:"pvzId,omitempty"`
+	UserId  *openapi_types.UUID `form:"userId,omitempty" json:"userId,omitempty"`
+}
+
+// GetReportsEmployeesMeParams defines parameters for GetReportsEmployeesMe.
+type GetReportsEmployeesMeParams struct {
+	// StartDate Начало периода по дате открытия приемки
+	StartDate *time.Time `form:"startDate,omitempty" json:"startDate,omitempty"`
+
+	// EndDate Конец периода по дате открытия приемки
+	EndDate *time.Time          `form:"endDate,omitempty" json:"endDate,omitempty"`
+	PvzId   *openapi_types.UUID `form:"pvzId,omitempty" json:"pvzId,omitempty"`
+}
+
+// GetReportsReturnsParams defines parameters for GetReportsReturns.
+type GetReportsReturnsParams struct {
+	StartDate *time.Time          `form:"startDate,omitempty" json:"startDate,omitempty"`
+	EndDate   *time.Time          `form:"endDate,omitempty" json:"endDate,omitempty"`
+	PvzId     *openapi_types.UUID `form:"pvzId,omitempty" json:"pvzId,omitempty"`
+}
+
+// GetReportsVolumeParams defines parameters for GetReportsVolume.
+type GetReportsVolumeParams struct {
+	// StartDate Первый день периода включительно
+	StartDate *openapi_types.Date `form:"startDate,omitempty" json:"startDate,omitempty"`
+
+	// EndDate Последний день периода включительно
+	EndDate *openapi_types.Date `form:"endDate,omitempty" json:"endDate,omitempty"`
+
+	// GroupBy Группировки через запятую, без группировок и периода возвращается одна строка с итогом
+	GroupBy *[]VolumeGroup      `form:"groupBy,omitempty" json:"groupBy,omitempty"`
+	Period  *VolumePeriod       `form:"period,omitempty" json:"period,omitempty"`
+	City    *string             `form:"city,omitempty" json:"city,omitempty"`
+	PvzId   *openapi_types.UUID `form:"pvzId,omitempty" json:"pvzId,omitempty"`
+}
+
+// GetTransfersParams defines parameters for GetTransfers.
+type GetTransfersParams struct {
+	// PvzId Перемещения, в которых ПВЗ исходный или назначения
+	PvzId  *openapi_types.UUID `form:"pvzId,omitempty" json:"pvzId,omitempty"`
+	Status *TransferStatus     `form:"status,omitempty" json:"status,omitempty"`
+	Page   *int                `form:"page,omitempty" json:"page,omitempty"`
+	Limit  *int                `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// PostTransfersParams defines parameters for PostTransfers.
+type PostTransfersParams struct {
+	// IdempotencyKey Ключ повтора запроса. Повтор с тем же ключом и телом в течение IDEMPOTENCY_KEY_TTL возвращает сохраненный ответ с заголовком Idempotent-Replayed, повтор с другим телом или пока первый запрос еще выполняется возвращает 409. Тело запроса с ключом больше допустимого для вложений и импорта возвращает 413
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// PostTransfersTransferIdArriveParams defines parameters for PostTransfersTransferIdArrive.
+type PostTransfersTransferIdArriveParams struct {
+	// IdempotencyKey Ключ повтора запроса. Повтор с тем же ключом и телом в течение IDEMPOTENCY_KEY_TTL возвращает сохраненный ответ с заголовком Idempotent-Replayed, повтор с другим телом или пока первый запрос еще выполняется возвращает 409. Тело запроса с ключом больше допустимого для вложений и импорта возвращает 413
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// PostTransfersTransferIdDispatchParams defines parameters for PostTransfersTransferIdDispatch.
+type PostTransfersTransferIdDispatchParams struct {
+	// IdempotencyKey Ключ повтора запроса. Повтор с тем же ключом и телом в течение IDEMPOTENCY_KEY_TTL возвращает сохраненный ответ с заголовком Idempotent-Replayed, повтор с другим телом или пока первый запрос еще выполняется возвращает 409. Тело запроса с ключом больше допустимого для вложений и импорта возвращает 413
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// PostDamageReportsReportIdDecisionJSONRequestBody defines body for PostDamageReportsReportIdDecision for application/json ContentType.
+type PostDamageReportsReportIdDecisionJSONRequestBody = DamageReportDecision
+
 // PostDummyLoginJSONRequestBody defines body for PostDummyLogin for application/json ContentType.
 type PostDummyLoginJSONRequestBody PostDummyLoginJSONBody
 
 // PostLoginJSONRequestBody defines body for PostLogin for application/json ContentType.
 type PostLoginJSONRequestBody PostLoginJSONBody
 
+// PostProductTypesJSONRequestBody defines body for PostProductTypes for application/json ContentType.
+type PostProductTypesJSONRequestBody = ProductTypeInput
+
+// PutProductTypesCodeJSONRequestBody defines body for PutProductTypesCode for application/json ContentType.
+type PutProductTypesCodeJSONRequestBody = ProductTypeUpdate
+
 // PostProductsJSONRequestBody defines body for PostProducts for application/json ContentType.
 type PostProductsJSONRequestBody PostProductsJSONBody
 
+// PostProductsBatchJSONRequestBody defines body for PostProductsBatch for application/json ContentType.
+type PostProductsBatchJSONRequestBody PostProductsBatchJSONBody
+
+// PostProductsProductIdAttachmentsMultipartRequestBody defines body for PostProductsProductIdAttachments for multipart/form-data ContentType.
+type PostProductsProductIdAttachmentsMultipartRequestBody = AttachmentUpload
+
+// PutProductsProductIdConditionJSONRequestBody defines body for PutProductsProductIdCondition for application/json ContentType.
+type PutProductsProductIdConditionJSONRequestBody = ProductConditionInput
+
 // PostPvzJSONRequestBody defines body for PostPvz for application/json ContentType.
 type PostPvzJSONRequestBody = PVZ
 
+// PostPvzPvzIdCellsJSONRequestBody defines body for PostPvzPvzIdCells for application/json ContentType.
+type PostPvzPvzIdCellsJSONRequestBody = StorageCellInput
+
+// PutPvzPvzIdCellsCellIdJSONRequestBody defines body for PutPvzPvzIdCellsCellId for application/json ContentType.
+type PutPvzPvzIdCellsCellIdJSONRequestBody = StorageCellUpdate
+
+// PostPvzPvzIdCellsCellIdProductsJSONRequestBody defines body for PostPvzPvzIdCellsCellIdProducts for application/json ContentType.
+type PostPvzPvzIdCellsCellIdProductsJSONRequestBody = ProductIDList
+
+// PostPvzPvzIdPickupJSONRequestBody defines body for PostPvzPvzIdPickup for application/json ContentType.
+type PostPvzPvzIdPickupJSONRequestBody = PickupRequest
+
+// PostPvzPvzIdPickupCodesJSONRequestBody defines body for PostPvzPvzIdPickupCodes for application/json ContentType.
+type PostPvzPvzIdPickupCodesJSONRequestBody = PickupCodeRef
+
+// PostPvzPvzIdReturnProductsJSONRequestBody defines body for PostPvzPvzIdReturnProducts for application/json ContentType.
+type PostPvzPvzIdReturnProductsJSONRequestBody = ProductIDList
+
 // PostReceptionsJSONRequestBody defines body for PostReceptions for application/json ContentType.
 type PostReceptionsJSONRequestBody PostReceptionsJSONBody
 
+// PostReceptionsReceptionIdAttachmentsMultipartRequestBody defines body for PostReceptionsReceptionIdAttachments for multipart/form-data ContentType.
+type PostReceptionsReceptionIdAttachmentsMultipartRequestBody = AttachmentUpload
+
+// PutReceptionsReceptionIdManifestJSONRequestBody defines body for PutReceptionsReceptionIdManifest for application/json ContentType.
+type PutReceptionsReceptionIdManifestJSONRequestBody = ManifestUpload
+
+// DeleteReceptionsReceptionIdProductsProductIdJSONRequestBody defines body for DeleteReceptionsReceptionIdProductsProductId for application/json ContentType.
+type DeleteReceptionsReceptionIdProductsProductIdJSONRequestBody DeleteReceptionsReceptionIdProductsProductIdJSONBody
+
+// PostReceptionsReceptionIdReopenJSONRequestBody defines body for PostReceptionsReceptionIdReopen for application/json ContentType.
+type PostReceptionsReceptionIdReopenJSONRequestBody PostReceptionsReceptionIdReopenJSONBody
+
 // PostRegisterJSONRequestBody defines body for PostRegister for application/json ContentType.
 type PostRegisterJSONRequestBody PostRegisterJSONBody
+
+// PostTransfersJSONRequestBody defines body for PostTransfers for application/json ContentType.
+type PostTransfersJSONRequestBody = TransferInput
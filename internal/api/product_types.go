@@ -0,0 +1,9 @@
+package api
+
+// Codes of the product types seeded by the product_types migration.
+// The catalog can be extended at runtime, so these are not the only valid types
+const (
+	ProductTypeElectronics ProductType = "electronics"
+	ProductTypeClothes     ProductType = "clothes"
+	ProductTypeShoes       ProductType = "shoes"
+)
@@ -10,4 +10,89 @@ var (
 	ErrNoReceptionsInProgress = errors.New("no receptions in progress")
 	ErrNoProductsInReception  = errors.New("no products in this reception")
 	ErrReceptionNotClosed     = errors.New("there is reception in progress")
+	ErrReceptionNotFound      = errors.New("reception not found")
+	ErrReceptionClosed        = errors.New("reception is already closed")
+	ErrProductNotFound        = errors.New("product not found")
+	ErrReopenWindowExpired    = errors.New("reception was closed too long ago to reopen")
+	ErrNewerReceptionExists   = errors.New("pvz already has a newer reception")
+	ErrInvalidReopenReason    = errors.New("reopen reason is required")
+	ErrUnknownStaleAction     = errors.New("unknown stale reception action")
+	ErrInvalidDeleteReason    = errors.New("invalid delete reason")
+	ErrDeleteCommentRequired  = errors.New("comment is required for reason other")
+	ErrDeleteCommentTooLong   = errors.New("delete comment is too long")
+	ErrEmptyProductBatch      = errors.New("product batch is empty")
+	ErrProductBatchTooLarge   = errors.New("product batch is too large")
+	ErrInvalidProductType     = errors.New("invalid product type")
+	ErrInvalidBarcode         = errors.New("invalid barcode")
+	ErrInvalidExternalOrderID = errors.New("invalid external order id")
+	ErrDuplicateBarcode       = errors.New("product with this barcode is already scanned")
+	ErrProductInOpenReception = errors.New("product is in a reception in progress")
+	ErrInvalidProductState    = errors.New("product is not in a suitable state")
+
+	ErrInvalidReceptionKind   = errors.New("invalid reception kind")
+	ErrInvalidReturnCondition = errors.New("return condition is required for customer returns")
+	ErrInvalidOriginalOrderID = errors.New("original order id is required for customer returns")
+	ErrUnexpectedReturnFields = errors.New("return condition and original order id are allowed only for customer returns")
+	ErrInvalidReportPeriod    = errors.New("startDate must not be after endDate")
+
+	ErrProductTypeNotFound    = errors.New("product type not found")
+	ErrProductTypeExists      = errors.New("product type with this code or legacy name already exists")
+	ErrProductTypeInUse       = errors.New("product type is used by products, deactivate it instead")
+	ErrInvalidProductTypeCode = errors.New("product type code must be lowercase latin letters, digits or underscores, start with a letter and be at most 32 characters")
+	ErrProductTypeNamesEmpty  = errors.New("product type needs at least one display name and none of them can be blank")
+
+	ErrInvalidManifest           = errors.New("invalid manifest")
+	ErrManifestTooLarge          = errors.New("manifest is too large")
+	ErrManifestNotFound          = errors.New("reception has no manifest")
+	ErrDiscrepancyReportNotReady = errors.New("discrepancy report is created when the reception is closed")
+
+	ErrStorageCellNotFound        = errors.New("storage cell not found")
+	ErrStorageCellExists          = errors.New("storage cell with this code already exists in the pvz")
+	ErrInvalidStorageCellCode     = errors.New("storage cell code must be latin letters, digits, dots or hyphens and at most 32 characters")
+	ErrInvalidStorageCellCapacity = errors.New("storage cell capacity must be positive")
+	ErrStorageCellFull            = errors.New("not enough free space in the storage cell")
+	ErrStorageCellInactive        = errors.New("storage cell is disabled")
+	ErrStorageCellNotEmpty        = errors.New("storage cell has products")
+	ErrCapacityBelowOccupied      = errors.New("capacity can't be less than the number of products in the cell")
+	ErrNoFreeStorageCell          = errors.New("no storage cell with enough free space")
+
+	ErrTransferNotFound      = errors.New("transfer not found")
+	ErrSameTransferPVZ       = errors.New("source and destination pvz of a transfer must differ")
+	ErrInvalidTransferStatus = errors.New("transfer is not in the required status")
+	ErrProductInTransfer     = errors.New("product is already in another transfer")
+
+	ErrInvalidPickupCodeRef       = errors.New("either productId or externalOrderId is required")
+	ErrPickupCodeNotFound         = errors.New("no active pickup code")
+	ErrInvalidPickupCode          = errors.New("wrong pickup code")
+	ErrPickupCodeAttemptsExceeded = errors.New("pickup code attempts exceeded, ask a moderator for a new code")
+
+	ErrInvalidIdempotencyKey    = errors.New("idempotency key must be 1 to 255 printable ascii characters")
+	ErrIdempotencyKeyReused     = errors.New("idempotency key was already used with a different request")
+	ErrIdempotencyKeyInProgress = errors.New("request with this idempotency key is still being processed")
+
+	ErrAttachmentNotFound        = errors.New("attachment not found")
+	ErrEmptyAttachment           = errors.New("attachment file is empty")
+	ErrAttachmentTooLarge        = errors.New("attachment file is too large")
+	ErrUnsupportedAttachmentType = errors.New("attachment must be a jpeg, png or webp image or a pdf document")
+	ErrBlobNotFound              = errors.New("blob not found")
+	ErrBlobChecksumMismatch      = errors.New("blob checksum mismatch")
+
+	ErrInvalidProductCondition = errors.New("product condition must be ok, damaged, opened or wet")
+	ErrDamageCommentRequired   = errors.New("a comment of at most 500 characters is required for a damaged product")
+	ErrDamageEvidenceRequired  = errors.New("attach a photo or a document to the product before flagging it")
+	ErrDamageReportNotFound    = errors.New("damage report not found")
+	ErrDamageReportDecided     = errors.New("damage report is already reviewed")
+	ErrInvalidDamageDecision   = errors.New("decision must be accepted or rejected")
+	ErrReviewCommentTooLong    = errors.New("review comment must be at most 500 characters")
+
+	ErrInvalidVolumeGroup  = errors.New("groupBy must list city, pvz or type")
+	ErrInvalidVolumePeriod = errors.New("period must be day, week or month")
+
+	ErrUnsupportedExportFormat = errors.New("export format must be csv or xlsx")
+	ErrInvalidExportColumn     = errors.New("unknown export column")
+
+	ErrPVZNotFound       = errors.New("pvz not found")
+	ErrPVZAddressExists  = errors.New("pvz with this address already exists")
+	ErrInvalidImportFile = errors.New("invalid import file")
+	ErrUnsupportedFormat = errors.New("unsupported import format")
 )
@@ -0,0 +1,58 @@
+package blobstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ST359/pvz-service/internal/config"
+)
+
+const (
+	KindLocal = "local"
+	KindS3    = "s3"
+)
+
+// Object is a blob with its metadata, SHA256 is a hex encoded checksum of Body
+type Object struct {
+	Body        io.Reader
+	Size        int64
+	ContentType string
+	SHA256      string
+}
+
+// BlobStore keeps files of attachments, keys are slash separated paths.
+// Get can return ErrBlobNotFound, Put can return ErrBlobChecksumMismatch. Deleting a missing blob is not an error,
+// S3 doesn't tell it apart either
+type BlobStore interface {
+	Put(ctx context.Context, key string, obj Object) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// New creates the store configured by BLOB_STORE
+func New(cfg *config.Config) (BlobStore, error) {
+	const op = "blobstore.New"
+
+	switch cfg.BlobStore {
+	case KindLocal:
+		return NewLocalStore(cfg.BlobLocalDir)
+	case KindS3:
+		return NewS3Store(cfg.S3Endpoint, cfg.S3Region, cfg.S3Bucket, cfg.S3AccessKey, cfg.S3SecretKey, cfg.S3Timeout)
+	}
+	return nil, fmt.Errorf("%s: unknown blob store %q, expected %s or %s", op, cfg.BlobStore, KindLocal, KindS3)
+}
+
+// validKey rejects keys that could escape the store root or address a bucket listing
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.HasSuffix(key, "/") {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
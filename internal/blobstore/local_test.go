@@ -0,0 +1,61 @@
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := NewLocalStore(dir)
+	require.NoError(t, err)
+
+	data := []byte("\x89PNG photo of a torn box")
+	sum := sha256.Sum256(data)
+	obj := Object{Body: bytes.NewReader(data), Size: int64(len(data)), ContentType: "image/png", SHA256: hex.EncodeToString(sum[:])}
+	require.NoError(t, store.Put(ctx, "attachments/a1", obj))
+
+	rc, err := store.Get(ctx, "attachments/a1")
+	require.NoError(t, err)
+	got, err := io.ReadAll(rc)
+	rc.Close()
+	require.NoError(t, err)
+	assert.Equal(t, data, got)
+
+	// a blob that doesn't match its checksum is not kept
+	err = store.Put(ctx, "attachments/a2", Object{Body: bytes.NewReader(data), Size: int64(len(data)), SHA256: emptySHA256})
+	assert.ErrorIs(t, err, errs.ErrBlobChecksumMismatch)
+	_, err = store.Get(ctx, "attachments/a2")
+	assert.ErrorIs(t, err, errs.ErrBlobNotFound)
+	entries, err := os.ReadDir(filepath.Join(dir, "attachments"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	require.NoError(t, store.Delete(ctx, "attachments/a1"))
+	require.NoError(t, store.Delete(ctx, "attachments/a1"))
+	_, err = store.Get(ctx, "attachments/a1")
+	assert.ErrorIs(t, err, errs.ErrBlobNotFound)
+
+	// an upload cancelled midway is not kept
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	err = store.Put(cancelled, "attachments/a3", obj)
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = store.Get(ctx, "attachments/a3")
+	assert.ErrorIs(t, err, errs.ErrBlobNotFound)
+
+	_, err = store.Get(ctx, "../outside")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, errs.ErrBlobNotFound)
+}
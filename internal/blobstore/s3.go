@@ -0,0 +1,139 @@
+package blobstore
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// checksumMismatchCodes are error codes S3-compatible storages answer with when a body doesn't match
+// its x-amz-checksum-sha256 header: AWS uses BadDigest, MinIO its own code
+var checksumMismatchCodes = map[string]bool{
+	"BadDigest":                   true,
+	"XAmzContentChecksumMismatch": true,
+	"XAmzContentSHA256Mismatch":   true,
+}
+
+// S3Store keeps blobs in a bucket of an S3-compatible storage. The bucket is addressed by path,
+// which is supported by AWS as well as MinIO and other stand-ins. Every request has to finish within timeout,
+// for Get it includes reading the body
+type S3Store struct {
+	client  *minio.Client
+	bucket  string
+	timeout time.Duration
+}
+
+func NewS3Store(endpoint, region, bucket, accessKey, secretKey string, timeout time.Duration) (*S3Store, error) {
+	const op = "blobstore.s3.New"
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if u.Scheme == "" || u.Host == "" || bucket == "" || accessKey == "" || secretKey == "" {
+		return nil, fmt.Errorf("%s: endpoint, bucket and credentials are required", op)
+	}
+	if timeout <= 0 {
+		return nil, fmt.Errorf("%s: timeout must be positive", op)
+	}
+	client, err := minio.New(u.Host, &minio.Options{
+		Creds:        credentials.NewStaticV4(accessKey, secretKey, ""),
+		Secure:       u.Scheme == "https",
+		Region:       region,
+		BucketLookup: minio.BucketLookupPath,
+		// lets Put send the checksum computed by the caller instead of one of its own
+		TrailingHeaders: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &S3Store{client: client, bucket: bucket, timeout: timeout}, nil
+}
+
+// Put uploads the blob in a single request, the storage verifies the body against obj.SHA256
+func (s *S3Store) Put(ctx context.Context, key string, obj Object) error {
+	const op = "blobstore.s3.Put"
+
+	if !validKey(key) {
+		return fmt.Errorf("%s: invalid blob key %q", op, key)
+	}
+	sum, err := hex.DecodeString(obj.SHA256)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	_, err = s.client.PutObject(ctx, s.bucket, key, obj.Body, obj.Size, minio.PutObjectOptions{
+		ContentType:          obj.ContentType,
+		UserMetadata:         map[string]string{"x-amz-checksum-sha256": base64.StdEncoding.EncodeToString(sum)},
+		DisableContentSha256: true,
+		DisableMultipart:     true,
+	})
+	if err != nil {
+		if checksumMismatchCodes[minio.ToErrorResponse(err).Code] {
+			return errs.ErrBlobChecksumMismatch
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	const op = "blobstore.s3.Get"
+
+	if !validKey(key) {
+		return nil, fmt.Errorf("%s: invalid blob key %q", op, key)
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	obj, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	// the object is requested lazily, Stat sends the request so a missing blob is reported here
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		cancel()
+		if minio.ToErrorResponse(err).Code == minio.NoSuchKey {
+			return nil, errs.ErrBlobNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &s3Body{Object: obj, cancel: cancel}, nil
+}
+
+// Delete removes the blob, a missing one is not an error
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	const op = "blobstore.s3.Delete"
+
+	if !validKey(key) {
+		return fmt.Errorf("%s: invalid blob key %q", op, key)
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	if err := s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// s3Body releases the request timeout of Get once the caller is done with the body
+type s3Body struct {
+	*minio.Object
+	cancel context.CancelFunc
+}
+
+func (b *s3Body) Close() error {
+	defer b.cancel()
+	return b.Object.Close()
+}
@@ -0,0 +1,119 @@
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// emptySHA256 is the hex encoded sha256 of an empty body
+const emptySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// fakeS3 is a local stand-in for an S3-compatible storage, it keeps objects in memory
+// and checks that requests are signed and bodies match their declared checksums
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	// delay holds every response back, to let requests run out of time
+	delay time.Duration
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	time.Sleep(f.delay)
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=access/") ||
+		r.Header.Get("X-Amz-Date") == "" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		sum := sha256.Sum256(body)
+		if base64.StdEncoding.EncodeToString(sum[:]) != r.Header.Get("X-Amz-Checksum-Sha256") {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, "<Error><Code>BadDigest</Code><Message>checksum mismatch</Message></Error>")
+			return
+		}
+		f.objects[r.URL.Path] = body
+	case http.MethodGet, http.MethodHead:
+		body, ok := f.objects[r.URL.Path]
+		if !ok {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, "<Error><Code>NoSuchKey</Code><Message>no such key</Message></Error>")
+			return
+		}
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		if r.Method == http.MethodGet {
+			w.Write(body)
+		}
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestS3Store(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeS3{objects: map[string][]byte{}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	store, err := NewS3Store(srv.URL, "us-east-1", "pvz", "access", "secret", time.Second)
+	require.NoError(t, err)
+
+	data := []byte("%PDF-1.4 act of damage")
+	sum := sha256.Sum256(data)
+	obj := Object{Body: bytes.NewReader(data), Size: int64(len(data)), ContentType: "application/pdf", SHA256: hex.EncodeToString(sum[:])}
+	require.NoError(t, store.Put(ctx, "attachments/a1", obj))
+	assert.Contains(t, fake.objects, "/pvz/attachments/a1")
+
+	rc, err := store.Get(ctx, "attachments/a1")
+	require.NoError(t, err)
+	got, err := io.ReadAll(rc)
+	rc.Close()
+	require.NoError(t, err)
+	assert.Equal(t, data, got)
+
+	err = store.Put(ctx, "attachments/a2", Object{Body: bytes.NewReader(data), Size: int64(len(data)), SHA256: emptySHA256})
+	assert.ErrorIs(t, err, errs.ErrBlobChecksumMismatch)
+
+	require.NoError(t, store.Delete(ctx, "attachments/a1"))
+	_, err = store.Get(ctx, "attachments/a1")
+	assert.ErrorIs(t, err, errs.ErrBlobNotFound)
+
+	assert.Error(t, store.Put(ctx, "../a3", obj))
+}
+
+func TestS3Store_Timeout(t *testing.T) {
+	fake := &fakeS3{objects: map[string][]byte{"/pvz/attachments/a1": []byte("photo")}, delay: 200 * time.Millisecond}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	store, err := NewS3Store(srv.URL, "us-east-1", "pvz", "access", "secret", 50*time.Millisecond)
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, err = store.Get(context.Background(), "attachments/a1")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 200*time.Millisecond)
+}
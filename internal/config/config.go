@@ -2,6 +2,7 @@ package config
 
 import (
 	"log"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
@@ -13,6 +14,62 @@ type Config struct {
 	DbPassword string `env:"DATABASE_PASSWORD"`
 	DbName     string `env:"DATABASE_NAME"`
 	Port       int    `env:"SERVER_PORT"`
+	// MetricsPort serves /metrics apart from the API, 0 disables metrics
+	MetricsPort int `env:"METRICS_PORT" env-default:"9090"`
+
+	ProductBatchMaxSize   int           `env:"PRODUCT_BATCH_MAX_SIZE" env-default:"500"`
+	ManifestMaxItems      int           `env:"MANIFEST_MAX_ITEMS" env-default:"5000"`
+	ReceptionReopenWindow time.Duration `env:"RECEPTION_REOPEN_WINDOW" env-default:"30m"`
+
+	// StaleReceptionTimeout is how long a reception may stay without scans, 0 disables the check
+	StaleReceptionTimeout       time.Duration `env:"STALE_RECEPTION_TIMEOUT" env-default:"12h"`
+	StaleReceptionCheckInterval time.Duration `env:"STALE_RECEPTION_CHECK_INTERVAL" env-default:"5m"`
+	// StaleReceptionAction is either close or flag
+	StaleReceptionAction string `env:"STALE_RECEPTION_ACTION" env-default:"close"`
+
+	// IdempotencyKeyTTL is how long responses to requests with an Idempotency-Key header are replayed
+	IdempotencyKeyTTL time.Duration `env:"IDEMPOTENCY_KEY_TTL" env-default:"24h"`
+	// IdempotencyCleanupInterval is how often expired keys are deleted, 0 disables the cleanup
+	IdempotencyCleanupInterval time.Duration `env:"IDEMPOTENCY_CLEANUP_INTERVAL" env-default:"1h"`
+
+	// AttachmentMaxSize is the largest attachment file in bytes
+	AttachmentMaxSize int64 `env:"ATTACHMENT_MAX_SIZE" env-default:"10485760"`
+	// BlobStore is either local or s3
+	BlobStore    string `env:"BLOB_STORE" env-default:"local"`
+	BlobLocalDir string `env:"BLOB_LOCAL_DIR" env-default:"./data/attachments"`
+	// S3Endpoint is scheme and host of an S3-compatible storage, buckets are addressed by path
+	S3Endpoint  string `env:"S3_ENDPOINT"`
+	S3Region    string `env:"S3_REGION" env-default:"us-east-1"`
+	S3Bucket    string `env:"S3_BUCKET"`
+	S3AccessKey string `env:"S3_ACCESS_KEY"`
+	S3SecretKey string `env:"S3_SECRET_KEY"`
+	// S3Timeout limits every request to the storage, downloads included
+	S3Timeout time.Duration `env:"S3_TIMEOUT" env-default:"30s"`
+
+	// TracingExporter is none, stdout or otlp. Incoming trace context is propagated to logs even with none
+	TracingExporter string `env:"TRACING_EXPORTER" env-default:"none"`
+	// TracingOTLPEndpoint is host:port of an OTLP/HTTP collector
+	TracingOTLPEndpoint string `env:"TRACING_OTLP_ENDPOINT" env-default:"localhost:4318"`
+	TracingOTLPInsecure bool   `env:"TRACING_OTLP_INSECURE" env-default:"true"`
+	// TracingSampleRatio is the share of new traces that are recorded, traces sampled by the caller are always recorded
+	TracingSampleRatio float64 `env:"TRACING_SAMPLE_RATIO" env-default:"1"`
+}
+
+// PickupCode holds settings of pickup codes. Only the HTTP server issues codes,
+// so they are loaded apart from Config and commands like import-pvz run without them
+type PickupCode struct {
+	Length      int `env:"PICKUP_CODE_LENGTH" env-default:"6"`
+	MaxAttempts int `env:"PICKUP_CODE_MAX_ATTEMPTS" env-default:"5"`
+	// Secret keys hashes of pickup codes, so codes can't be recovered from the database alone.
+	// Codes are short, so the secret has no default that would let anyone brute force the hashes
+	Secret string `env:"PICKUP_CODE_SECRET" env-required:"true"`
+	// Sender delivers codes to customers, either webhook or log. Log is for local development only
+	Sender string `env:"PICKUP_CODE_SENDER"`
+	// WebhookURL receives issued codes as JSON when Sender is webhook, the receiver delivers them to customers
+	WebhookURL string `env:"PICKUP_CODE_WEBHOOK_URL"`
+	// WebhookToken is sent as a bearer token, so the receiver can tell the requests come from the service
+	WebhookToken   string        `env:"PICKUP_CODE_WEBHOOK_TOKEN"`
+	WebhookTimeout time.Duration `env:"PICKUP_CODE_WEBHOOK_TIMEOUT" env-default:"5s"`
 }
 
 func MustLoad() *Config {
@@ -22,3 +79,11 @@ func MustLoad() *Config {
 	}
 	return &cfg
 }
+
+func MustLoadPickupCode() *PickupCode {
+	var cfg PickupCode
+	if err := cleanenv.ReadEnv(&cfg); err != nil {
+		log.Fatalf("failed to read pickup code config: %s", err)
+	}
+	return &cfg
+}
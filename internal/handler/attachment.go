@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	attachmentFormField = "file"
+	// multipartOverhead leaves room for boundaries and part headers on top of the file itself
+	multipartOverhead = 1 << 20
+)
+
+func (h *Handler) UploadReceptionAttachment(c *gin.Context) {
+	recID, err := uuid.Parse(c.Param("receptionId"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	h.uploadAttachment(c, func(file service.AttachmentFile) (api.Attachment, error) {
+		return h.Services.Attachment.AttachToReception(c.Request.Context(), recID, file, currentUserID(c))
+	})
+}
+
+func (h *Handler) UploadProductAttachment(c *gin.Context) {
+	prodID, err := uuid.Parse(c.Param("productId"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	h.uploadAttachment(c, func(file service.AttachmentFile) (api.Attachment, error) {
+		return h.Services.Attachment.AttachToProduct(c.Request.Context(), prodID, file, currentUserID(c))
+	})
+}
+
+// uploadAttachment reads the file from the multipart form and passes it to attach
+func (h *Handler) uploadAttachment(c *gin.Context, attach func(service.AttachmentFile) (api.Attachment, error)) {
+	const op = "handler.attachment.uploadAttachment"
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.Services.Attachment.MaxSize()+multipartOverhead)
+	header, err := c.FormFile(attachmentFormField)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, api.Error{Message: errs.ErrAttachmentTooLarge.Error()})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	body, err := header.Open()
+	if err != nil {
+		h.Logger.ErrorContext(c.Request.Context(), "failed to open uploaded file", slog.String("op", op), slog.String("error", err.Error()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		return
+	}
+	defer body.Close()
+
+	att, err := attach(service.AttachmentFile{Name: header.Filename, Size: header.Size, Body: body})
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrReceptionNotFound) || errors.Is(err, errs.ErrProductNotFound):
+			c.AbortWithStatusJSON(http.StatusNotFound, api.Error{Message: err.Error()})
+		case errors.Is(err, errs.ErrAttachmentTooLarge):
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, api.Error{Message: err.Error()})
+		case errors.Is(err, errs.ErrUnsupportedAttachmentType):
+			c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, api.Error{Message: err.Error()})
+		case errors.Is(err, errs.ErrEmptyAttachment):
+			c.AbortWithStatusJSON(http.StatusBadRequest, api.Error{Message: err.Error()})
+		default:
+			h.Logger.ErrorContext(c.Request.Context(), "failed to upload attachment", slog.String("op", op), slog.String("error", err.Error()))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, att)
+}
+
+func (h *Handler) GetReceptionAttachments(c *gin.Context) {
+	const op = "handler.attachment.GetReceptionAttachments"
+
+	recID, err := uuid.Parse(c.Param("receptionId"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	res, err := h.Services.Attachment.ListByReception(c.Request.Context(), recID)
+	if err != nil {
+		if errors.Is(err, errs.ErrReceptionNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, api.Error{Message: err.Error()})
+			return
+		}
+		h.Logger.ErrorContext(c.Request.Context(), "failed to get reception attachments", slog.String("op", op), slog.String("error", err.Error()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) GetProductAttachments(c *gin.Context) {
+	const op = "handler.attachment.GetProductAttachments"
+
+	prodID, err := uuid.Parse(c.Param("productId"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	res, err := h.Services.Attachment.ListByProduct(c.Request.Context(), prodID)
+	if err != nil {
+		if errors.Is(err, errs.ErrProductNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, api.Error{Message: err.Error()})
+			return
+		}
+		h.Logger.ErrorContext(c.Request.Context(), "failed to get product attachments", slog.String("op", op), slog.String("error", err.Error()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) GetAttachment(c *gin.Context) {
+	const op = "handler.attachment.GetAttachment"
+
+	id, err := uuid.Parse(c.Param("attachmentId"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	att, err := h.Services.Attachment.Get(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, errs.ErrAttachmentNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, api.Error{Message: err.Error()})
+			return
+		}
+		h.Logger.ErrorContext(c.Request.Context(), "failed to get attachment", slog.String("op", op), slog.String("error", err.Error()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		return
+	}
+	c.JSON(http.StatusOK, att)
+}
+
+// DownloadAttachment streams the file, the ETag carries its checksum so clients can verify the download
+func (h *Handler) DownloadAttachment(c *gin.Context) {
+	const op = "handler.attachment.DownloadAttachment"
+
+	id, err := uuid.Parse(c.Param("attachmentId"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	att, body, err := h.Services.Attachment.Open(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, errs.ErrAttachmentNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, api.Error{Message: err.Error()})
+			return
+		}
+		h.Logger.ErrorContext(c.Request.Context(), "failed to open attachment", slog.String("op", op), slog.String("error", err.Error()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		return
+	}
+	defer body.Close()
+
+	c.DataFromReader(http.StatusOK, att.Size, att.ContentType, body, map[string]string{
+		"ETag":                strconv.Quote(att.Sha256),
+		"Content-Disposition": mime.FormatMediaType("attachment", map[string]string{"filename": att.FileName}),
+	})
+}
@@ -0,0 +1,259 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockAttachmentService is a mock implementation of service.Attachment
+type MockAttachmentService struct {
+	mock.Mock
+}
+
+func (m *MockAttachmentService) AttachToReception(ctx context.Context, recID uuid.UUID, file service.AttachmentFile, userID uuid.UUID) (api.Attachment, error) {
+	args := m.Called(recID, file.Name, file.Size, userID)
+	return args.Get(0).(api.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentService) AttachToProduct(ctx context.Context, prodID uuid.UUID, file service.AttachmentFile, userID uuid.UUID) (api.Attachment, error) {
+	args := m.Called(prodID, file.Name, file.Size, userID)
+	return args.Get(0).(api.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentService) ListByReception(ctx context.Context, recID uuid.UUID) ([]api.Attachment, error) {
+	args := m.Called(recID)
+	return args.Get(0).([]api.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentService) ListByProduct(ctx context.Context, prodID uuid.UUID) ([]api.Attachment, error) {
+	args := m.Called(prodID)
+	return args.Get(0).([]api.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentService) Get(ctx context.Context, id uuid.UUID) (api.Attachment, error) {
+	args := m.Called(id)
+	return args.Get(0).(api.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentService) Open(ctx context.Context, id uuid.UUID) (api.Attachment, io.ReadCloser, error) {
+	args := m.Called(id)
+	body, _ := args.Get(1).(io.ReadCloser)
+	return args.Get(0).(api.Attachment), body, args.Error(2)
+}
+
+func (m *MockAttachmentService) MaxSize() int64 {
+	return 16
+}
+
+func setupAttachmentRouter(h *Handler, uid uuid.UUID) *gin.Engine {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(userRole, api.UserRoleEmployee)
+		c.Set(userID, uid)
+	})
+	router.GET("/receptions/:receptionId/attachments", h.GetReceptionAttachments)
+	router.POST("/receptions/:receptionId/attachments", h.UploadReceptionAttachment)
+	router.GET("/products/:productId/attachments", h.GetProductAttachments)
+	router.POST("/products/:productId/attachments", h.UploadProductAttachment)
+	router.GET("/attachments/:attachmentId", h.GetAttachment)
+	router.GET("/attachments/:attachmentId/content", h.DownloadAttachment)
+	return router
+}
+
+// multipartBody builds a form with the file under field
+func multipartBody(t *testing.T, field, name string, content []byte) (*bytes.Buffer, string) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	part, err := w.CreateFormFile(field, name)
+	require.NoError(t, err)
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return &buf, w.FormDataContentType()
+}
+
+func TestUploadAttachment(t *testing.T) {
+	recID := uuid.New()
+	prodID := uuid.New()
+	uid := uuid.New()
+	attID := uuid.New()
+	content := []byte("image")
+
+	tests := []struct {
+		name           string
+		path           string
+		field          string
+		content        []byte
+		mockSetup      func(*MockAttachmentService)
+		expectedStatus int
+	}{
+		{
+			name:    "reception attachment",
+			path:    "/receptions/" + recID.String() + "/attachments",
+			field:   "file",
+			content: content,
+			mockSetup: func(m *MockAttachmentService) {
+				m.On("AttachToReception", recID, "box.png", int64(len(content)), uid).
+					Return(api.Attachment{Id: attID, ReceptionId: &recID}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:    "product not found",
+			path:    "/products/" + prodID.String() + "/attachments",
+			field:   "file",
+			content: content,
+			mockSetup: func(m *MockAttachmentService) {
+				m.On("AttachToProduct", prodID, "box.png", int64(len(content)), uid).
+					Return(api.Attachment{}, errs.ErrProductNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:    "unsupported type",
+			path:    "/products/" + prodID.String() + "/attachments",
+			field:   "file",
+			content: content,
+			mockSetup: func(m *MockAttachmentService) {
+				m.On("AttachToProduct", prodID, "box.png", int64(len(content)), uid).
+					Return(api.Attachment{}, errs.ErrUnsupportedAttachmentType)
+			},
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:    "too large for the service",
+			path:    "/receptions/" + recID.String() + "/attachments",
+			field:   "file",
+			content: content,
+			mockSetup: func(m *MockAttachmentService) {
+				m.On("AttachToReception", recID, "box.png", int64(len(content)), uid).
+					Return(api.Attachment{}, errs.ErrAttachmentTooLarge)
+			},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:           "body over the limit",
+			path:           "/receptions/" + recID.String() + "/attachments",
+			field:          "file",
+			content:        bytes.Repeat([]byte{0}, multipartOverhead+16),
+			mockSetup:      func(m *MockAttachmentService) {},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:           "no file",
+			path:           "/receptions/" + recID.String() + "/attachments",
+			field:          "photo",
+			content:        content,
+			mockSetup:      func(m *MockAttachmentService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid reception id",
+			path:           "/receptions/1/attachments",
+			field:          "file",
+			content:        content,
+			mockSetup:      func(m *MockAttachmentService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAttachments := new(MockAttachmentService)
+			tt.mockSetup(mockAttachments)
+
+			h := &Handler{
+				Services: &service.Service{Attachment: mockAttachments},
+				Logger:   slog.Default(),
+			}
+			router := setupAttachmentRouter(h, uid)
+
+			body, contentType := multipartBody(t, tt.field, "box.png", tt.content)
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", tt.path, body)
+			req.Header.Set("Content-Type", contentType)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockAttachments.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDownloadAttachment(t *testing.T) {
+	attID := uuid.New()
+	sum := "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+	tests := []struct {
+		name           string
+		mockSetup      func(*MockAttachmentService)
+		expectedStatus int
+		expectedBody   string
+		expectedHeader map[string]string
+	}{
+		{
+			name: "downloaded",
+			mockSetup: func(m *MockAttachmentService) {
+				att := api.Attachment{Id: attID, FileName: "акт приемки.pdf", ContentType: "application/pdf", Size: 4, Sha256: sum}
+				m.On("Open", attID).Return(att, io.NopCloser(strings.NewReader("%PDF")), nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "%PDF",
+			expectedHeader: map[string]string{
+				"Content-Type":        "application/pdf",
+				"Content-Length":      "4",
+				"ETag":                `"` + sum + `"`,
+				"Content-Disposition": "attachment; filename*=utf-8''%D0%B0%D0%BA%D1%82%20%D0%BF%D1%80%D0%B8%D0%B5%D0%BC%D0%BA%D0%B8.pdf",
+			},
+		},
+		{
+			name: "not found",
+			mockSetup: func(m *MockAttachmentService) {
+				m.On("Open", attID).Return(api.Attachment{}, nil, errs.ErrAttachmentNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAttachments := new(MockAttachmentService)
+			tt.mockSetup(mockAttachments)
+
+			h := &Handler{
+				Services: &service.Service{Attachment: mockAttachments},
+				Logger:   slog.Default(),
+			}
+			router := setupAttachmentRouter(h, uuid.New())
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/attachments/"+attID.String()+"/content", nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			}
+			for name, value := range tt.expectedHeader {
+				assert.Equal(t, value, w.Header().Get(name), name)
+			}
+			mockAttachments.AssertExpectations(t)
+		})
+	}
+}
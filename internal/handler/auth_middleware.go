@@ -5,11 +5,13 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 const (
 	authHeader = "Authorization"
 	userRole   = "userRole"
+	userID     = "userID"
 )
 
 func (h *Handler) userRoleMW(c *gin.Context) {
@@ -23,12 +25,20 @@ func (h *Handler) userRoleMW(c *gin.Context) {
 		return
 	}
 
-	role, err := h.Services.User.ParseToken(headerParts[1])
+	role, id, err := h.Services.User.ParseToken(headerParts[1])
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusForbidden, ErrMessageAccessDenied)
 		return
 	}
 
 	c.Set(userRole, role)
+	c.Set(userID, id)
 	c.Next()
 }
+
+// currentUserID returns an id of the authenticated user, uuid.Nil for dummy tokens
+func currentUserID(c *gin.Context) uuid.UUID {
+	id, _ := c.Get(userID)
+	uid, _ := id.(uuid.UUID)
+	return uid
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) SetProductCondition(c *gin.Context) {
+	const op = "handler.damage_report.SetProductCondition"
+
+	role, _ := c.Get(userRole)
+	if role != api.UserRoleEmployee {
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrMessageAccessDenied)
+		return
+	}
+	prodID, err := uuid.Parse(c.Param("productId"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	var req api.PutProductsProductIdConditionJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+
+	prod, err := h.Services.DamageReport.SetCondition(c.Request.Context(), prodID, req, currentUserID(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrProductNotFound):
+			c.AbortWithStatusJSON(http.StatusNotFound, api.Error{Message: err.Error()})
+		case errors.Is(err, errs.ErrReceptionClosed):
+			c.AbortWithStatusJSON(http.StatusConflict, api.Error{Message: err.Error()})
+		case errors.Is(err, errs.ErrInvalidProductCondition) || errors.Is(err, errs.ErrDamageCommentRequired) ||
+			errors.Is(err, errs.ErrDamageEvidenceRequired):
+			c.AbortWithStatusJSON(http.StatusBadRequest, api.Error{Message: err.Error()})
+		default:
+			h.Logger.ErrorContext(c.Request.Context(), "failed to set product condition", slog.String("op", op), slog.String("error", err.Error()))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, prod)
+}
+
+func (h *Handler) ListDamageReports(c *gin.Context) {
+	const op = "handler.damage_report.ListDamageReports"
+
+	role, _ := c.Get(userRole)
+	if role != api.UserRoleModerator {
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrMessageAccessDenied)
+		return
+	}
+	// gin can't bind uuid query params, so pvzId is parsed separately
+	var query struct {
+		PvzID  string                  `form:"pvzId"`
+		Status *api.DamageReportStatus `form:"status"`
+		Page   *int                    `form:"page"`
+		Limit  *int                    `form:"limit"`
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	if !validPage(query.Page, query.Limit) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	if query.Status != nil && !validDamageReportStatus(*query.Status) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	pvzID, err := parseOptionalUUID(query.PvzID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	reports, err := h.Services.DamageReport.List(c.Request.Context(), api.GetDamageReportsParams{
+		PvzId:  pvzID,
+		Status: query.Status,
+		Page:   query.Page,
+		Limit:  query.Limit,
+	})
+	if err != nil {
+		h.Logger.ErrorContext(c.Request.Context(), "failed to list damage reports", slog.String("op", op), slog.String("error", err.Error()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		return
+	}
+	c.JSON(http.StatusOK, reports)
+}
+
+func (h *Handler) DecideDamageReport(c *gin.Context) {
+	const op = "handler.damage_report.DecideDamageReport"
+
+	role, _ := c.Get(userRole)
+	if role != api.UserRoleModerator {
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrMessageAccessDenied)
+		return
+	}
+	reportID, err := uuid.Parse(c.Param("reportId"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	var req api.PostDamageReportsReportIdDecisionJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+
+	report, err := h.Services.DamageReport.Decide(c.Request.Context(), reportID, req, currentUserID(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrDamageReportNotFound):
+			c.AbortWithStatusJSON(http.StatusNotFound, api.Error{Message: err.Error()})
+		case errors.Is(err, errs.ErrDamageReportDecided):
+			c.AbortWithStatusJSON(http.StatusConflict, api.Error{Message: err.Error()})
+		case errors.Is(err, errs.ErrInvalidDamageDecision) || errors.Is(err, errs.ErrReviewCommentTooLong):
+			c.AbortWithStatusJSON(http.StatusBadRequest, api.Error{Message: err.Error()})
+		default:
+			h.Logger.ErrorContext(c.Request.Context(), "failed to decide on damage report", slog.String("op", op), slog.String("error", err.Error()))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+func validDamageReportStatus(status api.DamageReportStatus) bool {
+	switch status {
+	case api.DamageReportPending, api.DamageReportAccepted, api.DamageReportRejected:
+		return true
+	}
+	return false
+}
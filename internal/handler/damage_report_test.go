@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockDamageReportService is a mock implementation of service.DamageReport
+type MockDamageReportService struct {
+	mock.Mock
+}
+
+func (m *MockDamageReportService) SetCondition(ctx context.Context, prodID uuid.UUID, input api.ProductConditionInput, userID uuid.UUID) (api.Product, error) {
+	args := m.Called(prodID, input, userID)
+	return args.Get(0).(api.Product), args.Error(1)
+}
+
+func (m *MockDamageReportService) List(ctx context.Context, params api.GetDamageReportsParams) ([]api.DamageReport, error) {
+	args := m.Called(params)
+	return args.Get(0).([]api.DamageReport), args.Error(1)
+}
+
+func (m *MockDamageReportService) Decide(ctx context.Context, reportID uuid.UUID, decision api.DamageReportDecision, userID uuid.UUID) (api.DamageReport, error) {
+	args := m.Called(reportID, decision, userID)
+	return args.Get(0).(api.DamageReport), args.Error(1)
+}
+
+func setupDamageReportRouter(h *Handler, role api.UserRole, uid uuid.UUID) *gin.Engine {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(userRole, role)
+		c.Set(userID, uid)
+	})
+	router.PUT("/products/:productId/condition", h.SetProductCondition)
+	router.GET("/damage_reports", h.ListDamageReports)
+	router.POST("/damage_reports/:reportId/decision", h.DecideDamageReport)
+	return router
+}
+
+func TestDamageReports(t *testing.T) {
+	prodID := uuid.New()
+	reportID := uuid.New()
+	pvzID := uuid.New()
+	uid := uuid.New()
+	comment := "torn box"
+	accepted := api.DamageReportAccepted
+
+	tests := []struct {
+		name           string
+		role           api.UserRole
+		method         string
+		path           string
+		body           string
+		mockSetup      func(*MockDamageReportService)
+		expectedStatus int
+	}{
+		{
+			name:   "flag as employee",
+			role:   api.UserRoleEmployee,
+			method: "PUT",
+			path:   "/products/" + prodID.String() + "/condition",
+			body:   `{"condition":"damaged","comment":"torn box"}`,
+			mockSetup: func(m *MockDamageReportService) {
+				m.On("SetCondition", prodID, api.ProductConditionInput{Condition: api.ConditionDamaged, Comment: &comment}, uid).
+					Return(api.Product{Id: &prodID}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "flag as moderator",
+			role:           api.UserRoleModerator,
+			method:         "PUT",
+			path:           "/products/" + prodID.String() + "/condition",
+			body:           `{"condition":"damaged","comment":"torn box"}`,
+			mockSetup:      func(m *MockDamageReportService) {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "flag without attachment",
+			role:   api.UserRoleEmployee,
+			method: "PUT",
+			path:   "/products/" + prodID.String() + "/condition",
+			body:   `{"condition":"wet","comment":"torn box"}`,
+			mockSetup: func(m *MockDamageReportService) {
+				m.On("SetCondition", prodID, mock.Anything, uid).Return(api.Product{}, errs.ErrDamageEvidenceRequired)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "flag in closed reception",
+			role:   api.UserRoleEmployee,
+			method: "PUT",
+			path:   "/products/" + prodID.String() + "/condition",
+			body:   `{"condition":"wet","comment":"torn box"}`,
+			mockSetup: func(m *MockDamageReportService) {
+				m.On("SetCondition", prodID, mock.Anything, uid).Return(api.Product{}, errs.ErrReceptionClosed)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:   "queue as moderator",
+			role:   api.UserRoleModerator,
+			method: "GET",
+			path:   "/damage_reports?status=accepted&pvzId=" + pvzID.String(),
+			mockSetup: func(m *MockDamageReportService) {
+				m.On("List", api.GetDamageReportsParams{Status: &accepted, PvzId: &pvzID}).
+					Return([]api.DamageReport{{Id: reportID}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "queue with unknown status",
+			role:           api.UserRoleModerator,
+			method:         "GET",
+			path:           "/damage_reports?status=lost",
+			mockSetup:      func(m *MockDamageReportService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "queue as employee",
+			role:           api.UserRoleEmployee,
+			method:         "GET",
+			path:           "/damage_reports",
+			mockSetup:      func(m *MockDamageReportService) {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "reject",
+			role:   api.UserRoleModerator,
+			method: "POST",
+			path:   "/damage_reports/" + reportID.String() + "/decision",
+			body:   `{"decision":"rejected"}`,
+			mockSetup: func(m *MockDamageReportService) {
+				m.On("Decide", reportID, api.DamageReportDecision{Decision: api.DecisionRejected}, uid).
+					Return(api.DamageReport{Id: reportID, Status: api.DamageReportRejected}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "decide twice",
+			role:   api.UserRoleModerator,
+			method: "POST",
+			path:   "/damage_reports/" + reportID.String() + "/decision",
+			body:   `{"decision":"accepted"}`,
+			mockSetup: func(m *MockDamageReportService) {
+				m.On("Decide", reportID, api.DamageReportDecision{Decision: api.DecisionAccepted}, uid).
+					Return(api.DamageReport{}, errs.ErrDamageReportDecided)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:   "decide unknown report",
+			role:   api.UserRoleModerator,
+			method: "POST",
+			path:   "/damage_reports/" + reportID.String() + "/decision",
+			body:   `{"decision":"accepted"}`,
+			mockSetup: func(m *MockDamageReportService) {
+				m.On("Decide", reportID, api.DamageReportDecision{Decision: api.DecisionAccepted}, uid).
+					Return(api.DamageReport{}, errs.ErrDamageReportNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockReports := new(MockDamageReportService)
+			tt.mockSetup(mockReports)
+
+			h := &Handler{
+				Services: &service.Service{DamageReport: mockReports},
+				Logger:   slog.Default(),
+			}
+			router := setupDamageReportRouter(h, tt.role, uid)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockReports.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// exportWriteTimeout bounds each write of an export instead of the whole response, large exports take longer than the server write timeout
+const exportWriteTimeout = 30 * time.Second
+
+var exportContentTypes = map[api.ExportFormat]string{
+	api.ExportCSV:  "text/csv; charset=utf-8",
+	api.ExportXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// exportQuery holds params shared by export endpoints, gin can't bind uuid and comma separated params
+type exportQuery struct {
+	Format    *api.ExportFormat `form:"format"`
+	Columns   string            `form:"columns"`
+	StartDate *time.Time        `form:"startDate"`
+	EndDate   *time.Time        `form:"endDate"`
+	PvzID     string            `form:"pvzId"`
+	City      *string           `form:"city"`
+}
+
+func (q exportQuery) columns() *[]string {
+	if q.Columns == "" {
+		return nil
+	}
+	columns := strings.Split(q.Columns, ",")
+	for i := range columns {
+		columns[i] = strings.TrimSpace(columns[i])
+	}
+	return &columns
+}
+
+func (h *Handler) ExportReceptions(c *gin.Context) {
+	h.export(c, "handler.export.ExportReceptions", "receptions", func(q exportQuery, pvzID *uuid.UUID, w io.Writer) error {
+		params := api.GetExportsReceptionsParams{Format: q.Format, Columns: q.columns(), StartDate: q.StartDate,
+			EndDate: q.EndDate, PvzId: pvzID, City: q.City}
+		return h.Services.Export.Receptions(c.Request.Context(), params, w)
+	})
+}
+
+func (h *Handler) ExportProducts(c *gin.Context) {
+	h.export(c, "handler.export.ExportProducts", "products", func(q exportQuery, pvzID *uuid.UUID, w io.Writer) error {
+		params := api.GetExportsProductsParams{Format: q.Format, Columns: q.columns(), StartDate: q.StartDate,
+			EndDate: q.EndDate, PvzId: pvzID, City: q.City}
+		return h.Services.Export.Products(c.Request.Context(), params, w)
+	})
+}
+
+// export streams a file produced by run, errors before the first byte are sent as json,
+// later ones drop the connection so the client doesn't take a truncated file for a complete one
+func (h *Handler) export(c *gin.Context, op, name string, run func(exportQuery, *uuid.UUID, io.Writer) error) {
+	role, _ := c.Get(userRole)
+	if role != api.UserRoleModerator {
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrMessageAccessDenied)
+		return
+	}
+	var query exportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	pvzID, err := parseOptionalUUID(query.PvzID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	format := api.ExportCSV
+	if query.Format != nil {
+		format = *query.Format
+	}
+	contentType, ok := exportContentTypes[format]
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusBadRequest, api.Error{Message: errs.ErrUnsupportedExportFormat.Error()})
+		return
+	}
+
+	w := &exportWriter{c: c, rc: http.NewResponseController(c.Writer), contentType: contentType,
+		disposition: mime.FormatMediaType("attachment", map[string]string{"filename": name + "." + string(format)})}
+	err = run(query, pvzID, w)
+	if err == nil {
+		return
+	}
+	if !c.Writer.Written() {
+		if errors.Is(err, errs.ErrInvalidReportPeriod) || errors.Is(err, errs.ErrUnsupportedExportFormat) ||
+			errors.Is(err, errs.ErrInvalidExportColumn) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, api.Error{Message: err.Error()})
+			return
+		}
+		h.Logger.ErrorContext(c.Request.Context(), "failed to export "+name, slog.String("op", op), slog.String("error", err.Error()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		return
+	}
+	h.Logger.ErrorContext(c.Request.Context(), "export of "+name+" interrupted", slog.String("op", op), slog.String("error", err.Error()))
+	panic(http.ErrAbortHandler)
+}
+
+// exportWriter sends file headers right before the first byte of an export and keeps pushing the write deadline
+type exportWriter struct {
+	c           *gin.Context
+	rc          *http.ResponseController
+	contentType string
+	disposition string
+}
+
+func (w *exportWriter) Write(p []byte) (int, error) {
+	if !w.c.Writer.Written() {
+		w.c.Header("Content-Type", w.contentType)
+		w.c.Header("Content-Disposition", w.disposition)
+		w.c.Status(http.StatusOK)
+	}
+	// not every ResponseWriter supports deadlines, e.g. the test recorder, the server timeout applies then
+	_ = w.rc.SetWriteDeadline(time.Now().Add(exportWriteTimeout))
+	return w.c.Writer.Write(p)
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockExportService is a mock implementation of service.Export, it writes the body given to Return before the error
+type MockExportService struct {
+	mock.Mock
+}
+
+func (m *MockExportService) Receptions(ctx context.Context, params api.GetExportsReceptionsParams, w io.Writer) error {
+	args := m.Called(params)
+	if body := args.String(0); body != "" {
+		io.WriteString(w, body)
+	}
+	return args.Error(1)
+}
+
+func (m *MockExportService) Products(ctx context.Context, params api.GetExportsProductsParams, w io.Writer) error {
+	args := m.Called(params)
+	if body := args.String(0); body != "" {
+		io.WriteString(w, body)
+	}
+	return args.Error(1)
+}
+
+func TestExport(t *testing.T) {
+	pvzID := uuid.New()
+	xlsx := api.ExportXLSX
+	city := "Москва"
+
+	tests := []struct {
+		name           string
+		role           api.UserRole
+		path           string
+		mockSetup      func(*MockExportService)
+		expectedCode   int
+		expectedBody   string
+		expectedHeader map[string]string
+	}{
+		{
+			name: "receptions csv",
+			role: api.UserRoleModerator,
+			path: "/exports/receptions?columns=receptionId,%20status&pvzId=" + pvzID.String(),
+			mockSetup: func(m *MockExportService) {
+				columns := []string{"receptionId", "status"}
+				m.On("Receptions", api.GetExportsReceptionsParams{Columns: &columns, PvzId: &pvzID}).Return("receptionId,status\n", nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: "receptionId,status\n",
+			expectedHeader: map[string]string{
+				"Content-Type":        "text/csv; charset=utf-8",
+				"Content-Disposition": "attachment; filename=receptions.csv",
+			},
+		},
+		{
+			name: "products xlsx",
+			role: api.UserRoleModerator,
+			path: "/exports/products?format=xlsx&city=" + city,
+			mockSetup: func(m *MockExportService) {
+				m.On("Products", api.GetExportsProductsParams{Format: &xlsx, City: &city}).Return("PK", nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedHeader: map[string]string{
+				"Content-Type":        "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+				"Content-Disposition": "attachment; filename=products.xlsx",
+			},
+		},
+		{
+			name:         "employee is not allowed",
+			role:         api.UserRoleEmployee,
+			path:         "/exports/receptions",
+			mockSetup:    func(m *MockExportService) {},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "unknown format",
+			role:         api.UserRoleModerator,
+			path:         "/exports/receptions?format=pdf",
+			mockSetup:    func(m *MockExportService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid pvz id",
+			role:         api.UserRoleModerator,
+			path:         "/exports/products?pvzId=abc",
+			mockSetup:    func(m *MockExportService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "unknown column",
+			role: api.UserRoleModerator,
+			path: "/exports/products?columns=price",
+			mockSetup: func(m *MockExportService) {
+				columns := []string{"price"}
+				m.On("Products", api.GetExportsProductsParams{Columns: &columns}).Return("", errs.ErrInvalidExportColumn)
+			},
+			expectedCode:   http.StatusBadRequest,
+			expectedHeader: map[string]string{"Content-Type": "application/json; charset=utf-8"},
+		},
+		{
+			name: "service error before the file",
+			role: api.UserRoleModerator,
+			path: "/exports/receptions",
+			mockSetup: func(m *MockExportService) {
+				m.On("Receptions", api.GetExportsReceptionsParams{}).Return("", assert.AnError)
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExport := new(MockExportService)
+			tt.mockSetup(mockExport)
+
+			router := setupExportRouter(mockExport, tt.role)
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			}
+			for name, value := range tt.expectedHeader {
+				assert.Equal(t, value, w.Header().Get(name), name)
+			}
+			mockExport.AssertExpectations(t)
+		})
+	}
+}
+
+func TestExport_Interrupted(t *testing.T) {
+	mockExport := new(MockExportService)
+	mockExport.On("Receptions", api.GetExportsReceptionsParams{}).Return("receptionId\n", assert.AnError)
+
+	router := setupExportRouter(mockExport, api.UserRoleModerator)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/exports/receptions", nil)
+
+	// the server drops the connection on ErrAbortHandler, so the client sees a failed download
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() { router.ServeHTTP(w, req) })
+	mockExport.AssertExpectations(t)
+}
+
+func setupExportRouter(m *MockExportService, role api.UserRole) *gin.Engine {
+	h := &Handler{
+		Services: &service.Service{Export: m},
+		Logger:   slog.Default(),
+	}
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(userRole, role)
+	})
+	router.GET("/exports/receptions", h.ExportReceptions)
+	router.GET("/exports/products", h.ExportProducts)
+	return router
+}
@@ -4,6 +4,7 @@ import (
 	"log/slog"
 
 	"github.com/ST359/pvz-service/internal/api"
+	"github.com/ST359/pvz-service/internal/metrics"
 	"github.com/ST359/pvz-service/internal/service"
 	"github.com/gin-gonic/gin"
 )
@@ -12,19 +13,27 @@ var (
 	ErrMessageAccessDenied        = api.Error{Message: "Access denied"}
 	ErrMessageBadRequest          = api.Error{Message: "Bad request"}
 	ErrMessageInternalServerError = api.Error{Message: "Internal server error"}
+	ErrMessageNotFound            = api.Error{Message: "Not found"}
+	ErrMessageRequestTooLarge     = api.Error{Message: "Request body is too large"}
 	ErrMessageWrongCredentials    = api.Error{Message: "Wrong credentials"}
 )
 
 type Handler struct {
 	Services *service.Service
 	Logger   *slog.Logger
+	// Metrics may be nil, then requests aren't measured
+	Metrics *metrics.HTTP
 }
 
-func NewHandler(service *service.Service, Logger *slog.Logger) *Handler {
-	return &Handler{Services: service, Logger: Logger}
+func NewHandler(service *service.Service, Logger *slog.Logger, metrics *metrics.HTTP) *Handler {
+	return &Handler{Services: service, Logger: Logger, Metrics: metrics}
 }
 func (h *Handler) InitRoutes() *gin.Engine {
 	r := gin.New()
+	r.Use(h.tracingMW)
+	if h.Metrics != nil {
+		r.Use(h.metricsMW)
+	}
 	public := r.Group("/")
 	{
 		public.POST("/dummyLogin", h.DummyLogin)
@@ -34,16 +43,68 @@ func (h *Handler) InitRoutes() *gin.Engine {
 
 	// Routes with auth
 	protected := r.Group("/")
-	protected.Use(h.userRoleMW)
+	protected.Use(h.userRoleMW, h.idempotencyMW)
 	{
 		protected.POST("/pvz", h.CreatePVZ)
 		protected.GET("/pvz", h.GetPVZ)
+		protected.POST("/pvz/import", h.ImportPVZ)
 		protected.POST("/pvz/:pvzId/close_last_reception", h.CloseLastReception)
 		protected.POST("/pvz/:pvzId/delete_last_product", h.DeleteLastProduct)
+		protected.POST("/pvz/:pvzId/return_products", h.ReturnProducts)
+		protected.GET("/pvz/:pvzId/inventory", h.GetInventory)
+		protected.POST("/pvz/:pvzId/pickup", h.Pickup)
+		protected.POST("/pvz/:pvzId/pickup_codes", h.RegeneratePickupCode)
+		protected.GET("/pvz/:pvzId/cells", h.GetStorageCells)
+		protected.POST("/pvz/:pvzId/cells", h.CreateStorageCell)
+		protected.GET("/pvz/:pvzId/cells/suggest", h.SuggestStorageCell)
+		protected.PUT("/pvz/:pvzId/cells/:cellId", h.UpdateStorageCell)
+		protected.DELETE("/pvz/:pvzId/cells/:cellId", h.DeleteStorageCell)
+		protected.POST("/pvz/:pvzId/cells/:cellId/products", h.PlaceProducts)
 
 		protected.POST("/receptions", h.CreateReception)
+		protected.GET("/receptions", h.ListReceptions)
+		protected.GET("/receptions/:receptionId", h.GetReception)
+		protected.POST("/receptions/:receptionId/reopen", h.ReopenReception)
+		protected.DELETE("/receptions/:receptionId/products/:productId", h.DeleteProduct)
+		protected.GET("/receptions/:receptionId/deleted_products", h.GetDeletedProducts)
+		protected.PUT("/receptions/:receptionId/manifest", h.UploadManifest)
+		protected.GET("/receptions/:receptionId/manifest", h.GetManifestProgress)
+		protected.GET("/receptions/:receptionId/discrepancies", h.GetDiscrepancies)
+		protected.GET("/receptions/:receptionId/attachments", h.GetReceptionAttachments)
+		protected.POST("/receptions/:receptionId/attachments", h.UploadReceptionAttachment)
 
 		protected.POST("/products", h.AddProduct)
+		protected.POST("/products/batch", h.AddProducts)
+		protected.GET("/products", h.FindProducts)
+		protected.GET("/products/:productId/transfers", h.GetProductTransfers)
+		protected.GET("/products/:productId/attachments", h.GetProductAttachments)
+		protected.POST("/products/:productId/attachments", h.UploadProductAttachment)
+		protected.PUT("/products/:productId/condition", h.SetProductCondition)
+
+		protected.GET("/damage_reports", h.ListDamageReports)
+		protected.POST("/damage_reports/:reportId/decision", h.DecideDamageReport)
+
+		protected.GET("/attachments/:attachmentId", h.GetAttachment)
+		protected.GET("/attachments/:attachmentId/content", h.DownloadAttachment)
+
+		protected.POST("/transfers", h.CreateTransfer)
+		protected.GET("/transfers", h.ListTransfers)
+		protected.GET("/transfers/:transferId", h.GetTransfer)
+		protected.POST("/transfers/:transferId/dispatch", h.DispatchTransfer)
+		protected.POST("/transfers/:transferId/arrive", h.ArriveTransfer)
+
+		protected.GET("/product_types", h.GetProductTypes)
+		protected.POST("/product_types", h.CreateProductType)
+		protected.PUT("/product_types/:code", h.UpdateProductType)
+		protected.DELETE("/product_types/:code", h.DeleteProductType)
+
+		protected.GET("/reports/returns", h.GetReturnsReport)
+		protected.GET("/reports/volume", h.GetVolumeReport)
+		protected.GET("/reports/employees", h.GetEmployeesReport)
+		protected.GET("/reports/employees/me", h.GetOwnProductivity)
+
+		protected.GET("/exports/receptions", h.ExportReceptions)
+		protected.GET("/exports/products", h.ExportProducts)
 	}
 	return r
 }
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// responseRecorder keeps a copy of the response body, so it can be stored for replays
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// idempotencyMW replays the stored response to a POST request retried with the same Idempotency-Key.
+// Responses with 5xx codes are not stored, so such requests can be retried with the same key
+func (h *Handler) idempotencyMW(c *gin.Context) {
+	const op = "handler.idempotency.idempotencyMW"
+
+	key := c.GetHeader(idempotencyKeyHeader)
+	if c.Request.Method != http.MethodPost || key == "" {
+		c.Next()
+		return
+	}
+	// the body is buffered to fingerprint it, so it is limited before any handler gets to limit it
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, h.maxIdempotentBodySize()))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, ErrMessageRequestTooLarge)
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	scope := idempotencyScope(c)
+	stored, err := h.Services.Idempotency.Begin(c.Request.Context(), scope, key, requestFingerprint(c.Request, body))
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrInvalidIdempotencyKey):
+			c.AbortWithStatusJSON(http.StatusBadRequest, api.Error{Message: err.Error()})
+		case errors.Is(err, errs.ErrIdempotencyKeyReused) || errors.Is(err, errs.ErrIdempotencyKeyInProgress):
+			c.AbortWithStatusJSON(http.StatusConflict, api.Error{Message: err.Error()})
+		default:
+			h.Logger.ErrorContext(c.Request.Context(), "failed to claim idempotency key", slog.String("op", op), slog.String("error", err.Error()))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		}
+		return
+	}
+	if stored != nil {
+		c.Header(idempotentReplayedHeader, "true")
+		c.Data(stored.StatusCode, stored.ContentType, stored.Body)
+		c.Abort()
+		return
+	}
+
+	recorder := &responseRecorder{ResponseWriter: c.Writer}
+	c.Writer = recorder
+	// the key is settled even if the client goes away and cancels the request
+	settleCtx := context.WithoutCancel(c.Request.Context())
+	done := false
+	// the key is freed if a handler panics, otherwise retries would wait for the whole TTL
+	defer func() {
+		if done {
+			return
+		}
+		if err := h.Services.Idempotency.Release(settleCtx, scope, key); err != nil {
+			h.Logger.ErrorContext(c.Request.Context(), "failed to release idempotency key", slog.String("op", op), slog.String("error", err.Error()))
+		}
+	}()
+
+	c.Next()
+
+	if recorder.Status() >= http.StatusInternalServerError {
+		return
+	}
+	done = true
+	resp := repository.IdempotentResponse{
+		StatusCode:  recorder.Status(),
+		ContentType: recorder.Header().Get("Content-Type"),
+		Body:        recorder.body.Bytes(),
+	}
+	if err := h.Services.Idempotency.Complete(settleCtx, scope, key, resp); err != nil {
+		h.Logger.ErrorContext(c.Request.Context(), "failed to store idempotent response", slog.String("op", op), slog.String("error", err.Error()))
+	}
+}
+
+// maxIdempotentBodySize is the largest body a protected POST route accepts, which is an attachment upload or an import
+func (h *Handler) maxIdempotentBodySize() int64 {
+	return max(h.Services.Attachment.MaxSize()+multipartOverhead, maxImportBodySize)
+}
+
+// idempotencyScope separates keys of different users, dummy tokens have no user and share a scope per role
+func idempotencyScope(c *gin.Context) string {
+	if id := currentUserID(c); id != uuid.Nil {
+		return id.String()
+	}
+	role, _ := c.Get(userRole)
+	r, _ := role.(api.UserRole)
+	return "dummy:" + string(r)
+}
+
+// requestFingerprint identifies a request by its route and body, a key can't be reused for another request
+func requestFingerprint(r *http.Request, body []byte) string {
+	sum := sha256.New()
+	sum.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	sum.Write(body)
+	return hex.EncodeToString(sum.Sum(nil))
+}
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/repository"
+	"github.com/ST359/pvz-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockIdempotencyService is a mock implementation of service.Idempotency
+type MockIdempotencyService struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyService) Begin(ctx context.Context, scope, key, fingerprint string) (*repository.IdempotentResponse, error) {
+	args := m.Called(scope, key, fingerprint)
+	return args.Get(0).(*repository.IdempotentResponse), args.Error(1)
+}
+
+func (m *MockIdempotencyService) Complete(ctx context.Context, scope, key string, resp repository.IdempotentResponse) error {
+	args := m.Called(scope, key, resp)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyService) Release(ctx context.Context, scope, key string) error {
+	args := m.Called(scope, key)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyService) DeleteExpiredKeys(ctx context.Context) (int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func setupIdempotencyRouter(h *Handler, uid uuid.UUID, status int) *gin.Engine {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(userRole, api.UserRoleEmployee)
+		c.Set(userID, uid)
+	}, h.idempotencyMW)
+	router.POST("/receptions", func(c *gin.Context) {
+		c.JSON(status, gin.H{"id": "new"})
+	})
+	router.GET("/receptions", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{})
+	})
+	return router
+}
+
+func TestIdempotencyMW(t *testing.T) {
+	uid := uuid.New()
+	scope := uid.String()
+	body := `{"pvzId":"1"}`
+	fp := requestFingerprint(httptest.NewRequest(http.MethodPost, "/receptions", nil), []byte(body))
+	created := repository.IdempotentResponse{
+		StatusCode:  http.StatusCreated,
+		ContentType: "application/json; charset=utf-8",
+		Body:        []byte(`{"id":"new"}`),
+	}
+
+	tests := []struct {
+		name             string
+		method           string
+		key              string
+		body             string
+		handlerStatus    int
+		mockSetup        func(*MockIdempotencyService)
+		expectedStatus   int
+		expectedReplayed bool
+	}{
+		{
+			name:          "first request stores the response",
+			method:        http.MethodPost,
+			key:           "key-1",
+			handlerStatus: http.StatusCreated,
+			mockSetup: func(m *MockIdempotencyService) {
+				m.On("Begin", scope, "key-1", fp).Return((*repository.IdempotentResponse)(nil), nil)
+				m.On("Complete", scope, "key-1", created).Return(nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:          "retry replays the stored response",
+			method:        http.MethodPost,
+			key:           "key-1",
+			handlerStatus: http.StatusCreated,
+			mockSetup: func(m *MockIdempotencyService) {
+				m.On("Begin", scope, "key-1", fp).Return(&created, nil)
+			},
+			expectedStatus:   http.StatusCreated,
+			expectedReplayed: true,
+		},
+		{
+			name:          "key reused with another body",
+			method:        http.MethodPost,
+			key:           "key-1",
+			handlerStatus: http.StatusCreated,
+			mockSetup: func(m *MockIdempotencyService) {
+				m.On("Begin", scope, "key-1", fp).
+					Return((*repository.IdempotentResponse)(nil), errs.ErrIdempotencyKeyReused)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:          "invalid key",
+			method:        http.MethodPost,
+			key:           "key 1",
+			handlerStatus: http.StatusCreated,
+			mockSetup: func(m *MockIdempotencyService) {
+				m.On("Begin", scope, "key 1", fp).
+					Return((*repository.IdempotentResponse)(nil), errs.ErrInvalidIdempotencyKey)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:          "server error releases the key",
+			method:        http.MethodPost,
+			key:           "key-1",
+			handlerStatus: http.StatusInternalServerError,
+			mockSetup: func(m *MockIdempotencyService) {
+				m.On("Begin", scope, "key-1", fp).Return((*repository.IdempotentResponse)(nil), nil)
+				m.On("Release", scope, "key-1").Return(nil)
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "body over the largest route limit",
+			method:         http.MethodPost,
+			key:            "key-1",
+			body:           strings.Repeat("a", maxImportBodySize+1),
+			handlerStatus:  http.StatusCreated,
+			mockSetup:      func(m *MockIdempotencyService) {},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:           "request without a key",
+			method:         http.MethodPost,
+			handlerStatus:  http.StatusCreated,
+			mockSetup:      func(m *MockIdempotencyService) {},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "get request is not tracked",
+			method:         http.MethodGet,
+			key:            "key-1",
+			mockSetup:      func(m *MockIdempotencyService) {},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockIdempotency := new(MockIdempotencyService)
+			tt.mockSetup(mockIdempotency)
+
+			h := &Handler{
+				Services: &service.Service{Idempotency: mockIdempotency, Attachment: new(MockAttachmentService)},
+				Logger:   slog.Default(),
+			}
+			router := setupIdempotencyRouter(h, uid, tt.handlerStatus)
+
+			reqBody := body
+			if tt.body != "" {
+				reqBody = tt.body
+			}
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, "/receptions", bytes.NewBufferString(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.key != "" {
+				req.Header.Set(idempotencyKeyHeader, tt.key)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedReplayed {
+				assert.Equal(t, "true", w.Header().Get(idempotentReplayedHeader))
+				assert.Equal(t, string(created.Body), w.Body.String())
+			}
+			mockIdempotency.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) UploadManifest(c *gin.Context) {
+	const op = "handler.manifest.UploadManifest"
+
+	role, _ := c.Get(userRole)
+	if role != api.UserRoleEmployee {
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrMessageAccessDenied)
+		return
+	}
+	recID, err := uuid.Parse(c.Param("receptionId"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+
+	var items []api.ManifestItem
+	if c.ContentType() == "text/csv" {
+		items, err = service.ParseManifestCSV(http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBodySize))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, api.Error{Message: err.Error()})
+			return
+		}
+	} else {
+		var req api.PutReceptionsReceptionIdManifestJSONRequestBody
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+			return
+		}
+		items = req.Items
+	}
+
+	progress, err := h.Services.Manifest.Upload(c.Request.Context(), recID, items)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrReceptionNotFound):
+			c.AbortWithStatusJSON(http.StatusNotFound, api.Error{Message: err.Error()})
+		case errors.Is(err, errs.ErrReceptionClosed):
+			c.AbortWithStatusJSON(http.StatusConflict, api.Error{Message: err.Error()})
+		case errors.Is(err, errs.ErrInvalidManifest) || errors.Is(err, errs.ErrManifestTooLarge) ||
+			errors.Is(err, errs.ErrInvalidProductType):
+			c.AbortWithStatusJSON(http.StatusBadRequest, api.Error{Message: err.Error()})
+		default:
+			h.Logger.ErrorContext(c.Request.Context(), "failed to upload manifest", slog.String("op", op), slog.String("error", err.Error()))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, progress)
+}
+
+func (h *Handler) GetManifestProgress(c *gin.Context) {
+	const op = "handler.manifest.GetManifestProgress"
+
+	recID, err := uuid.Parse(c.Param("receptionId"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	progress, err := h.Services.Manifest.Progress(c.Request.Context(), recID)
+	if err != nil {
+		if errors.Is(err, errs.ErrReceptionNotFound) || errors.Is(err, errs.ErrManifestNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, api.Error{Message: err.Error()})
+			return
+		}
+		h.Logger.ErrorContext(c.Request.Context(), "failed to get manifest progress", slog.String("op", op), slog.String("error", err.Error()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		return
+	}
+	c.JSON(http.StatusOK, progress)
+}
+
+func (h *Handler) GetDiscrepancies(c *gin.Context) {
+	const op = "handler.manifest.GetDiscrepancies"
+
+	recID, err := uuid.Parse(c.Param("receptionId"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	report, err := h.Services.Manifest.Discrepancies(c.Request.Context(), recID)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrReceptionNotFound) || errors.Is(err, errs.ErrManifestNotFound):
+			c.AbortWithStatusJSON(http.StatusNotFound, api.Error{Message: err.Error()})
+		case errors.Is(err, errs.ErrDiscrepancyReportNotReady):
+			c.AbortWithStatusJSON(http.StatusConflict, api.Error{Message: err.Error()})
+		default:
+			h.Logger.ErrorContext(c.Request.Context(), "failed to get discrepancies", slog.String("op", op), slog.String("error", err.Error()))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockManifestService is a mock implementation of service.Manifest
+type MockManifestService struct {
+	mock.Mock
+}
+
+func (m *MockManifestService) Upload(ctx context.Context, recID uuid.UUID, items []api.ManifestItem) (api.ManifestProgress, error) {
+	args := m.Called(recID, items)
+	return args.Get(0).(api.ManifestProgress), args.Error(1)
+}
+
+func (m *MockManifestService) Progress(ctx context.Context, recID uuid.UUID) (api.ManifestProgress, error) {
+	args := m.Called(recID)
+	return args.Get(0).(api.ManifestProgress), args.Error(1)
+}
+
+func (m *MockManifestService) Discrepancies(ctx context.Context, recID uuid.UUID) ([]api.ReceptionDiscrepancy, error) {
+	args := m.Called(recID)
+	return args.Get(0).([]api.ReceptionDiscrepancy), args.Error(1)
+}
+
+func TestManifest(t *testing.T) {
+	recID := uuid.New()
+	items := []api.ManifestItem{{Barcode: "4607001234567", Type: api.ProductTypeShoes}}
+	progress := api.ManifestProgress{ReceptionId: recID, Expected: 1, Missing: 1}
+	manifestPath := "/receptions/" + recID.String() + "/manifest"
+	discrepanciesPath := "/receptions/" + recID.String() + "/discrepancies"
+
+	tests := []struct {
+		name           string
+		role           api.UserRole
+		method         string
+		path           string
+		contentType    string
+		body           string
+		mockSetup      func(*MockManifestService)
+		expectedStatus int
+	}{
+		{
+			name:        "upload json",
+			role:        api.UserRoleEmployee,
+			method:      "PUT",
+			path:        manifestPath,
+			contentType: "application/json",
+			body:        `{"items":[{"barcode":"4607001234567","type":"shoes"}]}`,
+			mockSetup: func(m *MockManifestService) {
+				m.On("Upload", recID, items).Return(progress, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "upload csv",
+			role:        api.UserRoleEmployee,
+			method:      "PUT",
+			path:        manifestPath,
+			contentType: "text/csv",
+			body:        "barcode,type\n4607001234567,shoes\n",
+			mockSetup: func(m *MockManifestService) {
+				m.On("Upload", recID, items).Return(progress, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "csv without header",
+			role:           api.UserRoleEmployee,
+			method:         "PUT",
+			path:           manifestPath,
+			contentType:    "text/csv",
+			body:           "4607001234567,shoes\n",
+			mockSetup:      func(m *MockManifestService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "moderator cannot upload",
+			role:           api.UserRoleModerator,
+			method:         "PUT",
+			path:           manifestPath,
+			contentType:    "application/json",
+			body:           `{"items":[{"barcode":"4607001234567","type":"shoes"}]}`,
+			mockSetup:      func(m *MockManifestService) {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:        "upload to closed reception",
+			role:        api.UserRoleEmployee,
+			method:      "PUT",
+			path:        manifestPath,
+			contentType: "application/json",
+			body:        `{"items":[{"barcode":"4607001234567","type":"shoes"}]}`,
+			mockSetup: func(m *MockManifestService) {
+				m.On("Upload", recID, items).Return(api.ManifestProgress{}, errs.ErrReceptionClosed)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:   "progress",
+			role:   api.UserRoleModerator,
+			method: "GET",
+			path:   manifestPath,
+			mockSetup: func(m *MockManifestService) {
+				m.On("Progress", recID).Return(progress, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "progress without manifest",
+			role:   api.UserRoleEmployee,
+			method: "GET",
+			path:   manifestPath,
+			mockSetup: func(m *MockManifestService) {
+				m.On("Progress", recID).Return(api.ManifestProgress{}, errs.ErrManifestNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:   "discrepancies",
+			role:   api.UserRoleEmployee,
+			method: "GET",
+			path:   discrepanciesPath,
+			mockSetup: func(m *MockManifestService) {
+				m.On("Discrepancies", recID).Return([]api.ReceptionDiscrepancy{{Kind: api.DiscrepancyMissing}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "discrepancies of open reception",
+			role:   api.UserRoleEmployee,
+			method: "GET",
+			path:   discrepanciesPath,
+			mockSetup: func(m *MockManifestService) {
+				m.On("Discrepancies", recID).Return([]api.ReceptionDiscrepancy(nil), errs.ErrDiscrepancyReportNotReady)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockManifest := new(MockManifestService)
+			tt.mockSetup(mockManifest)
+
+			h := &Handler{
+				Services: &service.Service{Manifest: mockManifest},
+				Logger:   slog.Default(),
+			}
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set(userRole, tt.role)
+			})
+			router.PUT("/receptions/:receptionId/manifest", h.UploadManifest)
+			router.GET("/receptions/:receptionId/manifest", h.GetManifestProgress)
+			router.GET("/receptions/:receptionId/discrepancies", h.GetDiscrepancies)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockManifest.AssertExpectations(t)
+		})
+	}
+}
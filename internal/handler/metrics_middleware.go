@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute labels requests that didn't match any route, so random paths don't create new series
+const unmatchedRoute = "unmatched"
+
+// metricsMW records the count and latency of every request by method, route template and status
+func (h *Handler) metricsMW(c *gin.Context) {
+	start := time.Now()
+	c.Next()
+
+	route := c.FullPath()
+	method := c.Request.Method
+	if route == "" {
+		route = unmatchedRoute
+		if !knownMethod(method) {
+			method = "other"
+		}
+	}
+	h.Metrics.ObserveRequest(method, route, c.Writer.Status(), time.Since(start))
+}
+
+func knownMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ST359/pvz-service/internal/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsMW(t *testing.T) {
+	registry := metrics.NewRegistry()
+	h := &Handler{Logger: slog.Default(), Metrics: metrics.NewHTTP(registry)}
+	router := gin.New()
+	router.Use(h.metricsMW)
+	router.GET("/pvz/:pvzId/inventory", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/pvz/1/inventory", nil),
+		httptest.NewRequest(http.MethodGet, "/pvz/2/inventory", nil),
+		httptest.NewRequest(http.MethodGet, "/unknown", nil),
+		httptest.NewRequest("BREW", "/coffee", nil),
+	} {
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	w := httptest.NewRecorder()
+	metrics.Handler(registry).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(w.Body)
+	assert.Contains(t, string(body), `http_requests_total{method="GET",route="/pvz/:pvzId/inventory",status="204"} 2`)
+	assert.Contains(t, string(body), `http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.Contains(t, string(body), `http_requests_total{method="other",route="unmatched",status="404"} 1`)
+}
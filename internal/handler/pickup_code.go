@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) Pickup(c *gin.Context) {
+	const op = "handler.pickup_code.Pickup"
+
+	role, _ := c.Get(userRole)
+	if role != api.UserRoleEmployee {
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrMessageAccessDenied)
+		return
+	}
+	pvzID, err := uuid.Parse(c.Param("pvzId"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	var req api.PostPvzPvzIdPickupJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	prods, err := h.Services.PickupCode.Verify(c.Request.Context(), pvzID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrPickupCodeNotFound):
+			c.AbortWithStatusJSON(http.StatusNotFound, api.Error{Message: err.Error()})
+		case errors.Is(err, errs.ErrPickupCodeAttemptsExceeded):
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, api.Error{Message: err.Error()})
+		case errors.Is(err, errs.ErrInvalidPickupCode) || errors.Is(err, errs.ErrInvalidPickupCodeRef) ||
+			errors.Is(err, errs.ErrProductInOpenReception) || errors.Is(err, errs.ErrInvalidProductState):
+			c.AbortWithStatusJSON(http.StatusBadRequest, api.Error{Message: err.Error()})
+		default:
+			h.Logger.ErrorContext(c.Request.Context(), "failed to verify pickup code", slog.String("op", op), slog.String("error", err.Error()))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, prods)
+}
+
+func (h *Handler) RegeneratePickupCode(c *gin.Context) {
+	const op = "handler.pickup_code.RegeneratePickupCode"
+
+	role, _ := c.Get(userRole)
+	if role != api.UserRoleModerator {
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrMessageAccessDenied)
+		return
+	}
+	pvzID, err := uuid.Parse(c.Param("pvzId"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	var ref api.PostPvzPvzIdPickupCodesJSONRequestBody
+	if err := c.ShouldBindJSON(&ref); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	code, err := h.Services.PickupCode.Regenerate(c.Request.Context(), pvzID, ref)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrProductNotFound):
+			c.AbortWithStatusJSON(http.StatusNotFound, api.Error{Message: err.Error()})
+		case errors.Is(err, errs.ErrInvalidPickupCodeRef) || errors.Is(err, errs.ErrProductInOpenReception) ||
+			errors.Is(err, errs.ErrInvalidProductState):
+			c.AbortWithStatusJSON(http.StatusBadRequest, api.Error{Message: err.Error()})
+		default:
+			h.Logger.ErrorContext(c.Request.Context(), "failed to regenerate pickup code", slog.String("op", op), slog.String("error", err.Error()))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, code)
+}
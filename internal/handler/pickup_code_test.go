@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockPickupCodeService is a mock implementation of service.PickupCode
+type MockPickupCodeService struct {
+	mock.Mock
+}
+
+func (m *MockPickupCodeService) Regenerate(ctx context.Context, pvzID uuid.UUID, ref api.PickupCodeRef) (api.PickupCode, error) {
+	args := m.Called(pvzID, ref)
+	return args.Get(0).(api.PickupCode), args.Error(1)
+}
+
+func (m *MockPickupCodeService) Verify(ctx context.Context, pvzID uuid.UUID, req api.PickupRequest) ([]api.Product, error) {
+	args := m.Called(pvzID, req)
+	return args.Get(0).([]api.Product), args.Error(1)
+}
+
+func TestPickup(t *testing.T) {
+	pvzID, prodID := uuid.New(), uuid.New()
+	req := api.PickupRequest{ProductId: &prodID, Code: "123456"}
+	body := `{"productId":"` + prodID.String() + `","code":"123456"}`
+	path := "/pvz/" + pvzID.String() + "/pickup"
+
+	tests := []struct {
+		name           string
+		role           api.UserRole
+		path           string
+		body           string
+		mockSetup      func(*MockPickupCodeService)
+		expectedStatus int
+	}{
+		{
+			name: "issued",
+			role: api.UserRoleEmployee,
+			path: path,
+			body: body,
+			mockSetup: func(m *MockPickupCodeService) {
+				m.On("Verify", pvzID, req).Return([]api.Product{{Id: &prodID, State: api.ProductStateIssued}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "moderator is not allowed",
+			role:           api.UserRoleModerator,
+			path:           path,
+			body:           body,
+			mockSetup:      func(m *MockPickupCodeService) {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "invalid pvz id",
+			role:           api.UserRoleEmployee,
+			path:           "/pvz/not-a-uuid/pickup",
+			body:           body,
+			mockSetup:      func(m *MockPickupCodeService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "wrong code",
+			role: api.UserRoleEmployee,
+			path: path,
+			body: body,
+			mockSetup: func(m *MockPickupCodeService) {
+				m.On("Verify", pvzID, req).Return([]api.Product(nil), errs.ErrInvalidPickupCode)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "no active code",
+			role: api.UserRoleEmployee,
+			path: path,
+			body: body,
+			mockSetup: func(m *MockPickupCodeService) {
+				m.On("Verify", pvzID, req).Return([]api.Product(nil), errs.ErrPickupCodeNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "attempts exceeded",
+			role: api.UserRoleEmployee,
+			path: path,
+			body: body,
+			mockSetup: func(m *MockPickupCodeService) {
+				m.On("Verify", pvzID, req).Return([]api.Product(nil), errs.ErrPickupCodeAttemptsExceeded)
+			},
+			expectedStatus: http.StatusTooManyRequests,
+		},
+		{
+			name: "service error",
+			role: api.UserRoleEmployee,
+			path: path,
+			body: body,
+			mockSetup: func(m *MockPickupCodeService) {
+				m.On("Verify", pvzID, req).Return([]api.Product(nil), assert.AnError)
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCodes := new(MockPickupCodeService)
+			tt.mockSetup(mockCodes)
+
+			h := &Handler{
+				Services: &service.Service{PickupCode: mockCodes},
+				Logger:   slog.Default(),
+			}
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set(userRole, tt.role)
+			})
+			router.POST("/pvz/:pvzId/pickup", h.Pickup)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockCodes.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRegeneratePickupCode(t *testing.T) {
+	pvzID := uuid.New()
+	order := "ORD-1"
+	ref := api.PickupCodeRef{ExternalOrderId: &order}
+	body := `{"externalOrderId":"ORD-1"}`
+
+	tests := []struct {
+		name           string
+		role           api.UserRole
+		mockSetup      func(*MockPickupCodeService)
+		expectedStatus int
+	}{
+		{
+			name: "regenerated",
+			role: api.UserRoleModerator,
+			mockSetup: func(m *MockPickupCodeService) {
+				m.On("Regenerate", pvzID, ref).Return(api.PickupCode{Id: uuid.New(), PvzId: pvzID, ExternalOrderId: &order}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "employee is not allowed",
+			role:           api.UserRoleEmployee,
+			mockSetup:      func(m *MockPickupCodeService) {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "nothing stored",
+			role: api.UserRoleModerator,
+			mockSetup: func(m *MockPickupCodeService) {
+				m.On("Regenerate", pvzID, ref).Return(api.PickupCode{}, errs.ErrProductNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "product in open reception",
+			role: api.UserRoleModerator,
+			mockSetup: func(m *MockPickupCodeService) {
+				m.On("Regenerate", pvzID, ref).Return(api.PickupCode{}, errs.ErrProductInOpenReception)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "service error",
+			role: api.UserRoleModerator,
+			mockSetup: func(m *MockPickupCodeService) {
+				m.On("Regenerate", pvzID, ref).Return(api.PickupCode{}, assert.AnError)
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCodes := new(MockPickupCodeService)
+			tt.mockSetup(mockCodes)
+
+			h := &Handler{
+				Services: &service.Service{PickupCode: mockCodes},
+				Logger:   slog.Default(),
+			}
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set(userRole, tt.role)
+			})
+			router.POST("/pvz/:pvzId/pickup_codes", h.RegeneratePickupCode)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/pvz/"+pvzID.String()+"/pickup_codes", bytes.NewBufferString(body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockCodes.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const maxInventoryLimit = 100
+
+func (h *Handler) FindProducts(c *gin.Context) {
+	const op = "handler.product.FindProducts"
+	//auth handled in middleware
+	var params api.GetProductsParams
+	if err := c.ShouldBindQuery(&params); err != nil || params.Barcode == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	locations, err := h.Services.Product.FindByBarcode(c.Request.Context(), params.Barcode)
+	if err != nil {
+		if errors.Is(err, errs.ErrInvalidBarcode) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, api.Error{Message: err.Error()})
+			return
+		}
+		h.Logger.ErrorContext(c.Request.Context(), "failed to find products by barcode", slog.String("op", op), slog.String("error", err.Error()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		return
+	}
+	c.JSON(http.StatusOK, locations)
+}
+
+// ReturnProducts sends stored products back to the sender. Products are handed to customers
+// only by POST /pvz/:pvzId/pickup, after their pickup code is verified
+func (h *Handler) ReturnProducts(c *gin.Context) {
+	const op = "handler.product.ReturnProducts"
+	role, _ := c.Get(userRole)
+	if role != api.UserRoleEmployee {
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrMessageAccessDenied)
+		return
+	}
+	pvzID, err := uuid.Parse(c.Param("pvzId"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	var req api.ProductIDList
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	prods, err := h.Services.Product.ReturnProducts(c.Request.Context(), pvzID, req.ProductIds)
+	if err != nil {
+		if errors.Is(err, errs.ErrProductNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, api.Error{Message: err.Error()})
+			return
+		}
+		if errors.Is(err, errs.ErrEmptyProductBatch) || errors.Is(err, errs.ErrProductBatchTooLarge) ||
+			errors.Is(err, errs.ErrProductInOpenReception) || errors.Is(err, errs.ErrInvalidProductState) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, api.Error{Message: err.Error()})
+			return
+		}
+		h.Logger.ErrorContext(c.Request.Context(), "failed to return products", slog.String("op", op), slog.String("error", err.Error()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		return
+	}
+	c.JSON(http.StatusOK, prods)
+}
+
+func (h *Handler) GetInventory(c *gin.Context) {
+	const op = "handler.product.GetInventory"
+	//auth handled in middleware
+	pvzID, err := uuid.Parse(c.Param("pvzId"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	var params api.GetPvzPvzIdInventoryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	if (params.Page != nil && *params.Page < 1) || (params.Limit != nil && (*params.Limit < 1 || *params.Limit > maxInventoryLimit)) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	if params.State != nil && !validProductState(*params.State) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	prods, err := h.Services.Product.GetInventory(c.Request.Context(), pvzID, params)
+	if err != nil {
+		if errors.Is(err, errs.ErrPVZNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, ErrMessageNotFound)
+			return
+		}
+		h.Logger.ErrorContext(c.Request.Context(), "failed to get pvz inventory", slog.String("op", op), slog.String("error", err.Error()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		return
+	}
+	c.JSON(http.StatusOK, prods)
+}
+
+func validProductState(state api.ProductState) bool {
+	switch state {
+	case api.ProductStateReceived, api.ProductStateStored, api.ProductStateIssued, api.ProductStateReturnedToSender:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,318 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockProductService is a mock implementation of service.Product
+type MockProductService struct {
+	mock.Mock
+}
+
+func (m *MockProductService) FindByBarcode(ctx context.Context, barcode string) ([]api.ProductLocation, error) {
+	args := m.Called(barcode)
+	return args.Get(0).([]api.ProductLocation), args.Error(1)
+}
+
+func (m *MockProductService) ReturnProducts(ctx context.Context, pvzID uuid.UUID, ids []uuid.UUID) ([]api.Product, error) {
+	args := m.Called(pvzID, ids)
+	return args.Get(0).([]api.Product), args.Error(1)
+}
+
+func (m *MockProductService) GetInventory(ctx context.Context, pvzID uuid.UUID, params api.GetPvzPvzIdInventoryParams) ([]api.Product, error) {
+	args := m.Called(pvzID, params)
+	return args.Get(0).([]api.Product), args.Error(1)
+}
+
+func TestFindProducts(t *testing.T) {
+	barcode := "4607001234567"
+	prodID, recID, pvzID := uuid.New(), uuid.New(), uuid.New()
+	locations := []api.ProductLocation{{
+		Product:         api.Product{Id: &prodID, ReceptionId: recID, Type: api.ProductTypeShoes, Barcode: &barcode, State: api.ProductStateStored},
+		PvzId:           pvzID,
+		ReceptionStatus: api.Close,
+	}}
+
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func(*MockProductService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "found",
+			query: "?barcode=" + barcode,
+			mockSetup: func(m *MockProductService) {
+				m.On("FindByBarcode", barcode).Return(locations, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `[{"product":{"id":"` + prodID.String() + `","receptionId":"` + recID.String() +
+				`","type":"shoes","barcode":"4607001234567","state":"stored"},"pvzId":"` + pvzID.String() + `","receptionStatus":"close"}]`,
+		},
+		{
+			name:  "nothing found",
+			query: "?barcode=000",
+			mockSetup: func(m *MockProductService) {
+				m.On("FindByBarcode", "000").Return([]api.ProductLocation{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[]`,
+		},
+		{
+			name:           "missing barcode",
+			query:          "",
+			mockSetup:      func(m *MockProductService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"Bad request"}`,
+		},
+		{
+			name:  "invalid barcode",
+			query: "?barcode=%20x",
+			mockSetup: func(m *MockProductService) {
+				m.On("FindByBarcode", " x").Return([]api.ProductLocation(nil), errs.ErrInvalidBarcode)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"invalid barcode"}`,
+		},
+		{
+			name:  "service error",
+			query: "?barcode=" + barcode,
+			mockSetup: func(m *MockProductService) {
+				m.On("FindByBarcode", barcode).Return([]api.ProductLocation(nil), assert.AnError)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"message":"Internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockProduct := new(MockProductService)
+			tt.mockSetup(mockProduct)
+
+			h := &Handler{
+				Services: &service.Service{Product: mockProduct},
+				Logger:   slog.Default(),
+			}
+			router := gin.New()
+			router.GET("/products", h.FindProducts)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/products"+tt.query, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			mockProduct.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReturnProducts(t *testing.T) {
+	pvzID, prodID := uuid.New(), uuid.New()
+	returned := []api.Product{{Id: &prodID, ReceptionId: uuid.New(), Type: api.ProductTypeShoes, State: api.ProductStateReturnedToSender}}
+	body := `{"productIds":["` + prodID.String() + `"]}`
+
+	tests := []struct {
+		name           string
+		role           api.UserRole
+		path           string
+		body           string
+		mockSetup      func(*MockProductService)
+		expectedStatus int
+	}{
+		{
+			name: "returned",
+			role: api.UserRoleEmployee,
+			path: "/pvz/" + pvzID.String() + "/return_products",
+			body: body,
+			mockSetup: func(m *MockProductService) {
+				m.On("ReturnProducts", pvzID, []uuid.UUID{prodID}).Return(returned, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "moderator is not allowed",
+			role:           api.UserRoleModerator,
+			path:           "/pvz/" + pvzID.String() + "/return_products",
+			body:           body,
+			mockSetup:      func(m *MockProductService) {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "invalid pvz id",
+			role:           api.UserRoleEmployee,
+			path:           "/pvz/not-a-uuid/return_products",
+			body:           body,
+			mockSetup:      func(m *MockProductService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid body",
+			role:           api.UserRoleEmployee,
+			path:           "/pvz/" + pvzID.String() + "/return_products",
+			body:           `{"productIds":["not-a-uuid"]}`,
+			mockSetup:      func(m *MockProductService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "product not found",
+			role: api.UserRoleEmployee,
+			path: "/pvz/" + pvzID.String() + "/return_products",
+			body: body,
+			mockSetup: func(m *MockProductService) {
+				m.On("ReturnProducts", pvzID, []uuid.UUID{prodID}).Return([]api.Product(nil), fmt.Errorf("%w: %s", errs.ErrProductNotFound, prodID))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "product in open reception",
+			role: api.UserRoleEmployee,
+			path: "/pvz/" + pvzID.String() + "/return_products",
+			body: body,
+			mockSetup: func(m *MockProductService) {
+				m.On("ReturnProducts", pvzID, []uuid.UUID{prodID}).Return([]api.Product(nil), fmt.Errorf("%w: %s", errs.ErrProductInOpenReception, prodID))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "already returned",
+			role: api.UserRoleEmployee,
+			path: "/pvz/" + pvzID.String() + "/return_products",
+			body: body,
+			mockSetup: func(m *MockProductService) {
+				m.On("ReturnProducts", pvzID, []uuid.UUID{prodID}).Return([]api.Product(nil), errs.ErrInvalidProductState)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "service error",
+			role: api.UserRoleEmployee,
+			path: "/pvz/" + pvzID.String() + "/return_products",
+			body: body,
+			mockSetup: func(m *MockProductService) {
+				m.On("ReturnProducts", pvzID, []uuid.UUID{prodID}).Return([]api.Product(nil), assert.AnError)
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockProduct := new(MockProductService)
+			tt.mockSetup(mockProduct)
+
+			h := &Handler{
+				Services: &service.Service{Product: mockProduct},
+				Logger:   slog.Default(),
+			}
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set(userRole, tt.role)
+			})
+			router.POST("/pvz/:pvzId/return_products", h.ReturnProducts)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockProduct.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetInventory(t *testing.T) {
+	pvzID := uuid.New()
+	stored := api.ProductStateStored
+	page, limit := 2, 10
+
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func(*MockProductService)
+		expectedStatus int
+	}{
+		{
+			name:  "all products",
+			query: "",
+			mockSetup: func(m *MockProductService) {
+				m.On("GetInventory", pvzID, api.GetPvzPvzIdInventoryParams{}).Return([]api.Product{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "stored products page",
+			query: "?state=stored&page=2&limit=10",
+			mockSetup: func(m *MockProductService) {
+				m.On("GetInventory", pvzID, api.GetPvzPvzIdInventoryParams{State: &stored, Page: &page, Limit: &limit}).Return([]api.Product{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unknown state",
+			query:          "?state=lost",
+			mockSetup:      func(m *MockProductService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "limit too large",
+			query:          "?limit=101",
+			mockSetup:      func(m *MockProductService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "pvz not found",
+			query: "",
+			mockSetup: func(m *MockProductService) {
+				m.On("GetInventory", pvzID, api.GetPvzPvzIdInventoryParams{}).Return([]api.Product(nil), errs.ErrPVZNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:  "service error",
+			query: "",
+			mockSetup: func(m *MockProductService) {
+				m.On("GetInventory", pvzID, api.GetPvzPvzIdInventoryParams{}).Return([]api.Product(nil), assert.AnError)
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockProduct := new(MockProductService)
+			tt.mockSetup(mockProduct)
+
+			h := &Handler{
+				Services: &service.Service{Product: mockProduct},
+				Logger:   slog.Default(),
+			}
+			router := gin.New()
+			router.GET("/pvz/:pvzId/inventory", h.GetInventory)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/pvz/"+pvzID.String()+"/inventory"+tt.query, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockProduct.AssertExpectations(t)
+		})
+	}
+}
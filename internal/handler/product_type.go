@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) GetProductTypes(c *gin.Context) {
+	const op = "handler.product_type.GetProductTypes"
+
+	var params api.GetProductTypesParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	types, err := h.Services.ProductType.List(c.Request.Context(), params.IncludeInactive != nil && *params.IncludeInactive)
+	if err != nil {
+		h.Logger.ErrorContext(c.Request.Context(), "failed to list product types", slog.String("op", op), slog.String("error", err.Error()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		return
+	}
+	c.JSON(http.StatusOK, types)
+}
+
+func (h *Handler) CreateProductType(c *gin.Context) {
+	const op = "handler.product_type.CreateProductType"
+
+	role, _ := c.Get(userRole)
+	if role != api.UserRoleModerator {
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrMessageAccessDenied)
+		return
+	}
+	var req api.PostProductTypesJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	pt, err := h.Services.ProductType.Create(c.Request.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrProductTypeExists):
+			c.AbortWithStatusJSON(http.StatusConflict, api.Error{Message: err.Error()})
+		case errors.Is(err, errs.ErrInvalidProductTypeCode) || errors.Is(err, errs.ErrProductTypeNamesEmpty):
+			c.AbortWithStatusJSON(http.StatusBadRequest, api.Error{Message: err.Error()})
+		default:
+			h.Logger.ErrorContext(c.Request.Context(), "failed to create product type", slog.String("op", op), slog.String("error", err.Error()))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, pt)
+}
+
+func (h *Handler) UpdateProductType(c *gin.Context) {
+	const op = "handler.product_type.UpdateProductType"
+
+	role, _ := c.Get(userRole)
+	if role != api.UserRoleModerator {
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrMessageAccessDenied)
+		return
+	}
+	var req api.PutProductTypesCodeJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	pt, err := h.Services.ProductType.Update(c.Request.Context(), c.Param("code"), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrProductTypeNotFound):
+			c.AbortWithStatusJSON(http.StatusNotFound, api.Error{Message: err.Error()})
+		case errors.Is(err, errs.ErrProductTypeNamesEmpty):
+			c.AbortWithStatusJSON(http.StatusBadRequest, api.Error{Message: err.Error()})
+		default:
+			h.Logger.ErrorContext(c.Request.Context(), "failed to update product type", slog.String("op", op), slog.String("error", err.Error()))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, pt)
+}
+
+func (h *Handler) DeleteProductType(c *gin.Context) {
+	const op = "handler.product_type.DeleteProductType"
+
+	role, _ := c.Get(userRole)
+	if role != api.UserRoleModerator {
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrMessageAccessDenied)
+		return
+	}
+	if err := h.Services.ProductType.Delete(c.Request.Context(), c.Param("code")); err != nil {
+		switch {
+		case errors.Is(err, errs.ErrProductTypeNotFound):
+			c.AbortWithStatusJSON(http.StatusNotFound, api.Error{Message: err.Error()})
+		case errors.Is(err, errs.ErrProductTypeInUse):
+			c.AbortWithStatusJSON(http.StatusConflict, api.Error{Message: err.Error()})
+		default:
+			h.Logger.ErrorContext(c.Request.Context(), "failed to delete product type", slog.String("op", op), slog.String("error", err.Error()))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		}
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockProductTypeService is a mock implementation of service.ProductType
+type MockProductTypeService struct {
+	mock.Mock
+}
+
+func (m *MockProductTypeService) List(ctx context.Context, includeInactive bool) ([]api.ProductTypeInfo, error) {
+	args := m.Called(includeInactive)
+	return args.Get(0).([]api.ProductTypeInfo), args.Error(1)
+}
+
+func (m *MockProductTypeService) Create(ctx context.Context, pt api.ProductTypeInput) (api.ProductTypeInfo, error) {
+	args := m.Called(pt)
+	return args.Get(0).(api.ProductTypeInfo), args.Error(1)
+}
+
+func (m *MockProductTypeService) Update(ctx context.Context, code string, pt api.ProductTypeUpdate) (api.ProductTypeInfo, error) {
+	args := m.Called(code, pt)
+	return args.Get(0).(api.ProductTypeInfo), args.Error(1)
+}
+
+func (m *MockProductTypeService) Delete(ctx context.Context, code string) error {
+	args := m.Called(code)
+	return args.Error(0)
+}
+
+func (m *MockProductTypeService) ResolveProductType(ctx context.Context, name string) (api.ProductType, error) {
+	args := m.Called(name)
+	return args.Get(0).(api.ProductType), args.Error(1)
+}
+
+func setupProductTypeRouter(h *Handler, role api.UserRole) *gin.Engine {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(userRole, role)
+	})
+	router.GET("/product_types", h.GetProductTypes)
+	router.POST("/product_types", h.CreateProductType)
+	router.PUT("/product_types/:code", h.UpdateProductType)
+	router.DELETE("/product_types/:code", h.DeleteProductType)
+	return router
+}
+
+func TestProductTypes(t *testing.T) {
+	names := map[string]string{"ru": "Мебель"}
+	furniture := api.ProductTypeInfo{Code: "furniture", Names: names, Active: true}
+
+	tests := []struct {
+		name           string
+		role           api.UserRole
+		method         string
+		path           string
+		body           string
+		mockSetup      func(*MockProductTypeService)
+		expectedStatus int
+	}{
+		{
+			name:   "employee lists active types",
+			role:   api.UserRoleEmployee,
+			method: "GET",
+			path:   "/product_types",
+			mockSetup: func(m *MockProductTypeService) {
+				m.On("List", false).Return([]api.ProductTypeInfo{furniture}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "moderator lists all types",
+			role:   api.UserRoleModerator,
+			method: "GET",
+			path:   "/product_types?includeInactive=true",
+			mockSetup: func(m *MockProductTypeService) {
+				m.On("List", true).Return([]api.ProductTypeInfo{furniture}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "create",
+			role:   api.UserRoleModerator,
+			method: "POST",
+			path:   "/product_types",
+			body:   `{"code":"furniture","names":{"ru":"Мебель"}}`,
+			mockSetup: func(m *MockProductTypeService) {
+				m.On("Create", api.ProductTypeInput{Code: "furniture", Names: names}).Return(furniture, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "employee cannot create",
+			role:           api.UserRoleEmployee,
+			method:         "POST",
+			path:           "/product_types",
+			body:           `{"code":"furniture","names":{"ru":"Мебель"}}`,
+			mockSetup:      func(m *MockProductTypeService) {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "create existing code",
+			role:   api.UserRoleModerator,
+			method: "POST",
+			path:   "/product_types",
+			body:   `{"code":"furniture","names":{"ru":"Мебель"}}`,
+			mockSetup: func(m *MockProductTypeService) {
+				m.On("Create", api.ProductTypeInput{Code: "furniture", Names: names}).Return(api.ProductTypeInfo{}, errs.ErrProductTypeExists)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:   "create invalid code",
+			role:   api.UserRoleModerator,
+			method: "POST",
+			path:   "/product_types",
+			body:   `{"code":"Мебель","names":{"ru":"Мебель"}}`,
+			mockSetup: func(m *MockProductTypeService) {
+				m.On("Create", api.ProductTypeInput{Code: "Мебель", Names: names}).Return(api.ProductTypeInfo{}, errs.ErrInvalidProductTypeCode)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "deactivate",
+			role:   api.UserRoleModerator,
+			method: "PUT",
+			path:   "/product_types/furniture",
+			body:   `{"names":{"ru":"Мебель"},"active":false,"requiresSignature":true}`,
+			mockSetup: func(m *MockProductTypeService) {
+				m.On("Update", "furniture", api.ProductTypeUpdate{Names: names, RequiresSignature: true}).
+					Return(api.ProductTypeInfo{Code: "furniture", Names: names, RequiresSignature: true}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "update unknown type",
+			role:   api.UserRoleModerator,
+			method: "PUT",
+			path:   "/product_types/toys",
+			body:   `{"names":{"ru":"Игрушки"},"active":true,"requiresSignature":false}`,
+			mockSetup: func(m *MockProductTypeService) {
+				m.On("Update", "toys", api.ProductTypeUpdate{Names: map[string]string{"ru": "Игрушки"}, Active: true}).
+					Return(api.ProductTypeInfo{}, errs.ErrProductTypeNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:   "delete",
+			role:   api.UserRoleModerator,
+			method: "DELETE",
+			path:   "/product_types/furniture",
+			mockSetup: func(m *MockProductTypeService) {
+				m.On("Delete", "furniture").Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:   "delete type in use",
+			role:   api.UserRoleModerator,
+			method: "DELETE",
+			path:   "/product_types/shoes",
+			mockSetup: func(m *MockProductTypeService) {
+				m.On("Delete", "shoes").Return(errs.ErrProductTypeInUse)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTypes := new(MockProductTypeService)
+			tt.mockSetup(mockTypes)
+
+			h := &Handler{
+				Services: &service.Service{ProductType: mockTypes},
+				Logger:   slog.Default(),
+			}
+			router := setupProductTypeRouter(h, tt.role)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockTypes.AssertExpectations(t)
+		})
+	}
+}
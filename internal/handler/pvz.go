@@ -1,13 +1,19 @@
 package handler
 
 import (
+	"errors"
 	"log/slog"
+	"mime"
 	"net/http"
 
 	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/service"
 	"github.com/gin-gonic/gin"
 )
 
+const maxImportBodySize = 10 << 20
+
 func (h *Handler) CreatePVZ(c *gin.Context) {
 	const op = "handler.pvz.CreatePVZ"
 
@@ -19,14 +25,18 @@ func (h *Handler) CreatePVZ(c *gin.Context) {
 	var pvzreq api.PostPvzJSONRequestBody
 	err := c.ShouldBind(&pvzreq)
 	if err != nil {
-		h.Logger.Error("failed to bind pvz request", slog.String("op", op), slog.String("error", err.Error()))
+		h.Logger.ErrorContext(c.Request.Context(), "failed to bind pvz request", slog.String("op", op), slog.String("error", err.Error()))
 		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
 		return
 	}
 
-	pvzres, err := h.Services.PVZ.Create(pvzreq)
+	pvzres, err := h.Services.PVZ.Create(c.Request.Context(), pvzreq)
 	if err != nil {
-		h.Logger.Error("failed to create pvz", slog.String("op", op), slog.String("error", err.Error()))
+		if errors.Is(err, errs.ErrPVZAddressExists) {
+			c.AbortWithStatusJSON(http.StatusConflict, api.Error{Message: err.Error()})
+			return
+		}
+		h.Logger.ErrorContext(c.Request.Context(), "failed to create pvz", slog.String("op", op), slog.String("error", err.Error()))
 		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
 		return
 	}
@@ -39,15 +49,80 @@ func (h *Handler) GetPVZ(c *gin.Context) {
 
 	err := c.ShouldBindQuery(&params)
 	if err != nil {
-		h.Logger.Error("failed to bind pvz get request", slog.String("op", op), slog.String("error", err.Error()))
+		h.Logger.ErrorContext(c.Request.Context(), "failed to bind pvz get request", slog.String("op", op), slog.String("error", err.Error()))
 		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
 		return
 	}
-	info, err := h.Services.GetByDate(params)
+	info, err := h.Services.GetByDate(c.Request.Context(), params)
 	if err != nil {
-		h.Logger.Error("failed to get pvz by date", slog.String("op", op), slog.String("error", err.Error()))
+		h.Logger.ErrorContext(c.Request.Context(), "failed to get pvz by date", slog.String("op", op), slog.String("error", err.Error()))
 		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
 		return
 	}
 	c.JSON(http.StatusOK, info)
 }
+func (h *Handler) ImportPVZ(c *gin.Context) {
+	const op = "handler.pvz.ImportPVZ"
+
+	role, _ := c.Get(userRole)
+	if role != api.UserRoleModerator {
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrMessageAccessDenied)
+		return
+	}
+	var params api.PostPvzImportParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	format, ok := importFormat(params.Format, c.ContentType())
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+
+	rows, err := service.ParsePVZImport(http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBodySize), format)
+	if err != nil {
+		if errors.Is(err, errs.ErrInvalidImportFile) || errors.Is(err, errs.ErrUnsupportedFormat) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, api.Error{Message: err.Error()})
+			return
+		}
+		h.Logger.ErrorContext(c.Request.Context(), "failed to parse pvz import", slog.String("op", op), slog.String("error", err.Error()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		return
+	}
+
+	dryRun := params.DryRun != nil && *params.DryRun
+	report, err := h.Services.PVZ.Import(c.Request.Context(), rows, dryRun)
+	if err != nil {
+		if errors.Is(err, errs.ErrPVZAddressExists) {
+			c.AbortWithStatusJSON(http.StatusConflict, api.Error{Message: err.Error()})
+			return
+		}
+		h.Logger.ErrorContext(c.Request.Context(), "failed to import pvz", slog.String("op", op), slog.String("error", err.Error()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		return
+	}
+	switch {
+	case dryRun:
+		c.JSON(http.StatusOK, report)
+	case report.Invalid > 0:
+		c.JSON(http.StatusUnprocessableEntity, report)
+	default:
+		c.JSON(http.StatusCreated, report)
+	}
+}
+
+// importFormat picks an explicitly requested format or detects it from the Content-Type
+func importFormat(format *api.PVZImportFormat, contentType string) (api.PVZImportFormat, bool) {
+	if format != nil {
+		return *format, *format == api.PVZImportCSV || *format == api.PVZImportNDJSON
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv":
+		return api.PVZImportCSV, true
+	case "application/x-ndjson", "application/jsonl", "application/json":
+		return api.PVZImportNDJSON, true
+	}
+	return "", false
+}
@@ -2,6 +2,7 @@ package handler_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -11,6 +12,7 @@ import (
 	"log/slog"
 
 	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
 	"github.com/ST359/pvz-service/internal/handler"
 	"github.com/ST359/pvz-service/internal/service"
 	"github.com/gin-gonic/gin"
@@ -24,16 +26,21 @@ type MockPVZService struct {
 	mock.Mock
 }
 
-func (m *MockPVZService) Create(pvz api.PVZ) (api.PVZ, error) {
+func (m *MockPVZService) Create(ctx context.Context, pvz api.PVZ) (api.PVZ, error) {
 	args := m.Called(pvz)
 	return args.Get(0).(api.PVZ), args.Error(1)
 }
 
-func (m *MockPVZService) GetByDate(params api.GetPvzParams) ([]api.PVZInfo, error) {
+func (m *MockPVZService) GetByDate(ctx context.Context, params api.GetPvzParams) ([]api.PVZInfo, error) {
 	args := m.Called(params)
 	return args.Get(0).([]api.PVZInfo), args.Error(1)
 }
 
+func (m *MockPVZService) Import(ctx context.Context, rows []service.PVZImportRow, dryRun bool) (api.PVZImportReport, error) {
+	args := m.Called(rows, dryRun)
+	return args.Get(0).(api.PVZImportReport), args.Error(1)
+}
+
 func TestHandler_CreatePVZ(t *testing.T) {
 	// Common test data
 	testID := uuid.New()
@@ -84,6 +91,18 @@ func TestHandler_CreatePVZ(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedBody:   api.Error{Message: "Bad request"},
 		},
+		{
+			name: "address already exists",
+			role: api.UserRoleModerator,
+			requestBody: api.PVZ{
+				City: testCity,
+			},
+			mockSetup: func(m *MockPVZService) {
+				m.On("Create", mock.AnythingOfType("api.PVZ")).Return(api.PVZ{}, errs.ErrPVZAddressExists)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   api.Error{Message: errs.ErrPVZAddressExists.Error()},
+		},
 		{
 			name: "service error",
 			role: api.UserRoleModerator,
@@ -282,3 +301,148 @@ func TestHandler_GetPVZ(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_ImportPVZ(t *testing.T) {
+	testID := uuid.New()
+	address := "ул. Тверская, 1"
+	csvBody := "city,address\nМосква,\"ул. Тверская, 1\"\n"
+	rows := []service.PVZImportRow{{Line: 2, City: "Москва", Address: address}}
+	createdReport := api.PVZImportReport{
+		Total:   1,
+		Valid:   1,
+		Created: 1,
+		Rows: []api.PVZImportRowResult{{
+			Line:    2,
+			City:    "Москва",
+			Address: address,
+			Status:  api.PVZImportRowCreated,
+			Pvz:     &api.PVZ{Id: &testID, City: api.Moscow, Address: &address},
+		}},
+	}
+	invalidErrs := []string{"unsupported city"}
+	invalidReport := api.PVZImportReport{
+		Total:   1,
+		Invalid: 1,
+		Rows: []api.PVZImportRowResult{{
+			Line:    2,
+			City:    "Тверь",
+			Address: address,
+			Status:  api.PVZImportRowInvalid,
+			Errors:  &invalidErrs,
+		}},
+	}
+
+	tests := []struct {
+		name           string
+		role           interface{}
+		query          string
+		contentType    string
+		body           string
+		mockSetup      func(*MockPVZService)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name:        "successful import",
+			role:        api.UserRoleModerator,
+			contentType: "text/csv",
+			body:        csvBody,
+			mockSetup: func(m *MockPVZService) {
+				m.On("Import", rows, false).Return(createdReport, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   createdReport,
+		},
+		{
+			name:        "dry run with format from query",
+			role:        api.UserRoleModerator,
+			query:       "?dryRun=true&format=ndjson",
+			contentType: "text/plain",
+			body:        `{"city":"Москва","address":"ул. Тверская, 1"}` + "\n",
+			mockSetup: func(m *MockPVZService) {
+				m.On("Import", []service.PVZImportRow{{Line: 1, City: "Москва", Address: address}}, true).
+					Return(api.PVZImportReport{DryRun: true, Total: 1, Valid: 1}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   api.PVZImportReport{DryRun: true, Total: 1, Valid: 1},
+		},
+		{
+			name:        "invalid rows",
+			role:        api.UserRoleModerator,
+			contentType: "text/csv",
+			body:        csvBody,
+			mockSetup: func(m *MockPVZService) {
+				m.On("Import", rows, false).Return(invalidReport, nil)
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   invalidReport,
+		},
+		{
+			name:           "access denied for non-moderator",
+			role:           api.UserRoleEmployee,
+			contentType:    "text/csv",
+			body:           csvBody,
+			mockSetup:      func(m *MockPVZService) {},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   api.Error{Message: "Access denied"},
+		},
+		{
+			name:           "unknown content type",
+			role:           api.UserRoleModerator,
+			contentType:    "application/xml",
+			body:           "<pvz/>",
+			mockSetup:      func(m *MockPVZService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   api.Error{Message: "Bad request"},
+		},
+		{
+			name:           "file without required columns",
+			role:           api.UserRoleModerator,
+			contentType:    "text/csv",
+			body:           "city\nМосква\n",
+			mockSetup:      func(m *MockPVZService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   api.Error{Message: "invalid import file: header must contain city and address columns"},
+		},
+		{
+			name:        "service error",
+			role:        api.UserRoleModerator,
+			contentType: "text/csv",
+			body:        csvBody,
+			mockSetup: func(m *MockPVZService) {
+				m.On("Import", rows, false).Return(api.PVZImportReport{}, assert.AnError)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   api.Error{Message: "Internal server error"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPVZ := new(MockPVZService)
+			tt.mockSetup(mockPVZ)
+
+			h := &handler.Handler{
+				Services: &service.Service{
+					PVZ: mockPVZ,
+				},
+				Logger: slog.Default(),
+			}
+
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+			ctx.Set("userRole", tt.role)
+			ctx.Request = httptest.NewRequest("POST", "/pvz/import"+tt.query, bytes.NewBufferString(tt.body))
+			ctx.Request.Header.Set("Content-Type", tt.contentType)
+
+			h.ImportPVZ(ctx)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			expectedJSON, _ := json.Marshal(tt.expectedBody)
+			assert.JSONEq(t, string(expectedJSON), w.Body.String())
+
+			mockPVZ.AssertExpectations(t)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/ST359/pvz-service/internal/api"
 	errs "github.com/ST359/pvz-service/internal/app_errors"
@@ -19,19 +20,23 @@ func (h *Handler) CreateReception(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusForbidden, ErrMessageAccessDenied)
 		return
 	}
-	var pvzID api.PostReceptionsJSONBody
-	err := c.ShouldBind(&pvzID)
+	var recReq api.PostReceptionsJSONBody
+	err := c.ShouldBind(&recReq)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
 		return
 	}
-	reception, err := h.Services.Reception.Create(pvzID.PvzId)
+	reception, err := h.Services.Reception.Create(c.Request.Context(), recReq.PvzId, receptionKind(recReq.Kind), currentUserID(c))
 	if err != nil {
 		if errors.Is(err, errs.ErrReceptionNotClosed) {
 			c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
 			return
 		}
-		h.Logger.Error("failed to open reception", slog.String("op", op), slog.String("error", err.Error()))
+		if errors.Is(err, errs.ErrInvalidReceptionKind) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, api.Error{Message: err.Error()})
+			return
+		}
+		h.Logger.ErrorContext(c.Request.Context(), "failed to open reception", slog.String("op", op), slog.String("error", err.Error()))
 		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
 		return
 	}
@@ -47,13 +52,22 @@ func (h *Handler) CloseLastReception(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
 		return
 	}
-	reception, err := h.Services.Reception.CloseLastReception(pvzId)
+	var params api.PostPvzPvzIdCloseLastReceptionParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	reception, err := h.Services.Reception.CloseLastReception(c.Request.Context(), pvzId, receptionKind(params.Kind))
 	if err != nil {
 		if errors.Is(err, errs.ErrNoReceptionsInProgress) {
 			c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
 			return
 		}
-		h.Logger.Error("failed to close reception", slog.String("op", op), slog.String("error", err.Error()))
+		if errors.Is(err, errs.ErrInvalidReceptionKind) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, api.Error{Message: err.Error()})
+			return
+		}
+		h.Logger.ErrorContext(c.Request.Context(), "failed to close reception", slog.String("op", op), slog.String("error", err.Error()))
 		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
 		return
 	}
@@ -69,18 +83,120 @@ func (h *Handler) DeleteLastProduct(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
 		return
 	}
-	err = h.Services.Reception.DeleteLastProduct(pvzId)
+	var params api.PostPvzPvzIdDeleteLastProductParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	err = h.Services.Reception.DeleteLastProduct(c.Request.Context(), pvzId, receptionKind(params.Kind))
 	if err != nil {
 		if errors.Is(err, errs.ErrNoProductsInReception) || errors.Is(err, errs.ErrNoReceptionsInProgress) {
 			c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
 			return
 		}
-		h.Logger.Error("failed to delete last product", slog.String("op", op), slog.String("error", err.Error()))
+		if errors.Is(err, errs.ErrInvalidReceptionKind) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, api.Error{Message: err.Error()})
+			return
+		}
+		h.Logger.ErrorContext(c.Request.Context(), "failed to delete last product", slog.String("op", op), slog.String("error", err.Error()))
 		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
 		return
 	}
 	c.Status(http.StatusOK)
 }
+func (h *Handler) ReopenReception(c *gin.Context) {
+	const op = "handler.reception.ReopenReception"
+	role, _ := c.Get(userRole)
+	if role != api.UserRoleModerator {
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrMessageAccessDenied)
+		return
+	}
+	recID, err := uuid.Parse(c.Param("receptionId"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	var req api.PostReceptionsReceptionIdReopenJSONBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	reopen, err := h.Services.Reception.Reopen(c.Request.Context(), recID, currentUserID(c), req.Reason)
+	if err != nil {
+		if errors.Is(err, errs.ErrReceptionNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, api.Error{Message: err.Error()})
+			return
+		}
+		if errors.Is(err, errs.ErrReceptionNotClosed) || errors.Is(err, errs.ErrReopenWindowExpired) ||
+			errors.Is(err, errs.ErrNewerReceptionExists) || errors.Is(err, errs.ErrInvalidReopenReason) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, api.Error{Message: err.Error()})
+			return
+		}
+		h.Logger.ErrorContext(c.Request.Context(), "failed to reopen reception", slog.String("op", op), slog.String("error", err.Error()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		return
+	}
+	c.JSON(http.StatusOK, reopen)
+}
+func (h *Handler) DeleteProduct(c *gin.Context) {
+	const op = "handler.reception.DeleteProduct"
+	role, _ := c.Get(userRole)
+	if role != api.UserRoleEmployee {
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrMessageAccessDenied)
+		return
+	}
+	recID, err := uuid.Parse(c.Param("receptionId"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	prodID, err := uuid.Parse(c.Param("productId"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	var req api.DeleteReceptionsReceptionIdProductsProductIdJSONBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	del, err := h.Services.Reception.DeleteProduct(c.Request.Context(), recID, prodID, req.Reason, req.Comment)
+	if err != nil {
+		if errors.Is(err, errs.ErrReceptionNotFound) || errors.Is(err, errs.ErrProductNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, api.Error{Message: err.Error()})
+			return
+		}
+		if errors.Is(err, errs.ErrReceptionClosed) || errors.Is(err, errs.ErrInvalidDeleteReason) ||
+			errors.Is(err, errs.ErrDeleteCommentRequired) || errors.Is(err, errs.ErrDeleteCommentTooLong) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, api.Error{Message: err.Error()})
+			return
+		}
+		h.Logger.ErrorContext(c.Request.Context(), "failed to delete product", slog.String("op", op), slog.String("error", err.Error()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		return
+	}
+	c.JSON(http.StatusOK, del)
+}
+func (h *Handler) GetDeletedProducts(c *gin.Context) {
+	const op = "handler.reception.GetDeletedProducts"
+	//auth handled in middleware
+	recID, err := uuid.Parse(c.Param("receptionId"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	dels, err := h.Services.Reception.GetDeletedProducts(c.Request.Context(), recID)
+	if err != nil {
+		if errors.Is(err, errs.ErrReceptionNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, api.Error{Message: err.Error()})
+			return
+		}
+		h.Logger.ErrorContext(c.Request.Context(), "failed to get deleted products", slog.String("op", op), slog.String("error", err.Error()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		return
+	}
+	c.JSON(http.StatusOK, dels)
+}
 func (h *Handler) AddProduct(c *gin.Context) {
 	const op = "handler.reception.AddProduct"
 	role, _ := c.Get(userRole)
@@ -94,15 +210,235 @@ func (h *Handler) AddProduct(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
 		return
 	}
-	prodRes, err := h.Services.AddProduct(prodReq.PvzId, api.ProductType(prodReq.Type))
+	prodRes, err := h.Services.AddProduct(c.Request.Context(), prodReq.PvzId, receptionKind(prodReq.ReceptionKind), api.ProductInput{
+		Type:            prodReq.Type,
+		Barcode:         prodReq.Barcode,
+		ExternalOrderId: prodReq.ExternalOrderId,
+		ReturnCondition: prodReq.ReturnCondition,
+		OriginalOrderId: prodReq.OriginalOrderId,
+		CellId:          prodReq.CellId,
+	})
 	if err != nil {
+		if errors.Is(err, errs.ErrDuplicateBarcode) {
+			c.AbortWithStatusJSON(http.StatusConflict, api.Error{Message: err.Error()})
+			return
+		}
+		if code := storageCellErrorStatus(err); code != 0 {
+			c.AbortWithStatusJSON(code, api.Error{Message: err.Error()})
+			return
+		}
+		if isInvalidProductErr(err) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, api.Error{Message: err.Error()})
+			return
+		}
 		if errors.Is(err, errs.ErrNoReceptionsInProgress) {
 			c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
 			return
 		}
-		h.Logger.Error("failed to add product", slog.String("op", op), slog.String("error", err.Error()))
+		h.Logger.ErrorContext(c.Request.Context(), "failed to add product", slog.String("op", op), slog.String("error", err.Error()))
 		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
 		return
 	}
 	c.JSON(http.StatusCreated, prodRes)
 }
+func (h *Handler) AddProducts(c *gin.Context) {
+	const op = "handler.reception.AddProducts"
+	role, _ := c.Get(userRole)
+	if role != api.UserRoleEmployee {
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrMessageAccessDenied)
+		return
+	}
+	var batchReq api.PostProductsBatchJSONBody
+	err := c.ShouldBind(&batchReq)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	prods, err := h.Services.AddProducts(c.Request.Context(), batchReq.PvzId, receptionKind(batchReq.ReceptionKind), batchReq.Products)
+	if err != nil {
+		if errors.Is(err, errs.ErrDuplicateBarcode) {
+			c.AbortWithStatusJSON(http.StatusConflict, api.Error{Message: err.Error()})
+			return
+		}
+		if code := storageCellErrorStatus(err); code != 0 {
+			c.AbortWithStatusJSON(code, api.Error{Message: err.Error()})
+			return
+		}
+		if errors.Is(err, errs.ErrEmptyProductBatch) || errors.Is(err, errs.ErrProductBatchTooLarge) || isInvalidProductErr(err) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, api.Error{Message: err.Error()})
+			return
+		}
+		if errors.Is(err, errs.ErrNoReceptionsInProgress) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+			return
+		}
+		h.Logger.ErrorContext(c.Request.Context(), "failed to add products", slog.String("op", op), slog.String("error", err.Error()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		return
+	}
+	c.JSON(http.StatusCreated, api.ProductBatchResponse{Products: prods})
+}
+
+func (h *Handler) GetReturnsReport(c *gin.Context) {
+	const op = "handler.reception.GetReturnsReport"
+	role, _ := c.Get(userRole)
+	if role != api.UserRoleModerator {
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrMessageAccessDenied)
+		return
+	}
+	// gin can't bind uuid query params, so pvzId is parsed separately
+	var query struct {
+		StartDate *time.Time `form:"startDate"`
+		EndDate   *time.Time `form:"endDate"`
+		PvzID     string     `form:"pvzId"`
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	params := api.GetReportsReturnsParams{StartDate: query.StartDate, EndDate: query.EndDate}
+	if query.PvzID != "" {
+		pvzID, err := uuid.Parse(query.PvzID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+			return
+		}
+		params.PvzId = &pvzID
+	}
+	rows, err := h.Services.Reception.ReturnsReport(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, errs.ErrInvalidReportPeriod) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, api.Error{Message: err.Error()})
+			return
+		}
+		h.Logger.ErrorContext(c.Request.Context(), "failed to build returns report", slog.String("op", op), slog.String("error", err.Error()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		return
+	}
+	c.JSON(http.StatusOK, rows)
+}
+
+func (h *Handler) ListReceptions(c *gin.Context) {
+	const op = "handler.reception.ListReceptions"
+	//auth handled in middleware
+	// gin can't bind uuid query params, so pvzId and createdBy are parsed separately
+	var query struct {
+		PvzID     string               `form:"pvzId"`
+		Status    *api.ReceptionStatus `form:"status"`
+		Kind      *api.ReceptionKind   `form:"kind"`
+		CreatedBy string               `form:"createdBy"`
+		StartDate *time.Time           `form:"startDate"`
+		EndDate   *time.Time           `form:"endDate"`
+		Page      *int                 `form:"page"`
+		Limit     *int                 `form:"limit"`
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	if !validPage(query.Page, query.Limit) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	if query.Status != nil && *query.Status != api.InProgress && *query.Status != api.Close {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	if query.Kind != nil && *query.Kind != api.ReceptionKindInbound && *query.Kind != api.ReceptionKindCustomerReturn {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	pvzID, err := parseOptionalUUID(query.PvzID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	createdBy, err := parseOptionalUUID(query.CreatedBy)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	params := api.GetReceptionsParams{
+		PvzId:     pvzID,
+		Status:    query.Status,
+		Kind:      query.Kind,
+		CreatedBy: createdBy,
+		StartDate: query.StartDate,
+		EndDate:   query.EndDate,
+		Page:      query.Page,
+		Limit:     query.Limit,
+	}
+
+	recs, err := h.Services.Reception.List(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, errs.ErrInvalidReportPeriod) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, api.Error{Message: err.Error()})
+			return
+		}
+		h.Logger.ErrorContext(c.Request.Context(), "failed to list receptions", slog.String("op", op), slog.String("error", err.Error()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		return
+	}
+	c.JSON(http.StatusOK, recs)
+}
+
+func (h *Handler) GetReception(c *gin.Context) {
+	const op = "handler.reception.GetReception"
+	//auth handled in middleware
+	recID, err := uuid.Parse(c.Param("receptionId"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	var params api.GetReceptionsReceptionIdParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	if !validPage(params.Page, params.Limit) || (params.AfterSeq != nil && *params.AfterSeq < 0) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	details, err := h.Services.Reception.Get(c.Request.Context(), recID, params)
+	if err != nil {
+		if errors.Is(err, errs.ErrReceptionNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, api.Error{Message: err.Error()})
+			return
+		}
+		h.Logger.ErrorContext(c.Request.Context(), "failed to get reception", slog.String("op", op), slog.String("error", err.Error()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		return
+	}
+	c.JSON(http.StatusOK, details)
+}
+
+// parseOptionalUUID returns nil for an empty query param
+func parseOptionalUUID(raw string) (*uuid.UUID, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// validPage checks optional page and limit params of paginated endpoints
+func validPage(page, limit *int) bool {
+	return (page == nil || *page >= 1) && (limit == nil || (*limit >= 1 && *limit <= maxInventoryLimit))
+}
+
+// receptionKind defaults to inbound when the kind is not given
+func receptionKind(kind *api.ReceptionKind) api.ReceptionKind {
+	if kind == nil {
+		return api.ReceptionKindInbound
+	}
+	return *kind
+}
+
+func isInvalidProductErr(err error) bool {
+	return errors.Is(err, errs.ErrInvalidProductType) || errors.Is(err, errs.ErrInvalidBarcode) || errors.Is(err, errs.ErrInvalidExternalOrderID) ||
+		errors.Is(err, errs.ErrInvalidReceptionKind) || errors.Is(err, errs.ErrInvalidReturnCondition) ||
+		errors.Is(err, errs.ErrInvalidOriginalOrderID) || errors.Is(err, errs.ErrUnexpectedReturnFields)
+}
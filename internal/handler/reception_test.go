@@ -2,11 +2,13 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/ST359/pvz-service/internal/api"
 	errs "github.com/ST359/pvz-service/internal/app_errors"
@@ -22,31 +24,71 @@ type MockReceptionService struct {
 	mock.Mock
 }
 
-func (m *MockReceptionService) Create(pvzID uuid.UUID) (api.Reception, error) {
-	args := m.Called(pvzID)
+func (m *MockReceptionService) Create(ctx context.Context, pvzID uuid.UUID, kind api.ReceptionKind, userID uuid.UUID) (api.Reception, error) {
+	args := m.Called(pvzID, kind, userID)
 	return args.Get(0).(api.Reception), args.Error(1)
 }
 
-func (m *MockReceptionService) AddProduct(pvzID uuid.UUID, product api.ProductType) (api.Product, error) {
-	args := m.Called(pvzID, product)
+func (m *MockReceptionService) List(ctx context.Context, params api.GetReceptionsParams) ([]api.Reception, error) {
+	args := m.Called(params)
+	return args.Get(0).([]api.Reception), args.Error(1)
+}
+
+func (m *MockReceptionService) Get(ctx context.Context, recID uuid.UUID, params api.GetReceptionsReceptionIdParams) (api.ReceptionDetails, error) {
+	args := m.Called(recID, params)
+	return args.Get(0).(api.ReceptionDetails), args.Error(1)
+}
+
+func (m *MockReceptionService) AddProduct(ctx context.Context, pvzID uuid.UUID, kind api.ReceptionKind, product api.ProductInput) (api.Product, error) {
+	args := m.Called(pvzID, kind, product)
 	return args.Get(0).(api.Product), args.Error(1)
 }
 
-func (m *MockReceptionService) GetReceptionInProgress(pvzID uuid.UUID) (uuid.UUID, error) {
-	args := m.Called(pvzID)
+func (m *MockReceptionService) AddProducts(ctx context.Context, pvzID uuid.UUID, kind api.ReceptionKind, products []api.ProductInput) ([]api.Product, error) {
+	args := m.Called(pvzID, kind, products)
+	return args.Get(0).([]api.Product), args.Error(1)
+}
+
+func (m *MockReceptionService) GetReceptionInProgress(ctx context.Context, pvzID uuid.UUID, kind api.ReceptionKind) (uuid.UUID, error) {
+	args := m.Called(pvzID, kind)
 	return args.Get(0).(uuid.UUID), args.Error(1)
 }
 
-func (m *MockReceptionService) DeleteLastProduct(pvzID uuid.UUID) error {
-	args := m.Called(pvzID)
+func (m *MockReceptionService) DeleteLastProduct(ctx context.Context, pvzID uuid.UUID, kind api.ReceptionKind) error {
+	args := m.Called(pvzID, kind)
 	return args.Error(0)
 }
 
-func (m *MockReceptionService) CloseLastReception(pvzID uuid.UUID) (api.Reception, error) {
-	args := m.Called(pvzID)
+func (m *MockReceptionService) DeleteProduct(ctx context.Context, recID, prodID uuid.UUID, reason api.ProductDeleteReason, comment *string) (api.ProductDeletion, error) {
+	args := m.Called(recID, prodID, reason, comment)
+	return args.Get(0).(api.ProductDeletion), args.Error(1)
+}
+
+func (m *MockReceptionService) GetDeletedProducts(ctx context.Context, recID uuid.UUID) ([]api.ProductDeletion, error) {
+	args := m.Called(recID)
+	return args.Get(0).([]api.ProductDeletion), args.Error(1)
+}
+
+func (m *MockReceptionService) Reopen(ctx context.Context, recID, userID uuid.UUID, reason string) (api.ReceptionReopen, error) {
+	args := m.Called(recID, userID, reason)
+	return args.Get(0).(api.ReceptionReopen), args.Error(1)
+}
+
+func (m *MockReceptionService) SweepStaleReceptions(ctx context.Context) ([]api.Reception, error) {
+	args := m.Called()
+	return args.Get(0).([]api.Reception), args.Error(1)
+}
+
+func (m *MockReceptionService) CloseLastReception(ctx context.Context, pvzID uuid.UUID, kind api.ReceptionKind) (api.Reception, error) {
+	args := m.Called(pvzID, kind)
 	return args.Get(0).(api.Reception), args.Error(1)
 }
 
+func (m *MockReceptionService) ReturnsReport(ctx context.Context, params api.GetReportsReturnsParams) ([]api.ReturnsReportRow, error) {
+	args := m.Called(params)
+	return args.Get(0).([]api.ReturnsReportRow), args.Error(1)
+}
+
 func setupReceptionRouter(h *Handler) *gin.Engine {
 	router := gin.Default()
 	router.Use(func(c *gin.Context) {
@@ -54,8 +96,10 @@ func setupReceptionRouter(h *Handler) *gin.Engine {
 	})
 	router.POST("/receptions", h.CreateReception)
 	router.POST("/products", h.AddProduct)
+	router.POST("/products/batch", h.AddProducts)
 	router.DELETE("/receptions/:pvzId/products/last", h.DeleteLastProduct)
 	router.PUT("/receptions/:pvzId/close", h.CloseLastReception)
+	router.GET("/receptions/:receptionId/deleted_products", h.GetDeletedProducts)
 	return router
 }
 
@@ -69,7 +113,7 @@ func TestCreateReception_Success(t *testing.T) {
 		Status: api.InProgress,
 	}
 
-	mockReception.On("Create", pvzID).Return(reception, nil)
+	mockReception.On("Create", pvzID, api.ReceptionKindInbound, uuid.Nil).Return(reception, nil)
 
 	h := &Handler{
 		Services: &service.Service{Reception: mockReception},
@@ -113,7 +157,7 @@ func TestCreateReception_ReceptionNotClosed(t *testing.T) {
 	pvzID := uuid.New()
 	reqBody := api.PostReceptionsJSONBody{PvzId: pvzID}
 
-	mockReception.On("Create", pvzID).Return(api.Reception{}, errs.ErrReceptionNotClosed)
+	mockReception.On("Create", pvzID, api.ReceptionKindInbound, uuid.Nil).Return(api.Reception{}, errs.ErrReceptionNotClosed)
 
 	h := &Handler{
 		Services: &service.Service{Reception: mockReception},
@@ -140,7 +184,7 @@ func TestCloseLastReception_Success(t *testing.T) {
 		Status: api.Close,
 	}
 
-	mockReception.On("CloseLastReception", pvzID).Return(reception, nil)
+	mockReception.On("CloseLastReception", pvzID, api.ReceptionKindInbound).Return(reception, nil)
 
 	h := &Handler{
 		Services: &service.Service{Reception: mockReception},
@@ -164,7 +208,7 @@ func TestCloseLastReception_NoReceptions(t *testing.T) {
 	mockReception := new(MockReceptionService)
 	pvzID := uuid.New()
 
-	mockReception.On("CloseLastReception", pvzID).Return(api.Reception{}, errs.ErrNoReceptionsInProgress)
+	mockReception.On("CloseLastReception", pvzID, api.ReceptionKindInbound).Return(api.Reception{}, errs.ErrNoReceptionsInProgress)
 
 	h := &Handler{
 		Services: &service.Service{Reception: mockReception},
@@ -184,7 +228,7 @@ func TestDeleteLastProduct_Success(t *testing.T) {
 	mockReception := new(MockReceptionService)
 	pvzID := uuid.New()
 
-	mockReception.On("DeleteLastProduct", pvzID).Return(nil)
+	mockReception.On("DeleteLastProduct", pvzID, api.ReceptionKindInbound).Return(nil)
 
 	h := &Handler{
 		Services: &service.Service{Reception: mockReception},
@@ -204,7 +248,7 @@ func TestDeleteLastProduct_NoProducts(t *testing.T) {
 	mockReception := new(MockReceptionService)
 	pvzID := uuid.New()
 
-	mockReception.On("DeleteLastProduct", pvzID).Return(errs.ErrNoProductsInReception)
+	mockReception.On("DeleteLastProduct", pvzID, api.ReceptionKindInbound).Return(errs.ErrNoProductsInReception)
 
 	h := &Handler{
 		Services: &service.Service{Reception: mockReception},
@@ -225,7 +269,7 @@ func TestAddProduct_Success(t *testing.T) {
 	pvzID := uuid.New()
 	reqBody := api.PostProductsJSONBody{
 		PvzId: pvzID,
-		Type:  api.PostProductsJSONBodyTypeShoes,
+		Type:  api.ProductTypeShoes,
 	}
 	product := api.Product{
 		Id:          &pvzID,
@@ -233,7 +277,7 @@ func TestAddProduct_Success(t *testing.T) {
 		Type:        api.ProductTypeShoes,
 	}
 
-	mockReception.On("AddProduct", pvzID, api.ProductTypeShoes).Return(product, nil)
+	mockReception.On("AddProduct", pvzID, api.ReceptionKindInbound, api.ProductInput{Type: api.ProductTypeShoes}).Return(product, nil)
 
 	h := &Handler{
 		Services: &service.Service{Reception: mockReception},
@@ -260,10 +304,10 @@ func TestAddProduct_NoReception(t *testing.T) {
 	pvzID := uuid.New()
 	reqBody := api.PostProductsJSONBody{
 		PvzId: pvzID,
-		Type:  api.PostProductsJSONBodyTypeShoes,
+		Type:  api.ProductTypeShoes,
 	}
 
-	mockReception.On("AddProduct", pvzID, api.ProductTypeShoes).Return(api.Product{}, errs.ErrNoReceptionsInProgress)
+	mockReception.On("AddProduct", pvzID, api.ReceptionKindInbound, api.ProductInput{Type: api.ProductTypeShoes}).Return(api.Product{}, errs.ErrNoReceptionsInProgress)
 
 	h := &Handler{
 		Services: &service.Service{Reception: mockReception},
@@ -297,3 +341,712 @@ func TestAddProduct_NotEmployee(t *testing.T) {
 
 	assert.Equal(t, http.StatusForbidden, w.Code)
 }
+
+func TestAddProducts_Success(t *testing.T) {
+	mockReception := new(MockReceptionService)
+	pvzID, recID := uuid.New(), uuid.New()
+	firstID, secondID := uuid.New(), uuid.New()
+	items := []api.ProductInput{{Type: api.ProductTypeShoes}, {Type: api.ProductTypeClothes}}
+	products := []api.Product{
+		{Id: &firstID, ReceptionId: recID, Type: api.ProductTypeShoes},
+		{Id: &secondID, ReceptionId: recID, Type: api.ProductTypeClothes},
+	}
+
+	mockReception.On("AddProducts", pvzID, api.ReceptionKindInbound, items).Return(products, nil)
+
+	h := &Handler{
+		Services: &service.Service{Reception: mockReception},
+		Logger:   slog.Default(),
+	}
+	router := setupReceptionRouter(h)
+
+	body, _ := json.Marshal(api.PostProductsBatchJSONBody{PvzId: pvzID, Products: items})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/products/batch", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var response api.ProductBatchResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, products, response.Products)
+	mockReception.AssertExpectations(t)
+}
+
+func TestAddProducts_BatchTooLarge(t *testing.T) {
+	mockReception := new(MockReceptionService)
+	pvzID := uuid.New()
+	items := []api.ProductInput{{Type: api.ProductTypeShoes}, {Type: api.ProductTypeShoes}}
+
+	mockReception.On("AddProducts", pvzID, api.ReceptionKindInbound, items).Return([]api.Product(nil), errs.ErrProductBatchTooLarge)
+
+	h := &Handler{
+		Services: &service.Service{Reception: mockReception},
+		Logger:   slog.Default(),
+	}
+	router := setupReceptionRouter(h)
+
+	body, _ := json.Marshal(api.PostProductsBatchJSONBody{PvzId: pvzID, Products: items})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/products/batch", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"message":"product batch is too large"}`, w.Body.String())
+	mockReception.AssertExpectations(t)
+}
+
+func TestAddProducts_NotEmployee(t *testing.T) {
+	h := &Handler{
+		Logger: slog.Default(),
+	}
+	router := gin.Default()
+	router.Use(func(c *gin.Context) {
+		c.Set(userRole, api.UserRoleModerator)
+	})
+	router.POST("/products/batch", h.AddProducts)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/products/batch", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestAddProduct_DuplicateBarcode(t *testing.T) {
+	mockReception := new(MockReceptionService)
+	pvzID := uuid.New()
+	barcode := "4607001234567"
+	reqBody := api.PostProductsJSONBody{
+		PvzId:   pvzID,
+		Type:    api.ProductTypeShoes,
+		Barcode: &barcode,
+	}
+
+	mockReception.On("AddProduct", pvzID, api.ReceptionKindInbound, api.ProductInput{Type: api.ProductTypeShoes, Barcode: &barcode}).
+		Return(api.Product{}, errs.ErrDuplicateBarcode)
+
+	h := &Handler{
+		Services: &service.Service{Reception: mockReception},
+		Logger:   slog.Default(),
+	}
+	router := setupReceptionRouter(h)
+
+	body, _ := json.Marshal(reqBody)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/products", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.JSONEq(t, `{"message":"product with this barcode is already scanned"}`, w.Body.String())
+	mockReception.AssertExpectations(t)
+}
+
+func TestDeleteProduct(t *testing.T) {
+	recID := uuid.New()
+	prodID := uuid.New()
+	comment := "not ours"
+
+	tests := []struct {
+		name         string
+		role         api.UserRole
+		path         string
+		body         string
+		mockSetup    func(*MockReceptionService)
+		expectedCode int
+	}{
+		{
+			name: "success",
+			role: api.UserRoleEmployee,
+			path: "/receptions/" + recID.String() + "/products/" + prodID.String(),
+			body: `{"reason":"wrong_pvz","comment":"not ours"}`,
+			mockSetup: func(m *MockReceptionService) {
+				m.On("DeleteProduct", recID, prodID, api.DeleteReasonWrongPVZ, &comment).
+					Return(api.ProductDeletion{Product: api.Product{Id: &prodID}, Reason: api.DeleteReasonWrongPVZ, Comment: &comment}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "moderator is forbidden",
+			role:         api.UserRoleModerator,
+			path:         "/receptions/" + recID.String() + "/products/" + prodID.String(),
+			body:         `{"reason":"wrong_pvz"}`,
+			mockSetup:    func(m *MockReceptionService) {},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "invalid product id",
+			role:         api.UserRoleEmployee,
+			path:         "/receptions/" + recID.String() + "/products/abc",
+			body:         `{"reason":"wrong_pvz"}`,
+			mockSetup:    func(m *MockReceptionService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "missing reason",
+			role: api.UserRoleEmployee,
+			path: "/receptions/" + recID.String() + "/products/" + prodID.String(),
+			body: `{}`,
+			mockSetup: func(m *MockReceptionService) {
+				m.On("DeleteProduct", recID, prodID, api.ProductDeleteReason(""), (*string)(nil)).
+					Return(api.ProductDeletion{}, errs.ErrInvalidDeleteReason)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "reception closed",
+			role: api.UserRoleEmployee,
+			path: "/receptions/" + recID.String() + "/products/" + prodID.String(),
+			body: `{"reason":"damaged"}`,
+			mockSetup: func(m *MockReceptionService) {
+				m.On("DeleteProduct", recID, prodID, api.DeleteReasonDamaged, (*string)(nil)).
+					Return(api.ProductDeletion{}, errs.ErrReceptionClosed)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "product not found",
+			role: api.UserRoleEmployee,
+			path: "/receptions/" + recID.String() + "/products/" + prodID.String(),
+			body: `{"reason":"damaged"}`,
+			mockSetup: func(m *MockReceptionService) {
+				m.On("DeleteProduct", recID, prodID, api.DeleteReasonDamaged, (*string)(nil)).
+					Return(api.ProductDeletion{}, errs.ErrProductNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockReception := new(MockReceptionService)
+			tt.mockSetup(mockReception)
+
+			h := &Handler{
+				Services: &service.Service{Reception: mockReception},
+				Logger:   slog.Default(),
+			}
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set(userRole, tt.role)
+			})
+			router.DELETE("/receptions/:receptionId/products/:productId", h.DeleteProduct)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("DELETE", tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			mockReception.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetDeletedProducts_Success(t *testing.T) {
+	mockReception := new(MockReceptionService)
+	recID := uuid.New()
+
+	mockReception.On("GetDeletedProducts", recID).
+		Return([]api.ProductDeletion{{Reason: api.DeleteReasonUndoLast}}, nil)
+
+	h := &Handler{
+		Services: &service.Service{Reception: mockReception},
+		Logger:   slog.Default(),
+	}
+	router := setupReceptionRouter(h)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/receptions/"+recID.String()+"/deleted_products", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp []api.ProductDeletion
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp, 1)
+	mockReception.AssertExpectations(t)
+}
+
+func TestGetDeletedProducts_NotFound(t *testing.T) {
+	mockReception := new(MockReceptionService)
+	recID := uuid.New()
+
+	mockReception.On("GetDeletedProducts", recID).
+		Return([]api.ProductDeletion(nil), errs.ErrReceptionNotFound)
+
+	h := &Handler{
+		Services: &service.Service{Reception: mockReception},
+		Logger:   slog.Default(),
+	}
+	router := setupReceptionRouter(h)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/receptions/"+recID.String()+"/deleted_products", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockReception.AssertExpectations(t)
+}
+
+func TestReopenReception(t *testing.T) {
+	recID := uuid.New()
+	moderatorID := uuid.New()
+
+	tests := []struct {
+		name         string
+		role         api.UserRole
+		path         string
+		body         string
+		mockSetup    func(*MockReceptionService)
+		expectedCode int
+	}{
+		{
+			name: "success",
+			role: api.UserRoleModerator,
+			path: "/receptions/" + recID.String() + "/reopen",
+			body: `{"reason":"closed by mistake"}`,
+			mockSetup: func(m *MockReceptionService) {
+				m.On("Reopen", recID, moderatorID, "closed by mistake").
+					Return(api.ReceptionReopen{Reception: api.Reception{Id: &recID, Status: api.InProgress}, ReopenedBy: &moderatorID, Reason: "closed by mistake"}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "employee is forbidden",
+			role:         api.UserRoleEmployee,
+			path:         "/receptions/" + recID.String() + "/reopen",
+			body:         `{"reason":"closed by mistake"}`,
+			mockSetup:    func(m *MockReceptionService) {},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "invalid reception id",
+			role:         api.UserRoleModerator,
+			path:         "/receptions/abc/reopen",
+			body:         `{"reason":"closed by mistake"}`,
+			mockSetup:    func(m *MockReceptionService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "window expired",
+			role: api.UserRoleModerator,
+			path: "/receptions/" + recID.String() + "/reopen",
+			body: `{"reason":"closed by mistake"}`,
+			mockSetup: func(m *MockReceptionService) {
+				m.On("Reopen", recID, moderatorID, "closed by mistake").
+					Return(api.ReceptionReopen{}, errs.ErrReopenWindowExpired)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "reception not found",
+			role: api.UserRoleModerator,
+			path: "/receptions/" + recID.String() + "/reopen",
+			body: `{"reason":"closed by mistake"}`,
+			mockSetup: func(m *MockReceptionService) {
+				m.On("Reopen", recID, moderatorID, "closed by mistake").
+					Return(api.ReceptionReopen{}, errs.ErrReceptionNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockReception := new(MockReceptionService)
+			tt.mockSetup(mockReception)
+
+			h := &Handler{
+				Services: &service.Service{Reception: mockReception},
+				Logger:   slog.Default(),
+			}
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set(userRole, tt.role)
+				c.Set(userID, moderatorID)
+			})
+			router.POST("/receptions/:receptionId/reopen", h.ReopenReception)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			mockReception.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReceptionKinds(t *testing.T) {
+	pvzID := uuid.New()
+	order := "ORD-1"
+	opened := api.ReturnConditionOpened
+	returned := api.ProductInput{Type: api.ProductTypeShoes, ReturnCondition: &opened, OriginalOrderId: &order}
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		body         string
+		mockSetup    func(*MockReceptionService)
+		expectedCode int
+	}{
+		{
+			name:   "open customer return",
+			method: "POST",
+			path:   "/receptions",
+			body:   `{"pvzId":"` + pvzID.String() + `","kind":"customer_return"}`,
+			mockSetup: func(m *MockReceptionService) {
+				m.On("Create", pvzID, api.ReceptionKindCustomerReturn, uuid.Nil).
+					Return(api.Reception{PvzId: pvzID, Status: api.InProgress, Kind: api.ReceptionKindCustomerReturn}, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:   "unknown kind",
+			method: "POST",
+			path:   "/receptions",
+			body:   `{"pvzId":"` + pvzID.String() + `","kind":"exchange"}`,
+			mockSetup: func(m *MockReceptionService) {
+				m.On("Create", pvzID, api.ReceptionKind("exchange"), uuid.Nil).Return(api.Reception{}, errs.ErrInvalidReceptionKind)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "add returned product",
+			method: "POST",
+			path:   "/products",
+			body:   `{"pvzId":"` + pvzID.String() + `","type":"shoes","receptionKind":"customer_return","returnCondition":"opened","originalOrderId":"ORD-1"}`,
+			mockSetup: func(m *MockReceptionService) {
+				m.On("AddProduct", pvzID, api.ReceptionKindCustomerReturn, returned).
+					Return(api.Product{Type: api.ProductTypeShoes, ReturnCondition: &opened, OriginalOrderId: &order}, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:   "return fields on inbound product",
+			method: "POST",
+			path:   "/products",
+			body:   `{"pvzId":"` + pvzID.String() + `","type":"shoes","returnCondition":"opened","originalOrderId":"ORD-1"}`,
+			mockSetup: func(m *MockReceptionService) {
+				m.On("AddProduct", pvzID, api.ReceptionKindInbound, returned).Return(api.Product{}, errs.ErrUnexpectedReturnFields)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "close customer return",
+			method: "PUT",
+			path:   "/receptions/" + pvzID.String() + "/close?kind=customer_return",
+			mockSetup: func(m *MockReceptionService) {
+				m.On("CloseLastReception", pvzID, api.ReceptionKindCustomerReturn).
+					Return(api.Reception{PvzId: pvzID, Status: api.Close, Kind: api.ReceptionKindCustomerReturn}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:   "undo in customer return",
+			method: "DELETE",
+			path:   "/receptions/" + pvzID.String() + "/products/last?kind=customer_return",
+			mockSetup: func(m *MockReceptionService) {
+				m.On("DeleteLastProduct", pvzID, api.ReceptionKindCustomerReturn).Return(nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockReception := new(MockReceptionService)
+			tt.mockSetup(mockReception)
+
+			h := &Handler{
+				Services: &service.Service{Reception: mockReception},
+				Logger:   slog.Default(),
+			}
+			router := setupReceptionRouter(h)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			mockReception.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetReturnsReport(t *testing.T) {
+	pvzID := uuid.New()
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := []api.ReturnsReportRow{{PvzId: pvzID, Condition: api.ReturnConditionUnopened, Receptions: 1, Products: 2}}
+
+	tests := []struct {
+		name         string
+		role         api.UserRole
+		query        string
+		mockSetup    func(*MockReceptionService)
+		expectedCode int
+	}{
+		{
+			name:  "report",
+			role:  api.UserRoleModerator,
+			query: "?startDate=2025-01-01T00:00:00Z&pvzId=" + pvzID.String(),
+			mockSetup: func(m *MockReceptionService) {
+				m.On("ReturnsReport", api.GetReportsReturnsParams{StartDate: &start, PvzId: &pvzID}).Return(rows, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "employee is not allowed",
+			role:         api.UserRoleEmployee,
+			mockSetup:    func(m *MockReceptionService) {},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "invalid pvz id",
+			role:         api.UserRoleModerator,
+			query:        "?pvzId=abc",
+			mockSetup:    func(m *MockReceptionService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid date",
+			role:         api.UserRoleModerator,
+			query:        "?startDate=yesterday",
+			mockSetup:    func(m *MockReceptionService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "invalid period",
+			role: api.UserRoleModerator,
+			mockSetup: func(m *MockReceptionService) {
+				m.On("ReturnsReport", api.GetReportsReturnsParams{}).Return([]api.ReturnsReportRow(nil), errs.ErrInvalidReportPeriod)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "service error",
+			role: api.UserRoleModerator,
+			mockSetup: func(m *MockReceptionService) {
+				m.On("ReturnsReport", api.GetReportsReturnsParams{}).Return([]api.ReturnsReportRow(nil), assert.AnError)
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockReception := new(MockReceptionService)
+			tt.mockSetup(mockReception)
+
+			h := &Handler{
+				Services: &service.Service{Reception: mockReception},
+				Logger:   slog.Default(),
+			}
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set(userRole, tt.role)
+			})
+			router.GET("/reports/returns", h.GetReturnsReport)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/reports/returns"+tt.query, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedCode == http.StatusOK {
+				var response []api.ReturnsReportRow
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, rows, response)
+			}
+			mockReception.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListReceptions(t *testing.T) {
+	pvzID := uuid.New()
+	userID := uuid.New()
+	recID := uuid.New()
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	status := api.Close
+	kind := api.ReceptionKindCustomerReturn
+	page, limit := 2, 50
+	recs := []api.Reception{{Id: &recID, PvzId: pvzID, Status: api.Close, Kind: kind, CreatedBy: &userID}}
+
+	tests := []struct {
+		name         string
+		query        string
+		mockSetup    func(*MockReceptionService)
+		expectedCode int
+	}{
+		{
+			name: "filtered",
+			query: "?status=close&kind=customer_return&startDate=2025-01-01T00:00:00Z&page=2&limit=50&pvzId=" +
+				pvzID.String() + "&createdBy=" + userID.String(),
+			mockSetup: func(m *MockReceptionService) {
+				m.On("List", api.GetReceptionsParams{
+					PvzId: &pvzID, Status: &status, Kind: &kind, CreatedBy: &userID,
+					StartDate: &start, Page: &page, Limit: &limit,
+				}).Return(recs, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "invalid created by",
+			query:        "?createdBy=abc",
+			mockSetup:    func(m *MockReceptionService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid status",
+			query:        "?status=open",
+			mockSetup:    func(m *MockReceptionService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "limit too large",
+			query:        "?limit=1000",
+			mockSetup:    func(m *MockReceptionService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "invalid period",
+			mockSetup: func(m *MockReceptionService) {
+				m.On("List", api.GetReceptionsParams{}).Return([]api.Reception(nil), errs.ErrInvalidReportPeriod)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "service error",
+			mockSetup: func(m *MockReceptionService) {
+				m.On("List", api.GetReceptionsParams{}).Return([]api.Reception(nil), assert.AnError)
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockReception := new(MockReceptionService)
+			tt.mockSetup(mockReception)
+
+			h := &Handler{
+				Services: &service.Service{Reception: mockReception},
+				Logger:   slog.Default(),
+			}
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set(userRole, api.UserRoleModerator)
+			})
+			router.GET("/receptions", h.ListReceptions)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/receptions"+tt.query, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedCode == http.StatusOK {
+				var response []api.Reception
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, recs, response)
+			}
+			mockReception.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetReception(t *testing.T) {
+	recID := uuid.New()
+	page := 2
+	afterSeq := int64(12)
+	details := api.ReceptionDetails{
+		Reception:     api.Reception{Id: &recID, PvzId: uuid.New(), Status: api.InProgress, Kind: api.ReceptionKindInbound},
+		Products:      []api.Product{{ReceptionId: recID, Type: api.ProductTypeShoes, State: api.ProductStateStored}},
+		ProductsTotal: 31,
+	}
+
+	tests := []struct {
+		name         string
+		path         string
+		mockSetup    func(*MockReceptionService)
+		expectedCode int
+	}{
+		{
+			name: "reception with products",
+			path: "/receptions/" + recID.String() + "?page=2",
+			mockSetup: func(m *MockReceptionService) {
+				m.On("Get", recID, api.GetReceptionsReceptionIdParams{Page: &page}).Return(details, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "invalid id",
+			path:         "/receptions/abc",
+			mockSetup:    func(m *MockReceptionService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid page",
+			path:         "/receptions/" + recID.String() + "?page=0",
+			mockSetup:    func(m *MockReceptionService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "resumed after scan number",
+			path: "/receptions/" + recID.String() + "?afterSeq=12",
+			mockSetup: func(m *MockReceptionService) {
+				m.On("Get", recID, api.GetReceptionsReceptionIdParams{AfterSeq: &afterSeq}).Return(details, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "negative scan number",
+			path:         "/receptions/" + recID.String() + "?afterSeq=-1",
+			mockSetup:    func(m *MockReceptionService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "not found",
+			path: "/receptions/" + recID.String(),
+			mockSetup: func(m *MockReceptionService) {
+				m.On("Get", recID, api.GetReceptionsReceptionIdParams{}).Return(api.ReceptionDetails{}, errs.ErrReceptionNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockReception := new(MockReceptionService)
+			tt.mockSetup(mockReception)
+
+			h := &Handler{
+				Services: &service.Service{Reception: mockReception},
+				Logger:   slog.Default(),
+			}
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set(userRole, api.UserRoleEmployee)
+			})
+			router.GET("/receptions/:receptionId", h.GetReception)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedCode == http.StatusOK {
+				var response api.ReceptionDetails
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, details, response)
+			}
+			mockReception.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+func (h *Handler) GetVolumeReport(c *gin.Context) {
+	const op = "handler.report.GetVolumeReport"
+	role, _ := c.Get(userRole)
+	if role != api.UserRoleModerator {
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrMessageAccessDenied)
+		return
+	}
+	// dates, groups and pvzId are parsed separately, gin binds neither plain dates nor comma separated lists
+	var query struct {
+		StartDate string            `form:"startDate"`
+		EndDate   string            `form:"endDate"`
+		GroupBy   string            `form:"groupBy"`
+		Period    *api.VolumePeriod `form:"period"`
+		City      *string           `form:"city"`
+		PvzID     string            `form:"pvzId"`
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	params := api.GetReportsVolumeParams{Period: query.Period, City: query.City}
+	var err error
+	if params.StartDate, err = parseOptionalDate(query.StartDate); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	if params.EndDate, err = parseOptionalDate(query.EndDate); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	if params.PvzId, err = parseOptionalUUID(query.PvzID); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	if query.GroupBy != "" {
+		groups := []api.VolumeGroup{}
+		for _, group := range strings.Split(query.GroupBy, ",") {
+			groups = append(groups, api.VolumeGroup(strings.TrimSpace(group)))
+		}
+		params.GroupBy = &groups
+	}
+
+	rows, err := h.Services.Report.Volume(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, errs.ErrInvalidReportPeriod) || errors.Is(err, errs.ErrInvalidVolumePeriod) ||
+			errors.Is(err, errs.ErrInvalidVolumeGroup) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, api.Error{Message: err.Error()})
+			return
+		}
+		h.Logger.ErrorContext(c.Request.Context(), "failed to build volume report", slog.String("op", op), slog.String("error", err.Error()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		return
+	}
+	c.JSON(http.StatusOK, rows)
+}
+
+func (h *Handler) GetEmployeesReport(c *gin.Context) {
+	const op = "handler.report.GetEmployeesReport"
+	role, _ := c.Get(userRole)
+	if role != api.UserRoleModerator {
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrMessageAccessDenied)
+		return
+	}
+	// gin can't bind uuid query params, so pvzId and userId are parsed separately
+	var query struct {
+		StartDate *time.Time `form:"startDate"`
+		EndDate   *time.Time `form:"endDate"`
+		PvzID     string     `form:"pvzId"`
+		UserID    string     `form:"userId"`
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	params := api.GetReportsEmployeesParams{StartDate: query.StartDate, EndDate: query.EndDate}
+	var err error
+	if params.PvzId, err = parseOptionalUUID(query.PvzID); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	if params.UserId, err = parseOptionalUUID(query.UserID); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	rows, err := h.Services.Report.Employees(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, errs.ErrInvalidReportPeriod) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, api.Error{Message: err.Error()})
+			return
+		}
+		h.Logger.ErrorContext(c.Request.Context(), "failed to build employees report", slog.String("op", op), slog.String("error", err.Error()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		return
+	}
+	c.JSON(http.StatusOK, rows)
+}
+
+func (h *Handler) GetOwnProductivity(c *gin.Context) {
+	const op = "handler.report.GetOwnProductivity"
+	//auth handled in middleware, dummy tokens carry no user to report on
+	uid := currentUserID(c)
+	if uid == uuid.Nil {
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrMessageAccessDenied)
+		return
+	}
+	var query struct {
+		StartDate *time.Time `form:"startDate"`
+		EndDate   *time.Time `form:"endDate"`
+		PvzID     string     `form:"pvzId"`
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	params := api.GetReportsEmployeesMeParams{StartDate: query.StartDate, EndDate: query.EndDate}
+	var err error
+	if params.PvzId, err = parseOptionalUUID(query.PvzID); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	rows, err := h.Services.Report.OwnProductivity(c.Request.Context(), uid, params)
+	if err != nil {
+		if errors.Is(err, errs.ErrInvalidReportPeriod) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, api.Error{Message: err.Error()})
+			return
+		}
+		h.Logger.ErrorContext(c.Request.Context(), "failed to build own productivity", slog.String("op", op), slog.String("error", err.Error()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		return
+	}
+	c.JSON(http.StatusOK, rows)
+}
+
+// parseOptionalDate parses a YYYY-MM-DD date, an empty string gives nil
+func parseOptionalDate(s string) (*openapi_types.Date, error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(openapi_types.DateFormat, s)
+	if err != nil {
+		return nil, err
+	}
+	return &openapi_types.Date{Time: t}, nil
+}
@@ -0,0 +1,286 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockReportService is a mock implementation of service.Report
+type MockReportService struct {
+	mock.Mock
+}
+
+func (m *MockReportService) Volume(ctx context.Context, params api.GetReportsVolumeParams) ([]api.VolumeReportRow, error) {
+	args := m.Called(params)
+	return args.Get(0).([]api.VolumeReportRow), args.Error(1)
+}
+
+func (m *MockReportService) Employees(ctx context.Context, params api.GetReportsEmployeesParams) ([]api.EmployeeProductivityRow, error) {
+	args := m.Called(params)
+	return args.Get(0).([]api.EmployeeProductivityRow), args.Error(1)
+}
+
+func (m *MockReportService) OwnProductivity(ctx context.Context, userID uuid.UUID, params api.GetReportsEmployeesMeParams) ([]api.EmployeeProductivityRow, error) {
+	args := m.Called(userID, params)
+	return args.Get(0).([]api.EmployeeProductivityRow), args.Error(1)
+}
+
+func TestGetVolumeReport(t *testing.T) {
+	pvzID := uuid.New()
+	city := "Москва"
+	start := openapi_types.Date{Time: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)}
+	end := openapi_types.Date{Time: time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)}
+	daily := api.VolumePeriodDay
+	rows := []api.VolumeReportRow{{PeriodStart: &start, City: &city, Products: 5}}
+
+	tests := []struct {
+		name         string
+		role         api.UserRole
+		query        string
+		mockSetup    func(*MockReportService)
+		expectedCode int
+	}{
+		{
+			name:  "report",
+			role:  api.UserRoleModerator,
+			query: "?startDate=2025-03-01&endDate=2025-03-31&period=day&groupBy=city,%20type&city=" + city + "&pvzId=" + pvzID.String(),
+			mockSetup: func(m *MockReportService) {
+				groups := []api.VolumeGroup{api.VolumeGroupCity, api.VolumeGroupType}
+				m.On("Volume", api.GetReportsVolumeParams{StartDate: &start, EndDate: &end, Period: &daily,
+					GroupBy: &groups, City: &city, PvzId: &pvzID}).Return(rows, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "employee is not allowed",
+			role:         api.UserRoleEmployee,
+			mockSetup:    func(m *MockReportService) {},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "invalid date",
+			role:         api.UserRoleModerator,
+			query:        "?startDate=2025-03-01T00:00:00Z",
+			mockSetup:    func(m *MockReportService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid pvz id",
+			role:         api.UserRoleModerator,
+			query:        "?pvzId=abc",
+			mockSetup:    func(m *MockReportService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:  "invalid group",
+			role:  api.UserRoleModerator,
+			query: "?groupBy=region",
+			mockSetup: func(m *MockReportService) {
+				groups := []api.VolumeGroup{"region"}
+				m.On("Volume", api.GetReportsVolumeParams{GroupBy: &groups}).
+					Return([]api.VolumeReportRow(nil), errs.ErrInvalidVolumeGroup)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "service error",
+			role: api.UserRoleModerator,
+			mockSetup: func(m *MockReportService) {
+				m.On("Volume", api.GetReportsVolumeParams{}).Return([]api.VolumeReportRow(nil), assert.AnError)
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockReport := new(MockReportService)
+			tt.mockSetup(mockReport)
+
+			h := &Handler{
+				Services: &service.Service{Report: mockReport},
+				Logger:   slog.Default(),
+			}
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set(userRole, tt.role)
+			})
+			router.GET("/reports/volume", h.GetVolumeReport)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/reports/volume"+tt.query, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedCode == http.StatusOK {
+				var response []api.VolumeReportRow
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, rows, response)
+			}
+			mockReport.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetEmployeesReport(t *testing.T) {
+	userID := uuid.New()
+	pvzID := uuid.New()
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	perHour := 42.5
+	rows := []api.EmployeeProductivityRow{{UserId: userID, Email: "anna@example.com", PvzId: pvzID, Receptions: 3,
+		ClosedReceptions: 3, ProductsScanned: 85, ProductsDeleted: 2, DeletionRate: 0.0235, ProductsPerHour: &perHour}}
+
+	tests := []struct {
+		name         string
+		role         api.UserRole
+		query        string
+		mockSetup    func(*MockReportService)
+		expectedCode int
+	}{
+		{
+			name:  "report",
+			role:  api.UserRoleModerator,
+			query: "?startDate=2025-03-01T00:00:00Z&pvzId=" + pvzID.String() + "&userId=" + userID.String(),
+			mockSetup: func(m *MockReportService) {
+				m.On("Employees", api.GetReportsEmployeesParams{StartDate: &start, PvzId: &pvzID, UserId: &userID}).Return(rows, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "employee is not allowed",
+			role:         api.UserRoleEmployee,
+			mockSetup:    func(m *MockReportService) {},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "invalid user id",
+			role:         api.UserRoleModerator,
+			query:        "?userId=abc",
+			mockSetup:    func(m *MockReportService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "invalid period",
+			role: api.UserRoleModerator,
+			mockSetup: func(m *MockReportService) {
+				m.On("Employees", api.GetReportsEmployeesParams{}).Return([]api.EmployeeProductivityRow(nil), errs.ErrInvalidReportPeriod)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "service error",
+			role: api.UserRoleModerator,
+			mockSetup: func(m *MockReportService) {
+				m.On("Employees", api.GetReportsEmployeesParams{}).Return([]api.EmployeeProductivityRow(nil), assert.AnError)
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockReport := new(MockReportService)
+			tt.mockSetup(mockReport)
+
+			h := &Handler{
+				Services: &service.Service{Report: mockReport},
+				Logger:   slog.Default(),
+			}
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set(userRole, tt.role)
+			})
+			router.GET("/reports/employees", h.GetEmployeesReport)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/reports/employees"+tt.query, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedCode == http.StatusOK {
+				var response []api.EmployeeProductivityRow
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, rows, response)
+			}
+			mockReport.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetOwnProductivity(t *testing.T) {
+	uid := uuid.New()
+	pvzID := uuid.New()
+	rows := []api.EmployeeProductivityRow{{UserId: uid, PvzId: pvzID, Receptions: 1}}
+
+	tests := []struct {
+		name         string
+		uid          uuid.UUID
+		query        string
+		mockSetup    func(*MockReportService)
+		expectedCode int
+	}{
+		{
+			name:  "own numbers",
+			uid:   uid,
+			query: "?pvzId=" + pvzID.String(),
+			mockSetup: func(m *MockReportService) {
+				m.On("OwnProductivity", uid, api.GetReportsEmployeesMeParams{PvzId: &pvzID}).Return(rows, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "dummy token",
+			uid:          uuid.Nil,
+			mockSetup:    func(m *MockReportService) {},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "invalid date",
+			uid:          uid,
+			query:        "?endDate=today",
+			mockSetup:    func(m *MockReportService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockReport := new(MockReportService)
+			tt.mockSetup(mockReport)
+
+			h := &Handler{
+				Services: &service.Service{Report: mockReport},
+				Logger:   slog.Default(),
+			}
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set(userRole, api.UserRoleEmployee)
+				if tt.uid != uuid.Nil {
+					c.Set(userID, tt.uid)
+				}
+			})
+			router.GET("/reports/employees/me", h.GetOwnProductivity)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/reports/employees/me"+tt.query, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			mockReport.AssertExpectations(t)
+		})
+	}
+}
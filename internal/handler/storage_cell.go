@@ -0,0 +1,227 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) GetStorageCells(c *gin.Context) {
+	const op = "handler.storage_cell.GetStorageCells"
+	//auth handled in middleware
+	pvzID, err := uuid.Parse(c.Param("pvzId"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	cells, err := h.Services.StorageCell.List(c.Request.Context(), pvzID)
+	if err != nil {
+		if errors.Is(err, errs.ErrPVZNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, ErrMessageNotFound)
+			return
+		}
+		h.Logger.ErrorContext(c.Request.Context(), "failed to list storage cells", slog.String("op", op), slog.String("error", err.Error()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		return
+	}
+	c.JSON(http.StatusOK, cells)
+}
+
+func (h *Handler) CreateStorageCell(c *gin.Context) {
+	const op = "handler.storage_cell.CreateStorageCell"
+
+	role, _ := c.Get(userRole)
+	if role != api.UserRoleModerator {
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrMessageAccessDenied)
+		return
+	}
+	pvzID, err := uuid.Parse(c.Param("pvzId"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	var req api.PostPvzPvzIdCellsJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	cell, err := h.Services.StorageCell.Create(c.Request.Context(), pvzID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrInvalidStorageCellCode) || errors.Is(err, errs.ErrInvalidStorageCellCapacity):
+			c.AbortWithStatusJSON(http.StatusBadRequest, api.Error{Message: err.Error()})
+		case errors.Is(err, errs.ErrPVZNotFound):
+			c.AbortWithStatusJSON(http.StatusNotFound, api.Error{Message: err.Error()})
+		case errors.Is(err, errs.ErrStorageCellExists):
+			c.AbortWithStatusJSON(http.StatusConflict, api.Error{Message: err.Error()})
+		default:
+			h.Logger.ErrorContext(c.Request.Context(), "failed to create storage cell", slog.String("op", op), slog.String("error", err.Error()))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, cell)
+}
+
+func (h *Handler) UpdateStorageCell(c *gin.Context) {
+	const op = "handler.storage_cell.UpdateStorageCell"
+
+	role, _ := c.Get(userRole)
+	if role != api.UserRoleModerator {
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrMessageAccessDenied)
+		return
+	}
+	pvzID, cellID, ok := storageCellParams(c)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	var req api.PutPvzPvzIdCellsCellIdJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	cell, err := h.Services.StorageCell.Update(c.Request.Context(), pvzID, cellID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrInvalidStorageCellCapacity):
+			c.AbortWithStatusJSON(http.StatusBadRequest, api.Error{Message: err.Error()})
+		case errors.Is(err, errs.ErrStorageCellNotFound):
+			c.AbortWithStatusJSON(http.StatusNotFound, api.Error{Message: err.Error()})
+		case errors.Is(err, errs.ErrCapacityBelowOccupied):
+			c.AbortWithStatusJSON(http.StatusConflict, api.Error{Message: err.Error()})
+		default:
+			h.Logger.ErrorContext(c.Request.Context(), "failed to update storage cell", slog.String("op", op), slog.String("error", err.Error()))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, cell)
+}
+
+func (h *Handler) DeleteStorageCell(c *gin.Context) {
+	const op = "handler.storage_cell.DeleteStorageCell"
+
+	role, _ := c.Get(userRole)
+	if role != api.UserRoleModerator {
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrMessageAccessDenied)
+		return
+	}
+	pvzID, cellID, ok := storageCellParams(c)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	if err := h.Services.StorageCell.Delete(c.Request.Context(), pvzID, cellID); err != nil {
+		switch {
+		case errors.Is(err, errs.ErrStorageCellNotFound):
+			c.AbortWithStatusJSON(http.StatusNotFound, api.Error{Message: err.Error()})
+		case errors.Is(err, errs.ErrStorageCellNotEmpty):
+			c.AbortWithStatusJSON(http.StatusConflict, api.Error{Message: err.Error()})
+		default:
+			h.Logger.ErrorContext(c.Request.Context(), "failed to delete storage cell", slog.String("op", op), slog.String("error", err.Error()))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		}
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) SuggestStorageCell(c *gin.Context) {
+	const op = "handler.storage_cell.SuggestStorageCell"
+
+	role, _ := c.Get(userRole)
+	if role != api.UserRoleEmployee {
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrMessageAccessDenied)
+		return
+	}
+	pvzID, err := uuid.Parse(c.Param("pvzId"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	var params api.GetPvzPvzIdCellsSuggestParams
+	if err := c.ShouldBindQuery(&params); err != nil || (params.Count != nil && *params.Count < 1) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	cell, err := h.Services.StorageCell.Suggest(c.Request.Context(), pvzID, params.Count)
+	if err != nil {
+		if errors.Is(err, errs.ErrNoFreeStorageCell) {
+			c.AbortWithStatusJSON(http.StatusConflict, api.Error{Message: err.Error()})
+			return
+		}
+		h.Logger.ErrorContext(c.Request.Context(), "failed to suggest storage cell", slog.String("op", op), slog.String("error", err.Error()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		return
+	}
+	c.JSON(http.StatusOK, cell)
+}
+
+func (h *Handler) PlaceProducts(c *gin.Context) {
+	const op = "handler.storage_cell.PlaceProducts"
+
+	role, _ := c.Get(userRole)
+	if role != api.UserRoleEmployee {
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrMessageAccessDenied)
+		return
+	}
+	pvzID, cellID, ok := storageCellParams(c)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	var req api.ProductIDList
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	prods, err := h.Services.StorageCell.Place(c.Request.Context(), pvzID, cellID, req.ProductIds)
+	if err != nil {
+		if errors.Is(err, errs.ErrProductNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, api.Error{Message: err.Error()})
+			return
+		}
+		if code := storageCellErrorStatus(err); code != 0 {
+			c.AbortWithStatusJSON(code, api.Error{Message: err.Error()})
+			return
+		}
+		if errors.Is(err, errs.ErrEmptyProductBatch) || errors.Is(err, errs.ErrProductBatchTooLarge) ||
+			errors.Is(err, errs.ErrInvalidProductState) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, api.Error{Message: err.Error()})
+			return
+		}
+		h.Logger.ErrorContext(c.Request.Context(), "failed to place products", slog.String("op", op), slog.String("error", err.Error()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		return
+	}
+	c.JSON(http.StatusOK, prods)
+}
+
+func storageCellParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	pvzID, err := uuid.Parse(c.Param("pvzId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, false
+	}
+	cellID, err := uuid.Parse(c.Param("cellId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, false
+	}
+	return pvzID, cellID, true
+}
+
+// storageCellErrorStatus returns the response code for errors of a cell products are put into, 0 for other errors
+func storageCellErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, errs.ErrStorageCellNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, errs.ErrStorageCellFull) || errors.Is(err, errs.ErrStorageCellInactive):
+		return http.StatusConflict
+	}
+	return 0
+}
@@ -0,0 +1,233 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockStorageCellService is a mock implementation of service.StorageCell
+type MockStorageCellService struct {
+	mock.Mock
+}
+
+func (m *MockStorageCellService) List(ctx context.Context, pvzID uuid.UUID) ([]api.StorageCell, error) {
+	args := m.Called(pvzID)
+	return args.Get(0).([]api.StorageCell), args.Error(1)
+}
+
+func (m *MockStorageCellService) Create(ctx context.Context, pvzID uuid.UUID, cell api.StorageCellInput) (api.StorageCell, error) {
+	args := m.Called(pvzID, cell)
+	return args.Get(0).(api.StorageCell), args.Error(1)
+}
+
+func (m *MockStorageCellService) Update(ctx context.Context, pvzID, cellID uuid.UUID, cell api.StorageCellUpdate) (api.StorageCell, error) {
+	args := m.Called(pvzID, cellID, cell)
+	return args.Get(0).(api.StorageCell), args.Error(1)
+}
+
+func (m *MockStorageCellService) Delete(ctx context.Context, pvzID, cellID uuid.UUID) error {
+	args := m.Called(pvzID, cellID)
+	return args.Error(0)
+}
+
+func (m *MockStorageCellService) Suggest(ctx context.Context, pvzID uuid.UUID, count *int) (api.StorageCell, error) {
+	args := m.Called(pvzID, count)
+	return args.Get(0).(api.StorageCell), args.Error(1)
+}
+
+func (m *MockStorageCellService) Place(ctx context.Context, pvzID, cellID uuid.UUID, ids []uuid.UUID) ([]api.Product, error) {
+	args := m.Called(pvzID, cellID, ids)
+	return args.Get(0).([]api.Product), args.Error(1)
+}
+
+func setupStorageCellRouter(h *Handler, role api.UserRole) *gin.Engine {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(userRole, role)
+	})
+	router.GET("/pvz/:pvzId/cells", h.GetStorageCells)
+	router.POST("/pvz/:pvzId/cells", h.CreateStorageCell)
+	router.GET("/pvz/:pvzId/cells/suggest", h.SuggestStorageCell)
+	router.PUT("/pvz/:pvzId/cells/:cellId", h.UpdateStorageCell)
+	router.DELETE("/pvz/:pvzId/cells/:cellId", h.DeleteStorageCell)
+	router.POST("/pvz/:pvzId/cells/:cellId/products", h.PlaceProducts)
+	return router
+}
+
+func TestStorageCells(t *testing.T) {
+	pvzID := uuid.New()
+	cellID := uuid.New()
+	prodID := uuid.New()
+	cellPath := "/pvz/" + pvzID.String() + "/cells/" + cellID.String()
+	count := 3
+
+	tests := []struct {
+		name           string
+		role           api.UserRole
+		method         string
+		path           string
+		body           string
+		mockSetup      func(*MockStorageCellService)
+		expectedStatus int
+	}{
+		{
+			name:   "list as employee",
+			role:   api.UserRoleEmployee,
+			method: "GET",
+			path:   "/pvz/" + pvzID.String() + "/cells",
+			mockSetup: func(m *MockStorageCellService) {
+				m.On("List", pvzID).Return([]api.StorageCell{{Id: cellID, Code: "A-01"}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "create as moderator",
+			role:   api.UserRoleModerator,
+			method: "POST",
+			path:   "/pvz/" + pvzID.String() + "/cells",
+			body:   `{"code":"A-01","capacity":10}`,
+			mockSetup: func(m *MockStorageCellService) {
+				m.On("Create", pvzID, api.StorageCellInput{Code: "A-01", Capacity: 10}).
+					Return(api.StorageCell{Id: cellID, Code: "A-01", Capacity: 10}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "create as employee",
+			role:           api.UserRoleEmployee,
+			method:         "POST",
+			path:           "/pvz/" + pvzID.String() + "/cells",
+			body:           `{"code":"A-01","capacity":10}`,
+			mockSetup:      func(m *MockStorageCellService) {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "create duplicate code",
+			role:   api.UserRoleModerator,
+			method: "POST",
+			path:   "/pvz/" + pvzID.String() + "/cells",
+			body:   `{"code":"A-01","capacity":10}`,
+			mockSetup: func(m *MockStorageCellService) {
+				m.On("Create", pvzID, api.StorageCellInput{Code: "A-01", Capacity: 10}).
+					Return(api.StorageCell{}, errs.ErrStorageCellExists)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:   "shrink below occupied",
+			role:   api.UserRoleModerator,
+			method: "PUT",
+			path:   cellPath,
+			body:   `{"capacity":1,"active":true}`,
+			mockSetup: func(m *MockStorageCellService) {
+				m.On("Update", pvzID, cellID, api.StorageCellUpdate{Capacity: 1, Active: true}).
+					Return(api.StorageCell{}, errs.ErrCapacityBelowOccupied)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:   "delete non-empty cell",
+			role:   api.UserRoleModerator,
+			method: "DELETE",
+			path:   cellPath,
+			mockSetup: func(m *MockStorageCellService) {
+				m.On("Delete", pvzID, cellID).Return(errs.ErrStorageCellNotEmpty)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:   "suggest",
+			role:   api.UserRoleEmployee,
+			method: "GET",
+			path:   "/pvz/" + pvzID.String() + "/cells/suggest?count=3",
+			mockSetup: func(m *MockStorageCellService) {
+				m.On("Suggest", pvzID, &count).Return(api.StorageCell{Id: cellID, Code: "A-01"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "suggest without room",
+			role:   api.UserRoleEmployee,
+			method: "GET",
+			path:   "/pvz/" + pvzID.String() + "/cells/suggest",
+			mockSetup: func(m *MockStorageCellService) {
+				m.On("Suggest", pvzID, (*int)(nil)).Return(api.StorageCell{}, errs.ErrNoFreeStorageCell)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "suggest zero products",
+			role:           api.UserRoleEmployee,
+			method:         "GET",
+			path:           "/pvz/" + pvzID.String() + "/cells/suggest?count=0",
+			mockSetup:      func(m *MockStorageCellService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "place products",
+			role:   api.UserRoleEmployee,
+			method: "POST",
+			path:   cellPath + "/products",
+			body:   `{"productIds":["` + prodID.String() + `"]}`,
+			mockSetup: func(m *MockStorageCellService) {
+				m.On("Place", pvzID, cellID, []uuid.UUID{prodID}).
+					Return([]api.Product{{Id: &prodID, CellId: &cellID}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "place into full cell",
+			role:   api.UserRoleEmployee,
+			method: "POST",
+			path:   cellPath + "/products",
+			body:   `{"productIds":["` + prodID.String() + `"]}`,
+			mockSetup: func(m *MockStorageCellService) {
+				m.On("Place", pvzID, cellID, []uuid.UUID{prodID}).
+					Return([]api.Product(nil), errs.ErrStorageCellFull)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "place with invalid cell id",
+			role:           api.UserRoleEmployee,
+			method:         "POST",
+			path:           "/pvz/" + pvzID.String() + "/cells/A-01/products",
+			body:           `{"productIds":["` + prodID.String() + `"]}`,
+			mockSetup:      func(m *MockStorageCellService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCells := new(MockStorageCellService)
+			tt.mockSetup(mockCells)
+
+			h := &Handler{
+				Services: &service.Service{StorageCell: mockCells},
+				Logger:   slog.Default(),
+			}
+			router := setupStorageCellRouter(h, tt.role)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockCells.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) CreateTransfer(c *gin.Context) {
+	const op = "handler.transfer.CreateTransfer"
+
+	role, _ := c.Get(userRole)
+	if role != api.UserRoleEmployee {
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrMessageAccessDenied)
+		return
+	}
+	var req api.PostTransfersJSONRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	transfer, err := h.Services.Transfer.Create(c.Request.Context(), req, currentUserID(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrPVZNotFound) || errors.Is(err, errs.ErrProductNotFound):
+			c.AbortWithStatusJSON(http.StatusNotFound, api.Error{Message: err.Error()})
+		case errors.Is(err, errs.ErrProductInTransfer):
+			c.AbortWithStatusJSON(http.StatusConflict, api.Error{Message: err.Error()})
+		case errors.Is(err, errs.ErrSameTransferPVZ) || errors.Is(err, errs.ErrEmptyProductBatch) ||
+			errors.Is(err, errs.ErrProductBatchTooLarge) || errors.Is(err, errs.ErrProductInOpenReception) ||
+			errors.Is(err, errs.ErrInvalidProductState):
+			c.AbortWithStatusJSON(http.StatusBadRequest, api.Error{Message: err.Error()})
+		default:
+			h.Logger.ErrorContext(c.Request.Context(), "failed to create transfer", slog.String("op", op), slog.String("error", err.Error()))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, transfer)
+}
+
+func (h *Handler) ListTransfers(c *gin.Context) {
+	const op = "handler.transfer.ListTransfers"
+	//auth handled in middleware
+	// gin can't bind uuid query params, so pvzId is parsed separately
+	var query struct {
+		PvzID  string              `form:"pvzId"`
+		Status *api.TransferStatus `form:"status"`
+		Page   *int                `form:"page"`
+		Limit  *int                `form:"limit"`
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	if !validPage(query.Page, query.Limit) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	if query.Status != nil && !validTransferStatus(*query.Status) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	pvzID, err := parseOptionalUUID(query.PvzID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	transfers, err := h.Services.Transfer.List(c.Request.Context(), api.GetTransfersParams{
+		PvzId:  pvzID,
+		Status: query.Status,
+		Page:   query.Page,
+		Limit:  query.Limit,
+	})
+	if err != nil {
+		h.Logger.ErrorContext(c.Request.Context(), "failed to list transfers", slog.String("op", op), slog.String("error", err.Error()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		return
+	}
+	c.JSON(http.StatusOK, transfers)
+}
+
+func (h *Handler) GetTransfer(c *gin.Context) {
+	const op = "handler.transfer.GetTransfer"
+	//auth handled in middleware
+	transferID, err := uuid.Parse(c.Param("transferId"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	transfer, err := h.Services.Transfer.Get(c.Request.Context(), transferID)
+	if err != nil {
+		if errors.Is(err, errs.ErrTransferNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, ErrMessageNotFound)
+			return
+		}
+		h.Logger.ErrorContext(c.Request.Context(), "failed to get transfer", slog.String("op", op), slog.String("error", err.Error()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		return
+	}
+	c.JSON(http.StatusOK, transfer)
+}
+
+func (h *Handler) DispatchTransfer(c *gin.Context) {
+	h.moveTransfer(c, "handler.transfer.DispatchTransfer", h.Services.Transfer.Dispatch)
+}
+
+func (h *Handler) ArriveTransfer(c *gin.Context) {
+	h.moveTransfer(c, "handler.transfer.ArriveTransfer", h.Services.Transfer.Arrive)
+}
+
+// moveTransfer handles dispatch and arrival requests, both move the transfer to its next status
+func (h *Handler) moveTransfer(c *gin.Context, op string, move func(context.Context, uuid.UUID, uuid.UUID) (api.Transfer, error)) {
+	role, _ := c.Get(userRole)
+	if role != api.UserRoleEmployee {
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrMessageAccessDenied)
+		return
+	}
+	transferID, err := uuid.Parse(c.Param("transferId"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	transfer, err := move(c.Request.Context(), transferID, currentUserID(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrTransferNotFound):
+			c.AbortWithStatusJSON(http.StatusNotFound, api.Error{Message: err.Error()})
+		case errors.Is(err, errs.ErrInvalidTransferStatus) || errors.Is(err, errs.ErrDuplicateBarcode):
+			c.AbortWithStatusJSON(http.StatusConflict, api.Error{Message: err.Error()})
+		case errors.Is(err, errs.ErrNoReceptionsInProgress) || errors.Is(err, errs.ErrProductInOpenReception) ||
+			errors.Is(err, errs.ErrInvalidProductState):
+			c.AbortWithStatusJSON(http.StatusBadRequest, api.Error{Message: err.Error()})
+		default:
+			h.Logger.ErrorContext(c.Request.Context(), "failed to move transfer", slog.String("op", op), slog.String("error", err.Error()))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, transfer)
+}
+
+func (h *Handler) GetProductTransfers(c *gin.Context) {
+	const op = "handler.transfer.GetProductTransfers"
+	//auth handled in middleware
+	productID, err := uuid.Parse(c.Param("productId"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
+		return
+	}
+	transfers, err := h.Services.Transfer.ProductHistory(c.Request.Context(), productID)
+	if err != nil {
+		if errors.Is(err, errs.ErrProductNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, ErrMessageNotFound)
+			return
+		}
+		h.Logger.ErrorContext(c.Request.Context(), "failed to get product transfers", slog.String("op", op), slog.String("error", err.Error()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
+		return
+	}
+	c.JSON(http.StatusOK, transfers)
+}
+
+func validTransferStatus(status api.TransferStatus) bool {
+	switch status {
+	case api.TransferStatusCreated, api.TransferStatusDispatched, api.TransferStatusArrived:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,231 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTransferService is a mock implementation of service.Transfer
+type MockTransferService struct {
+	mock.Mock
+}
+
+func (m *MockTransferService) Create(ctx context.Context, transfer api.TransferInput, userID uuid.UUID) (api.Transfer, error) {
+	args := m.Called(transfer, userID)
+	return args.Get(0).(api.Transfer), args.Error(1)
+}
+
+func (m *MockTransferService) Dispatch(ctx context.Context, transferID, userID uuid.UUID) (api.Transfer, error) {
+	args := m.Called(transferID, userID)
+	return args.Get(0).(api.Transfer), args.Error(1)
+}
+
+func (m *MockTransferService) Arrive(ctx context.Context, transferID, userID uuid.UUID) (api.Transfer, error) {
+	args := m.Called(transferID, userID)
+	return args.Get(0).(api.Transfer), args.Error(1)
+}
+
+func (m *MockTransferService) List(ctx context.Context, params api.GetTransfersParams) ([]api.Transfer, error) {
+	args := m.Called(params)
+	return args.Get(0).([]api.Transfer), args.Error(1)
+}
+
+func (m *MockTransferService) Get(ctx context.Context, transferID uuid.UUID) (api.Transfer, error) {
+	args := m.Called(transferID)
+	return args.Get(0).(api.Transfer), args.Error(1)
+}
+
+func (m *MockTransferService) ProductHistory(ctx context.Context, productID uuid.UUID) ([]api.Transfer, error) {
+	args := m.Called(productID)
+	return args.Get(0).([]api.Transfer), args.Error(1)
+}
+
+func setupTransferRouter(h *Handler, role api.UserRole, uid uuid.UUID) *gin.Engine {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(userRole, role)
+		c.Set(userID, uid)
+	})
+	router.POST("/transfers", h.CreateTransfer)
+	router.GET("/transfers", h.ListTransfers)
+	router.GET("/transfers/:transferId", h.GetTransfer)
+	router.POST("/transfers/:transferId/dispatch", h.DispatchTransfer)
+	router.POST("/transfers/:transferId/arrive", h.ArriveTransfer)
+	router.GET("/products/:productId/transfers", h.GetProductTransfers)
+	return router
+}
+
+func TestTransfers(t *testing.T) {
+	uid := uuid.New()
+	transferID := uuid.New()
+	source, destination := uuid.New(), uuid.New()
+	prodID := uuid.New()
+	createBody := `{"sourcePvzId":"` + source.String() + `","destinationPvzId":"` + destination.String() + `","productIds":["` + prodID.String() + `"]}`
+	input := api.TransferInput{SourcePvzId: source, DestinationPvzId: destination, ProductIds: []uuid.UUID{prodID}}
+	dispatched := api.TransferStatusDispatched
+
+	tests := []struct {
+		name           string
+		role           api.UserRole
+		method         string
+		path           string
+		body           string
+		mockSetup      func(*MockTransferService)
+		expectedStatus int
+	}{
+		{
+			name:   "create",
+			role:   api.UserRoleEmployee,
+			method: "POST",
+			path:   "/transfers",
+			body:   createBody,
+			mockSetup: func(m *MockTransferService) {
+				m.On("Create", input, uid).Return(api.Transfer{Id: transferID, Status: api.TransferStatusCreated}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "create as moderator",
+			role:           api.UserRoleModerator,
+			method:         "POST",
+			path:           "/transfers",
+			body:           createBody,
+			mockSetup:      func(m *MockTransferService) {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "create with product in another transfer",
+			role:   api.UserRoleEmployee,
+			method: "POST",
+			path:   "/transfers",
+			body:   createBody,
+			mockSetup: func(m *MockTransferService) {
+				m.On("Create", input, uid).Return(api.Transfer{}, errs.ErrProductInTransfer)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:   "create between the same pvz",
+			role:   api.UserRoleEmployee,
+			method: "POST",
+			path:   "/transfers",
+			body:   createBody,
+			mockSetup: func(m *MockTransferService) {
+				m.On("Create", input, uid).Return(api.Transfer{}, errs.ErrSameTransferPVZ)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "list dispatched transfers of pvz",
+			role:   api.UserRoleModerator,
+			method: "GET",
+			path:   "/transfers?pvzId=" + source.String() + "&status=dispatched",
+			mockSetup: func(m *MockTransferService) {
+				m.On("List", api.GetTransfersParams{PvzId: &source, Status: &dispatched}).
+					Return([]api.Transfer{{Id: transferID, Status: dispatched}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "list with unknown status",
+			role:           api.UserRoleEmployee,
+			method:         "GET",
+			path:           "/transfers?status=lost",
+			mockSetup:      func(m *MockTransferService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "get missing transfer",
+			role:   api.UserRoleEmployee,
+			method: "GET",
+			path:   "/transfers/" + transferID.String(),
+			mockSetup: func(m *MockTransferService) {
+				m.On("Get", transferID).Return(api.Transfer{}, errs.ErrTransferNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:   "dispatch",
+			role:   api.UserRoleEmployee,
+			method: "POST",
+			path:   "/transfers/" + transferID.String() + "/dispatch",
+			mockSetup: func(m *MockTransferService) {
+				m.On("Dispatch", transferID, uid).Return(api.Transfer{Id: transferID, Status: dispatched}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "dispatch twice",
+			role:   api.UserRoleEmployee,
+			method: "POST",
+			path:   "/transfers/" + transferID.String() + "/dispatch",
+			mockSetup: func(m *MockTransferService) {
+				m.On("Dispatch", transferID, uid).Return(api.Transfer{}, errs.ErrInvalidTransferStatus)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:   "arrive without open reception",
+			role:   api.UserRoleEmployee,
+			method: "POST",
+			path:   "/transfers/" + transferID.String() + "/arrive",
+			mockSetup: func(m *MockTransferService) {
+				m.On("Arrive", transferID, uid).Return(api.Transfer{}, errs.ErrNoReceptionsInProgress)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "product history",
+			role:   api.UserRoleModerator,
+			method: "GET",
+			path:   "/products/" + prodID.String() + "/transfers",
+			mockSetup: func(m *MockTransferService) {
+				m.On("ProductHistory", prodID).Return([]api.Transfer{{Id: transferID}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "history of missing product",
+			role:   api.UserRoleModerator,
+			method: "GET",
+			path:   "/products/" + prodID.String() + "/transfers",
+			mockSetup: func(m *MockTransferService) {
+				m.On("ProductHistory", prodID).Return([]api.Transfer(nil), errs.ErrProductNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTransfers := new(MockTransferService)
+			tt.mockSetup(mockTransfers)
+
+			h := &Handler{
+				Services: &service.Service{Transfer: mockTransfers},
+				Logger:   slog.Default(),
+			}
+			router := setupTransferRouter(h, tt.role, uid)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockTransfers.AssertExpectations(t)
+		})
+	}
+}
@@ -16,7 +16,7 @@ func (h *Handler) DummyLogin(c *gin.Context) {
 	var role api.PostDummyLoginJSONBody
 	err := c.ShouldBind(&role)
 	if err != nil {
-		h.Logger.Error("failed to bind dummy login request", slog.String("op", op), slog.String("error", err.Error()))
+		h.Logger.ErrorContext(c.Request.Context(), "failed to bind dummy login request", slog.String("op", op), slog.String("error", err.Error()))
 		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
 		return
 	}
@@ -26,7 +26,7 @@ func (h *Handler) DummyLogin(c *gin.Context) {
 	}
 	tok, err := h.Services.GenerateToken(string(role.Role))
 	if err != nil {
-		h.Logger.Error("failed to generate dummy token", slog.String("op", op), slog.String("error", err.Error()))
+		h.Logger.ErrorContext(c.Request.Context(), "failed to generate dummy token", slog.String("op", op), slog.String("error", err.Error()))
 		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
 		return
 	}
@@ -39,17 +39,17 @@ func (h *Handler) Login(c *gin.Context) {
 
 	err := c.ShouldBind(&creds)
 	if err != nil {
-		h.Logger.Error("failed to bind login credentials", slog.String("op", op), slog.String("error", err.Error()))
+		h.Logger.ErrorContext(c.Request.Context(), "failed to bind login credentials", slog.String("op", op), slog.String("error", err.Error()))
 		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
 		return
 	}
-	tok, err := h.Services.Login(creds)
+	tok, err := h.Services.Login(c.Request.Context(), creds)
 	if err != nil {
 		if errors.Is(err, errs.ErrWrongCreds) {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, ErrMessageWrongCredentials)
 			return
 		}
-		h.Logger.Error("failed to login user", slog.String("op", op), slog.String("error", err.Error()))
+		h.Logger.ErrorContext(c.Request.Context(), "failed to login user", slog.String("op", op), slog.String("error", err.Error()))
 		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
 		return
 	}
@@ -62,17 +62,17 @@ func (h *Handler) Register(c *gin.Context) {
 
 	err := c.ShouldBind(&creds)
 	if err != nil {
-		h.Logger.Error("failed to bind register credentials", slog.String("op", op), slog.String("error", err.Error()))
+		h.Logger.ErrorContext(c.Request.Context(), "failed to bind register credentials", slog.String("op", op), slog.String("error", err.Error()))
 		c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
 		return
 	}
-	user, err := h.Services.CreateUser(creds)
+	user, err := h.Services.CreateUser(c.Request.Context(), creds)
 	if err != nil {
 		if errors.Is(err, errs.ErrEmailExists) || errors.Is(err, errs.ErrPasswordTooLong) {
 			c.AbortWithStatusJSON(http.StatusBadRequest, ErrMessageBadRequest)
 			return
 		}
-		h.Logger.Error("failed to register user", slog.String("op", op), slog.String("error", err.Error()))
+		h.Logger.ErrorContext(c.Request.Context(), "failed to register user", slog.String("op", op), slog.String("error", err.Error()))
 		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrMessageInternalServerError)
 		return
 	}
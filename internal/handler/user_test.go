@@ -2,6 +2,7 @@ package handler_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"log/slog"
 	"net/http"
@@ -24,19 +25,19 @@ type MockUserService struct {
 	mock.Mock
 }
 
-func (m *MockUserService) CreateUser(creds api.PostRegisterJSONBody) (api.User, error) {
+func (m *MockUserService) CreateUser(ctx context.Context, creds api.PostRegisterJSONBody) (api.User, error) {
 	args := m.Called(creds)
 	return args.Get(0).(api.User), args.Error(1)
 }
 
-func (m *MockUserService) Login(creds api.PostLoginJSONBody) (string, error) {
+func (m *MockUserService) Login(ctx context.Context, creds api.PostLoginJSONBody) (string, error) {
 	args := m.Called(creds)
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockUserService) ParseToken(tok string) (api.UserRole, error) {
+func (m *MockUserService) ParseToken(tok string) (api.UserRole, uuid.UUID, error) {
 	args := m.Called(tok)
-	return args.Get(0).(api.UserRole), args.Error(1)
+	return args.Get(0).(api.UserRole), args.Get(1).(uuid.UUID), args.Error(2)
 }
 
 func (m *MockUserService) GenerateToken(role string) (string, error) {
@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ST359/pvz-service/internal/api"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// NewRegistry creates a registry with metrics of the Go runtime, the service registers its own metrics on top
+func NewRegistry() *prometheus.Registry {
+	r := prometheus.NewRegistry()
+	r.MustRegister(collectors.NewGoCollector())
+	return r
+}
+
+// Handler serves all metrics of the registry to Prometheus
+func Handler(r *prometheus.Registry) http.Handler {
+	return promhttp.HandlerFor(r, promhttp.HandlerOpts{Registry: r})
+}
+
+// HTTP holds metrics of served requests, labeled by method, route template and status
+type HTTP struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+func NewHTTP(r prometheus.Registerer) *HTTP {
+	f := promauto.With(r)
+	return &HTTP{
+		requests: f.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Number of served HTTP requests.",
+		}, []string{"method", "route", "status"}),
+		duration: f.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Time spent serving HTTP requests.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+	}
+}
+
+// ObserveRequest records a served request, route is a template like /pvz/:pvzId so the number of series stays bounded
+func (h *HTTP) ObserveRequest(method, route string, status int, elapsed time.Duration) {
+	code := strconv.Itoa(status)
+	h.requests.WithLabelValues(method, route, code).Inc()
+	h.duration.WithLabelValues(method, route, code).Observe(elapsed.Seconds())
+}
+
+// RegisterDBStats exposes stats of the connection pool as go_sql_* metrics, they are read from db on every scrape
+func RegisterDBStats(r prometheus.Registerer, db *sql.DB, dbName string) {
+	r.MustRegister(collectors.NewDBStatsCollector(db, dbName))
+}
+
+// Business counts domain events reported by services
+type Business struct {
+	pvzsCreated      prometheus.Counter
+	receptionsOpened *prometheus.CounterVec
+	receptionsClosed *prometheus.CounterVec
+	productsAdded    *prometheus.CounterVec
+	productsDeleted  prometheus.Counter
+}
+
+func NewBusiness(r prometheus.Registerer) *Business {
+	f := promauto.With(r)
+	return &Business{
+		pvzsCreated: f.NewCounter(prometheus.CounterOpts{
+			Name: "pvzs_created_total",
+			Help: "Number of created PVZs.",
+		}),
+		receptionsOpened: f.NewCounterVec(prometheus.CounterOpts{
+			Name: "receptions_opened_total",
+			Help: "Number of opened receptions.",
+		}, []string{"kind"}),
+		receptionsClosed: f.NewCounterVec(prometheus.CounterOpts{
+			Name: "receptions_closed_total",
+			Help: "Number of closed receptions, reason is manual or auto_closed.",
+		}, []string{"kind", "reason"}),
+		productsAdded: f.NewCounterVec(prometheus.CounterOpts{
+			Name: "products_added_total",
+			Help: "Number of products scanned into receptions.",
+		}, []string{"kind"}),
+		productsDeleted: f.NewCounter(prometheus.CounterOpts{
+			Name: "products_deleted_total",
+			Help: "Number of products deleted from receptions in progress.",
+		}),
+	}
+}
+
+func (b *Business) PVZsCreated(n int) {
+	b.pvzsCreated.Add(float64(n))
+}
+
+func (b *Business) ReceptionOpened(kind api.ReceptionKind) {
+	b.receptionsOpened.WithLabelValues(string(kind)).Inc()
+}
+
+func (b *Business) ReceptionClosed(kind api.ReceptionKind, reason api.ReceptionCloseReason) {
+	b.receptionsClosed.WithLabelValues(string(kind), string(reason)).Inc()
+}
+
+func (b *Business) ProductsAdded(kind api.ReceptionKind, n int) {
+	b.productsAdded.WithLabelValues(string(kind)).Add(float64(n))
+}
+
+func (b *Business) ProductsDeleted(n int) {
+	b.productsDeleted.Add(float64(n))
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ST359/pvz-service/internal/api"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scrape(t *testing.T, r *prometheus.Registry) string {
+	w := httptest.NewRecorder()
+	Handler(r).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	body, err := io.ReadAll(w.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestAppMetrics(t *testing.T) {
+	r := NewRegistry()
+	NewHTTP(r).ObserveRequest(http.MethodPost, "/pvz/:pvzId/close_last_reception", http.StatusOK, 20*time.Millisecond)
+	b := NewBusiness(r)
+	b.PVZsCreated(2)
+	b.ReceptionOpened(api.ReceptionKindInbound)
+	b.ReceptionClosed(api.ReceptionKindInbound, api.CloseReasonAutoClosed)
+	b.ProductsAdded(api.ReceptionKindCustomerReturn, 3)
+	b.ProductsDeleted(1)
+
+	body := scrape(t, r)
+	assert.Contains(t, body, `http_requests_total{method="POST",route="/pvz/:pvzId/close_last_reception",status="200"} 1`)
+	assert.Contains(t, body, `http_request_duration_seconds_bucket{method="POST",route="/pvz/:pvzId/close_last_reception",status="200",le="0.025"} 1`)
+	assert.Contains(t, body, "pvzs_created_total 2\n")
+	assert.Contains(t, body, `receptions_opened_total{kind="inbound"} 1`)
+	assert.Contains(t, body, `receptions_closed_total{kind="inbound",reason="auto_closed"} 1`)
+	assert.Contains(t, body, `products_added_total{kind="customer_return"} 3`)
+	assert.Contains(t, body, "products_deleted_total 1\n")
+}
+
+func TestRegistry_RuntimeAndDBStats(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	r := NewRegistry()
+	RegisterDBStats(r, db, "pvz")
+
+	body := scrape(t, r)
+	assert.Contains(t, body, "go_goroutines ")
+	assert.Contains(t, body, `go_sql_max_open_connections{db_name="pvz"} 0`)
+}
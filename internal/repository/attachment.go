@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/google/uuid"
+)
+
+const (
+	attachmentReceptionKey = "attachments_reception_id_fkey"
+	attachmentProductKey   = "attachments_product_id_fkey"
+)
+
+// attachmentColumns are selected or returned whenever a full api.Attachment is read, in order of attachmentFields.
+// The download url is not stored, it is filled by the service
+const attachmentColumns = "id, reception_id, product_id, file_name, content_type, size, sha256, uploaded_by, uploaded_at"
+
+// attachmentFields returns scan destinations for attachmentColumns
+func attachmentFields(a *api.Attachment) []any {
+	return []any{&a.Id, &a.ReceptionId, &a.ProductId, &a.FileName, &a.ContentType, &a.Size, &a.Sha256, &a.UploadedBy, &a.UploadedAt}
+}
+
+type AttachmentPostgres struct {
+	db *sql.DB
+}
+
+func NewAttachmentPostgres(db *sql.DB) *AttachmentPostgres {
+	return &AttachmentPostgres{db: db}
+}
+
+// Create stores metadata of a file already put into the blob store under storageKey,
+// can return ErrReceptionNotFound and ErrProductNotFound
+func (a *AttachmentPostgres) Create(ctx context.Context, att api.Attachment, storageKey string) (api.Attachment, error) {
+	const op = "repository.attachment.Create"
+
+	var res api.Attachment
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	err := psql.Insert(attachmentsTable).
+		Columns("id", "reception_id", "product_id", "file_name", "content_type", "size", "sha256", "storage_key", "uploaded_by").
+		Values(att.Id, att.ReceptionId, att.ProductId, att.FileName, att.ContentType, att.Size, att.Sha256, storageKey, att.UploadedBy).
+		Suffix("RETURNING " + attachmentColumns).
+		RunWith(a.db).
+		QueryRowContext(ctx).Scan(attachmentFields(&res)...)
+	if err != nil {
+		switch {
+		case isForeignKeyViolation(err, attachmentReceptionKey):
+			return api.Attachment{}, errs.ErrReceptionNotFound
+		case isForeignKeyViolation(err, attachmentProductKey):
+			return api.Attachment{}, errs.ErrProductNotFound
+		}
+		return api.Attachment{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return res, nil
+}
+
+// ListByReception returns attachments of the reception oldest first, can return ErrReceptionNotFound
+func (a *AttachmentPostgres) ListByReception(ctx context.Context, recID uuid.UUID) ([]api.Attachment, error) {
+	const op = "repository.attachment.ListByReception"
+
+	res, err := a.list(ctx, receptionsTable, "reception_id", recID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrReceptionNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return res, nil
+}
+
+// ListByProduct returns attachments of the product oldest first, can return ErrProductNotFound
+func (a *AttachmentPostgres) ListByProduct(ctx context.Context, prodID uuid.UUID) ([]api.Attachment, error) {
+	const op = "repository.attachment.ListByProduct"
+
+	res, err := a.list(ctx, productsTable, "product_id", prodID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrProductNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return res, nil
+}
+
+// list returns attachments whose column refers to ownerID, sql.ErrNoRows means there is no such owner in ownerTable
+func (a *AttachmentPostgres) list(ctx context.Context, ownerTable, column string, ownerID uuid.UUID) ([]api.Attachment, error) {
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	var exists bool
+	err := psql.Select("COUNT(*)>0").
+		From(ownerTable).
+		Where(squirrel.Eq{"id": ownerID}).
+		RunWith(a.db).
+		QueryRowContext(ctx).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+
+	rows, err := psql.Select(attachmentColumns).
+		From(attachmentsTable).
+		Where(squirrel.Eq{column: ownerID}).
+		OrderBy("uploaded_at", "id").
+		RunWith(a.db).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []api.Attachment{}
+	for rows.Next() {
+		var att api.Attachment
+		if err := rows.Scan(attachmentFields(&att)...); err != nil {
+			return nil, err
+		}
+		res = append(res, att)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetByID returns the attachment and the key of its file in the blob store, can return ErrAttachmentNotFound
+func (a *AttachmentPostgres) GetByID(ctx context.Context, id uuid.UUID) (api.Attachment, string, error) {
+	const op = "repository.attachment.GetByID"
+
+	var (
+		att        api.Attachment
+		storageKey string
+	)
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	err := psql.Select(attachmentColumns, "storage_key").
+		From(attachmentsTable).
+		Where(squirrel.Eq{"id": id}).
+		RunWith(a.db).
+		QueryRowContext(ctx).Scan(append(attachmentFields(&att), &storageKey)...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return api.Attachment{}, "", errs.ErrAttachmentNotFound
+		}
+		return api.Attachment{}, "", fmt.Errorf("%s: %w", op, err)
+	}
+	return att, storageKey, nil
+}
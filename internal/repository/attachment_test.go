@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var attachmentRowColumns = []string{"id", "reception_id", "product_id", "file_name", "content_type", "size", "sha256", "uploaded_by", "uploaded_at"}
+
+const attachmentSum = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+func TestAttachmentPostgres_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewAttachmentPostgres(db)
+	attID := uuid.New()
+	recID := uuid.New()
+	prodID := uuid.New()
+	userID := uuid.New()
+	now := time.Now()
+	key := "attachments/" + attID.String()
+
+	tests := []struct {
+		name        string
+		input       api.Attachment
+		mockSetup   func()
+		expected    api.Attachment
+		expectedErr error
+	}{
+		{
+			name: "reception attachment",
+			input: api.Attachment{Id: attID, ReceptionId: &recID, FileName: "box.png", ContentType: "image/png",
+				Size: 4, Sha256: attachmentSum, UploadedBy: &userID},
+			mockSetup: func() {
+				rows := sqlmock.NewRows(attachmentRowColumns).
+					AddRow(attID, recID, nil, "box.png", "image/png", 4, attachmentSum, userID, now)
+				mock.ExpectQuery(`INSERT INTO attachments \(id,reception_id,product_id,file_name,content_type,size,sha256,storage_key,uploaded_by\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6,\$7,\$8,\$9\) RETURNING id, reception_id`).
+					WithArgs(attID, &recID, nil, "box.png", "image/png", int64(4), attachmentSum, key, &userID).
+					WillReturnRows(rows)
+			},
+			expected: api.Attachment{Id: attID, ReceptionId: &recID, FileName: "box.png", ContentType: "image/png",
+				Size: 4, Sha256: attachmentSum, UploadedBy: &userID, UploadedAt: now},
+		},
+		{
+			name:  "reception not found",
+			input: api.Attachment{Id: attID, ReceptionId: &recID, FileName: "box.png", ContentType: "image/png", Size: 4, Sha256: attachmentSum},
+			mockSetup: func() {
+				mock.ExpectQuery("INSERT INTO attachments").
+					WillReturnError(&pq.Error{Code: foreignKeyViolationCode, Constraint: attachmentReceptionKey})
+			},
+			expectedErr: errs.ErrReceptionNotFound,
+		},
+		{
+			name:  "product not found",
+			input: api.Attachment{Id: attID, ProductId: &prodID, FileName: "act.pdf", ContentType: "application/pdf", Size: 4, Sha256: attachmentSum},
+			mockSetup: func() {
+				mock.ExpectQuery("INSERT INTO attachments").
+					WillReturnError(&pq.Error{Code: foreignKeyViolationCode, Constraint: attachmentProductKey})
+			},
+			expectedErr: errs.ErrProductNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			result, err := repo.Create(context.Background(), tt.input, key)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAttachmentPostgres_ListByProduct(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewAttachmentPostgres(db)
+	attID := uuid.New()
+	prodID := uuid.New()
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		mockSetup   func()
+		expected    []api.Attachment
+		expectedErr error
+	}{
+		{
+			name: "attachments",
+			mockSetup: func() {
+				mock.ExpectQuery(`SELECT COUNT\(\*\)>0 FROM products WHERE id = \$1`).
+					WithArgs(prodID).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				rows := sqlmock.NewRows(attachmentRowColumns).
+					AddRow(attID, nil, prodID, "dent.jpg", "image/jpeg", 4, attachmentSum, nil, now)
+				mock.ExpectQuery(`SELECT id, reception_id, .* FROM attachments WHERE product_id = \$1 ORDER BY uploaded_at, id`).
+					WithArgs(prodID).
+					WillReturnRows(rows)
+			},
+			expected: []api.Attachment{{Id: attID, ProductId: &prodID, FileName: "dent.jpg", ContentType: "image/jpeg",
+				Size: 4, Sha256: attachmentSum, UploadedAt: now}},
+		},
+		{
+			name: "no attachments",
+			mockSetup: func() {
+				mock.ExpectQuery(`SELECT COUNT\(\*\)>0 FROM products`).
+					WithArgs(prodID).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectQuery("SELECT id, reception_id, .* FROM attachments").
+					WithArgs(prodID).
+					WillReturnRows(sqlmock.NewRows(attachmentRowColumns))
+			},
+			expected: []api.Attachment{},
+		},
+		{
+			name: "product not found",
+			mockSetup: func() {
+				mock.ExpectQuery(`SELECT COUNT\(\*\)>0 FROM products`).
+					WithArgs(prodID).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+			},
+			expectedErr: errs.ErrProductNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			result, err := repo.ListByProduct(context.Background(), prodID)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAttachmentPostgres_GetByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewAttachmentPostgres(db)
+	attID := uuid.New()
+	recID := uuid.New()
+	now := time.Now()
+	key := "attachments/" + attID.String()
+
+	tests := []struct {
+		name        string
+		mockSetup   func()
+		expected    api.Attachment
+		expectedKey string
+		expectedErr error
+	}{
+		{
+			name: "found",
+			mockSetup: func() {
+				rows := sqlmock.NewRows(append(attachmentRowColumns, "storage_key")).
+					AddRow(attID, recID, nil, "box.png", "image/png", 4, attachmentSum, nil, now, key)
+				mock.ExpectQuery(`SELECT id, reception_id, .*, storage_key FROM attachments WHERE id = \$1`).
+					WithArgs(attID).
+					WillReturnRows(rows)
+			},
+			expected: api.Attachment{Id: attID, ReceptionId: &recID, FileName: "box.png", ContentType: "image/png",
+				Size: 4, Sha256: attachmentSum, UploadedAt: now},
+			expectedKey: key,
+		},
+		{
+			name: "not found",
+			mockSetup: func() {
+				mock.ExpectQuery("SELECT id, reception_id, .* FROM attachments").
+					WithArgs(attID).
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedErr: errs.ErrAttachmentNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			result, key, err := repo.GetByID(context.Background(), attID)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+				assert.Equal(t, tt.expectedKey, key)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/google/uuid"
+)
+
+const defaultDamageReportsLimit = 30
+
+// damageReportColumns are selected from damageReportsFrom whenever a full api.DamageReport is read, in order of damageReportFields
+const damageReportColumns = "d.id, d.product_id, p.reception_id, r.pvz_id, d.condition, d.comment, d.status, d.reported_by, d.reported_at, d.reviewed_by, d.reviewed_at, d.review_comment"
+
+// damageReportsFrom joins reports with their products and receptions, which give the reception and the PVZ of a report
+const damageReportsFrom = damageReportsTable + " d JOIN " + productsTable + " p ON p.id = d.product_id JOIN " + receptionsTable + " r ON r.id = p.reception_id"
+
+// damageReportFields returns scan destinations for damageReportColumns
+func damageReportFields(d *api.DamageReport) []any {
+	return []any{&d.Id, &d.ProductId, &d.ReceptionId, &d.PvzId, &d.Condition, &d.Comment, &d.Status, &d.ReportedBy, &d.ReportedAt, &d.ReviewedBy, &d.ReviewedAt, &d.ReviewComment}
+}
+
+type DamageReportPostgres struct {
+	db *sql.DB
+}
+
+func NewDamageReportPostgres(db *sql.DB) *DamageReportPostgres {
+	return &DamageReportPostgres{db: db}
+}
+
+// SetCondition changes the condition of a product of an in progress reception on behalf of the user, uuid.Nil leaves the reporter empty.
+// A condition other than ok puts the product into the review queue and needs an attachment of the product, ok withdraws a pending report.
+// Can return ErrProductNotFound, ErrReceptionClosed and ErrDamageEvidenceRequired
+func (d *DamageReportPostgres) SetCondition(ctx context.Context, prodID uuid.UUID, input api.ProductConditionInput, userID uuid.UUID) (api.Product, error) {
+	const op = "repository.damage_report.SetCondition"
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return api.Product{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	// the reception is share locked so it can't be closed before its summary sees the new condition
+	var status api.ReceptionStatus
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	err = psql.Select("r.status").
+		From(productsTable + " p").
+		Join(receptionsTable + " r ON r.id = p.reception_id").
+		Where(squirrel.Eq{"p.id": prodID, "p.deleted_at": nil}).
+		Suffix("FOR UPDATE OF p FOR SHARE OF r").
+		RunWith(tx).
+		QueryRowContext(ctx).Scan(&status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return api.Product{}, errs.ErrProductNotFound
+		}
+		return api.Product{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if status != api.InProgress {
+		return api.Product{}, errs.ErrReceptionClosed
+	}
+
+	if input.Condition != api.ConditionOk {
+		var hasEvidence bool
+		err = psql.Select("COUNT(*)>0").
+			From(attachmentsTable).
+			Where(squirrel.Eq{"product_id": prodID}).
+			RunWith(tx).
+			QueryRowContext(ctx).Scan(&hasEvidence)
+		if err != nil {
+			return api.Product{}, fmt.Errorf("%s: %w", op, err)
+		}
+		if !hasEvidence {
+			return api.Product{}, errs.ErrDamageEvidenceRequired
+		}
+	}
+
+	var prod api.Product
+	err = psql.Update(productsTable).
+		Set("condition", input.Condition).
+		Where(squirrel.Eq{"id": prodID}).
+		Suffix("RETURNING " + productColumns).
+		RunWith(tx).
+		QueryRowContext(ctx).Scan(productFields(&prod)...)
+	if err != nil {
+		return api.Product{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if input.Condition == api.ConditionOk {
+		_, err = psql.Delete(damageReportsTable).
+			Where(squirrel.Eq{"product_id": prodID, "status": api.DamageReportPending}).
+			RunWith(tx).
+			ExecContext(ctx)
+	} else {
+		var reportedBy *uuid.UUID
+		if userID != uuid.Nil {
+			reportedBy = &userID
+		}
+		_, err = psql.Insert(damageReportsTable).
+			Columns("product_id", "condition", "comment", "reported_by").
+			Values(prodID, input.Condition, input.Comment, reportedBy).
+			Suffix("ON CONFLICT (product_id) WHERE status = 'pending' DO UPDATE SET condition = EXCLUDED.condition, " +
+				"comment = EXCLUDED.comment, reported_by = EXCLUDED.reported_by, reported_at = now()").
+			RunWith(tx).
+			ExecContext(ctx)
+	}
+	if err != nil {
+		return api.Product{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return api.Product{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return prod, nil
+}
+
+// List returns reports of not deleted products oldest first, pending ones unless another status is given
+func (d *DamageReportPostgres) List(ctx context.Context, params api.GetDamageReportsParams) ([]api.DamageReport, error) {
+	const op = "repository.damage_report.List"
+
+	limit := defaultDamageReportsLimit
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+	offset := 0
+	if params.Page != nil {
+		offset = (*params.Page - 1) * limit
+	}
+	status := api.DamageReportPending
+	if params.Status != nil {
+		status = *params.Status
+	}
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	query := psql.Select(damageReportColumns).
+		From(damageReportsFrom).
+		Where(squirrel.Eq{"d.status": status, "p.deleted_at": nil})
+	if params.PvzId != nil {
+		query = query.Where(squirrel.Eq{"r.pvz_id": *params.PvzId})
+	}
+	rows, err := query.OrderBy("d.reported_at", "d.id").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		RunWith(d.db).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	res := []api.DamageReport{}
+	for rows.Next() {
+		var report api.DamageReport
+		if err := rows.Scan(damageReportFields(&report)...); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		res = append(res, report)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return res, nil
+}
+
+// Decide reviews a pending report on behalf of the moderator, uuid.Nil leaves the reviewer empty.
+// A rejected report puts the product back to ok and refreshes the damage counts of a closed reception summary.
+// Can return ErrDamageReportNotFound and ErrDamageReportDecided
+func (d *DamageReportPostgres) Decide(ctx context.Context, reportID uuid.UUID, decision api.DamageReportDecision, userID uuid.UUID) (api.DamageReport, error) {
+	const op = "repository.damage_report.Decide"
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return api.DamageReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var prodID uuid.UUID
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	err = psql.Select("product_id").
+		From(damageReportsTable).
+		Where(squirrel.Eq{"id": reportID}).
+		RunWith(tx).
+		QueryRowContext(ctx).Scan(&prodID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return api.DamageReport{}, errs.ErrDamageReportNotFound
+		}
+		return api.DamageReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+	// the product is locked before the report, in the same order as SetCondition does
+	var recID uuid.UUID
+	err = psql.Select("reception_id").
+		From(productsTable).
+		Where(squirrel.Eq{"id": prodID}).
+		Suffix("FOR UPDATE").
+		RunWith(tx).
+		QueryRowContext(ctx).Scan(&recID)
+	if err != nil {
+		return api.DamageReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var reviewedBy *uuid.UUID
+	if userID != uuid.Nil {
+		reviewedBy = &userID
+	}
+	res, err := psql.Update(damageReportsTable).
+		Set("status", decision.Decision).
+		Set("reviewed_by", reviewedBy).
+		Set("reviewed_at", squirrel.Expr("now()")).
+		Set("review_comment", decision.Comment).
+		Where(squirrel.Eq{"id": reportID, "status": api.DamageReportPending}).
+		RunWith(tx).
+		ExecContext(ctx)
+	if err != nil {
+		return api.DamageReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return api.DamageReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return api.DamageReport{}, errs.ErrDamageReportDecided
+	}
+
+	if decision.Decision == api.DecisionRejected {
+		_, err = psql.Update(productsTable).
+			Set("condition", api.ConditionOk).
+			Where(squirrel.Eq{"id": prodID}).
+			RunWith(tx).
+			ExecContext(ctx)
+		if err != nil {
+			return api.DamageReport{}, fmt.Errorf("%s: %w", op, err)
+		}
+		_, err = psql.Update(receptionsTable+" r").
+			Set("summary", squirrel.Expr("r.summary || jsonb_build_object("+damageSummary+")")).
+			Where(squirrel.And{squirrel.Eq{"r.id": recID}, squirrel.NotEq{"r.summary": nil}}).
+			RunWith(tx).
+			ExecContext(ctx)
+		if err != nil {
+			return api.DamageReport{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	var report api.DamageReport
+	err = psql.Select(damageReportColumns).
+		From(damageReportsFrom).
+		Where(squirrel.Eq{"d.id": reportID}).
+		RunWith(tx).
+		QueryRowContext(ctx).Scan(damageReportFields(&report)...)
+	if err != nil {
+		return api.DamageReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return api.DamageReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return report, nil
+}
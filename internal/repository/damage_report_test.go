@@ -0,0 +1,307 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var damageReportRowColumns = []string{"id", "product_id", "reception_id", "pvz_id", "condition", "comment", "status", "reported_by", "reported_at", "reviewed_by", "reviewed_at", "review_comment"}
+
+func TestDamageReportPostgres_SetCondition(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewDamageReportPostgres(db)
+	prodID := uuid.New()
+	recID := uuid.New()
+	userID := uuid.New()
+	now := time.Now()
+	comment := "torn box"
+	productColumnNames := []string{"id", "date", "reception_id", "type", "barcode", "external_order_id", "state", "state_changed_at", "return_condition", "original_order_id", "cell_id", "scan_seq", "condition"}
+
+	expectLock := func(status api.ReceptionStatus) {
+		mock.ExpectQuery(`SELECT r.status FROM products p JOIN receptions r ON r.id = p.reception_id WHERE p.deleted_at IS NULL AND p.id = \$1 FOR UPDATE OF p FOR SHARE OF r`).
+			WithArgs(prodID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(status))
+	}
+	expectUpdate := func(condition api.ProductCondition) {
+		mock.ExpectQuery(`UPDATE products SET condition = \$1 WHERE id = \$2 RETURNING id`).
+			WithArgs(condition, prodID).
+			WillReturnRows(sqlmock.NewRows(productColumnNames).
+				AddRow(prodID, now, recID, api.ProductTypeShoes, nil, nil, api.ProductStateReceived, nil, nil, nil, nil, 3, condition))
+	}
+
+	tests := []struct {
+		name        string
+		input       api.ProductConditionInput
+		mockSetup   func()
+		expected    api.Product
+		expectedErr error
+	}{
+		{
+			name:  "flagged damaged",
+			input: api.ProductConditionInput{Condition: api.ConditionDamaged, Comment: &comment},
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectLock(api.InProgress)
+				mock.ExpectQuery(`SELECT COUNT\(\*\)>0 FROM attachments WHERE product_id = \$1`).
+					WithArgs(prodID).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				expectUpdate(api.ConditionDamaged)
+				mock.ExpectExec(`INSERT INTO damage_reports \(product_id,condition,comment,reported_by\) VALUES \(\$1,\$2,\$3,\$4\) ON CONFLICT \(product_id\) WHERE status = 'pending' DO UPDATE SET condition = EXCLUDED.condition`).
+					WithArgs(prodID, api.ConditionDamaged, &comment, &userID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expected: api.Product{Id: &prodID, DateTime: &now, ReceptionId: recID, Type: api.ProductTypeShoes, State: api.ProductStateReceived,
+				ScanSeq: ptrTo(int64(3)), Condition: ptrTo(api.ConditionDamaged)},
+		},
+		{
+			name:  "back to ok withdraws the pending report",
+			input: api.ProductConditionInput{Condition: api.ConditionOk},
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectLock(api.InProgress)
+				expectUpdate(api.ConditionOk)
+				mock.ExpectExec(`DELETE FROM damage_reports WHERE product_id = \$1 AND status = \$2`).
+					WithArgs(prodID, api.DamageReportPending).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expected: api.Product{Id: &prodID, DateTime: &now, ReceptionId: recID, Type: api.ProductTypeShoes, State: api.ProductStateReceived,
+				ScanSeq: ptrTo(int64(3)), Condition: ptrTo(api.ConditionOk)},
+		},
+		{
+			name:  "no attachment",
+			input: api.ProductConditionInput{Condition: api.ConditionWet, Comment: &comment},
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectLock(api.InProgress)
+				mock.ExpectQuery(`SELECT COUNT\(\*\)>0 FROM attachments`).
+					WithArgs(prodID).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrDamageEvidenceRequired,
+		},
+		{
+			name:  "reception closed",
+			input: api.ProductConditionInput{Condition: api.ConditionWet, Comment: &comment},
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectLock(api.Close)
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrReceptionClosed,
+		},
+		{
+			name:  "product not found",
+			input: api.ProductConditionInput{Condition: api.ConditionWet, Comment: &comment},
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT r.status FROM products p").
+					WithArgs(prodID).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrProductNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			result, err := repo.SetCondition(context.Background(), prodID, tt.input, userID)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDamageReportPostgres_List(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewDamageReportPostgres(db)
+	reportID := uuid.New()
+	prodID := uuid.New()
+	recID := uuid.New()
+	pvzID := uuid.New()
+	now := time.Now()
+	accepted := api.DamageReportAccepted
+
+	tests := []struct {
+		name      string
+		params    api.GetDamageReportsParams
+		mockSetup func()
+		expected  []api.DamageReport
+	}{
+		{
+			name:   "pending by default",
+			params: api.GetDamageReportsParams{},
+			mockSetup: func() {
+				rows := sqlmock.NewRows(damageReportRowColumns).
+					AddRow(reportID, prodID, recID, pvzID, api.ConditionWet, "wet box", api.DamageReportPending, nil, now, nil, nil, nil)
+				mock.ExpectQuery(`SELECT d.id, d.product_id, p.reception_id, r.pvz_id, .* FROM damage_reports d JOIN products p ON p.id = d.product_id JOIN receptions r ON r.id = p.reception_id WHERE d.status = \$1 AND p.deleted_at IS NULL ORDER BY d.reported_at, d.id LIMIT 30 OFFSET 0`).
+					WithArgs(api.DamageReportPending).
+					WillReturnRows(rows)
+			},
+			expected: []api.DamageReport{{Id: reportID, ProductId: prodID, ReceptionId: recID, PvzId: pvzID, Condition: api.ConditionWet,
+				Comment: "wet box", Status: api.DamageReportPending, ReportedAt: now}},
+		},
+		{
+			name:   "accepted in a pvz",
+			params: api.GetDamageReportsParams{Status: &accepted, PvzId: &pvzID, Page: ptrToInt(2), Limit: ptrToInt(10)},
+			mockSetup: func() {
+				mock.ExpectQuery(`FROM damage_reports d .* WHERE d.status = \$1 AND p.deleted_at IS NULL AND r.pvz_id = \$2 ORDER BY d.reported_at, d.id LIMIT 10 OFFSET 10`).
+					WithArgs(api.DamageReportAccepted, pvzID).
+					WillReturnRows(sqlmock.NewRows(damageReportRowColumns))
+			},
+			expected: []api.DamageReport{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			result, err := repo.List(context.Background(), tt.params)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDamageReportPostgres_Decide(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewDamageReportPostgres(db)
+	reportID := uuid.New()
+	prodID := uuid.New()
+	recID := uuid.New()
+	pvzID := uuid.New()
+	userID := uuid.New()
+	now := time.Now()
+	comment := "the box is fine"
+
+	expectLocks := func() {
+		mock.ExpectQuery(`SELECT product_id FROM damage_reports WHERE id = \$1`).
+			WithArgs(reportID).
+			WillReturnRows(sqlmock.NewRows([]string{"product_id"}).AddRow(prodID))
+		mock.ExpectQuery(`SELECT reception_id FROM products WHERE id = \$1 FOR UPDATE`).
+			WithArgs(prodID).
+			WillReturnRows(sqlmock.NewRows([]string{"reception_id"}).AddRow(recID))
+	}
+
+	tests := []struct {
+		name        string
+		decision    api.DamageReportDecision
+		mockSetup   func()
+		expected    api.DamageReport
+		expectedErr error
+	}{
+		{
+			name:     "rejected",
+			decision: api.DamageReportDecision{Decision: api.DecisionRejected, Comment: &comment},
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectLocks()
+				mock.ExpectExec(`UPDATE damage_reports SET status = \$1, reviewed_by = \$2, reviewed_at = now\(\), review_comment = \$3 WHERE id = \$4 AND status = \$5`).
+					WithArgs(api.DecisionRejected, &userID, &comment, reportID, api.DamageReportPending).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE products SET condition = \$1 WHERE id = \$2`).
+					WithArgs(api.ConditionOk, prodID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE receptions r SET summary = r.summary \|\| jsonb_build_object\('damagedByCondition', (.+)\) WHERE \(r.id = \$1 AND r.summary IS NOT NULL\)`).
+					WithArgs(recID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`SELECT d.id, .* FROM damage_reports d .* WHERE d.id = \$1`).
+					WithArgs(reportID).
+					WillReturnRows(sqlmock.NewRows(damageReportRowColumns).
+						AddRow(reportID, prodID, recID, pvzID, api.ConditionDamaged, "torn", api.DamageReportRejected, nil, now, userID, now, comment))
+				mock.ExpectCommit()
+			},
+			expected: api.DamageReport{Id: reportID, ProductId: prodID, ReceptionId: recID, PvzId: pvzID, Condition: api.ConditionDamaged,
+				Comment: "torn", Status: api.DamageReportRejected, ReportedAt: now, ReviewedBy: &userID, ReviewedAt: &now, ReviewComment: &comment},
+		},
+		{
+			name:     "accepted keeps the condition",
+			decision: api.DamageReportDecision{Decision: api.DecisionAccepted},
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectLocks()
+				mock.ExpectExec("UPDATE damage_reports SET status").
+					WithArgs(api.DecisionAccepted, &userID, nil, reportID, api.DamageReportPending).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("SELECT d.id, .* FROM damage_reports d").
+					WithArgs(reportID).
+					WillReturnRows(sqlmock.NewRows(damageReportRowColumns).
+						AddRow(reportID, prodID, recID, pvzID, api.ConditionDamaged, "torn", api.DamageReportAccepted, nil, now, userID, now, nil))
+				mock.ExpectCommit()
+			},
+			expected: api.DamageReport{Id: reportID, ProductId: prodID, ReceptionId: recID, PvzId: pvzID, Condition: api.ConditionDamaged,
+				Comment: "torn", Status: api.DamageReportAccepted, ReportedAt: now, ReviewedBy: &userID, ReviewedAt: &now},
+		},
+		{
+			name:     "already decided",
+			decision: api.DamageReportDecision{Decision: api.DecisionAccepted},
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectLocks()
+				mock.ExpectExec("UPDATE damage_reports SET status").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrDamageReportDecided,
+		},
+		{
+			name:     "not found",
+			decision: api.DamageReportDecision{Decision: api.DecisionAccepted},
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT product_id FROM damage_reports").
+					WithArgs(reportID).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrDamageReportNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			result, err := repo.Decide(context.Background(), reportID, tt.decision, userID)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/ST359/pvz-service/internal/api"
+	"github.com/google/uuid"
+)
+
+// ExportFilter selects receptions opened within the period at the PVZ or in the city, nil fields don't filter
+type ExportFilter struct {
+	StartDate *time.Time
+	EndDate   *time.Time
+	PvzID     *uuid.UUID
+	City      *string
+}
+
+// ExportReception is a reception with its PVZ and the number of its not deleted products
+type ExportReception struct {
+	Reception api.Reception
+	City      string
+	Address   *string
+	Products  int64
+}
+
+// ExportProduct is a not deleted product with its reception and PVZ
+type ExportProduct struct {
+	Product   api.Product
+	Reception api.Reception
+	City      string
+	Address   *string
+}
+
+type ExportPostgres struct {
+	db *sql.DB
+}
+
+func NewExportPostgres(db *sql.DB) *ExportPostgres {
+	return &ExportPostgres{db: db}
+}
+
+// Receptions passes receptions matching the filter to fn oldest first while reading them,
+// so an export never holds all of them in memory. An error of fn stops reading and is returned as is
+func (e *ExportPostgres) Receptions(ctx context.Context, filter ExportFilter, fn func(ExportReception) error) error {
+	const op = "repository.export.Receptions"
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	query := psql.Select("r.id", "r.date", "r.pvz_id", "r.status", "r.closed_at", "r.close_reason", "r.kind", "r.created_by",
+		"pv.city", "pv.address",
+		"(SELECT COUNT(*) FROM "+productsTable+" p WHERE p.reception_id = r.id AND p.deleted_at IS NULL)").
+		From(receptionsTable + " r").
+		Join(pvzTable + " pv ON pv.id = r.pvz_id")
+	rows, err := exportFiltered(query, filter).
+		OrderBy("r.date", "r.id").
+		RunWith(e.db).
+		QueryContext(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rec ExportReception
+		r := &rec.Reception
+		if err := rows.Scan(&r.Id, &r.DateTime, &r.PvzId, &r.Status, &r.ClosedAt, &r.CloseReason, &r.Kind, &r.CreatedBy,
+			&rec.City, &rec.Address, &rec.Products); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// Products passes not deleted products of receptions matching the filter to fn while reading them,
+// ordered by reception and scan order. An error of fn stops reading and is returned as is
+func (e *ExportPostgres) Products(ctx context.Context, filter ExportFilter, fn func(ExportProduct) error) error {
+	const op = "repository.export.Products"
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	query := psql.Select("p.id", "p.date", "p.type", "p.barcode", "p.external_order_id", "p.state", "p.condition",
+		"p.return_condition", "p.original_order_id", "p.reception_id",
+		"r.date", "r.kind", "r.status", "r.pvz_id", "pv.city", "pv.address").
+		From(productsTable + " p").
+		Join(receptionsTable + " r ON r.id = p.reception_id").
+		Join(pvzTable + " pv ON pv.id = r.pvz_id").
+		Where(squirrel.Eq{"p.deleted_at": nil})
+	rows, err := exportFiltered(query, filter).
+		OrderBy("r.date", "r.id", "p.scan_seq", "p.id").
+		RunWith(e.db).
+		QueryContext(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var prod ExportProduct
+		p, r := &prod.Product, &prod.Reception
+		if err := rows.Scan(&p.Id, &p.DateTime, &p.Type, &p.Barcode, &p.ExternalOrderId, &p.State, &p.Condition,
+			&p.ReturnCondition, &p.OriginalOrderId, &p.ReceptionId,
+			&r.DateTime, &r.Kind, &r.Status, &r.PvzId, &prod.City, &prod.Address); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		r.Id = &p.ReceptionId
+		if err := fn(prod); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// exportFiltered adds conditions of the filter on receptions r joined with PVZs pv
+func exportFiltered(query squirrel.SelectBuilder, filter ExportFilter) squirrel.SelectBuilder {
+	if filter.StartDate != nil {
+		query = query.Where(squirrel.GtOrEq{"r.date": *filter.StartDate})
+	}
+	if filter.EndDate != nil {
+		query = query.Where(squirrel.LtOrEq{"r.date": *filter.EndDate})
+	}
+	if filter.PvzID != nil {
+		query = query.Where(squirrel.Eq{"r.pvz_id": *filter.PvzID})
+	}
+	if filter.City != nil {
+		query = query.Where(squirrel.Eq{"pv.city": *filter.City})
+	}
+	return query
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ST359/pvz-service/internal/api"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportPostgres_Receptions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewExportPostgres(db)
+	recID := uuid.New()
+	pvzID := uuid.New()
+	now := time.Now()
+	start := now.Add(-24 * time.Hour)
+	city := "Москва"
+	address := "ул. Тверская, 1"
+	columns := []string{"id", "date", "pvz_id", "status", "closed_at", "close_reason", "kind", "created_by", "city", "address", "products"}
+	errStop := errors.New("stop")
+
+	tests := []struct {
+		name        string
+		filter      ExportFilter
+		mockSetup   func()
+		fnErr       error
+		expected    []ExportReception
+		expectedErr error
+	}{
+		{
+			name:   "filtered",
+			filter: ExportFilter{StartDate: &start, EndDate: &now, PvzID: &pvzID, City: &city},
+			mockSetup: func() {
+				mock.ExpectQuery(`SELECT r.id, r.date, .*, pv.city, pv.address, \(SELECT COUNT\(\*\) FROM products p WHERE p.reception_id = r.id AND p.deleted_at IS NULL\) `+
+					`FROM receptions r JOIN pvzs pv ON pv.id = r.pvz_id WHERE r.date >= \$1 AND r.date <= \$2 AND r.pvz_id = \$3 AND pv.city = \$4 ORDER BY r.date, r.id`).
+					WithArgs(start, now, pvzID, city).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(recID, now, pvzID, api.Close, now, nil, api.ReceptionKindInbound, nil, city, address, 4))
+			},
+			expected: []ExportReception{{
+				Reception: api.Reception{Id: &recID, DateTime: now, PvzId: pvzID, Status: api.Close, ClosedAt: &now, Kind: api.ReceptionKindInbound},
+				City:      city, Address: &address, Products: 4,
+			}},
+		},
+		{
+			name: "stopped by the callback",
+			mockSetup: func() {
+				mock.ExpectQuery("SELECT r.id").
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(recID, now, pvzID, api.InProgress, nil, nil, api.ReceptionKindInbound, nil, city, nil, 0).
+						AddRow(uuid.New(), now, pvzID, api.InProgress, nil, nil, api.ReceptionKindInbound, nil, city, nil, 0))
+			},
+			fnErr: errStop,
+			expected: []ExportReception{{
+				Reception: api.Reception{Id: &recID, DateTime: now, PvzId: pvzID, Status: api.InProgress, Kind: api.ReceptionKindInbound},
+				City:      city,
+			}},
+			expectedErr: errStop,
+		},
+		{
+			name: "database error",
+			mockSetup: func() {
+				mock.ExpectQuery("SELECT r.id").WillReturnError(sql.ErrConnDone)
+			},
+			expectedErr: errors.New("repository.export.Receptions: sql: connection is already closed"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			var result []ExportReception
+			err := repo.Receptions(context.Background(), tt.filter, func(rec ExportReception) error {
+				result = append(result, rec)
+				return tt.fnErr
+			})
+
+			if tt.expectedErr != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestExportPostgres_Products(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewExportPostgres(db)
+	prodID := uuid.New()
+	recID := uuid.New()
+	pvzID := uuid.New()
+	now := time.Now()
+	city := "Казань"
+	barcode := "4600000000001"
+	condition := api.ConditionOk
+	columns := []string{"id", "date", "type", "barcode", "external_order_id", "state", "condition", "return_condition",
+		"original_order_id", "reception_id", "date", "kind", "status", "pvz_id", "city", "address"}
+
+	mock.ExpectQuery(`SELECT p.id, p.date, .* FROM products p JOIN receptions r ON r.id = p.reception_id JOIN pvzs pv ON pv.id = r.pvz_id ` +
+		`WHERE p.deleted_at IS NULL AND pv.city = \$1 ORDER BY r.date, r.id, p.scan_seq, p.id`).
+		WithArgs(city).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(prodID, now, "shoes", barcode, nil, api.ProductStateStored, condition, nil, nil, recID, now, api.ReceptionKindInbound, api.Close, pvzID, city, nil))
+
+	var result []ExportProduct
+	err = repo.Products(context.Background(), ExportFilter{City: &city}, func(prod ExportProduct) error {
+		result = append(result, prod)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []ExportProduct{{
+		Product: api.Product{Id: &prodID, DateTime: &now, Type: "shoes", Barcode: &barcode, State: api.ProductStateStored,
+			Condition: &condition, ReceptionId: recID},
+		Reception: api.Reception{Id: &recID, DateTime: now, Kind: api.ReceptionKindInbound, Status: api.Close, PvzId: pvzID},
+		City:      city,
+	}}, result)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+)
+
+// IdempotentResponse is a response stored for replaying on retries of a request
+type IdempotentResponse struct {
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+type IdempotencyPostgres struct {
+	db *sql.DB
+}
+
+func NewIdempotencyPostgres(db *sql.DB) *IdempotencyPostgres {
+	return &IdempotencyPostgres{db: db}
+}
+
+// Begin claims the key for a request with the given fingerprint. It returns nil if the request has to be processed
+// and the stored response if it was already processed. An expired key is claimed again.
+// Can return ErrIdempotencyKeyReused and ErrIdempotencyKeyInProgress
+func (i *IdempotencyPostgres) Begin(ctx context.Context, scope, key, fingerprint string, ttl time.Duration) (*IdempotentResponse, error) {
+	const op = "repository.idempotency.Begin"
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	res, err := psql.Insert(idempotencyKeysTable).
+		Columns("scope", "key", "fingerprint", "expires_at").
+		Values(scope, key, fingerprint, squirrel.Expr("now() + make_interval(secs => ?)", ttl.Seconds())).
+		Suffix("ON CONFLICT (scope, key) DO UPDATE SET fingerprint = EXCLUDED.fingerprint, expires_at = EXCLUDED.expires_at, " +
+			"status_code = NULL, content_type = NULL, response = NULL, created_at = now() " +
+			"WHERE " + idempotencyKeysTable + ".expires_at <= now()").
+		RunWith(i.db).
+		ExecContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	claimed, err := res.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if claimed > 0 {
+		return nil, nil
+	}
+
+	var (
+		stored      string
+		statusCode  sql.NullInt64
+		contentType sql.NullString
+		body        []byte
+	)
+	err = psql.Select("fingerprint", "status_code", "content_type", "response").
+		From(idempotencyKeysTable).
+		Where(squirrel.Eq{"scope": scope, "key": key}).
+		RunWith(i.db).
+		QueryRowContext(ctx).Scan(&stored, &statusCode, &contentType, &body)
+	if err != nil {
+		// the key was released by a failed request in the meantime
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrIdempotencyKeyInProgress
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if stored != fingerprint {
+		return nil, errs.ErrIdempotencyKeyReused
+	}
+	if !statusCode.Valid {
+		return nil, errs.ErrIdempotencyKeyInProgress
+	}
+	return &IdempotentResponse{StatusCode: int(statusCode.Int64), ContentType: contentType.String, Body: body}, nil
+}
+
+// Complete stores the response to the request that claimed the key
+func (i *IdempotencyPostgres) Complete(ctx context.Context, scope, key string, resp IdempotentResponse) error {
+	const op = "repository.idempotency.Complete"
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	_, err := psql.Update(idempotencyKeysTable).
+		Set("status_code", resp.StatusCode).
+		Set("content_type", resp.ContentType).
+		Set("response", resp.Body).
+		Where(squirrel.Eq{"scope": scope, "key": key}).
+		RunWith(i.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// Release frees a key whose request failed without a response worth replaying, so the client can retry it
+func (i *IdempotencyPostgres) Release(ctx context.Context, scope, key string) error {
+	const op = "repository.idempotency.Release"
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	_, err := psql.Delete(idempotencyKeysTable).
+		Where(squirrel.Eq{"scope": scope, "key": key, "status_code": nil}).
+		RunWith(i.db).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// DeleteExpired removes expired keys and returns how many were removed
+func (i *IdempotencyPostgres) DeleteExpired(ctx context.Context) (int64, error) {
+	const op = "repository.idempotency.DeleteExpired"
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	res, err := psql.Delete(idempotencyKeysTable).
+		Where("expires_at <= now()").
+		RunWith(i.db).
+		ExecContext(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return n, nil
+}
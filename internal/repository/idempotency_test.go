@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyPostgres_Begin(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewIdempotencyPostgres(db)
+	scope, key, fingerprint := "user", "key-1", "abc"
+	ttl := 24 * time.Hour
+	selectQuery := `SELECT fingerprint, status_code, content_type, response FROM idempotency_keys WHERE key = \$1 AND scope = \$2`
+
+	tests := []struct {
+		name        string
+		mockSetup   func()
+		expected    *IdempotentResponse
+		expectedErr error
+	}{
+		{
+			name: "claimed",
+			mockSetup: func() {
+				mock.ExpectExec(`INSERT INTO idempotency_keys \(scope,key,fingerprint,expires_at\) VALUES \(\$1,\$2,\$3,now\(\) \+ make_interval\(secs => \$4\)\) ON CONFLICT`).
+					WithArgs(scope, key, fingerprint, ttl.Seconds()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "replayed",
+			mockSetup: func() {
+				mock.ExpectExec("INSERT INTO idempotency_keys").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(selectQuery).
+					WithArgs(key, scope).
+					WillReturnRows(sqlmock.NewRows([]string{"fingerprint", "status_code", "content_type", "response"}).
+						AddRow(fingerprint, 201, "application/json", []byte(`{"id":1}`)))
+			},
+			expected: &IdempotentResponse{StatusCode: 201, ContentType: "application/json", Body: []byte(`{"id":1}`)},
+		},
+		{
+			name: "reused for another request",
+			mockSetup: func() {
+				mock.ExpectExec("INSERT INTO idempotency_keys").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(selectQuery).
+					WithArgs(key, scope).
+					WillReturnRows(sqlmock.NewRows([]string{"fingerprint", "status_code", "content_type", "response"}).
+						AddRow("other", 201, "application/json", []byte(`{}`)))
+			},
+			expectedErr: errs.ErrIdempotencyKeyReused,
+		},
+		{
+			name: "still in progress",
+			mockSetup: func() {
+				mock.ExpectExec("INSERT INTO idempotency_keys").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(selectQuery).
+					WithArgs(key, scope).
+					WillReturnRows(sqlmock.NewRows([]string{"fingerprint", "status_code", "content_type", "response"}).
+						AddRow(fingerprint, nil, nil, nil))
+			},
+			expectedErr: errs.ErrIdempotencyKeyInProgress,
+		},
+		{
+			name: "released in the meantime",
+			mockSetup: func() {
+				mock.ExpectExec("INSERT INTO idempotency_keys").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(selectQuery).
+					WithArgs(key, scope).
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedErr: errs.ErrIdempotencyKeyInProgress,
+		},
+		{
+			name: "database error",
+			mockSetup: func() {
+				mock.ExpectExec("INSERT INTO idempotency_keys").
+					WillReturnError(errors.New("db error"))
+			},
+			expectedErr: errors.New("db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			result, err := repo.Begin(context.Background(), scope, key, fingerprint, ttl)
+
+			if tt.expectedErr != nil {
+				if errors.Is(tt.expectedErr, errs.ErrIdempotencyKeyReused) || errors.Is(tt.expectedErr, errs.ErrIdempotencyKeyInProgress) {
+					assert.ErrorIs(t, err, tt.expectedErr)
+				} else {
+					assert.ErrorContains(t, err, tt.expectedErr.Error())
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestIdempotencyPostgres_CompleteRelease(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewIdempotencyPostgres(db)
+	resp := IdempotentResponse{StatusCode: 201, ContentType: "application/json", Body: []byte(`{}`)}
+
+	mock.ExpectExec(`UPDATE idempotency_keys SET status_code = \$1, content_type = \$2, response = \$3 WHERE key = \$4 AND scope = \$5`).
+		WithArgs(201, "application/json", []byte(`{}`), "key-1", "user").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.Complete(context.Background(), "user", "key-1", resp))
+
+	mock.ExpectExec(`DELETE FROM idempotency_keys WHERE key = \$1 AND scope = \$2 AND status_code IS NULL`).
+		WithArgs("key-2", "user").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.Release(context.Background(), "user", "key-2"))
+
+	mock.ExpectExec(`DELETE FROM idempotency_keys WHERE expires_at <= now\(\)`).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	n, err := repo.DeleteExpired(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), n)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/google/uuid"
+)
+
+type ManifestPostgres struct {
+	db *sql.DB
+}
+
+func NewManifestPostgres(db *sql.DB) *ManifestPostgres {
+	return &ManifestPostgres{db: db}
+}
+
+// Replace stores items as the manifest of the reception dropping the previous one,
+// can return ErrReceptionNotFound and ErrReceptionClosed
+func (m *ManifestPostgres) Replace(ctx context.Context, recID uuid.UUID, items []api.ManifestItem) error {
+	const op = "repository.manifest.Replace"
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	// the lock keeps the reception from being closed until the manifest is stored
+	var status api.ReceptionStatus
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	err = psql.Select("status").
+		From(receptionsTable).
+		Where(squirrel.Eq{"id": recID}).
+		Suffix("FOR UPDATE").
+		RunWith(tx).
+		QueryRowContext(ctx).Scan(&status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errs.ErrReceptionNotFound
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if status != api.InProgress {
+		return errs.ErrReceptionClosed
+	}
+
+	_, err = psql.Delete(manifestItemsTable).
+		Where(squirrel.Eq{"reception_id": recID}).
+		RunWith(tx).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	query := psql.Insert(manifestItemsTable).Columns("reception_id", "barcode", "type")
+	for _, item := range items {
+		query = query.Values(recID, item.Barcode, item.Type)
+	}
+	if _, err := query.RunWith(tx).ExecContext(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// Progress compares not deleted products of the reception with its manifest,
+// can return ErrReceptionNotFound and ErrManifestNotFound
+func (m *ManifestPostgres) Progress(ctx context.Context, recID uuid.UUID) (api.ManifestProgress, error) {
+	const op = "repository.manifest.Progress"
+
+	progress := api.ManifestProgress{ReceptionId: recID}
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	err := psql.Select().
+		Column("(SELECT COUNT(*) FROM "+manifestItemsTable+" m WHERE m.reception_id = r.id)").
+		Column("(SELECT COUNT(*) FROM "+manifestItemsTable+" m JOIN "+productsTable+" p "+manifestProductJoin+" WHERE m.reception_id = r.id)").
+		Column("(SELECT COUNT(*) FROM "+manifestItemsTable+" m JOIN "+productsTable+" p "+manifestProductJoin+" WHERE m.reception_id = r.id AND p.type <> m.type)").
+		Column("(SELECT COUNT(*) FROM "+productsTable+" p WHERE p.reception_id = r.id AND p.deleted_at IS NULL AND "+notInManifest+")").
+		From(receptionsTable+" r").
+		Where(squirrel.Eq{"r.id": recID}).
+		RunWith(m.db).
+		QueryRowContext(ctx).Scan(&progress.Expected, &progress.Scanned, &progress.WrongType, &progress.Extra)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return api.ManifestProgress{}, errs.ErrReceptionNotFound
+		}
+		return api.ManifestProgress{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if progress.Expected == 0 {
+		return api.ManifestProgress{}, errs.ErrManifestNotFound
+	}
+	progress.Missing = progress.Expected - progress.Scanned
+	return progress, nil
+}
+
+// Discrepancies returns the report stored when the reception was closed,
+// can return ErrReceptionNotFound, ErrManifestNotFound and ErrDiscrepancyReportNotReady
+func (m *ManifestPostgres) Discrepancies(ctx context.Context, recID uuid.UUID) ([]api.ReceptionDiscrepancy, error) {
+	const op = "repository.manifest.Discrepancies"
+
+	var (
+		status      api.ReceptionStatus
+		hasManifest bool
+	)
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	err := psql.Select("r.status").
+		Column("EXISTS (SELECT 1 FROM "+manifestItemsTable+" m WHERE m.reception_id = r.id)").
+		From(receptionsTable+" r").
+		Where(squirrel.Eq{"r.id": recID}).
+		RunWith(m.db).
+		QueryRowContext(ctx).Scan(&status, &hasManifest)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrReceptionNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !hasManifest {
+		return nil, errs.ErrManifestNotFound
+	}
+	if status != api.Close {
+		return nil, errs.ErrDiscrepancyReportNotReady
+	}
+
+	rows, err := psql.Select("kind", "barcode", "expected_type", "actual_type", "product_id").
+		From(discrepanciesTable).
+		Where(squirrel.Eq{"reception_id": recID}).
+		OrderBy("kind", "barcode").
+		RunWith(m.db).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	res := []api.ReceptionDiscrepancy{}
+	for rows.Next() {
+		var d api.ReceptionDiscrepancy
+		if err := rows.Scan(&d.Kind, &d.Barcode, &d.ExpectedType, &d.ActualType, &d.ProductId); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		res = append(res, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return res, nil
+}
+
+// manifestProductJoin matches manifest items aliased as m with not deleted products aliased as p by barcode
+const manifestProductJoin = "ON p.reception_id = m.reception_id AND p.barcode = m.barcode AND p.deleted_at IS NULL"
+
+// notInManifest holds for products aliased as p whose barcode is not in the manifest of their reception,
+// products without a barcode are never in a manifest
+const notInManifest = "NOT EXISTS (SELECT 1 FROM " + manifestItemsTable + " m WHERE m.reception_id = p.reception_id AND m.barcode = p.barcode)"
+
+// createDiscrepancyReports stores missing, wrong type and extra items of the receptions compared to their manifests,
+// nothing is stored for receptions without a manifest
+func createDiscrepancyReports(ctx context.Context, tx *sql.Tx, recIDs []uuid.UUID) error {
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+
+	missing := squirrel.Select("m.reception_id").
+		Column("?", api.DiscrepancyMissing).
+		Columns("m.barcode", "m.type").
+		From(manifestItemsTable + " m").
+		Where(squirrel.Eq{"m.reception_id": recIDs}).
+		Where("NOT EXISTS (SELECT 1 FROM " + productsTable + " p " + manifestProductJoin + ")")
+	_, err := psql.Insert(discrepanciesTable).
+		Columns("reception_id", "kind", "barcode", "expected_type").
+		Select(missing).
+		RunWith(tx).
+		ExecContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	wrongType := squirrel.Select("m.reception_id").
+		Column("?", api.DiscrepancyWrongType).
+		Columns("m.barcode", "m.type", "p.type", "p.id").
+		From(manifestItemsTable + " m").
+		Join(productsTable + " p " + manifestProductJoin).
+		Where(squirrel.Eq{"m.reception_id": recIDs}).
+		Where("p.type <> m.type")
+	_, err = psql.Insert(discrepanciesTable).
+		Columns("reception_id", "kind", "barcode", "expected_type", "actual_type", "product_id").
+		Select(wrongType).
+		RunWith(tx).
+		ExecContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	extra := squirrel.Select("p.reception_id").
+		Column("?", api.DiscrepancyExtra).
+		Columns("p.barcode", "p.type", "p.id").
+		From(productsTable + " p").
+		Where(squirrel.Eq{"p.reception_id": recIDs, "p.deleted_at": nil}).
+		Where("EXISTS (SELECT 1 FROM " + manifestItemsTable + " m WHERE m.reception_id = p.reception_id)").
+		Where(notInManifest)
+	_, err = psql.Insert(discrepanciesTable).
+		Columns("reception_id", "kind", "barcode", "actual_type", "product_id").
+		Select(extra).
+		RunWith(tx).
+		ExecContext(ctx)
+	return err
+}
@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifestPostgres_Replace(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewManifestPostgres(db)
+	recID := uuid.New()
+	items := []api.ManifestItem{
+		{Barcode: "4607001234567", Type: api.ProductTypeShoes},
+		{Barcode: "4607001234568", Type: api.ProductTypeClothes},
+	}
+
+	tests := []struct {
+		name        string
+		mockSetup   func()
+		expectedErr error
+	}{
+		{
+			name: "replaced",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT status FROM receptions WHERE id = \\$1 FOR UPDATE").
+					WithArgs(recID).
+					WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(api.InProgress))
+				mock.ExpectExec("DELETE FROM reception_manifest_items WHERE reception_id = \\$1").
+					WithArgs(recID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO reception_manifest_items \\(reception_id,barcode,type\\) VALUES \\(\\$1,\\$2,\\$3\\),\\(\\$4,\\$5,\\$6\\)").
+					WithArgs(recID, "4607001234567", api.ProductTypeShoes, recID, "4607001234568", api.ProductTypeClothes).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "reception closed",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT status FROM receptions").
+					WithArgs(recID).
+					WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(api.Close))
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrReceptionClosed,
+		},
+		{
+			name: "reception not found",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT status FROM receptions").
+					WithArgs(recID).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrReceptionNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			err := repo.Replace(context.Background(), recID, items)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestManifestPostgres_Progress(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewManifestPostgres(db)
+	recID := uuid.New()
+	columns := []string{"expected", "scanned", "wrong_type", "extra"}
+
+	tests := []struct {
+		name        string
+		mockSetup   func()
+		expected    api.ManifestProgress
+		expectedErr error
+	}{
+		{
+			name: "in progress",
+			mockSetup: func() {
+				mock.ExpectQuery("SELECT (.+) FROM receptions r WHERE r.id = \\$1").
+					WithArgs(recID).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(10, 7, 1, 2))
+			},
+			expected: api.ManifestProgress{ReceptionId: recID, Expected: 10, Scanned: 7, Missing: 3, WrongType: 1, Extra: 2},
+		},
+		{
+			name: "no manifest",
+			mockSetup: func() {
+				mock.ExpectQuery("SELECT (.+) FROM receptions r").
+					WithArgs(recID).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(0, 0, 0, 4))
+			},
+			expectedErr: errs.ErrManifestNotFound,
+		},
+		{
+			name: "reception not found",
+			mockSetup: func() {
+				mock.ExpectQuery("SELECT (.+) FROM receptions r").
+					WithArgs(recID).
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedErr: errs.ErrReceptionNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			result, err := repo.Progress(context.Background(), recID)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestManifestPostgres_Discrepancies(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewManifestPostgres(db)
+	recID := uuid.New()
+	prodID := uuid.New()
+	barcode := "4607001234567"
+	expectedType, actualType := api.ProductTypeShoes, api.ProductTypeClothes
+
+	tests := []struct {
+		name        string
+		mockSetup   func()
+		expected    []api.ReceptionDiscrepancy
+		expectedErr error
+	}{
+		{
+			name: "report of closed reception",
+			mockSetup: func() {
+				mock.ExpectQuery("SELECT r.status, EXISTS (.+) FROM receptions r WHERE r.id = \\$1").
+					WithArgs(recID).
+					WillReturnRows(sqlmock.NewRows([]string{"status", "exists"}).AddRow(api.Close, true))
+				mock.ExpectQuery("SELECT kind, barcode, expected_type, actual_type, product_id FROM reception_discrepancies WHERE reception_id = \\$1 ORDER BY kind, barcode").
+					WithArgs(recID).
+					WillReturnRows(sqlmock.NewRows([]string{"kind", "barcode", "expected_type", "actual_type", "product_id"}).
+						AddRow(api.DiscrepancyWrongType, barcode, expectedType, actualType, prodID))
+			},
+			expected: []api.ReceptionDiscrepancy{{
+				Kind:         api.DiscrepancyWrongType,
+				Barcode:      &barcode,
+				ExpectedType: &expectedType,
+				ActualType:   &actualType,
+				ProductId:    &prodID,
+			}},
+		},
+		{
+			name: "reception in progress",
+			mockSetup: func() {
+				mock.ExpectQuery("SELECT r.status").
+					WithArgs(recID).
+					WillReturnRows(sqlmock.NewRows([]string{"status", "exists"}).AddRow(api.InProgress, true))
+			},
+			expectedErr: errs.ErrDiscrepancyReportNotReady,
+		},
+		{
+			name: "no manifest",
+			mockSetup: func() {
+				mock.ExpectQuery("SELECT r.status").
+					WithArgs(recID).
+					WillReturnRows(sqlmock.NewRows([]string{"status", "exists"}).AddRow(api.Close, false))
+			},
+			expectedErr: errs.ErrManifestNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			result, err := repo.Discrepancies(context.Background(), recID)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
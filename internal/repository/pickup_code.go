@@ -0,0 +1,397 @@
+package repository
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/google/uuid"
+)
+
+// NewPickupCode is a pickup code to be stored, the code itself is kept only as a hash
+type NewPickupCode struct {
+	api.PickupCode
+	Hash string
+}
+
+// PickupCodeMinter generates codes for targets and returns them in the same order,
+// the plain codes stay with the minter so they can be sent once the transaction commits
+type PickupCodeMinter func(targets []api.PickupCode) ([]NewPickupCode, error)
+
+type PickupCodePostgres struct {
+	db *sql.DB
+}
+
+func NewPickupCodePostgres(db *sql.DB) *PickupCodePostgres {
+	return &PickupCodePostgres{db: db}
+}
+
+// issueReceptionCodes stores codes minted for stored products of inbound receptions that have none yet,
+// customer returns go back to the sender and are never picked up. Nil mint issues nothing
+func issueReceptionCodes(ctx context.Context, tx *sql.Tx, recs []api.Reception, mint PickupCodeMinter) ([]api.PickupCode, error) {
+	if mint == nil {
+		return []api.PickupCode{}, nil
+	}
+	var targets []api.PickupCode
+	for _, rec := range recs {
+		if rec.Kind == api.ReceptionKindCustomerReturn {
+			continue
+		}
+		recTargets, err := receptionTargets(ctx, tx, *rec.Id)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, recTargets...)
+	}
+	if len(targets) == 0 {
+		return []api.PickupCode{}, nil
+	}
+	codes, err := mint(targets)
+	if err != nil {
+		return nil, err
+	}
+	return createPickupCodes(ctx, tx, codes)
+}
+
+// receptionTargets groups stored products of the reception that have no pickup code yet:
+// products of the same external order share a code, any other product gets its own
+func receptionTargets(ctx context.Context, tx *sql.Tx, recID uuid.UUID) ([]api.PickupCode, error) {
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	rows, err := psql.Select("r.pvz_id", "p.id", "p.external_order_id").
+		From(productsTable + " p").
+		Join(receptionsTable + " r ON r.id = p.current_reception_id").
+		Where(squirrel.Eq{
+			"p.current_reception_id": recID,
+			"p.state":                api.ProductStateStored,
+			"p.deleted_at":           nil,
+			"p.pickup_code_id":       nil,
+		}).
+		OrderBy("p.date").
+		RunWith(tx).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []api.PickupCode{}
+	orders := make(map[string]int)
+	for rows.Next() {
+		var (
+			pvzID, prodID uuid.UUID
+			order         *string
+		)
+		if err := rows.Scan(&pvzID, &prodID, &order); err != nil {
+			return nil, err
+		}
+		if order != nil {
+			if i, ok := orders[*order]; ok {
+				res[i].ProductIds = append(res[i].ProductIds, prodID)
+				continue
+			}
+			orders[*order] = len(res)
+		}
+		res = append(res, api.PickupCode{
+			PvzId:           pvzID,
+			ReceptionId:     recID,
+			ExternalOrderId: order,
+			ProductIds:      []uuid.UUID{prodID},
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// RegenerationTarget returns stored products a new code for ref has to cover: all stored products
+// of the order, or the products sharing an active code with the product.
+// Can return ErrProductNotFound, ErrProductInOpenReception and ErrInvalidProductState
+func (p *PickupCodePostgres) RegenerationTarget(ctx context.Context, pvzID uuid.UUID, ref api.PickupCodeRef) (api.PickupCode, error) {
+	const op = "repository.pickup_code.RegenerationTarget"
+
+	target := api.PickupCode{PvzId: pvzID, ExternalOrderId: ref.ExternalOrderId}
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	query := psql.Select("p.id", "p.current_reception_id", "p.state").
+		From(productsTable + " p").
+		Join(receptionsTable + " r ON r.id = p.current_reception_id").
+		Where(squirrel.Eq{"r.pvz_id": pvzID, "p.deleted_at": nil}).
+		OrderBy("p.date")
+
+	if ref.ProductId != nil {
+		var (
+			state  api.ProductState
+			codeID *uuid.UUID
+		)
+		err := psql.Select("p.current_reception_id", "p.state", "p.external_order_id", "p.pickup_code_id").
+			From(productsTable+" p").
+			Join(receptionsTable+" r ON r.id = p.current_reception_id").
+			Where(squirrel.Eq{"p.id": *ref.ProductId, "r.pvz_id": pvzID, "p.deleted_at": nil}).
+			RunWith(p.db).
+			QueryRowContext(ctx).Scan(&target.ReceptionId, &state, &target.ExternalOrderId, &codeID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return api.PickupCode{}, errs.ErrProductNotFound
+			}
+			return api.PickupCode{}, fmt.Errorf("%s: %w", op, err)
+		}
+		if err := checkPickupState(*ref.ProductId, state); err != nil {
+			return api.PickupCode{}, err
+		}
+		if codeID == nil {
+			target.ProductIds = []uuid.UUID{*ref.ProductId}
+			return target, nil
+		}
+		query = query.Where(squirrel.Eq{"p.pickup_code_id": *codeID})
+	} else {
+		query = query.Where(squirrel.Eq{"p.external_order_id": *ref.ExternalOrderId}).
+			Where(squirrel.Eq{"p.state": []api.ProductState{api.ProductStateReceived, api.ProductStateStored}})
+	}
+
+	rows, err := query.RunWith(p.db).QueryContext(ctx)
+	if err != nil {
+		return api.PickupCode{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id    uuid.UUID
+			state api.ProductState
+		)
+		if err := rows.Scan(&id, &target.ReceptionId, &state); err != nil {
+			return api.PickupCode{}, fmt.Errorf("%s: %w", op, err)
+		}
+		if state == api.ProductStateReceived {
+			return api.PickupCode{}, fmt.Errorf("%w: %s", errs.ErrProductInOpenReception, id)
+		}
+		if state == api.ProductStateStored {
+			target.ProductIds = append(target.ProductIds, id)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return api.PickupCode{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(target.ProductIds) == 0 {
+		return api.PickupCode{}, errs.ErrProductNotFound
+	}
+	return target, nil
+}
+
+func checkPickupState(id uuid.UUID, state api.ProductState) error {
+	switch state {
+	case api.ProductStateStored:
+		return nil
+	case api.ProductStateReceived:
+		return fmt.Errorf("%w: %s", errs.ErrProductInOpenReception, id)
+	default:
+		return fmt.Errorf("%w: %s is %s", errs.ErrInvalidProductState, id, state)
+	}
+}
+
+// Create stores the codes in one transaction. Active codes of the covered products are revoked,
+// so every product has at most one code a customer can use
+func (p *PickupCodePostgres) Create(ctx context.Context, codes []NewPickupCode) ([]api.PickupCode, error) {
+	const op = "repository.pickup_code.Create"
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	res, err := createPickupCodes(ctx, tx, codes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return res, nil
+}
+
+// createPickupCodes stores the codes and revokes active codes of the covered products,
+// so every product has at most one code a customer can use
+func createPickupCodes(ctx context.Context, tx *sql.Tx, codes []NewPickupCode) ([]api.PickupCode, error) {
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	res := make([]api.PickupCode, 0, len(codes))
+	for _, code := range codes {
+		covered, args, err := squirrel.Select("pickup_code_id").
+			From(productsTable).
+			Where(squirrel.Eq{"id": code.ProductIds}).
+			ToSql()
+		if err != nil {
+			return nil, err
+		}
+		_, err = psql.Update(pickupCodesTable).
+			Set("revoked_at", squirrel.Expr("now()")).
+			Where("id IN ("+covered+")", args...).
+			Where(squirrel.Eq{"used_at": nil, "revoked_at": nil}).
+			RunWith(tx).
+			ExecContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		created := code.PickupCode
+		err = psql.Insert(pickupCodesTable).
+			Columns("pvz_id", "reception_id", "external_order_id", "code_hash").
+			Values(code.PvzId, code.ReceptionId, code.ExternalOrderId, code.Hash).
+			Suffix("RETURNING id, created_at").
+			RunWith(tx).
+			QueryRowContext(ctx).Scan(&created.Id, &created.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = psql.Update(productsTable).
+			Set("pickup_code_id", created.Id).
+			Where(squirrel.Eq{"id": code.ProductIds}).
+			RunWith(tx).
+			ExecContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, created)
+	}
+	return res, nil
+}
+
+// Verify finds an active code of the product or order with the given hash and issues its stored products.
+// A wrong code counts as a failed attempt for every active code of ref, a code with maxAttempts failures
+// can't be used anymore. Can return ErrPickupCodeNotFound, ErrPickupCodeAttemptsExceeded, ErrInvalidPickupCode,
+// ErrProductInOpenReception and ErrInvalidProductState
+func (p *PickupCodePostgres) Verify(ctx context.Context, pvzID uuid.UUID, ref api.PickupCodeRef, hash string, maxAttempts int) ([]api.Product, error) {
+	const op = "repository.pickup_code.Verify"
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	query := psql.Select("c.id", "c.code_hash", "c.failed_attempts").
+		From(pickupCodesTable + " c").
+		Where(squirrel.Eq{"c.pvz_id": pvzID, "c.used_at": nil, "c.revoked_at": nil}).
+		Suffix("FOR UPDATE OF c")
+	if ref.ProductId != nil {
+		query = query.Join(productsTable + " p ON p.pickup_code_id = c.id").
+			Where(squirrel.Eq{"p.id": *ref.ProductId})
+	} else {
+		query = query.Where(squirrel.Eq{"c.external_order_id": *ref.ExternalOrderId})
+	}
+	rows, err := query.RunWith(tx).QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	var (
+		found     bool
+		available []uuid.UUID
+		matched   uuid.UUID
+	)
+	for rows.Next() {
+		var (
+			id       uuid.UUID
+			codeHash string
+			attempts int
+		)
+		if err := rows.Scan(&id, &codeHash, &attempts); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		found = true
+		if attempts >= maxAttempts {
+			continue
+		}
+		available = append(available, id)
+		if subtle.ConstantTimeCompare([]byte(codeHash), []byte(hash)) == 1 {
+			matched = id
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !found {
+		return nil, errs.ErrPickupCodeNotFound
+	}
+	if len(available) == 0 {
+		return nil, errs.ErrPickupCodeAttemptsExceeded
+	}
+
+	if matched == uuid.Nil {
+		_, err = psql.Update(pickupCodesTable).
+			Set("failed_attempts", squirrel.Expr("failed_attempts + 1")).
+			Where(squirrel.Eq{"id": available}).
+			RunWith(tx).
+			ExecContext(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		return nil, errs.ErrInvalidPickupCode
+	}
+
+	var inOpenReception bool
+	err = psql.Select("COUNT(*)>0").
+		From(productsTable).
+		Where(squirrel.Eq{"pickup_code_id": matched, "state": api.ProductStateReceived, "deleted_at": nil}).
+		RunWith(tx).
+		QueryRowContext(ctx).Scan(&inOpenReception)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if inOpenReception {
+		return nil, errs.ErrProductInOpenReception
+	}
+
+	rows, err = psql.Update(productsTable).
+		Set("state", api.ProductStateIssued).
+		Set("state_changed_at", squirrel.Expr("now()")).
+		Where(squirrel.Eq{"pickup_code_id": matched, "state": api.ProductStateStored, "deleted_at": nil}).
+		Suffix("RETURNING " + productColumns).
+		RunWith(tx).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	res := []api.Product{}
+	for rows.Next() {
+		var prod api.Product
+		if err := rows.Scan(productFields(&prod)...); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		res = append(res, prod)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(res) == 0 {
+		return nil, errs.ErrInvalidProductState
+	}
+
+	_, err = psql.Update(pickupCodesTable).
+		Set("used_at", squirrel.Expr("now()")).
+		Where(squirrel.Eq{"id": matched}).
+		RunWith(tx).
+		ExecContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return res, nil
+}
@@ -0,0 +1,364 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssueReceptionCodes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	recID, returnID, pvzID := uuid.New(), uuid.New(), uuid.New()
+	first, second, third := uuid.New(), uuid.New(), uuid.New()
+	firstCode, secondCode := uuid.New(), uuid.New()
+	now := time.Now()
+	order := "ORD-1"
+	recs := []api.Reception{
+		{Id: &recID, PvzId: pvzID, Kind: api.ReceptionKindInbound},
+		{Id: &returnID, PvzId: pvzID, Kind: api.ReceptionKindCustomerReturn},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT r.pvz_id, p.id, p.external_order_id FROM products p JOIN receptions r ON r.id = p.current_reception_id WHERE p.current_reception_id = \\$1 AND p.deleted_at IS NULL AND p.pickup_code_id IS NULL AND p.state = \\$2 ORDER BY p.date").
+		WithArgs(recID, api.ProductStateStored).
+		WillReturnRows(sqlmock.NewRows([]string{"pvz_id", "id", "external_order_id"}).
+			AddRow(pvzID, first, order).
+			AddRow(pvzID, second, nil).
+			AddRow(pvzID, third, order))
+	for _, code := range []struct {
+		id       uuid.UUID
+		order    driver.Value
+		hash     string
+		products []driver.Value
+	}{
+		{id: firstCode, order: &order, hash: "hash-0", products: []driver.Value{first, third}},
+		{id: secondCode, order: nil, hash: "hash-1", products: []driver.Value{second}},
+	} {
+		mock.ExpectExec("UPDATE pickup_codes SET revoked_at = now\\(\\)").
+			WithArgs(code.products...).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("INSERT INTO pickup_codes").
+			WithArgs(pvzID, recID, code.order, code.hash).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(code.id, now))
+		mock.ExpectExec("UPDATE products SET pickup_code_id").
+			WithArgs(append([]driver.Value{code.id}, code.products...)...).
+			WillReturnResult(sqlmock.NewResult(0, int64(len(code.products))))
+	}
+
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	var minted []api.PickupCode
+	created, err := issueReceptionCodes(context.Background(), tx, recs, func(targets []api.PickupCode) ([]NewPickupCode, error) {
+		minted = targets
+		codes := make([]NewPickupCode, len(targets))
+		for i, target := range targets {
+			codes[i] = NewPickupCode{PickupCode: target, Hash: fmt.Sprintf("hash-%d", i)}
+		}
+		return codes, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []api.PickupCode{
+		{PvzId: pvzID, ReceptionId: recID, ExternalOrderId: &order, ProductIds: []uuid.UUID{first, third}},
+		{PvzId: pvzID, ReceptionId: recID, ProductIds: []uuid.UUID{second}},
+	}, minted)
+	require.Len(t, created, 2)
+	assert.Equal(t, firstCode, created[0].Id)
+	assert.Equal(t, secondCode, created[1].Id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIssueReceptionCodes_NoMinter(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	recID := uuid.New()
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	require.NoError(t, err)
+
+	created, err := issueReceptionCodes(context.Background(), tx, []api.Reception{{Id: &recID}}, nil)
+	require.NoError(t, err)
+	assert.Empty(t, created)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPickupCodePostgres_RegenerationTarget(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPickupCodePostgres(db)
+	pvzID, recID, prodID, codeID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	otherID := uuid.New()
+	order := "ORD-1"
+	productColumns := []string{"reception_id", "state", "external_order_id", "pickup_code_id"}
+
+	tests := []struct {
+		name        string
+		ref         api.PickupCodeRef
+		mockSetup   func()
+		expected    api.PickupCode
+		expectedErr error
+	}{
+		{
+			name: "product without a code",
+			ref:  api.PickupCodeRef{ProductId: &prodID},
+			mockSetup: func() {
+				mock.ExpectQuery("SELECT p.current_reception_id, p.state, p.external_order_id, p.pickup_code_id FROM products p").
+					WithArgs(prodID, pvzID).
+					WillReturnRows(sqlmock.NewRows(productColumns).AddRow(recID, api.ProductStateStored, nil, nil))
+			},
+			expected: api.PickupCode{PvzId: pvzID, ReceptionId: recID, ProductIds: []uuid.UUID{prodID}},
+		},
+		{
+			name: "product sharing a code",
+			ref:  api.PickupCodeRef{ProductId: &prodID},
+			mockSetup: func() {
+				mock.ExpectQuery("SELECT p.current_reception_id, p.state, p.external_order_id, p.pickup_code_id FROM products p").
+					WithArgs(prodID, pvzID).
+					WillReturnRows(sqlmock.NewRows(productColumns).AddRow(recID, api.ProductStateStored, order, codeID))
+				mock.ExpectQuery("SELECT p.id, p.current_reception_id, p.state FROM products p JOIN receptions r ON r.id = p.current_reception_id WHERE p.deleted_at IS NULL AND r.pvz_id = \\$1 AND p.pickup_code_id = \\$2 ORDER BY p.date").
+					WithArgs(pvzID, codeID).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "reception_id", "state"}).
+						AddRow(prodID, recID, api.ProductStateStored).
+						AddRow(otherID, recID, api.ProductStateIssued))
+			},
+			expected: api.PickupCode{PvzId: pvzID, ReceptionId: recID, ExternalOrderId: &order, ProductIds: []uuid.UUID{prodID}},
+		},
+		{
+			name: "product in open reception",
+			ref:  api.PickupCodeRef{ProductId: &prodID},
+			mockSetup: func() {
+				mock.ExpectQuery("SELECT p.current_reception_id, p.state, p.external_order_id, p.pickup_code_id FROM products p").
+					WithArgs(prodID, pvzID).
+					WillReturnRows(sqlmock.NewRows(productColumns).AddRow(recID, api.ProductStateReceived, nil, nil))
+			},
+			expectedErr: errs.ErrProductInOpenReception,
+		},
+		{
+			name: "product not found",
+			ref:  api.PickupCodeRef{ProductId: &prodID},
+			mockSetup: func() {
+				mock.ExpectQuery("SELECT p.current_reception_id, p.state, p.external_order_id, p.pickup_code_id FROM products p").
+					WithArgs(prodID, pvzID).
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedErr: errs.ErrProductNotFound,
+		},
+		{
+			name: "whole order",
+			ref:  api.PickupCodeRef{ExternalOrderId: &order},
+			mockSetup: func() {
+				mock.ExpectQuery("SELECT p.id, p.current_reception_id, p.state FROM products p JOIN receptions r ON r.id = p.current_reception_id WHERE p.deleted_at IS NULL AND r.pvz_id = \\$1 AND p.external_order_id = \\$2 AND p.state IN \\(\\$3,\\$4\\) ORDER BY p.date").
+					WithArgs(pvzID, order, api.ProductStateReceived, api.ProductStateStored).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "reception_id", "state"}).
+						AddRow(prodID, recID, api.ProductStateStored).
+						AddRow(otherID, recID, api.ProductStateStored))
+			},
+			expected: api.PickupCode{PvzId: pvzID, ReceptionId: recID, ExternalOrderId: &order, ProductIds: []uuid.UUID{prodID, otherID}},
+		},
+		{
+			name: "nothing stored for the order",
+			ref:  api.PickupCodeRef{ExternalOrderId: &order},
+			mockSetup: func() {
+				mock.ExpectQuery("SELECT p.id, p.current_reception_id, p.state FROM products p").
+					WithArgs(pvzID, order, api.ProductStateReceived, api.ProductStateStored).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "reception_id", "state"}))
+			},
+			expectedErr: errs.ErrProductNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			result, err := repo.RegenerationTarget(context.Background(), pvzID, tt.ref)
+
+			if tt.expectedErr != nil {
+				assert.True(t, errors.Is(err, tt.expectedErr), err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPickupCodePostgres_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPickupCodePostgres(db)
+	pvzID, recID, prodID, codeID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	now := time.Now()
+	code := NewPickupCode{
+		PickupCode: api.PickupCode{PvzId: pvzID, ReceptionId: recID, ProductIds: []uuid.UUID{prodID}},
+		Hash:       "hash",
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE pickup_codes SET revoked_at = now\\(\\) WHERE id IN \\(SELECT pickup_code_id FROM products WHERE id IN \\(\\$1\\)\\) AND revoked_at IS NULL AND used_at IS NULL").
+		WithArgs(prodID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO pickup_codes \\(pvz_id,reception_id,external_order_id,code_hash\\) VALUES \\(\\$1,\\$2,\\$3,\\$4\\) RETURNING id, created_at").
+		WithArgs(pvzID, recID, nil, "hash").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(codeID, now))
+	mock.ExpectExec("UPDATE products SET pickup_code_id = \\$1 WHERE id IN \\(\\$2\\)").
+		WithArgs(codeID, prodID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	created, err := repo.Create(context.Background(), []NewPickupCode{code})
+	require.NoError(t, err)
+	require.Len(t, created, 1)
+	assert.Equal(t, codeID, created[0].Id)
+	assert.Equal(t, now, created[0].CreatedAt)
+	assert.Equal(t, []uuid.UUID{prodID}, created[0].ProductIds)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPickupCodePostgres_Verify(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPickupCodePostgres(db)
+	pvzID, recID, prodID := uuid.New(), uuid.New(), uuid.New()
+	codeID, otherCodeID := uuid.New(), uuid.New()
+	order := "ORD-1"
+	ref := api.PickupCodeRef{ExternalOrderId: &order}
+	now := time.Now()
+	codeColumns := []string{"id", "code_hash", "failed_attempts"}
+	productColumnNames := []string{"id", "date", "reception_id", "type", "barcode", "external_order_id", "state", "state_changed_at", "return_condition", "original_order_id", "cell_id", "scan_seq", "condition"}
+
+	expectCodes := func(rows *sqlmock.Rows) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT c.id, c.code_hash, c.failed_attempts FROM pickup_codes c WHERE c.pvz_id = \\$1 AND c.revoked_at IS NULL AND c.used_at IS NULL AND c.external_order_id = \\$2 FOR UPDATE OF c").
+			WithArgs(pvzID, order).
+			WillReturnRows(rows)
+	}
+
+	tests := []struct {
+		name        string
+		ref         api.PickupCodeRef
+		mockSetup   func()
+		expectedLen int
+		expectedErr error
+	}{
+		{
+			name: "correct code",
+			ref:  ref,
+			mockSetup: func() {
+				expectCodes(sqlmock.NewRows(codeColumns).
+					AddRow(otherCodeID, "other", 0).
+					AddRow(codeID, "hash", 1))
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\)>0 FROM products WHERE deleted_at IS NULL AND pickup_code_id = \\$1 AND state = \\$2").
+					WithArgs(codeID, api.ProductStateReceived).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectQuery("UPDATE products SET state = \\$1, state_changed_at = now\\(\\) WHERE deleted_at IS NULL AND pickup_code_id = \\$2 AND state = \\$3 RETURNING").
+					WithArgs(api.ProductStateIssued, codeID, api.ProductStateStored).
+					WillReturnRows(sqlmock.NewRows(productColumnNames).
+						AddRow(prodID, now, recID, api.ProductTypeShoes, nil, order, api.ProductStateIssued, now, nil, nil, nil, 1, "ok"))
+				mock.ExpectExec("UPDATE pickup_codes SET used_at = now\\(\\) WHERE id = \\$1").
+					WithArgs(codeID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedLen: 1,
+		},
+		{
+			name: "code of a product",
+			ref:  api.PickupCodeRef{ProductId: &prodID},
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT c.id, c.code_hash, c.failed_attempts FROM pickup_codes c JOIN products p ON p.pickup_code_id = c.id WHERE c.pvz_id = \\$1 AND c.revoked_at IS NULL AND c.used_at IS NULL AND p.id = \\$2 FOR UPDATE OF c").
+					WithArgs(pvzID, prodID).
+					WillReturnRows(sqlmock.NewRows(codeColumns))
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrPickupCodeNotFound,
+		},
+		{
+			name: "wrong code counts an attempt",
+			ref:  ref,
+			mockSetup: func() {
+				expectCodes(sqlmock.NewRows(codeColumns).
+					AddRow(codeID, "other", 1).
+					AddRow(otherCodeID, "another", 3))
+				mock.ExpectExec("UPDATE pickup_codes SET failed_attempts = failed_attempts \\+ 1 WHERE id IN \\(\\$1\\)").
+					WithArgs(codeID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedErr: errs.ErrInvalidPickupCode,
+		},
+		{
+			name: "attempts exceeded",
+			ref:  ref,
+			mockSetup: func() {
+				expectCodes(sqlmock.NewRows(codeColumns).AddRow(codeID, "hash", 3))
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrPickupCodeAttemptsExceeded,
+		},
+		{
+			name: "reception reopened",
+			ref:  ref,
+			mockSetup: func() {
+				expectCodes(sqlmock.NewRows(codeColumns).AddRow(codeID, "hash", 0))
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\)>0 FROM products").
+					WithArgs(codeID, api.ProductStateReceived).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrProductInOpenReception,
+		},
+		{
+			name: "products already issued",
+			ref:  ref,
+			mockSetup: func() {
+				expectCodes(sqlmock.NewRows(codeColumns).AddRow(codeID, "hash", 0))
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\)>0 FROM products").
+					WithArgs(codeID, api.ProductStateReceived).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectQuery("UPDATE products SET state").
+					WithArgs(api.ProductStateIssued, codeID, api.ProductStateStored).
+					WillReturnRows(sqlmock.NewRows(productColumnNames))
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrInvalidProductState,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			result, err := repo.Verify(context.Background(), pvzID, tt.ref, "hash", 3)
+
+			if tt.expectedErr != nil {
+				assert.True(t, errors.Is(err, tt.expectedErr), err)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, result, tt.expectedLen)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -2,10 +2,15 @@ package repository
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/ST359/pvz-service/internal/config"
-	_ "github.com/lib/pq"
+	"github.com/XSAM/otelsql"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 )
 
 const (
@@ -13,19 +18,79 @@ const (
 	pvzTable        = "pvzs"
 	receptionsTable = "receptions"
 	productsTable   = "products"
+
+	receptionReopensTable   = "reception_reopens"
+	pickupCodesTable        = "pickup_codes"
+	productTypesTable       = "product_types"
+	manifestItemsTable      = "reception_manifest_items"
+	discrepanciesTable      = "reception_discrepancies"
+	storageCellsTable       = "storage_cells"
+	transfersTable          = "transfers"
+	transferItemsTable      = "transfer_items"
+	idempotencyKeysTable    = "idempotency_keys"
+	attachmentsTable        = "attachments"
+	damageReportsTable      = "damage_reports"
+	productVolumeDailyTable = "product_volume_daily"
+)
+
+// tracer starts spans for work of repositories besides queries, queries are traced by otelsql
+var tracer = otel.Tracer("github.com/ST359/pvz-service/internal/repository")
+
+const (
+	uniqueViolationCode     = "23505"
+	foreignKeyViolationCode = "23503"
 )
 
 func NewPostgresDB(cfg *config.Config) (*sql.DB, error) {
 	const op = "storage.postgres.New"
 
 	psqlInfo := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable", cfg.DbHost, cfg.DbPort, cfg.DbUser, cfg.DbPassword, cfg.DbName)
-	db, err := sql.Open("postgres", psqlInfo)
+	connector, err := pq.NewConnector(psqlInfo)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+	// a query span ends once the database answered, reading of the rows is left to spans of the caller
+	db := otelsql.OpenDB(connector,
+		otelsql.WithAttributes(semconv.DBSystemNamePostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{DisableErrSkip: true, OmitRows: true}))
 	err = db.Ping()
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 	return db, nil
 }
+
+// isUniqueViolation reports whether err is a postgres unique violation of the given constraint(index)
+func isUniqueViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == uniqueViolationCode && pqErr.Constraint == constraint
+}
+
+// nullJSON scans a nullable JSON column into *dest, NULL leaves it nil
+type nullJSON[T any] struct {
+	dest **T
+}
+
+func (n nullJSON[T]) Scan(src any) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*n.dest = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported json column type %T", src)
+	}
+	val := new(T)
+	if err := json.Unmarshal(data, val); err != nil {
+		return err
+	}
+	*n.dest = val
+	return nil
+}
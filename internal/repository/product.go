@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/google/uuid"
+)
+
+const defaultInventoryLimit = 30
+
+// productColumns are selected or returned whenever a full api.Product is read, in order of productFields
+const productColumns = "id, date, reception_id, type, barcode, external_order_id, state, state_changed_at, return_condition, original_order_id, cell_id, scan_seq, condition"
+
+// productFields returns scan destinations for productColumns
+func productFields(p *api.Product) []interface{} {
+	return []interface{}{&p.Id, &p.DateTime, &p.ReceptionId, &p.Type, &p.Barcode, &p.ExternalOrderId, &p.State, &p.StateChangedAt, &p.ReturnCondition, &p.OriginalOrderId, &p.CellId, &p.ScanSeq, &p.Condition}
+}
+
+type ProductPostgres struct {
+	db *sql.DB
+}
+
+func NewProductPostgres(db *sql.DB) *ProductPostgres {
+	return &ProductPostgres{db: db}
+}
+
+// FindByBarcode returns every not deleted product with the given barcode across all PVZs, newest first
+func (p *ProductPostgres) FindByBarcode(ctx context.Context, barcode string) ([]api.ProductLocation, error) {
+	const op = "repository.product.FindByBarcode"
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	rows, err := psql.Select("p.id", "p.date", "p.reception_id", "p.type", "p.barcode", "p.external_order_id", "p.state", "p.state_changed_at", "p.return_condition", "p.original_order_id", "p.cell_id", "p.scan_seq", "p.condition", "r.pvz_id", "r.status").
+		Column("CASE WHEN p.state IN ('received', 'stored') THEN c.code END").
+		From(productsTable + " p").
+		Join(receptionsTable + " r ON r.id = p.current_reception_id").
+		LeftJoin(storageCellsTable + " c ON c.id = p.cell_id").
+		Where(squirrel.Eq{"p.barcode": barcode, "p.deleted_at": nil}).
+		OrderBy("p.date DESC").
+		RunWith(p.db).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	res := []api.ProductLocation{}
+	for rows.Next() {
+		var loc api.ProductLocation
+		dest := append(productFields(&loc.Product), &loc.PvzId, &loc.ReceptionStatus, &loc.CellCode)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		res = append(res, loc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return res, nil
+}
+
+// ChangeState moves products of the PVZ from one state to another, either all of them or none.
+// Active pickup codes left without stored products are revoked, so they can't be used for products that left.
+// Can return ErrProductNotFound, ErrProductInOpenReception and ErrInvalidProductState
+func (p *ProductPostgres) ChangeState(ctx context.Context, pvzID uuid.UUID, ids []uuid.UUID, from, to api.ProductState) ([]api.Product, error) {
+	const op = "repository.product.ChangeState"
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	// product rows are locked, so a reception can't be closed or reopened under a state change
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	rows, err := psql.Select("p.id", "p.state").
+		From(productsTable + " p").
+		Join(receptionsTable + " r ON r.id = p.current_reception_id").
+		Where(squirrel.Eq{"p.id": ids, "p.deleted_at": nil, "r.pvz_id": pvzID}).
+		Suffix("FOR UPDATE OF p").
+		RunWith(tx).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	states := make(map[uuid.UUID]api.ProductState, len(ids))
+	for rows.Next() {
+		var (
+			id    uuid.UUID
+			state api.ProductState
+		)
+		if err := rows.Scan(&id, &state); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		states[id] = state
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, id := range ids {
+		state, ok := states[id]
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("%w: %s", errs.ErrProductNotFound, id)
+		case state == from:
+		case state == api.ProductStateReceived:
+			return nil, fmt.Errorf("%w: %s", errs.ErrProductInOpenReception, id)
+		default:
+			return nil, fmt.Errorf("%w: %s is %s", errs.ErrInvalidProductState, id, state)
+		}
+	}
+
+	rows, err = psql.Update(productsTable).
+		Set("state", to).
+		Set("state_changed_at", squirrel.Expr("now()")).
+		Where(squirrel.Eq{"id": ids}).
+		Suffix("RETURNING " + productColumns).
+		RunWith(tx).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	res := make([]api.Product, 0, len(ids))
+	for rows.Next() {
+		var prod api.Product
+		if err := rows.Scan(productFields(&prod)...); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		res = append(res, prod)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// a code of an order stays active while other products of the order are still stored
+	covered, args, err := squirrel.Select("pickup_code_id").
+		From(productsTable).
+		Where(squirrel.Eq{"id": ids}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	_, err = psql.Update(pickupCodesTable).
+		Set("revoked_at", squirrel.Expr("now()")).
+		Where("id IN ("+covered+")", args...).
+		Where(squirrel.Eq{"used_at": nil, "revoked_at": nil}).
+		Where("NOT EXISTS (SELECT 1 FROM "+productsTable+" s WHERE s.pickup_code_id = "+pickupCodesTable+".id AND s.state = ? AND s.deleted_at IS NULL)",
+			api.ProductStateStored).
+		RunWith(tx).
+		ExecContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return res, nil
+}
+
+// GetInventory returns not deleted products of the PVZ newest first, optionally in the given state.
+// Products on the way to another PVZ are not in the inventory of any PVZ.
+// Can return ErrPVZNotFound
+func (p *ProductPostgres) GetInventory(ctx context.Context, pvzID uuid.UUID, params api.GetPvzPvzIdInventoryParams) ([]api.Product, error) {
+	const op = "repository.product.GetInventory"
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	var exists bool
+	err := psql.Select("COUNT(*)>0").
+		From(pvzTable).
+		Where(squirrel.Eq{"id": pvzID}).
+		RunWith(p.db).
+		QueryRowContext(ctx).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !exists {
+		return nil, errs.ErrPVZNotFound
+	}
+
+	limit := defaultInventoryLimit
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+	offset := 0
+	if params.Page != nil {
+		offset = (*params.Page - 1) * limit
+	}
+
+	query := psql.Select("p.id", "p.date", "p.reception_id", "p.type", "p.barcode", "p.external_order_id", "p.state", "p.state_changed_at", "p.return_condition", "p.original_order_id", "p.cell_id", "p.scan_seq", "p.condition").
+		From(productsTable + " p").
+		Join(receptionsTable + " r ON r.id = p.current_reception_id").
+		Where(squirrel.Eq{"r.pvz_id": pvzID, "p.deleted_at": nil}).
+		Where(squirrel.NotEq{"p.state": api.ProductStateInTransit})
+	if params.State != nil {
+		query = query.Where(squirrel.Eq{"p.state": *params.State})
+	}
+	rows, err := query.OrderBy("p.date DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		RunWith(p.db).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	res := []api.Product{}
+	for rows.Next() {
+		var prod api.Product
+		if err := rows.Scan(productFields(&prod)...); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		res = append(res, prod)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return res, nil
+}
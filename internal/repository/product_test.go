@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProductPostgres_FindByBarcode(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewProductPostgres(db)
+	barcode := "4607001234567"
+	prodID, recID, pvzID, cellID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		mockSetup   func()
+		expected    []api.ProductLocation
+		expectedErr bool
+	}{
+		{
+			name: "found in closed reception",
+			mockSetup: func() {
+				rows := sqlmock.NewRows([]string{"id", "date", "reception_id", "type", "barcode", "external_order_id", "state", "state_changed_at", "return_condition", "original_order_id", "cell_id", "scan_seq", "condition", "pvz_id", "status", "cell_code"}).
+					AddRow(prodID, now, recID, api.ProductTypeShoes, barcode, nil, api.ProductStateStored, now, nil, nil, cellID, 1, "ok", pvzID, "close", "A-01")
+				mock.ExpectQuery("SELECT (.+) FROM products p JOIN receptions r ON r.id = p.current_reception_id LEFT JOIN storage_cells c ON c.id = p.cell_id WHERE p.barcode = \\$1 AND p.deleted_at IS NULL ORDER BY p.date DESC").
+					WithArgs(barcode).
+					WillReturnRows(rows)
+			},
+			expected: []api.ProductLocation{{
+				Product:         api.Product{Id: &prodID, DateTime: &now, ReceptionId: recID, Type: api.ProductTypeShoes, Barcode: &barcode, State: api.ProductStateStored, StateChangedAt: &now, CellId: &cellID, ScanSeq: ptrTo(int64(1)), Condition: ptrTo(api.ConditionOk)},
+				PvzId:           pvzID,
+				ReceptionStatus: api.Close,
+				CellCode:        ptrTo("A-01"),
+			}},
+		},
+		{
+			name: "not found",
+			mockSetup: func() {
+				mock.ExpectQuery("SELECT (.+) FROM products p").
+					WithArgs(barcode).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "date", "reception_id", "type", "barcode", "external_order_id", "state", "state_changed_at", "return_condition", "original_order_id", "cell_id", "scan_seq", "condition", "pvz_id", "status", "cell_code"}))
+			},
+			expected: []api.ProductLocation{},
+		},
+		{
+			name: "database error",
+			mockSetup: func() {
+				mock.ExpectQuery("SELECT (.+) FROM products p").
+					WithArgs(barcode).
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			result, err := repo.FindByBarcode(context.Background(), barcode)
+
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestProductPostgres_ChangeState(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewProductPostgres(db)
+	pvzID, recID := uuid.New(), uuid.New()
+	firstID, secondID := uuid.New(), uuid.New()
+	ids := []uuid.UUID{firstID, secondID}
+	now := time.Now()
+	productColumnNames := []string{"id", "date", "reception_id", "type", "barcode", "external_order_id", "state", "state_changed_at", "return_condition", "original_order_id", "cell_id", "scan_seq", "condition"}
+
+	expectLock := func(rows *sqlmock.Rows) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT p.id, p.state FROM products p JOIN receptions r ON r.id = p.current_reception_id WHERE p.deleted_at IS NULL AND p.id IN \\(\\$1,\\$2\\) AND r.pvz_id = \\$3 FOR UPDATE OF p").
+			WithArgs(firstID, secondID, pvzID).
+			WillReturnRows(rows)
+	}
+
+	tests := []struct {
+		name        string
+		mockSetup   func()
+		expectedLen int
+		expectedErr error
+	}{
+		{
+			name: "all products stored",
+			mockSetup: func() {
+				expectLock(sqlmock.NewRows([]string{"id", "state"}).
+					AddRow(firstID, api.ProductStateStored).
+					AddRow(secondID, api.ProductStateStored))
+				mock.ExpectQuery("UPDATE products SET state = \\$1, state_changed_at = now\\(\\) WHERE id IN \\(\\$2,\\$3\\) RETURNING").
+					WithArgs(api.ProductStateReturnedToSender, firstID, secondID).
+					WillReturnRows(sqlmock.NewRows(productColumnNames).
+						AddRow(firstID, now, recID, api.ProductTypeShoes, nil, nil, api.ProductStateReturnedToSender, now, nil, nil, nil, 1, "ok").
+						AddRow(secondID, now, recID, api.ProductTypeClothes, nil, nil, api.ProductStateReturnedToSender, now, nil, nil, nil, 1, "ok"))
+				mock.ExpectExec("UPDATE pickup_codes SET revoked_at = now\\(\\) WHERE id IN \\(SELECT pickup_code_id FROM products WHERE id IN \\(\\$1,\\$2\\)\\) AND revoked_at IS NULL AND used_at IS NULL AND NOT EXISTS \\(SELECT 1 FROM products s WHERE s.pickup_code_id = pickup_codes.id AND s.state = \\$3 AND s.deleted_at IS NULL\\)").
+					WithArgs(firstID, secondID, api.ProductStateStored).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedLen: 2,
+		},
+		{
+			name: "product of another pvz",
+			mockSetup: func() {
+				expectLock(sqlmock.NewRows([]string{"id", "state"}).
+					AddRow(firstID, api.ProductStateStored))
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrProductNotFound,
+		},
+		{
+			name: "product in open reception",
+			mockSetup: func() {
+				expectLock(sqlmock.NewRows([]string{"id", "state"}).
+					AddRow(firstID, api.ProductStateStored).
+					AddRow(secondID, api.ProductStateReceived))
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrProductInOpenReception,
+		},
+		{
+			name: "product already issued",
+			mockSetup: func() {
+				expectLock(sqlmock.NewRows([]string{"id", "state"}).
+					AddRow(firstID, api.ProductStateReturnedToSender).
+					AddRow(secondID, api.ProductStateStored))
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrInvalidProductState,
+		},
+		{
+			name: "database error",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT p.id, p.state FROM products p").
+					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
+			},
+			expectedErr: sql.ErrConnDone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			result, err := repo.ChangeState(context.Background(), pvzID, ids, api.ProductStateStored, api.ProductStateReturnedToSender)
+
+			if tt.expectedErr != nil {
+				assert.True(t, errors.Is(err, tt.expectedErr), err)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, result, tt.expectedLen)
+				for _, prod := range result {
+					assert.Equal(t, api.ProductStateReturnedToSender, prod.State)
+				}
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestProductPostgres_GetInventory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewProductPostgres(db)
+	pvzID, recID := uuid.New(), uuid.New()
+	now := time.Now()
+	stored := api.ProductStateStored
+	page, limit := 2, 10
+	productColumnNames := []string{"id", "date", "reception_id", "type", "barcode", "external_order_id", "state", "state_changed_at", "return_condition", "original_order_id", "cell_id", "scan_seq", "condition"}
+
+	tests := []struct {
+		name        string
+		params      api.GetPvzPvzIdInventoryParams
+		mockSetup   func()
+		expectedLen int
+		expectedErr error
+	}{
+		{
+			name:   "default page",
+			params: api.GetPvzPvzIdInventoryParams{},
+			mockSetup: func() {
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\)>0 FROM pvzs WHERE id = \\$1").
+					WithArgs(pvzID).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectQuery("SELECT (.+) FROM products p JOIN receptions r ON r.id = p.current_reception_id WHERE p.deleted_at IS NULL AND r.pvz_id = \\$1 AND p.state <> \\$2 ORDER BY p.date DESC LIMIT 30 OFFSET 0").
+					WithArgs(pvzID, api.ProductStateInTransit).
+					WillReturnRows(sqlmock.NewRows(productColumnNames).
+						AddRow(uuid.New(), now, recID, api.ProductTypeShoes, nil, nil, api.ProductStateReceived, nil, nil, nil, nil, 1, "ok").
+						AddRow(uuid.New(), now, recID, api.ProductTypeShoes, nil, nil, api.ProductStateIssued, now, nil, nil, nil, 1, "ok"))
+			},
+			expectedLen: 2,
+		},
+		{
+			name:   "filtered by state",
+			params: api.GetPvzPvzIdInventoryParams{State: &stored, Page: &page, Limit: &limit},
+			mockSetup: func() {
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\)>0 FROM pvzs").
+					WithArgs(pvzID).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectQuery("SELECT (.+) FROM products p JOIN receptions r ON r.id = p.current_reception_id WHERE p.deleted_at IS NULL AND r.pvz_id = \\$1 AND p.state <> \\$2 AND p.state = \\$3 ORDER BY p.date DESC LIMIT 10 OFFSET 10").
+					WithArgs(pvzID, api.ProductStateInTransit, stored).
+					WillReturnRows(sqlmock.NewRows(productColumnNames).
+						AddRow(uuid.New(), now, recID, api.ProductTypeShoes, nil, nil, api.ProductStateStored, now, nil, nil, nil, 1, "ok"))
+			},
+			expectedLen: 1,
+		},
+		{
+			name: "pvz not found",
+			mockSetup: func() {
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\)>0 FROM pvzs").
+					WithArgs(pvzID).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+			},
+			expectedErr: errs.ErrPVZNotFound,
+		},
+		{
+			name: "database error",
+			mockSetup: func() {
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\)>0 FROM pvzs").
+					WithArgs(pvzID).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectQuery("SELECT (.+) FROM products p").
+					WithArgs(pvzID, api.ProductStateInTransit).
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedErr: sql.ErrConnDone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			result, err := repo.GetInventory(context.Background(), pvzID, tt.params)
+
+			if tt.expectedErr != nil {
+				assert.True(t, errors.Is(err, tt.expectedErr), err)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, result, tt.expectedLen)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
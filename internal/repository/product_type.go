@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/lib/pq"
+)
+
+const (
+	productTypePKey       = "product_types_pkey"
+	productTypeLegacyKey  = "product_types_legacy_name_key"
+	productTypeForeignKey = "products_type_fkey"
+)
+
+// productTypeColumns are selected or returned whenever a full api.ProductTypeInfo is read, in order of scanProductType
+const productTypeColumns = "code, names, legacy_name, active, requires_signature, created_at, updated_at"
+
+type ProductTypePostgres struct {
+	db *sql.DB
+}
+
+func NewProductTypePostgres(db *sql.DB) *ProductTypePostgres {
+	return &ProductTypePostgres{db: db}
+}
+
+func (p *ProductTypePostgres) List(ctx context.Context, includeInactive bool) ([]api.ProductTypeInfo, error) {
+	const op = "repository.product_type.List"
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	query := psql.Select(productTypeColumns).
+		From(productTypesTable).
+		OrderBy("code")
+	if !includeInactive {
+		query = query.Where(squirrel.Eq{"active": true})
+	}
+	rows, err := query.RunWith(p.db).QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	types := []api.ProductTypeInfo{}
+	for rows.Next() {
+		pt, err := scanProductType(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		types = append(types, pt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return types, nil
+}
+
+// Resolve returns the product type with the given code or legacy name, can return ErrProductTypeNotFound
+func (p *ProductTypePostgres) Resolve(ctx context.Context, name string) (api.ProductTypeInfo, error) {
+	const op = "repository.product_type.Resolve"
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	row := psql.Select(productTypeColumns).
+		From(productTypesTable).
+		Where(squirrel.Or{squirrel.Eq{"code": name}, squirrel.Eq{"legacy_name": name}}).
+		RunWith(p.db).
+		QueryRowContext(ctx)
+	pt, err := scanProductType(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return api.ProductTypeInfo{}, errs.ErrProductTypeNotFound
+		}
+		return api.ProductTypeInfo{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return pt, nil
+}
+
+// Create can return ErrProductTypeExists
+func (p *ProductTypePostgres) Create(ctx context.Context, pt api.ProductTypeInput) (api.ProductTypeInfo, error) {
+	const op = "repository.product_type.Create"
+
+	names, err := json.Marshal(pt.Names)
+	if err != nil {
+		return api.ProductTypeInfo{}, fmt.Errorf("%s: %w", op, err)
+	}
+	active, requiresSignature := true, false
+	if pt.Active != nil {
+		active = *pt.Active
+	}
+	if pt.RequiresSignature != nil {
+		requiresSignature = *pt.RequiresSignature
+	}
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	row := psql.Insert(productTypesTable).
+		Columns("code", "names", "legacy_name", "active", "requires_signature").
+		Values(pt.Code, names, pt.LegacyName, active, requiresSignature).
+		Suffix("RETURNING " + productTypeColumns).
+		RunWith(p.db).
+		QueryRowContext(ctx)
+	res, err := scanProductType(row)
+	if err != nil {
+		if isUniqueViolation(err, productTypePKey) || isUniqueViolation(err, productTypeLegacyKey) {
+			return api.ProductTypeInfo{}, errs.ErrProductTypeExists
+		}
+		return api.ProductTypeInfo{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return res, nil
+}
+
+// Update can return ErrProductTypeNotFound
+func (p *ProductTypePostgres) Update(ctx context.Context, code string, pt api.ProductTypeUpdate) (api.ProductTypeInfo, error) {
+	const op = "repository.product_type.Update"
+
+	names, err := json.Marshal(pt.Names)
+	if err != nil {
+		return api.ProductTypeInfo{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	row := psql.Update(productTypesTable).
+		Set("names", names).
+		Set("active", pt.Active).
+		Set("requires_signature", pt.RequiresSignature).
+		Set("updated_at", squirrel.Expr("CURRENT_TIMESTAMP")).
+		Where(squirrel.Eq{"code": code}).
+		Suffix("RETURNING " + productTypeColumns).
+		RunWith(p.db).
+		QueryRowContext(ctx)
+	res, err := scanProductType(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return api.ProductTypeInfo{}, errs.ErrProductTypeNotFound
+		}
+		return api.ProductTypeInfo{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return res, nil
+}
+
+// Delete can return ErrProductTypeNotFound and ErrProductTypeInUse
+func (p *ProductTypePostgres) Delete(ctx context.Context, code string) error {
+	const op = "repository.product_type.Delete"
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	res, err := psql.Delete(productTypesTable).
+		Where(squirrel.Eq{"code": code}).
+		RunWith(p.db).
+		ExecContext(ctx)
+	if err != nil {
+		if isForeignKeyViolation(err, productTypeForeignKey) {
+			return errs.ErrProductTypeInUse
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return errs.ErrProductTypeNotFound
+	}
+	return nil
+}
+
+func scanProductType(row squirrel.RowScanner) (api.ProductTypeInfo, error) {
+	var (
+		pt    api.ProductTypeInfo
+		names []byte
+	)
+	err := row.Scan(&pt.Code, &names, &pt.LegacyName, &pt.Active, &pt.RequiresSignature, &pt.CreatedAt, &pt.UpdatedAt)
+	if err != nil {
+		return api.ProductTypeInfo{}, err
+	}
+	if err := json.Unmarshal(names, &pt.Names); err != nil {
+		return api.ProductTypeInfo{}, err
+	}
+	return pt, nil
+}
+
+// isForeignKeyViolation reports whether err is a postgres foreign key violation of the given constraint
+func isForeignKeyViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == foreignKeyViolationCode && pqErr.Constraint == constraint
+}
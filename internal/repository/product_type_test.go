@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var productTypeRowColumns = []string{"code", "names", "legacy_name", "active", "requires_signature", "created_at", "updated_at"}
+
+func TestProductTypePostgres_Resolve(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewProductTypePostgres(db)
+	now := time.Now()
+	legacy := "обувь"
+
+	tests := []struct {
+		name        string
+		mockSetup   func()
+		expected    api.ProductTypeInfo
+		expectedErr error
+	}{
+		{
+			name: "found by legacy name",
+			mockSetup: func() {
+				rows := sqlmock.NewRows(productTypeRowColumns).
+					AddRow("shoes", []byte(`{"ru": "Обувь", "en": "Shoes"}`), legacy, true, false, now, now)
+				mock.ExpectQuery(`SELECT code, names, legacy_name, active, requires_signature, created_at, updated_at FROM product_types WHERE \(code = \$1 OR legacy_name = \$2\)`).
+					WithArgs(legacy, legacy).
+					WillReturnRows(rows)
+			},
+			expected: api.ProductTypeInfo{
+				Code:       "shoes",
+				Names:      map[string]string{"ru": "Обувь", "en": "Shoes"},
+				LegacyName: &legacy,
+				Active:     true,
+				CreatedAt:  now,
+				UpdatedAt:  now,
+			},
+		},
+		{
+			name: "not found",
+			mockSetup: func() {
+				mock.ExpectQuery("SELECT (.+) FROM product_types").
+					WithArgs(legacy, legacy).
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedErr: errs.ErrProductTypeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			result, err := repo.Resolve(context.Background(), legacy)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestProductTypePostgres_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewProductTypePostgres(db)
+	now := time.Now()
+	input := api.ProductTypeInput{Code: "furniture", Names: map[string]string{"ru": "Мебель"}}
+
+	tests := []struct {
+		name        string
+		mockSetup   func()
+		expectedErr error
+	}{
+		{
+			name: "created with defaults",
+			mockSetup: func() {
+				rows := sqlmock.NewRows(productTypeRowColumns).
+					AddRow("furniture", []byte(`{"ru": "Мебель"}`), nil, true, false, now, now)
+				mock.ExpectQuery("INSERT INTO product_types").
+					WithArgs("furniture", []byte(`{"ru":"Мебель"}`), nil, true, false).
+					WillReturnRows(rows)
+			},
+		},
+		{
+			name: "code already exists",
+			mockSetup: func() {
+				mock.ExpectQuery("INSERT INTO product_types").
+					WithArgs("furniture", []byte(`{"ru":"Мебель"}`), nil, true, false).
+					WillReturnError(&pq.Error{Code: uniqueViolationCode, Constraint: productTypePKey})
+			},
+			expectedErr: errs.ErrProductTypeExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			result, err := repo.Create(context.Background(), input)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "furniture", result.Code)
+				assert.True(t, result.Active)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestProductTypePostgres_Delete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewProductTypePostgres(db)
+
+	tests := []struct {
+		name        string
+		mockSetup   func()
+		expectedErr error
+	}{
+		{
+			name: "deleted",
+			mockSetup: func() {
+				mock.ExpectExec("DELETE FROM product_types WHERE code = \\$1").
+					WithArgs("furniture").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "not found",
+			mockSetup: func() {
+				mock.ExpectExec("DELETE FROM product_types").
+					WithArgs("furniture").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expectedErr: errs.ErrProductTypeNotFound,
+		},
+		{
+			name: "used by products",
+			mockSetup: func() {
+				mock.ExpectExec("DELETE FROM product_types").
+					WithArgs("furniture").
+					WillReturnError(&pq.Error{Code: foreignKeyViolationCode, Constraint: productTypeForeignKey})
+			},
+			expectedErr: errs.ErrProductTypeInUse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			err := repo.Delete(context.Background(), "furniture")
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -8,12 +9,15 @@ import (
 
 	"github.com/Masterminds/squirrel"
 	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
 	"github.com/google/uuid"
 )
 
 const (
 	defaultLimit  = 10
 	defaultOffset = 0
+
+	pvzCityAddressIndex = "idx_pvzs_city_address"
 )
 
 type PVZPostgres struct {
@@ -23,23 +27,93 @@ type PVZPostgres struct {
 func NewPVZPostgres(db *sql.DB) *PVZPostgres {
 	return &PVZPostgres{db: db}
 }
-func (p *PVZPostgres) Create(pvz api.PVZ) (api.PVZ, error) {
+
+// Create can return ErrPVZAddressExists
+func (p *PVZPostgres) Create(ctx context.Context, pvz api.PVZ) (api.PVZ, error) {
 	const op = "repository.pvz.Create"
 
 	var resPVZ api.PVZ
 	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 	err := psql.Insert(pvzTable).
-		Columns("city").
-		Values(pvz.City).
-		Suffix("RETURNING id, registration_date, city").
+		Columns("city", "address").
+		Values(pvz.City, pvz.Address).
+		Suffix("RETURNING id, registration_date, city, address").
 		RunWith(p.db).
-		QueryRow().Scan(&resPVZ.Id, &resPVZ.RegistrationDate, &resPVZ.City)
+		QueryRowContext(ctx).Scan(&resPVZ.Id, &resPVZ.RegistrationDate, &resPVZ.City, &resPVZ.Address)
 	if err != nil {
+		if isUniqueViolation(err, pvzCityAddressIndex) {
+			return api.PVZ{}, errs.ErrPVZAddressExists
+		}
 		return api.PVZ{}, fmt.Errorf("%s: %w", op, err)
 	}
 	return resPVZ, nil
 }
-func (p *PVZPostgres) GetByDate(params api.GetPvzParams) ([]api.PVZInfo, error) {
+
+// CreateBatch inserts all given PVZs in a single transaction, either all of them are created or none.
+// Can return ErrPVZAddressExists
+func (p *PVZPostgres) CreateBatch(ctx context.Context, pvzs []api.PVZ) ([]api.PVZ, error) {
+	const op = "repository.pvz.CreateBatch"
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	created := make([]api.PVZ, 0, len(pvzs))
+	for _, pvz := range pvzs {
+		var resPVZ api.PVZ
+		err := psql.Insert(pvzTable).
+			Columns("city", "address").
+			Values(pvz.City, pvz.Address).
+			Suffix("RETURNING id, registration_date, city, address").
+			RunWith(tx).
+			QueryRowContext(ctx).Scan(&resPVZ.Id, &resPVZ.RegistrationDate, &resPVZ.City, &resPVZ.Address)
+		if err != nil {
+			if isUniqueViolation(err, pvzCityAddressIndex) {
+				return nil, errs.ErrPVZAddressExists
+			}
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		created = append(created, resPVZ)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return created, nil
+}
+
+// GetByCities returns all PVZs registered with an address in given cities
+func (p *PVZPostgres) GetByCities(ctx context.Context, cities []api.PVZCity) ([]api.PVZ, error) {
+	const op = "repository.pvz.GetByCities"
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	rows, err := psql.Select("id", "registration_date", "city", "address").
+		From(pvzTable).
+		Where(squirrel.And{squirrel.Eq{"city": cities}, squirrel.NotEq{"address": nil}}).
+		RunWith(p.db).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var result []api.PVZ
+	for rows.Next() {
+		var pvz api.PVZ
+		if err := rows.Scan(&pvz.Id, &pvz.RegistrationDate, &pvz.City, &pvz.Address); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		result = append(result, pvz)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return result, nil
+}
+func (p *PVZPostgres) GetByDate(ctx context.Context, params api.GetPvzParams) ([]api.PVZInfo, error) {
 	const op = "repository.pvz.GetByDate"
 
 	// Prepare parameters
@@ -66,7 +140,7 @@ func (p *PVZPostgres) GetByDate(params api.GetPvzParams) ([]api.PVZInfo, error)
 		endDate = nil
 	}
 
-	rows, err := p.db.Query(
+	rows, err := p.db.QueryContext(ctx,
 		"SELECT * FROM get_pvz_with_receptions_paginated($1, $2, $3, $4)",
 		startDate,
 		endDate,
@@ -77,6 +151,9 @@ func (p *PVZPostgres) GetByDate(params api.GetPvzParams) ([]api.PVZInfo, error)
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 	defer rows.Close()
+	// the query span ends once the function answered, reading rows and unmarshalling receptions get a span of their own
+	_, span := tracer.Start(ctx, op+".decode")
+	defer span.End()
 
 	var result []api.PVZInfo
 
@@ -84,11 +161,12 @@ func (p *PVZPostgres) GetByDate(params api.GetPvzParams) ([]api.PVZInfo, error)
 		var (
 			pvzID          uuid.UUID
 			city           string
+			address        sql.NullString
 			regDate        time.Time
 			receptionsJSON []byte
 		)
 
-		if err := rows.Scan(&pvzID, &city, &regDate, &receptionsJSON); err != nil {
+		if err := rows.Scan(&pvzID, &city, &address, &regDate, &receptionsJSON); err != nil {
 			return nil, fmt.Errorf("%s: %w", op, err)
 		}
 
@@ -100,6 +178,9 @@ func (p *PVZPostgres) GetByDate(params api.GetPvzParams) ([]api.PVZInfo, error)
 			},
 			Receptions: nil,
 		}
+		if address.Valid {
+			pvzInfo.Pvz.Address = &address.String
+		}
 
 		// Only process receptions if JSON exists and is not empty
 		if len(receptionsJSON) > 0 && string(receptionsJSON) != "null" {
@@ -1,13 +1,16 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -32,10 +35,10 @@ func TestPVZPostgres_Create(t *testing.T) {
 				City: api.Moscow,
 			},
 			mockSetup: func() {
-				rows := sqlmock.NewRows([]string{"id", "registration_date", "city"}).
-					AddRow(uuid.New(), time.Now(), api.Moscow)
+				rows := sqlmock.NewRows([]string{"id", "registration_date", "city", "address"}).
+					AddRow(uuid.New(), time.Now(), api.Moscow, nil)
 				mock.ExpectQuery("INSERT INTO pvzs").
-					WithArgs(api.Moscow).
+					WithArgs(api.Moscow, nil).
 					WillReturnRows(rows)
 			},
 			expected: func(t *testing.T, result api.PVZ) {
@@ -52,7 +55,7 @@ func TestPVZPostgres_Create(t *testing.T) {
 			},
 			mockSetup: func() {
 				mock.ExpectQuery("INSERT INTO pvzs").
-					WithArgs(api.Moscow).
+					WithArgs(api.Moscow, nil).
 					WillReturnError(sql.ErrConnDone)
 			},
 			expected:    func(t *testing.T, result api.PVZ) {},
@@ -63,7 +66,7 @@ func TestPVZPostgres_Create(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
-			result, err := repo.Create(tt.input)
+			result, err := repo.Create(context.Background(), tt.input)
 
 			if tt.expectedErr {
 				assert.Error(t, err)
@@ -102,8 +105,8 @@ func TestPVZPostgres_GetByDate(t *testing.T) {
 				Limit:     ptrToInt(10),
 			},
 			mockSetup: func() {
-				rows := sqlmock.NewRows([]string{"pvz_id", "city", "registration_date", "receptions"}).
-					AddRow(testUUID, api.Moscow, now, []byte("[]"))
+				rows := sqlmock.NewRows([]string{"pvz_id", "city", "address", "registration_date", "receptions"}).
+					AddRow(testUUID, api.Moscow, nil, now, []byte("[]"))
 				mock.ExpectQuery("SELECT \\* FROM get_pvz_with_receptions_paginated").
 					WithArgs(now, nil, 10, 0).
 					WillReturnRows(rows)
@@ -117,7 +120,7 @@ func TestPVZPostgres_GetByDate(t *testing.T) {
 				Limit: ptrToInt(10),
 			},
 			mockSetup: func() {
-				rows := sqlmock.NewRows([]string{"pvz_id", "city", "registration_date", "receptions"})
+				rows := sqlmock.NewRows([]string{"pvz_id", "city", "address", "registration_date", "receptions"})
 				mock.ExpectQuery("SELECT \\* FROM get_pvz_with_receptions_paginated").
 					WithArgs(nil, nil, 10, 0).
 					WillReturnRows(rows)
@@ -144,8 +147,8 @@ func TestPVZPostgres_GetByDate(t *testing.T) {
 				Limit: ptrToInt(10),
 			},
 			mockSetup: func() {
-				rows := sqlmock.NewRows([]string{"pvz_id", "city", "registration_date", "receptions"}).
-					AddRow(testUUID, api.Moscow, now, []byte("{invalid}"))
+				rows := sqlmock.NewRows([]string{"pvz_id", "city", "address", "registration_date", "receptions"}).
+					AddRow(testUUID, api.Moscow, nil, now, []byte("{invalid}"))
 				mock.ExpectQuery("SELECT \\* FROM get_pvz_with_receptions_paginated").
 					WithArgs(nil, nil, 10, 0).
 					WillReturnRows(rows)
@@ -158,7 +161,7 @@ func TestPVZPostgres_GetByDate(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
-			result, err := repo.GetByDate(tt.params)
+			result, err := repo.GetByDate(context.Background(), tt.params)
 
 			if tt.expectedErr {
 				assert.Error(t, err)
@@ -172,6 +175,103 @@ func TestPVZPostgres_GetByDate(t *testing.T) {
 	}
 }
 
+func TestPVZPostgres_CreateBatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPVZPostgres(db)
+	arbat, baumana := "Арбат 10", "Баумана 5"
+	input := []api.PVZ{
+		{City: api.Moscow, Address: &arbat},
+		{City: api.Kazan, Address: &baumana},
+	}
+
+	tests := []struct {
+		name        string
+		mockSetup   func()
+		expectedLen int
+		expectedErr error
+	}{
+		{
+			name: "all created in one transaction",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO pvzs").
+					WithArgs(api.Moscow, arbat).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "registration_date", "city", "address"}).
+						AddRow(uuid.New(), time.Now(), api.Moscow, arbat))
+				mock.ExpectQuery("INSERT INTO pvzs").
+					WithArgs(api.Kazan, baumana).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "registration_date", "city", "address"}).
+						AddRow(uuid.New(), time.Now(), api.Kazan, baumana))
+				mock.ExpectCommit()
+			},
+			expectedLen: 2,
+		},
+		{
+			name: "duplicate address rolls back",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO pvzs").
+					WithArgs(api.Moscow, arbat).
+					WillReturnError(&pq.Error{Code: uniqueViolationCode, Constraint: pvzCityAddressIndex})
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrPVZAddressExists,
+		},
+		{
+			name: "database error rolls back",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO pvzs").
+					WithArgs(api.Moscow, arbat).
+					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
+			},
+			expectedErr: sql.ErrConnDone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			result, err := repo.CreateBatch(context.Background(), input)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, result, tt.expectedLen)
+				assert.Equal(t, baumana, *result[1].Address)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPVZPostgres_GetByCities(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPVZPostgres(db)
+	address := "Тверская 1"
+
+	rows := sqlmock.NewRows([]string{"id", "registration_date", "city", "address"}).
+		AddRow(uuid.New(), time.Now(), api.Moscow, address)
+	mock.ExpectQuery("SELECT id, registration_date, city, address FROM pvzs WHERE \\(city IN \\(\\$1,\\$2\\) AND address IS NOT NULL\\)").
+		WithArgs(api.Moscow, api.Kazan).
+		WillReturnRows(rows)
+
+	result, err := repo.GetByCities(context.Background(), []api.PVZCity{api.Moscow, api.Kazan})
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Equal(t, address, *result[0].Address)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func ptrToInt(i int) *int {
 	return &i
 }
@@ -1,16 +1,52 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/ST359/pvz-service/internal/api"
 	errs "github.com/ST359/pvz-service/internal/app_errors"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+// receptionInProgressIndex allows only one reception in progress per PVZ and kind
+const receptionInProgressIndex = "idx_receptions_one_in_progress_per_kind"
+
+const receptionColumns = "id, date, pvz_id, status, closed_at, close_reason, stale_flagged_at, kind, created_by, summary"
+
+// receptionFields returns scan destinations matching receptionColumns
+func receptionFields(r *api.Reception) []any {
+	return []any{&r.Id, &r.DateTime, &r.PvzId, &r.Status, &r.ClosedAt, &r.CloseReason, &r.StaleFlaggedAt, &r.Kind, &r.CreatedBy,
+		nullJSON[api.ReceptionSummary]{&r.Summary}}
+}
+
+// closeSummary builds api.ReceptionSummary of a reception aliased as r that is being closed now
+const closeSummary = `json_build_object(
+	'productsByType', COALESCE((SELECT json_object_agg(t.type, t.n) FROM (SELECT p.type, COUNT(*) AS n FROM ` + productsTable + ` p WHERE p.reception_id = r.id AND p.deleted_at IS NULL GROUP BY p.type) t), '{}'::json),
+	'productsTotal', (SELECT COUNT(*) FROM ` + productsTable + ` p WHERE p.reception_id = r.id AND p.deleted_at IS NULL),
+	'deletions', (SELECT COUNT(*) FROM ` + productsTable + ` p WHERE p.reception_id = r.id AND p.deleted_at IS NOT NULL),
+	'durationSeconds', GREATEST(EXTRACT(EPOCH FROM now() - r.date), 0)::BIGINT,
+	` + damageSummary + `)`
+
+// damageSummary lists the damage counts of api.ReceptionSummary for a reception aliased as r,
+// it is also used to refresh a stored summary when a moderator rejects a damage report
+const damageSummary = `'damagedByCondition', COALESCE((SELECT json_object_agg(t.condition, t.n) FROM (SELECT p.condition, COUNT(*) AS n FROM ` + productsTable + ` p WHERE p.reception_id = r.id AND p.deleted_at IS NULL AND p.condition <> 'ok' GROUP BY p.condition) t), '{}'::json),
+	'damagedTotal', (SELECT COUNT(*) FROM ` + productsTable + ` p WHERE p.reception_id = r.id AND p.deleted_at IS NULL AND p.condition <> 'ok')`
+
+// staleReceptionsLockKey is a key of the advisory lock held while stale receptions are swept,
+// so only one replica does it at a time
+const staleReceptionsLockKey int64 = 0x7076_7a5f_7374_616c
+
+const defaultReceptionsLimit = 30
+
+// lastActivity is the time of the latest scan or deletion in a reception aliased as r
+const lastActivity = "GREATEST(r.date, (SELECT MAX(GREATEST(p.date, p.deleted_at)) FROM " + productsTable + " p WHERE p.reception_id = r.id))"
+
 type ReceptionPostgres struct {
 	db *sql.DB
 }
@@ -19,48 +55,238 @@ func NewReceptionPostgres(db *sql.DB) *ReceptionPostgres {
 	return &ReceptionPostgres{db: db}
 }
 
-func (r *ReceptionPostgres) Create(pvzID uuid.UUID) (api.Reception, error) {
+// Create stores userID as the creator unless it is uuid.Nil,
+// can return ErrReceptionNotClosed if the PVZ has a reception of the same kind in progress
+func (r *ReceptionPostgres) Create(ctx context.Context, pvzID uuid.UUID, kind api.ReceptionKind, userID uuid.UUID) (api.Reception, error) {
 	const op = "repository.reception.Create"
 
+	var createdBy *uuid.UUID
+	if userID != uuid.Nil {
+		createdBy = &userID
+	}
 	var rec api.Reception
 	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 	err := psql.Insert(receptionsTable).
-		Columns("pvz_id").
-		Values(pvzID).
-		Suffix("RETURNING id, date, pvz_id, status").
+		Columns("pvz_id", "kind", "created_by").
+		Values(pvzID, kind, createdBy).
+		Suffix("RETURNING " + receptionColumns).
 		RunWith(r.db).
-		QueryRow().Scan(&rec.Id, &rec.DateTime, &rec.PvzId, &rec.Status)
+		QueryRowContext(ctx).Scan(receptionFields(&rec)...)
 	if err != nil {
+		if isUniqueViolation(err, receptionInProgressIndex) {
+			return api.Reception{}, errs.ErrReceptionNotClosed
+		}
 		return api.Reception{}, fmt.Errorf("%s: %w", op, err)
 	}
 	return rec, nil
 }
-func (r *ReceptionPostgres) AddProduct(recID uuid.UUID, prodType api.ProductType) (api.Product, error) {
+
+// AddProduct can return ErrDuplicateBarcode and storage cell errors if the product is scanned into a cell
+func (r *ReceptionPostgres) AddProduct(ctx context.Context, recID uuid.UUID, product api.ProductInput) (api.Product, error) {
 	const op = "repository.reception.AddProduct"
 
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return api.Product{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	seq, err := reserveScanSeqs(ctx, tx, recID, 1)
+	if err != nil {
+		if errors.Is(err, errs.ErrNoReceptionsInProgress) {
+			return api.Product{}, err
+		}
+		return api.Product{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := checkBarcodes(ctx, tx, recID, []api.ProductInput{product}); err != nil {
+		if errors.Is(err, errs.ErrDuplicateBarcode) {
+			return api.Product{}, err
+		}
+		return api.Product{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := reserveScannedCells(ctx, tx, recID, []api.ProductInput{product}); err != nil {
+		if isStorageCellError(err) {
+			return api.Product{}, err
+		}
+		return api.Product{}, fmt.Errorf("%s: %w", op, err)
+	}
+
 	var prod api.Product
 	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
-	err := psql.Insert(productsTable).
-		Columns("reception_id", "type").
-		Values(recID, prodType).
-		Suffix("RETURNING id, date, reception_id, type").
-		RunWith(r.db).
-		QueryRow().Scan(&prod.Id, &prod.DateTime, &prod.ReceptionId, &prod.Type)
+	err = psql.Insert(productsTable).
+		Columns("reception_id", "current_reception_id", "type", "barcode", "external_order_id", "return_condition", "original_order_id", "cell_id", "scan_seq").
+		Values(recID, recID, product.Type, product.Barcode, product.ExternalOrderId, product.ReturnCondition, product.OriginalOrderId, product.CellId, seq).
+		Suffix("RETURNING " + productColumns).
+		RunWith(tx).
+		QueryRowContext(ctx).Scan(productFields(&prod)...)
 	if err != nil {
 		return api.Product{}, fmt.Errorf("%s: %w", op, err)
 	}
+
+	if err := tx.Commit(); err != nil {
+		return api.Product{}, fmt.Errorf("%s: %w", op, err)
+	}
 	return prod, nil
 }
-func (r *ReceptionPostgres) GetReceptionInProgress(pvzID uuid.UUID) (uuid.UUID, error) {
+
+// AddProducts inserts all products with a single statement, can return ErrDuplicateBarcode and storage cell errors
+func (r *ReceptionPostgres) AddProducts(ctx context.Context, recID uuid.UUID, products []api.ProductInput) ([]api.Product, error) {
+	const op = "repository.reception.AddProducts"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	seq, err := reserveScanSeqs(ctx, tx, recID, len(products))
+	if err != nil {
+		if errors.Is(err, errs.ErrNoReceptionsInProgress) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := checkBarcodes(ctx, tx, recID, products); err != nil {
+		if errors.Is(err, errs.ErrDuplicateBarcode) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := reserveScannedCells(ctx, tx, recID, products); err != nil {
+		if isStorageCellError(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	query := psql.Insert(productsTable).
+		Columns("reception_id", "current_reception_id", "type", "barcode", "external_order_id", "return_condition", "original_order_id", "cell_id", "scan_seq")
+	for i, p := range products {
+		query = query.Values(recID, recID, p.Type, p.Barcode, p.ExternalOrderId, p.ReturnCondition, p.OriginalOrderId, p.CellId, seq+int64(i))
+	}
+	rows, err := query.Suffix("RETURNING " + productColumns).
+		RunWith(tx).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	res := make([]api.Product, 0, len(products))
+	for rows.Next() {
+		var prod api.Product
+		if err := rows.Scan(productFields(&prod)...); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		res = append(res, prod)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return res, nil
+}
+
+// reserveScanSeqs gives out n scan numbers of the reception in progress and returns the first of them.
+// The reception row stays locked until the end of the transaction, so scans of one reception are numbered
+// in the order they commit, and closing and undo wait for them. Numbers of a rolled back scan are given out again.
+// Can return ErrNoReceptionsInProgress if the reception is missing or already closed
+func reserveScanSeqs(ctx context.Context, tx *sql.Tx, recID uuid.UUID, n int) (int64, error) {
+	var last int64
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	err := psql.Update(receptionsTable).
+		Set("last_scan_seq", squirrel.Expr("last_scan_seq + ?", n)).
+		Where(squirrel.Eq{"id": recID, "status": api.InProgress}).
+		Suffix("RETURNING last_scan_seq").
+		RunWith(tx).
+		QueryRowContext(ctx).Scan(&last)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, errs.ErrNoReceptionsInProgress
+		}
+		return 0, err
+	}
+	return last - int64(n) + 1, nil
+}
+
+// lockReceptionInProgress locks the reception row until the end of the transaction, so it waits for scans in flight.
+// Can return ErrNoReceptionsInProgress if the reception is missing or already closed
+func lockReceptionInProgress(ctx context.Context, tx *sql.Tx, recID uuid.UUID) error {
+	var status api.ReceptionStatus
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	err := psql.Select("status").
+		From(receptionsTable).
+		Where(squirrel.Eq{"id": recID}).
+		Suffix("FOR UPDATE").
+		RunWith(tx).
+		QueryRowContext(ctx).Scan(&status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errs.ErrNoReceptionsInProgress
+		}
+		return err
+	}
+	if status != api.InProgress {
+		return errs.ErrNoReceptionsInProgress
+	}
+	return nil
+}
+
+// checkBarcodes makes sure none of the barcodes is already scanned into an open reception
+// or stored at the PVZ of the reception, issued products can come back as customer returns. Barcodes stay locked until the end of the transaction,
+// so concurrent scans of the same parcel are serialized. Can return ErrDuplicateBarcode
+func checkBarcodes(ctx context.Context, tx *sql.Tx, recID uuid.UUID, products []api.ProductInput) error {
+	barcodes := make([]string, 0, len(products))
+	for _, p := range products {
+		if p.Barcode != nil {
+			barcodes = append(barcodes, *p.Barcode)
+		}
+	}
+	if len(barcodes) == 0 {
+		return nil
+	}
+
+	// locks are taken in sorted order to avoid deadlocks between overlapping batches
+	_, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext(b)) FROM (SELECT DISTINCT unnest($1::text[]) AS b ORDER BY b) barcodes", pq.Array(barcodes))
+	if err != nil {
+		return err
+	}
+
+	var dup string
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	err = psql.Select("p.barcode").
+		From(productsTable + " p").
+		Join(receptionsTable + " r ON r.id = p.current_reception_id").
+		Where(squirrel.Eq{"p.barcode": barcodes, "p.deleted_at": nil, "p.state": []api.ProductState{api.ProductStateReceived, api.ProductStateStored}}).
+		Where(squirrel.Or{
+			squirrel.Eq{"r.status": "in_progress"},
+			squirrel.Expr("r.pvz_id = (SELECT pvz_id FROM "+receptionsTable+" WHERE id = ?)", recID),
+		}).
+		Limit(1).
+		RunWith(tx).
+		QueryRowContext(ctx).Scan(&dup)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	return fmt.Errorf("%w: %s", errs.ErrDuplicateBarcode, dup)
+}
+func (r *ReceptionPostgres) GetReceptionInProgress(ctx context.Context, pvzID uuid.UUID, kind api.ReceptionKind) (uuid.UUID, error) {
 	const op = "repository.pvz.ReceptionInProgress"
 
 	var id uuid.UUID
 	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 	err := psql.Select("id").
 		From(receptionsTable).
-		Where(squirrel.And{squirrel.Eq{"pvz_id": pvzID}, squirrel.Eq{"status": "in_progress"}}).
+		Where(squirrel.And{squirrel.Eq{"pvz_id": pvzID}, squirrel.Eq{"status": "in_progress"}, squirrel.Eq{"kind": kind}}).
 		RunWith(r.db).
-		QueryRow().Scan(&id)
+		QueryRowContext(ctx).Scan(&id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return uuid.Nil, errs.ErrNoReceptionsInProgress
@@ -70,25 +296,34 @@ func (r *ReceptionPostgres) GetReceptionInProgress(pvzID uuid.UUID) (uuid.UUID,
 	return id, nil
 }
 
-// DeleteLastProduct can return ErrNoProductsInReception
-func (r *ReceptionPostgres) DeleteLastProduct(recID uuid.UUID) error {
+// DeleteLastProduct marks the product of the reception with the highest scan number as deleted with the undo_last reason,
+// can return ErrNoProductsInReception and ErrNoReceptionsInProgress
+func (r *ReceptionPostgres) DeleteLastProduct(ctx context.Context, recID uuid.UUID) error {
 	const op = "repository.pvz.DeleteLastProduct"
 
-	tx, err := r.db.Begin()
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 	defer tx.Rollback()
 
+	// exclusive lock serializes concurrent undo requests, so each of them removes a different product
+	if err := lockReceptionInProgress(ctx, tx, recID); err != nil {
+		if errors.Is(err, errs.ErrNoReceptionsInProgress) {
+			return err
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
 	var lastProductID uuid.UUID
 	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 	err = psql.Select("id").
 		From("products").
-		Where(squirrel.Eq{"reception_id": recID}).
-		OrderBy("date DESC").
+		Where(squirrel.Eq{"reception_id": recID, "deleted_at": nil}).
+		OrderBy("scan_seq DESC").
 		Limit(1).
 		RunWith(tx).
-		QueryRow().
+		QueryRowContext(ctx).
 		Scan(&lastProductID)
 
 	if err != nil {
@@ -99,10 +334,12 @@ func (r *ReceptionPostgres) DeleteLastProduct(recID uuid.UUID) error {
 	}
 
 	if lastProductID != uuid.Nil {
-		_, err = psql.Delete("products").
+		_, err = psql.Update("products").
+			Set("deleted_at", squirrel.Expr("now()")).
+			Set("delete_reason", api.DeleteReasonUndoLast).
 			Where(squirrel.Eq{"id": lastProductID}).
 			RunWith(tx).
-			Exec()
+			ExecContext(ctx)
 
 		if err != nil {
 			return fmt.Errorf("%s: %w", op, err)
@@ -115,19 +352,537 @@ func (r *ReceptionPostgres) DeleteLastProduct(recID uuid.UUID) error {
 
 	return nil
 }
-func (r *ReceptionPostgres) CloseLastReception(recID uuid.UUID) (api.Reception, error) {
+
+// DeleteProduct marks a product of an in progress reception as deleted,
+// can return ErrReceptionNotFound, ErrReceptionClosed and ErrProductNotFound
+func (r *ReceptionPostgres) DeleteProduct(ctx context.Context, recID, prodID uuid.UUID, reason api.ProductDeleteReason, comment *string) (api.ProductDeletion, error) {
+	const op = "repository.reception.DeleteProduct"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return api.ProductDeletion{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var status api.ReceptionStatus
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	err = psql.Select("status").
+		From(receptionsTable).
+		Where(squirrel.Eq{"id": recID}).
+		Suffix("FOR SHARE").
+		RunWith(tx).
+		QueryRowContext(ctx).Scan(&status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return api.ProductDeletion{}, errs.ErrReceptionNotFound
+		}
+		return api.ProductDeletion{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if status != api.InProgress {
+		return api.ProductDeletion{}, errs.ErrReceptionClosed
+	}
+
+	var del api.ProductDeletion
+	err = psql.Update(productsTable).
+		Set("deleted_at", squirrel.Expr("now()")).
+		Set("delete_reason", reason).
+		Set("delete_comment", comment).
+		Where(squirrel.Eq{"id": prodID, "reception_id": recID, "deleted_at": nil}).
+		Suffix("RETURNING " + productColumns + ", deleted_at, delete_reason, delete_comment").
+		RunWith(tx).
+		QueryRowContext(ctx).Scan(append(productFields(&del.Product), &del.DeletedAt, &del.Reason, &del.Comment)...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return api.ProductDeletion{}, errs.ErrProductNotFound
+		}
+		return api.ProductDeletion{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return api.ProductDeletion{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return del, nil
+}
+
+// GetDeletedProducts returns products deleted from the reception in order of deletion,
+// can return ErrReceptionNotFound
+func (r *ReceptionPostgres) GetDeletedProducts(ctx context.Context, recID uuid.UUID) ([]api.ProductDeletion, error) {
+	const op = "repository.reception.GetDeletedProducts"
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	var exists bool
+	err := psql.Select("COUNT(*)>0").
+		From(receptionsTable).
+		Where(squirrel.Eq{"id": recID}).
+		RunWith(r.db).
+		QueryRowContext(ctx).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !exists {
+		return nil, errs.ErrReceptionNotFound
+	}
+
+	rows, err := psql.Select(productColumns, "deleted_at", "delete_reason", "delete_comment").
+		From(productsTable).
+		Where(squirrel.And{squirrel.Eq{"reception_id": recID}, squirrel.NotEq{"deleted_at": nil}}).
+		OrderBy("deleted_at").
+		RunWith(r.db).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	res := []api.ProductDeletion{}
+	for rows.Next() {
+		var del api.ProductDeletion
+		if err := rows.Scan(append(productFields(&del.Product), &del.DeletedAt, &del.Reason, &del.Comment)...); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		res = append(res, del)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return res, nil
+}
+
+// CloseLastReception waits for scans in flight, moves products of the reception to stored, stores discrepancies
+// with its manifest and pickup codes minted by mint, so a closed reception never misses its codes.
+// Can return ErrNoReceptionsInProgress if the reception was closed concurrently
+func (r *ReceptionPostgres) CloseLastReception(ctx context.Context, recID uuid.UUID, mint PickupCodeMinter) (api.Reception, []api.PickupCode, error) {
 	const op = "repository.pvz.CloseLastReception"
 
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return api.Reception{}, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
 	var rec api.Reception
 	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
-	err := psql.Update(receptionsTable).
+	err = psql.Update(receptionsTable+" r").
 		Set("status", "close").
+		Set("closed_at", squirrel.Expr("now()")).
+		Set("close_reason", api.CloseReasonManual).
+		Set("summary", squirrel.Expr(closeSummary)).
+		Where(squirrel.Eq{"id": recID, "status": api.InProgress}).
+		Suffix("RETURNING " + receptionColumns).
+		RunWith(tx).
+		QueryRowContext(ctx).Scan(receptionFields(&rec)...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return api.Reception{}, nil, errs.ErrNoReceptionsInProgress
+		}
+		return api.Reception{}, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := changeProductsState(ctx, tx, []uuid.UUID{recID}, api.ProductStateReceived, api.ProductStateStored); err != nil {
+		return api.Reception{}, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := createDiscrepancyReports(ctx, tx, []uuid.UUID{recID}); err != nil {
+		return api.Reception{}, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	codes, err := issueReceptionCodes(ctx, tx, []api.Reception{rec}, mint)
+	if err != nil {
+		return api.Reception{}, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return api.Reception{}, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return rec, codes, nil
+}
+
+// changeProductsState moves not deleted products of the receptions from one state to another
+func changeProductsState(ctx context.Context, tx *sql.Tx, recIDs []uuid.UUID, from, to api.ProductState) error {
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	_, err := psql.Update(productsTable).
+		Set("state", to).
+		Set("state_changed_at", squirrel.Expr("now()")).
+		Where(squirrel.Eq{"current_reception_id": recIDs, "state": from, "deleted_at": nil}).
+		RunWith(tx).
+		ExecContext(ctx)
+	return err
+}
+
+// Reopen puts a closed reception back in progress and records who reopened it and why.
+// A reception can be reopened only within window after closing and only if it is the latest one of its kind at the PVZ,
+// can return ErrReceptionNotFound, ErrReceptionNotClosed, ErrReopenWindowExpired and ErrNewerReceptionExists
+func (r *ReceptionPostgres) Reopen(ctx context.Context, recID, userID uuid.UUID, reason string, window time.Duration) (api.ReceptionReopen, error) {
+	const op = "repository.reception.Reopen"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return api.ReceptionReopen{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var (
+		rec          api.Reception
+		withinWindow bool
+	)
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	err = psql.Select(receptionColumns).
+		Column("closed_at IS NOT NULL AND closed_at >= now() - make_interval(secs => ?)", window.Seconds()).
+		From(receptionsTable).
+		Where(squirrel.Eq{"id": recID}).
+		Suffix("FOR UPDATE").
+		RunWith(tx).
+		QueryRowContext(ctx).Scan(append(receptionFields(&rec), &withinWindow)...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return api.ReceptionReopen{}, errs.ErrReceptionNotFound
+		}
+		return api.ReceptionReopen{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if rec.Status != api.Close {
+		return api.ReceptionReopen{}, errs.ErrReceptionNotClosed
+	}
+	if !withinWindow {
+		return api.ReceptionReopen{}, errs.ErrReopenWindowExpired
+	}
+
+	var newerExists bool
+	err = psql.Select("COUNT(*)>0").
+		From(receptionsTable).
+		Where(squirrel.Eq{"pvz_id": rec.PvzId, "kind": rec.Kind}).
+		Where(squirrel.Gt{"date": rec.DateTime}).
+		RunWith(tx).
+		QueryRowContext(ctx).Scan(&newerExists)
+	if err != nil {
+		return api.ReceptionReopen{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if newerExists {
+		return api.ReceptionReopen{}, errs.ErrNewerReceptionExists
+	}
+
+	reopen := api.ReceptionReopen{Reason: reason, PreviousClosedAt: *rec.ClosedAt}
+	if userID != uuid.Nil {
+		reopen.ReopenedBy = &userID
+	}
+	err = psql.Update(receptionsTable).
+		Set("status", api.InProgress).
+		Set("closed_at", nil).
+		Set("close_reason", nil).
+		Set("stale_flagged_at", nil).
+		Set("summary", nil).
+		Where(squirrel.Eq{"id": recID}).
+		Suffix("RETURNING " + receptionColumns).
+		RunWith(tx).
+		QueryRowContext(ctx).Scan(receptionFields(&reopen.Reception)...)
+	if err != nil {
+		if isUniqueViolation(err, receptionInProgressIndex) {
+			return api.ReceptionReopen{}, errs.ErrReceptionNotClosed
+		}
+		return api.ReceptionReopen{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// products issued in the meantime stay issued
+	if err := changeProductsState(ctx, tx, []uuid.UUID{recID}, api.ProductStateStored, api.ProductStateReceived); err != nil {
+		return api.ReceptionReopen{}, fmt.Errorf("%s: %w", op, err)
+	}
+	// the report is created again on the next close
+	_, err = psql.Delete(discrepanciesTable).
+		Where(squirrel.Eq{"reception_id": recID}).
+		RunWith(tx).
+		ExecContext(ctx)
+	if err != nil {
+		return api.ReceptionReopen{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = psql.Insert(receptionReopensTable).
+		Columns("reception_id", "reopened_by", "reason", "previous_closed_at").
+		Values(recID, reopen.ReopenedBy, reason, reopen.PreviousClosedAt).
+		Suffix("RETURNING reopened_at").
+		RunWith(tx).
+		QueryRowContext(ctx).Scan(&reopen.ReopenedAt)
+	if err != nil {
+		return api.ReceptionReopen{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return api.ReceptionReopen{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return reopen, nil
+}
+
+// CloseStale closes in progress receptions without scans or deletions for longer than idleFor
+// with the auto_closed reason, moves their products to stored and stores discrepancies with their manifests
+// and pickup codes minted by mint.
+// Returns nothing if another replica is sweeping at the moment
+func (r *ReceptionPostgres) CloseStale(ctx context.Context, idleFor time.Duration, mint PickupCodeMinter) ([]api.Reception, []api.PickupCode, error) {
+	const op = "repository.reception.CloseStale"
+
+	var codes []api.PickupCode
+	recs, err := r.sweepStale(ctx, func(q squirrel.UpdateBuilder) squirrel.UpdateBuilder {
+		return q.Set("status", api.Close).
+			Set("closed_at", squirrel.Expr("now()")).
+			Set("close_reason", api.CloseReasonAutoClosed).
+			Set("summary", squirrel.Expr(closeSummary))
+	}, func(tx *sql.Tx, recs []api.Reception) error {
+		if len(recs) == 0 {
+			return nil
+		}
+		ids := make([]uuid.UUID, 0, len(recs))
+		for _, rec := range recs {
+			ids = append(ids, *rec.Id)
+		}
+		if err := changeProductsState(ctx, tx, ids, api.ProductStateReceived, api.ProductStateStored); err != nil {
+			return err
+		}
+		if err := createDiscrepancyReports(ctx, tx, ids); err != nil {
+			return err
+		}
+		var err error
+		codes, err = issueReceptionCodes(ctx, tx, recs, mint)
+		return err
+	}, idleFor)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return recs, codes, nil
+}
+
+// FlagStale marks in progress receptions without scans or deletions for longer than idleFor,
+// every reception is flagged once. Returns nothing if another replica is sweeping at the moment
+func (r *ReceptionPostgres) FlagStale(ctx context.Context, idleFor time.Duration) ([]api.Reception, error) {
+	const op = "repository.reception.FlagStale"
+
+	recs, err := r.sweepStale(ctx, func(q squirrel.UpdateBuilder) squirrel.UpdateBuilder {
+		return q.Set("stale_flagged_at", squirrel.Expr("now()")).
+			Where(squirrel.Eq{"r.stale_flagged_at": nil})
+	}, nil, idleFor)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return recs, nil
+}
+
+// sweepStale applies the update built by set to stale receptions while holding staleReceptionsLockKey,
+// after is called with updated receptions in the same transaction if not nil
+func (r *ReceptionPostgres) sweepStale(ctx context.Context, set func(squirrel.UpdateBuilder) squirrel.UpdateBuilder, after func(*sql.Tx, []api.Reception) error, idleFor time.Duration) ([]api.Reception, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err := tx.QueryRowContext(ctx, "SELECT pg_try_advisory_xact_lock($1)", staleReceptionsLockKey).Scan(&locked); err != nil {
+		return nil, err
+	}
+	if !locked {
+		return nil, nil
+	}
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	query := set(psql.Update(receptionsTable+" r")).
+		Where(squirrel.Eq{"r.status": api.InProgress}).
+		Where(lastActivity+" < now() - make_interval(secs => ?)", idleFor.Seconds()).
+		Suffix("RETURNING " + receptionColumns)
+	rows, err := query.RunWith(tx).QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []api.Reception{}
+	for rows.Next() {
+		var rec api.Reception
+		if err := rows.Scan(receptionFields(&rec)...); err != nil {
+			return nil, err
+		}
+		res = append(res, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if after != nil {
+		if err := after(tx, res); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ReturnsReport counts not deleted products of customer return receptions opened within the period,
+// grouped by PVZ and return condition
+func (r *ReceptionPostgres) ReturnsReport(ctx context.Context, params api.GetReportsReturnsParams) ([]api.ReturnsReportRow, error) {
+	const op = "repository.reception.ReturnsReport"
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	query := psql.Select("r.pvz_id", "p.return_condition", "COUNT(DISTINCT r.id)", "COUNT(*)").
+		From(productsTable + " p").
+		Join(receptionsTable + " r ON r.id = p.reception_id").
+		Where(squirrel.Eq{"r.kind": api.ReceptionKindCustomerReturn, "p.deleted_at": nil}).
+		Where(squirrel.NotEq{"p.return_condition": nil})
+	if params.StartDate != nil {
+		query = query.Where(squirrel.GtOrEq{"r.date": *params.StartDate})
+	}
+	if params.EndDate != nil {
+		query = query.Where(squirrel.LtOrEq{"r.date": *params.EndDate})
+	}
+	if params.PvzId != nil {
+		query = query.Where(squirrel.Eq{"r.pvz_id": *params.PvzId})
+	}
+	rows, err := query.GroupBy("r.pvz_id", "p.return_condition").
+		OrderBy("r.pvz_id", "p.return_condition").
+		RunWith(r.db).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	res := []api.ReturnsReportRow{}
+	for rows.Next() {
+		var row api.ReturnsReportRow
+		if err := rows.Scan(&row.PvzId, &row.Condition, &row.Receptions, &row.Products); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		res = append(res, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return res, nil
+}
+
+// List returns receptions matching all given filters, newest first
+func (r *ReceptionPostgres) List(ctx context.Context, params api.GetReceptionsParams) ([]api.Reception, error) {
+	const op = "repository.reception.List"
+
+	limit := defaultReceptionsLimit
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+	offset := 0
+	if params.Page != nil {
+		offset = (*params.Page - 1) * limit
+	}
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	query := psql.Select(receptionColumns).From(receptionsTable)
+	if params.PvzId != nil {
+		query = query.Where(squirrel.Eq{"pvz_id": *params.PvzId})
+	}
+	if params.Status != nil {
+		query = query.Where(squirrel.Eq{"status": *params.Status})
+	}
+	if params.Kind != nil {
+		query = query.Where(squirrel.Eq{"kind": *params.Kind})
+	}
+	if params.CreatedBy != nil {
+		query = query.Where(squirrel.Eq{"created_by": *params.CreatedBy})
+	}
+	if params.StartDate != nil {
+		query = query.Where(squirrel.GtOrEq{"date": *params.StartDate})
+	}
+	if params.EndDate != nil {
+		query = query.Where(squirrel.LtOrEq{"date": *params.EndDate})
+	}
+	rows, err := query.OrderBy("date DESC", "id").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		RunWith(r.db).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	res := []api.Reception{}
+	for rows.Next() {
+		var rec api.Reception
+		if err := rows.Scan(receptionFields(&rec)...); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		res = append(res, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return res, nil
+}
+
+// GetByID can return ErrReceptionNotFound
+func (r *ReceptionPostgres) GetByID(ctx context.Context, recID uuid.UUID) (api.Reception, error) {
+	const op = "repository.reception.GetByID"
+
+	var rec api.Reception
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	err := psql.Select(receptionColumns).
+		From(receptionsTable).
 		Where(squirrel.Eq{"id": recID}).
-		Suffix("RETURNING id, date, pvz_id, status").
 		RunWith(r.db).
-		QueryRow().Scan(&rec.Id, &rec.DateTime, &rec.PvzId, &rec.Status)
+		QueryRowContext(ctx).Scan(receptionFields(&rec)...)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return api.Reception{}, errs.ErrReceptionNotFound
+		}
 		return api.Reception{}, fmt.Errorf("%s: %w", op, err)
 	}
 	return rec, nil
 }
+
+// GetProducts returns a page of not deleted products of the reception in scan order and their total count,
+// products scanned up to AfterSeq are skipped but still counted in the total
+func (r *ReceptionPostgres) GetProducts(ctx context.Context, recID uuid.UUID, params api.GetReceptionsReceptionIdParams) ([]api.Product, int, error) {
+	const op = "repository.reception.GetProducts"
+
+	limit := defaultReceptionsLimit
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+	offset := 0
+	if params.Page != nil {
+		offset = (*params.Page - 1) * limit
+	}
+
+	var total int
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	err := psql.Select("COUNT(*)").
+		From(productsTable).
+		Where(squirrel.Eq{"reception_id": recID, "deleted_at": nil}).
+		RunWith(r.db).
+		QueryRowContext(ctx).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query := psql.Select(productColumns).
+		From(productsTable).
+		Where(squirrel.Eq{"reception_id": recID, "deleted_at": nil})
+	if params.AfterSeq != nil {
+		query = query.Where(squirrel.Gt{"scan_seq": *params.AfterSeq})
+	}
+	rows, err := query.OrderBy("scan_seq").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		RunWith(r.db).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	res := []api.Product{}
+	for rows.Next() {
+		var prod api.Product
+		if err := rows.Scan(productFields(&prod)...); err != nil {
+			return nil, 0, fmt.Errorf("%s: %w", op, err)
+		}
+		res = append(res, prod)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return res, total, nil
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// These tests need a real postgres with all migrations applied, e.g. the one from docker-compose:
+// TEST_DATABASE_DSN="host=localhost port=5432 user=postgres password=password dbname=pvz-service sslmode=disable"
+const testDSNEnv = "TEST_DATABASE_DSN"
+
+const parallelRequests = 32
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNEnv)
+	}
+	db, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	require.NoError(t, db.Ping())
+	db.SetMaxOpenConns(parallelRequests + 1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func createTestPVZ(t *testing.T, db *sql.DB) uuid.UUID {
+	t.Helper()
+	pvz, err := NewPVZPostgres(db).Create(context.Background(), api.PVZ{City: api.Kazan})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Exec("DELETE FROM pvzs WHERE id = $1", *pvz.Id) })
+	return *pvz.Id
+}
+
+// runParallel starts n calls of f at the same moment and waits for all of them
+func runParallel(n int, f func(i int)) {
+	var start, done sync.WaitGroup
+	start.Add(1)
+	done.Add(n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer done.Done()
+			start.Wait()
+			f(i)
+		}(i)
+	}
+	start.Done()
+	done.Wait()
+}
+
+func TestReceptionPostgres_ConcurrentCreate(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewReceptionPostgres(db)
+	pvzID := createTestPVZ(t, db)
+
+	kinds := []api.ReceptionKind{api.ReceptionKindInbound, api.ReceptionKindCustomerReturn}
+	errCh := make(chan error, parallelRequests)
+	runParallel(parallelRequests, func(i int) {
+		_, err := repo.Create(context.Background(), pvzID, kinds[i%len(kinds)], uuid.Nil)
+		errCh <- err
+	})
+	close(errCh)
+
+	created := 0
+	for err := range errCh {
+		if err == nil {
+			created++
+			continue
+		}
+		assert.ErrorIs(t, err, errs.ErrReceptionNotClosed)
+	}
+	assert.Equal(t, len(kinds), created, "one reception of each kind")
+
+	var inProgress int
+	require.NoError(t, db.QueryRow("SELECT COUNT(DISTINCT kind) FROM receptions WHERE pvz_id = $1 AND status = 'in_progress'", pvzID).Scan(&inProgress))
+	assert.Equal(t, len(kinds), inProgress)
+}
+
+func TestReceptionPostgres_ConcurrentScansAndClose(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewReceptionPostgres(db)
+	pvzID := createTestPVZ(t, db)
+	rec, err := repo.Create(context.Background(), pvzID, api.ReceptionKindInbound, uuid.Nil)
+	require.NoError(t, err)
+
+	var (
+		mu    sync.Mutex
+		added int
+	)
+	runParallel(parallelRequests, func(i int) {
+		if i == parallelRequests/2 {
+			_, _, err := repo.CloseLastReception(context.Background(), *rec.Id, nil)
+			assert.NoError(t, err)
+			return
+		}
+		_, err := repo.AddProduct(context.Background(), *rec.Id, api.ProductInput{Type: api.ProductTypeShoes})
+		if err != nil {
+			assert.ErrorIs(t, err, errs.ErrNoReceptionsInProgress)
+			return
+		}
+		mu.Lock()
+		added++
+		mu.Unlock()
+	})
+
+	// every scan either made it before closing or was rejected, nothing is lost or added silently
+	var stored int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM products WHERE reception_id = $1", *rec.Id).Scan(&stored))
+	assert.Equal(t, added, stored)
+
+	_, err = repo.AddProduct(context.Background(), *rec.Id, api.ProductInput{Type: api.ProductTypeShoes})
+	assert.ErrorIs(t, err, errs.ErrNoReceptionsInProgress)
+}
+
+func TestReceptionPostgres_ConcurrentDeleteLastProduct(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewReceptionPostgres(db)
+	pvzID := createTestPVZ(t, db)
+	rec, err := repo.Create(context.Background(), pvzID, api.ReceptionKindInbound, uuid.Nil)
+	require.NoError(t, err)
+
+	const products = parallelRequests / 2
+	for i := 0; i < products; i++ {
+		_, err := repo.AddProduct(context.Background(), *rec.Id, api.ProductInput{Type: api.ProductTypeClothes})
+		require.NoError(t, err)
+	}
+
+	errCh := make(chan error, parallelRequests)
+	runParallel(parallelRequests, func(int) {
+		errCh <- repo.DeleteLastProduct(context.Background(), *rec.Id)
+	})
+	close(errCh)
+
+	deleted := 0
+	for err := range errCh {
+		if err == nil {
+			deleted++
+			continue
+		}
+		assert.ErrorIs(t, err, errs.ErrNoProductsInReception)
+	}
+	assert.Equal(t, products, deleted, "every undo must remove a different product")
+
+	var left int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM products WHERE reception_id = $1 AND deleted_at IS NULL", *rec.Id).Scan(&left))
+	assert.Zero(t, left)
+}
@@ -1,7 +1,9 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"testing"
 	"time"
@@ -10,10 +12,36 @@ import (
 	"github.com/ST359/pvz-service/internal/api"
 	errs "github.com/ST359/pvz-service/internal/app_errors"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+var receptionTestColumns = []string{"id", "date", "pvz_id", "status", "closed_at", "close_reason", "stale_flagged_at", "kind", "created_by", "summary"}
+
+// expectReceptionLock expects the reception row to be locked with the given lock strength
+func expectReceptionLock(mock sqlmock.Sqlmock, recID uuid.UUID, lock string, status api.ReceptionStatus) {
+	mock.ExpectQuery("SELECT status FROM receptions WHERE id = \\$1 " + lock).
+		WithArgs(recID).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(status))
+}
+
+// expectScanSeqs expects n scan numbers to be given out by the reception, last is the counter after the update
+// and zero means the reception is not in progress
+func expectScanSeqs(mock sqlmock.Sqlmock, recID uuid.UUID, n int, last int64) {
+	q := mock.ExpectQuery("UPDATE receptions SET last_scan_seq = last_scan_seq \\+ \\$1 WHERE id = \\$2 AND status = \\$3 RETURNING last_scan_seq").
+		WithArgs(n, recID, api.InProgress)
+	if last == 0 {
+		q.WillReturnError(sql.ErrNoRows)
+		return
+	}
+	q.WillReturnRows(sqlmock.NewRows([]string{"last_scan_seq"}).AddRow(last))
+}
+
+func ptrTo[T any](v T) *T {
+	return &v
+}
+
 func TestReceptionPostgres_Create(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
@@ -22,50 +50,70 @@ func TestReceptionPostgres_Create(t *testing.T) {
 	repo := NewReceptionPostgres(db)
 	pvzID := uuid.New()
 	recID := uuid.New()
+	userID := uuid.New()
 	now := time.Now()
 
 	tests := []struct {
 		name        string
 		pvzID       uuid.UUID
+		kind        api.ReceptionKind
+		userID      uuid.UUID
 		mockSetup   func()
 		expected    api.Reception
 		expectedErr error
 	}{
 		{
-			name:  "successful creation",
-			pvzID: pvzID,
+			name:   "successful creation",
+			pvzID:  pvzID,
+			kind:   api.ReceptionKindCustomerReturn,
+			userID: userID,
 			mockSetup: func() {
-				rows := sqlmock.NewRows([]string{"id", "date", "pvz_id", "status"}).
-					AddRow(recID, now, pvzID, "in_progress")
-				mock.ExpectQuery("INSERT INTO receptions").
-					WithArgs(pvzID).
+				rows := sqlmock.NewRows(receptionTestColumns).
+					AddRow(recID, now, pvzID, "in_progress", nil, nil, nil, api.ReceptionKindCustomerReturn, userID, nil)
+				mock.ExpectQuery("INSERT INTO receptions \\(pvz_id,kind,created_by\\) VALUES \\(\\$1,\\$2,\\$3\\)").
+					WithArgs(pvzID, api.ReceptionKindCustomerReturn, userID).
 					WillReturnRows(rows)
 			},
 			expected: api.Reception{
-				Id:       &recID,
-				DateTime: now,
-				PvzId:    pvzID,
-				Status:   "in_progress",
+				Id:        &recID,
+				DateTime:  now,
+				PvzId:     pvzID,
+				Status:    "in_progress",
+				Kind:      api.ReceptionKindCustomerReturn,
+				CreatedBy: &userID,
 			},
 			expectedErr: nil,
 		},
 		{
 			name:  "database error",
 			pvzID: pvzID,
+			kind:  api.ReceptionKindInbound,
 			mockSetup: func() {
 				mock.ExpectQuery("INSERT INTO receptions").
-					WithArgs(pvzID).
+					WithArgs(pvzID, api.ReceptionKindInbound, nil).
 					WillReturnError(sql.ErrConnDone)
 			},
 			expected:    api.Reception{},
 			expectedErr: errors.New("repository.reception.Create: sql: connection is already closed"),
 		},
+		{
+			name:  "another reception of the kind opened concurrently",
+			pvzID: pvzID,
+			kind:  api.ReceptionKindInbound,
+			mockSetup: func() {
+				mock.ExpectQuery("INSERT INTO receptions").
+					WithArgs(pvzID, api.ReceptionKindInbound, nil).
+					WillReturnError(&pq.Error{Code: uniqueViolationCode, Constraint: receptionInProgressIndex})
+			},
+			expected:    api.Reception{},
+			expectedErr: errs.ErrReceptionNotClosed,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
-			result, err := repo.Create(tt.pvzID)
+			result, err := repo.Create(context.Background(), tt.pvzID, tt.kind, tt.userID)
 
 			if tt.expectedErr != nil {
 				assert.Error(t, err)
@@ -87,54 +135,243 @@ func TestReceptionPostgres_AddProduct(t *testing.T) {
 	repo := NewReceptionPostgres(db)
 	recID := uuid.New()
 	prodID := uuid.New()
+	pvzID := uuid.New()
+	cellID := uuid.New()
 	now := time.Now()
 	prodType := api.ProductTypeElectronics
+	barcode := "4607001234567"
+	productColumnNames := []string{"id", "date", "reception_id", "type", "barcode", "external_order_id", "state", "state_changed_at", "return_condition", "original_order_id", "cell_id", "scan_seq", "condition"}
 
 	tests := []struct {
 		name        string
 		recID       uuid.UUID
-		prodType    api.ProductType
+		product     api.ProductInput
 		mockSetup   func()
 		expected    api.Product
 		expectedErr error
 	}{
 		{
-			name:     "successful add product",
-			recID:    recID,
-			prodType: prodType,
+			name:    "successful add product",
+			recID:   recID,
+			product: api.ProductInput{Type: prodType},
 			mockSetup: func() {
-				rows := sqlmock.NewRows([]string{"id", "date", "reception_id", "type"}).
-					AddRow(prodID, now, recID, prodType)
+				rows := sqlmock.NewRows(productColumnNames).
+					AddRow(prodID, now, recID, prodType, nil, nil, api.ProductStateReceived, nil, nil, nil, nil, 5, "ok")
+				mock.ExpectBegin()
+				expectScanSeqs(mock, recID, 1, 5)
 				mock.ExpectQuery("INSERT INTO products").
-					WithArgs(recID, prodType).
+					WithArgs(recID, recID, prodType, nil, nil, nil, nil, nil, int64(5)).
+					WillReturnRows(rows)
+				mock.ExpectCommit()
+			},
+			expected: api.Product{
+				Id:          &prodID,
+				DateTime:    &now,
+				ReceptionId: recID,
+				Type:        prodType,
+				State:       api.ProductStateReceived,
+				ScanSeq:     ptrTo(int64(5)),
+				Condition:   ptrTo(api.ConditionOk),
+			},
+			expectedErr: nil,
+		},
+		{
+			name:    "successful add customer return",
+			recID:   recID,
+			product: api.ProductInput{Type: prodType, ReturnCondition: ptrTo(api.ReturnConditionOpened), OriginalOrderId: ptrTo("ORD-1")},
+			mockSetup: func() {
+				rows := sqlmock.NewRows(productColumnNames).
+					AddRow(prodID, now, recID, prodType, nil, nil, api.ProductStateReceived, nil, api.ReturnConditionOpened, "ORD-1", nil, 5, "ok")
+				mock.ExpectBegin()
+				expectScanSeqs(mock, recID, 1, 5)
+				mock.ExpectQuery("INSERT INTO products \\(reception_id,current_reception_id,type,barcode,external_order_id,return_condition,original_order_id,cell_id,scan_seq\\)").
+					WithArgs(recID, recID, prodType, nil, nil, ptrTo(api.ReturnConditionOpened), ptrTo("ORD-1"), nil, int64(5)).
 					WillReturnRows(rows)
+				mock.ExpectCommit()
+			},
+			expected: api.Product{
+				Id:              &prodID,
+				DateTime:        &now,
+				ReceptionId:     recID,
+				Type:            prodType,
+				State:           api.ProductStateReceived,
+				ReturnCondition: ptrTo(api.ReturnConditionOpened),
+				OriginalOrderId: ptrTo("ORD-1"),
+				ScanSeq:         ptrTo(int64(5)),
+				Condition:       ptrTo(api.ConditionOk),
+			},
+			expectedErr: nil,
+		},
+		{
+			name:    "scanned into full cell",
+			recID:   recID,
+			product: api.ProductInput{Type: prodType, CellId: &cellID},
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectScanSeqs(mock, recID, 1, 5)
+				mock.ExpectQuery("SELECT pvz_id FROM receptions WHERE id = \\$1").
+					WithArgs(recID).
+					WillReturnRows(sqlmock.NewRows([]string{"pvz_id"}).AddRow(pvzID))
+				mock.ExpectQuery("SELECT capacity, active FROM storage_cells").
+					WithArgs(cellID, pvzID).
+					WillReturnRows(sqlmock.NewRows([]string{"capacity", "active"}).AddRow(5, true))
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM products WHERE cell_id = \\$1").
+					WithArgs(cellID, api.ProductStateReceived, api.ProductStateStored).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrStorageCellFull,
+		},
+		{
+			name:    "successful add product with barcode",
+			recID:   recID,
+			product: api.ProductInput{Type: prodType, Barcode: &barcode},
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectScanSeqs(mock, recID, 1, 5)
+				mock.ExpectExec("SELECT pg_advisory_xact_lock").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("SELECT p.barcode FROM products p JOIN receptions r").
+					WithArgs(barcode, api.ProductStateReceived, api.ProductStateStored, "in_progress", recID).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery("INSERT INTO products").
+					WithArgs(recID, recID, prodType, barcode, nil, nil, nil, nil, int64(5)).
+					WillReturnRows(sqlmock.NewRows(productColumnNames).
+						AddRow(prodID, now, recID, prodType, barcode, nil, api.ProductStateReceived, nil, nil, nil, nil, 5, "ok"))
+				mock.ExpectCommit()
 			},
 			expected: api.Product{
 				Id:          &prodID,
 				DateTime:    &now,
 				ReceptionId: recID,
 				Type:        prodType,
+				Barcode:     &barcode,
+				State:       api.ProductStateReceived,
+				ScanSeq:     ptrTo(int64(5)),
+				Condition:   ptrTo(api.ConditionOk),
 			},
 			expectedErr: nil,
 		},
 		{
-			name:     "database error",
-			recID:    recID,
-			prodType: prodType,
+			name:    "barcode already scanned",
+			recID:   recID,
+			product: api.ProductInput{Type: prodType, Barcode: &barcode},
 			mockSetup: func() {
+				mock.ExpectBegin()
+				expectScanSeqs(mock, recID, 1, 5)
+				mock.ExpectExec("SELECT pg_advisory_xact_lock").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("SELECT p.barcode FROM products p JOIN receptions r").
+					WithArgs(barcode, api.ProductStateReceived, api.ProductStateStored, "in_progress", recID).
+					WillReturnRows(sqlmock.NewRows([]string{"barcode"}).AddRow(barcode))
+				mock.ExpectRollback()
+			},
+			expected:    api.Product{},
+			expectedErr: errs.ErrDuplicateBarcode,
+		},
+		{
+			name:    "database error",
+			recID:   recID,
+			product: api.ProductInput{Type: prodType},
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectScanSeqs(mock, recID, 1, 5)
 				mock.ExpectQuery("INSERT INTO products").
-					WithArgs(recID, prodType).
+					WithArgs(recID, recID, prodType, nil, nil, nil, nil, nil, int64(5)).
 					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
 			},
 			expected:    api.Product{},
 			expectedErr: errors.New("repository.reception.AddProduct: sql: connection is already closed"),
 		},
+		{
+			name:    "reception closed before the scan",
+			recID:   recID,
+			product: api.ProductInput{Type: prodType},
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectScanSeqs(mock, recID, 1, 0)
+				mock.ExpectRollback()
+			},
+			expected:    api.Product{},
+			expectedErr: errs.ErrNoReceptionsInProgress,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			result, err := repo.AddProduct(context.Background(), tt.recID, tt.product)
+
+			if tt.expectedErr != nil {
+				assert.Error(t, err)
+				if errors.Is(tt.expectedErr, errs.ErrDuplicateBarcode) {
+					assert.ErrorIs(t, err, errs.ErrDuplicateBarcode)
+				} else {
+					assert.Equal(t, tt.expectedErr.Error(), err.Error())
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReceptionPostgres_AddProducts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewReceptionPostgres(db)
+	recID := uuid.New()
+	firstID, secondID := uuid.New(), uuid.New()
+	now := time.Now()
+	items := []api.ProductInput{{Type: api.ProductTypeElectronics}, {Type: api.ProductTypeShoes}}
+	productColumnNames := []string{"id", "date", "reception_id", "type", "barcode", "external_order_id", "state", "state_changed_at", "return_condition", "original_order_id", "cell_id", "scan_seq", "condition"}
+
+	tests := []struct {
+		name        string
+		mockSetup   func()
+		expected    []api.Product
+		expectedErr error
+	}{
+		{
+			name: "single insert for the whole batch",
+			mockSetup: func() {
+				rows := sqlmock.NewRows(productColumnNames).
+					AddRow(firstID, now, recID, api.ProductTypeElectronics, nil, nil, api.ProductStateReceived, nil, nil, nil, nil, 6, "ok").
+					AddRow(secondID, now, recID, api.ProductTypeShoes, nil, nil, api.ProductStateReceived, nil, nil, nil, nil, 7, "ok")
+				mock.ExpectBegin()
+				expectScanSeqs(mock, recID, 2, 7)
+				mock.ExpectQuery("INSERT INTO products \\(reception_id,current_reception_id,type,barcode,external_order_id,return_condition,original_order_id,cell_id,scan_seq\\) VALUES \\(\\$1,\\$2,\\$3,\\$4,\\$5,\\$6,\\$7,\\$8,\\$9\\),\\(\\$10,\\$11,\\$12,\\$13,\\$14,\\$15,\\$16,\\$17,\\$18\\)").
+					WithArgs(recID, recID, api.ProductTypeElectronics, nil, nil, nil, nil, nil, int64(6), recID, recID, api.ProductTypeShoes, nil, nil, nil, nil, nil, int64(7)).
+					WillReturnRows(rows)
+				mock.ExpectCommit()
+			},
+			expected: []api.Product{
+				{Id: &firstID, DateTime: &now, ReceptionId: recID, Type: api.ProductTypeElectronics, State: api.ProductStateReceived, ScanSeq: ptrTo(int64(6)), Condition: ptrTo(api.ConditionOk)},
+				{Id: &secondID, DateTime: &now, ReceptionId: recID, Type: api.ProductTypeShoes, State: api.ProductStateReceived, ScanSeq: ptrTo(int64(7)), Condition: ptrTo(api.ConditionOk)},
+			},
+		},
+		{
+			name: "database error",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectScanSeqs(mock, recID, 2, 7)
+				mock.ExpectQuery("INSERT INTO products").
+					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
+			},
+			expectedErr: errors.New("repository.reception.AddProducts: sql: connection is already closed"),
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
-			result, err := repo.AddProduct(tt.recID, tt.prodType)
+			result, err := repo.AddProducts(context.Background(), recID, items)
 
 			if tt.expectedErr != nil {
 				assert.Error(t, err)
@@ -169,8 +406,8 @@ func TestReceptionPostgres_GetReceptionInProgress(t *testing.T) {
 			pvzID: pvzID,
 			mockSetup: func() {
 				rows := sqlmock.NewRows([]string{"id"}).AddRow(recID)
-				mock.ExpectQuery("SELECT id FROM receptions").
-					WithArgs(pvzID, "in_progress").
+				mock.ExpectQuery("SELECT id FROM receptions WHERE \\(pvz_id = \\$1 AND status = \\$2 AND kind = \\$3\\)").
+					WithArgs(pvzID, "in_progress", api.ReceptionKindCustomerReturn).
 					WillReturnRows(rows)
 			},
 			expected:    recID,
@@ -181,7 +418,7 @@ func TestReceptionPostgres_GetReceptionInProgress(t *testing.T) {
 			pvzID: pvzID,
 			mockSetup: func() {
 				mock.ExpectQuery("SELECT id FROM receptions").
-					WithArgs(pvzID, "in_progress").
+					WithArgs(pvzID, "in_progress", api.ReceptionKindCustomerReturn).
 					WillReturnError(sql.ErrNoRows)
 			},
 			expected:    uuid.Nil,
@@ -192,7 +429,7 @@ func TestReceptionPostgres_GetReceptionInProgress(t *testing.T) {
 			pvzID: pvzID,
 			mockSetup: func() {
 				mock.ExpectQuery("SELECT id FROM receptions").
-					WithArgs(pvzID, "in_progress").
+					WithArgs(pvzID, "in_progress", api.ReceptionKindCustomerReturn).
 					WillReturnError(sql.ErrConnDone)
 			},
 			expected:    uuid.Nil,
@@ -203,7 +440,7 @@ func TestReceptionPostgres_GetReceptionInProgress(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
-			result, err := repo.GetReceptionInProgress(tt.pvzID)
+			result, err := repo.GetReceptionInProgress(context.Background(), tt.pvzID, api.ReceptionKindCustomerReturn)
 
 			if tt.expectedErr != nil {
 				assert.Error(t, err)
@@ -242,12 +479,13 @@ func TestReceptionPostgres_DeleteLastProduct(t *testing.T) {
 			recID: recID,
 			mockSetup: func() {
 				mock.ExpectBegin()
+				expectReceptionLock(mock, recID, "FOR UPDATE", api.InProgress)
 				rows := sqlmock.NewRows([]string{"id"}).AddRow(prodID)
-				mock.ExpectQuery("SELECT id FROM products").
+				mock.ExpectQuery("SELECT id FROM products WHERE deleted_at IS NULL AND reception_id = \\$1 ORDER BY scan_seq DESC LIMIT 1").
 					WithArgs(recID).
 					WillReturnRows(rows)
-				mock.ExpectExec("DELETE FROM products").
-					WithArgs(prodID).
+				mock.ExpectExec("UPDATE products SET deleted_at = now\\(\\), delete_reason = \\$1").
+					WithArgs(api.DeleteReasonUndoLast, prodID).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 				mock.ExpectCommit()
 			},
@@ -258,6 +496,7 @@ func TestReceptionPostgres_DeleteLastProduct(t *testing.T) {
 			recID: recID,
 			mockSetup: func() {
 				mock.ExpectBegin()
+				expectReceptionLock(mock, recID, "FOR UPDATE", api.InProgress)
 				mock.ExpectQuery("SELECT id FROM products").
 					WithArgs(recID).
 					WillReturnError(sql.ErrNoRows)
@@ -270,6 +509,7 @@ func TestReceptionPostgres_DeleteLastProduct(t *testing.T) {
 			recID: recID,
 			mockSetup: func() {
 				mock.ExpectBegin()
+				expectReceptionLock(mock, recID, "FOR UPDATE", api.InProgress)
 				mock.ExpectQuery("SELECT id FROM products").
 					WithArgs(recID).
 					WillReturnError(sql.ErrConnDone)
@@ -278,16 +518,17 @@ func TestReceptionPostgres_DeleteLastProduct(t *testing.T) {
 			expectedErr: errors.New("repository.pvz.DeleteLastProduct: sql: connection is already closed"),
 		},
 		{
-			name:  "database error during delete",
+			name:  "database error during soft delete",
 			recID: recID,
 			mockSetup: func() {
 				mock.ExpectBegin()
+				expectReceptionLock(mock, recID, "FOR UPDATE", api.InProgress)
 				rows := sqlmock.NewRows([]string{"id"}).AddRow(prodID)
 				mock.ExpectQuery("SELECT id FROM products").
 					WithArgs(recID).
 					WillReturnRows(rows)
-				mock.ExpectExec("DELETE FROM products").
-					WithArgs(prodID).
+				mock.ExpectExec("UPDATE products SET deleted_at = now\\(\\), delete_reason = \\$1").
+					WithArgs(api.DeleteReasonUndoLast, prodID).
 					WillReturnError(sql.ErrConnDone)
 				mock.ExpectRollback()
 			},
@@ -298,7 +539,7 @@ func TestReceptionPostgres_DeleteLastProduct(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
-			err := repo.DeleteLastProduct(tt.recID)
+			err := repo.DeleteLastProduct(context.Background(), tt.recID)
 
 			if tt.expectedErr != nil {
 				assert.Error(t, err)
@@ -311,58 +552,316 @@ func TestReceptionPostgres_DeleteLastProduct(t *testing.T) {
 	}
 }
 
-func TestReceptionPostgres_CloseLastReception(t *testing.T) {
+func TestReceptionPostgres_DeleteProduct(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
 	defer db.Close()
 
 	repo := NewReceptionPostgres(db)
 	recID := uuid.New()
-	pvzID := uuid.New()
+	prodID := uuid.New()
 	now := time.Now()
+	comment := "scanned the neighbour parcel"
+	columns := []string{"id", "date", "reception_id", "type", "barcode", "external_order_id", "state", "state_changed_at", "return_condition", "original_order_id", "cell_id", "scan_seq", "condition", "deleted_at", "delete_reason", "delete_comment"}
 
 	tests := []struct {
 		name        string
-		recID       uuid.UUID
 		mockSetup   func()
-		expected    api.Reception
 		expectedErr error
+	}{
+		{
+			name: "successful delete",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT status FROM receptions WHERE id = \\$1 FOR SHARE").
+					WithArgs(recID).
+					WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(api.InProgress))
+				mock.ExpectQuery("UPDATE products SET deleted_at = now\\(\\), delete_reason = \\$1, delete_comment = \\$2 WHERE deleted_at IS NULL AND id = \\$3 AND reception_id = \\$4 RETURNING").
+					WithArgs(api.DeleteReasonMistakenScan, &comment, prodID, recID).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(prodID, now, recID, api.ProductTypeClothes, nil, nil, api.ProductStateReceived, nil, nil, nil, nil, 1, "ok", now, api.DeleteReasonMistakenScan, comment))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "reception not found",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT status FROM receptions").
+					WithArgs(recID).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrReceptionNotFound,
+		},
+		{
+			name: "reception closed",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT status FROM receptions").
+					WithArgs(recID).
+					WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(api.Close))
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrReceptionClosed,
+		},
+		{
+			name: "product not in reception or already deleted",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT status FROM receptions").
+					WithArgs(recID).
+					WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(api.InProgress))
+				mock.ExpectQuery("UPDATE products").
+					WithArgs(api.DeleteReasonMistakenScan, &comment, prodID, recID).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrProductNotFound,
+		},
+		{
+			name: "database error",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT status FROM receptions").
+					WithArgs(recID).
+					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
+			},
+			expectedErr: sql.ErrConnDone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			result, err := repo.DeleteProduct(context.Background(), recID, prodID, api.DeleteReasonMistakenScan, &comment)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, prodID, *result.Product.Id)
+				assert.Equal(t, api.DeleteReasonMistakenScan, result.Reason)
+				assert.Equal(t, comment, *result.Comment)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReceptionPostgres_GetDeletedProducts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewReceptionPostgres(db)
+	recID := uuid.New()
+	now := time.Now()
+	columns := []string{"id", "date", "reception_id", "type", "barcode", "external_order_id", "state", "state_changed_at", "return_condition", "original_order_id", "cell_id", "scan_seq", "condition", "deleted_at", "delete_reason", "delete_comment"}
+
+	tests := []struct {
+		name        string
+		mockSetup   func()
+		expectedLen int
+		expectedErr error
+	}{
+		{
+			name: "returns deleted products",
+			mockSetup: func() {
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\)>0 FROM receptions").
+					WithArgs(recID).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectQuery("FROM products WHERE \\(reception_id = \\$1 AND deleted_at IS NOT NULL\\) ORDER BY deleted_at").
+					WithArgs(recID).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(uuid.New(), now, recID, api.ProductTypeShoes, nil, nil, api.ProductStateReceived, nil, nil, nil, nil, 1, "ok", now, api.DeleteReasonUndoLast, nil).
+						AddRow(uuid.New(), now, recID, api.ProductTypeClothes, nil, nil, api.ProductStateReceived, nil, nil, nil, nil, 1, "ok", now, api.DeleteReasonDamaged, "torn"))
+			},
+			expectedLen: 2,
+		},
+		{
+			name: "reception not found",
+			mockSetup: func() {
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\)>0 FROM receptions").
+					WithArgs(recID).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+			},
+			expectedErr: errs.ErrReceptionNotFound,
+		},
+		{
+			name: "database error",
+			mockSetup: func() {
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\)>0 FROM receptions").
+					WithArgs(recID).
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedErr: sql.ErrConnDone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			result, err := repo.GetDeletedProducts(context.Background(), recID)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, result, tt.expectedLen)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// expectDiscrepancyReports expects missing, wrong type and extra items of the receptions to be stored
+func expectDiscrepancyReports(mock sqlmock.Sqlmock, recIDs ...uuid.UUID) {
+	for _, kind := range []api.DiscrepancyKind{api.DiscrepancyMissing, api.DiscrepancyWrongType, api.DiscrepancyExtra} {
+		args := []driver.Value{kind}
+		for _, id := range recIDs {
+			args = append(args, id)
+		}
+		mock.ExpectExec("INSERT INTO reception_discrepancies (.+) SELECT").
+			WithArgs(args...).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+}
+
+func TestReceptionPostgres_CloseLastReception(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewReceptionPostgres(db)
+	recID := uuid.New()
+	pvzID := uuid.New()
+	prodID, codeID := uuid.New(), uuid.New()
+	now := time.Now()
+	mint := func(targets []api.PickupCode) ([]NewPickupCode, error) {
+		codes := make([]NewPickupCode, len(targets))
+		for i, target := range targets {
+			codes[i] = NewPickupCode{PickupCode: target, Hash: "hash"}
+		}
+		return codes, nil
+	}
+
+	tests := []struct {
+		name          string
+		recID         uuid.UUID
+		mint          PickupCodeMinter
+		mockSetup     func()
+		expected      api.Reception
+		expectedCodes []api.PickupCode
+		expectedErr   error
 	}{
 		{
 			name:  "successful close",
 			recID: recID,
 			mockSetup: func() {
-				rows := sqlmock.NewRows([]string{"id", "date", "pvz_id", "status"}).
-					AddRow(recID, now, pvzID, "close")
-				mock.ExpectQuery("UPDATE receptions").
-					WithArgs("close", recID).
+				rows := sqlmock.NewRows(receptionTestColumns).
+					AddRow(recID, now, pvzID, "close", now, api.CloseReasonManual, nil, api.ReceptionKindInbound, nil,
+						[]byte(`{"productsByType": {"shoes": 2, "clothes": 1}, "productsTotal": 3, "deletions": 1, "durationSeconds": 600}`))
+				mock.ExpectBegin()
+				mock.ExpectQuery("UPDATE receptions r SET status = \\$1, closed_at = now\\(\\), close_reason = \\$2, summary = json_build_object\\((.+)\\) WHERE id = \\$3 AND status = \\$4 RETURNING").
+					WithArgs("close", api.CloseReasonManual, recID, api.InProgress).
 					WillReturnRows(rows)
+				mock.ExpectExec("UPDATE products SET state = \\$1, state_changed_at = now\\(\\) WHERE current_reception_id IN \\(\\$2\\) AND deleted_at IS NULL AND state = \\$3").
+					WithArgs(api.ProductStateStored, recID, api.ProductStateReceived).
+					WillReturnResult(sqlmock.NewResult(0, 3))
+				expectDiscrepancyReports(mock, recID)
+				mock.ExpectCommit()
 			},
 			expected: api.Reception{
-				Id:       &recID,
-				DateTime: now,
-				PvzId:    pvzID,
-				Status:   "close",
+				Id:          &recID,
+				DateTime:    now,
+				PvzId:       pvzID,
+				Status:      "close",
+				ClosedAt:    &now,
+				CloseReason: ptrTo(api.CloseReasonManual),
+				Kind:        api.ReceptionKindInbound,
+				Summary: &api.ReceptionSummary{
+					ProductsByType:  map[string]int{api.ProductTypeShoes: 2, api.ProductTypeClothes: 1},
+					ProductsTotal:   3,
+					Deletions:       1,
+					DurationSeconds: 600,
+				},
 			},
 			expectedErr: nil,
 		},
+		{
+			name:  "pickup codes stored with the reception",
+			recID: recID,
+			mint:  mint,
+			mockSetup: func() {
+				rows := sqlmock.NewRows(receptionTestColumns).
+					AddRow(recID, now, pvzID, "close", now, api.CloseReasonManual, nil, api.ReceptionKindInbound, nil, nil)
+				mock.ExpectBegin()
+				mock.ExpectQuery("UPDATE receptions r SET status").
+					WithArgs("close", api.CloseReasonManual, recID, api.InProgress).
+					WillReturnRows(rows)
+				mock.ExpectExec("UPDATE products SET state").
+					WithArgs(api.ProductStateStored, recID, api.ProductStateReceived).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				expectDiscrepancyReports(mock, recID)
+				mock.ExpectQuery("SELECT r.pvz_id, p.id, p.external_order_id FROM products p").
+					WithArgs(recID, api.ProductStateStored).
+					WillReturnRows(sqlmock.NewRows([]string{"pvz_id", "id", "external_order_id"}).AddRow(pvzID, prodID, nil))
+				mock.ExpectExec("UPDATE pickup_codes SET revoked_at").
+					WithArgs(prodID).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("INSERT INTO pickup_codes").
+					WithArgs(pvzID, recID, nil, "hash").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(codeID, now))
+				mock.ExpectExec("UPDATE products SET pickup_code_id").
+					WithArgs(codeID, prodID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expected: api.Reception{
+				Id:          &recID,
+				DateTime:    now,
+				PvzId:       pvzID,
+				Status:      "close",
+				ClosedAt:    &now,
+				CloseReason: ptrTo(api.CloseReasonManual),
+				Kind:        api.ReceptionKindInbound,
+			},
+			expectedCodes: []api.PickupCode{{Id: codeID, PvzId: pvzID, ReceptionId: recID, ProductIds: []uuid.UUID{prodID}, CreatedAt: now}},
+		},
 		{
 			name:  "database error",
 			recID: recID,
 			mockSetup: func() {
+				mock.ExpectBegin()
 				mock.ExpectQuery("UPDATE receptions").
-					WithArgs("close", recID).
+					WithArgs("close", api.CloseReasonManual, recID, api.InProgress).
 					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
 			},
 			expected:    api.Reception{},
 			expectedErr: errors.New("repository.pvz.CloseLastReception: sql: connection is already closed"),
 		},
+		{
+			name:  "closed concurrently",
+			recID: recID,
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("UPDATE receptions").
+					WithArgs("close", api.CloseReasonManual, recID, api.InProgress).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expected:    api.Reception{},
+			expectedErr: errs.ErrNoReceptionsInProgress,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
-			result, err := repo.CloseLastReception(tt.recID)
+			result, codes, err := repo.CloseLastReception(context.Background(), tt.recID, tt.mint)
 
 			if tt.expectedErr != nil {
 				assert.Error(t, err)
@@ -370,8 +869,455 @@ func TestReceptionPostgres_CloseLastReception(t *testing.T) {
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.expected, result)
+				if tt.mint != nil {
+					assert.Equal(t, tt.expectedCodes, codes)
+				}
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReceptionPostgres_Reopen(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewReceptionPostgres(db)
+	recID := uuid.New()
+	pvzID := uuid.New()
+	userID := uuid.New()
+	opened := time.Now().Add(-time.Hour)
+	closed := time.Now().Add(-time.Minute)
+	reason := "closed by mistake"
+	window := 30 * time.Minute
+	columns := append(receptionTestColumns, "within_window")
+
+	tests := []struct {
+		name        string
+		mockSetup   func()
+		expectedErr error
+	}{
+		{
+			name: "successful reopen",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id, date, pvz_id, status, closed_at, close_reason, stale_flagged_at, kind, created_by, summary, closed_at IS NOT NULL AND closed_at >= now\\(\\) - make_interval\\(secs => \\$1\\) FROM receptions WHERE id = \\$2 FOR UPDATE").
+					WithArgs(window.Seconds(), recID).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(recID, opened, pvzID, api.Close, closed, api.CloseReasonManual, nil, api.ReceptionKindInbound, nil, nil, true))
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\)>0 FROM receptions WHERE kind = \\$1 AND pvz_id = \\$2 AND date > \\$3").
+					WithArgs(api.ReceptionKindInbound, pvzID, opened).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectQuery("UPDATE receptions SET status = \\$1, closed_at = \\$2, close_reason = \\$3, stale_flagged_at = \\$4, summary = \\$5 WHERE id = \\$6").
+					WithArgs(api.InProgress, nil, nil, nil, nil, recID).
+					WillReturnRows(sqlmock.NewRows(receptionTestColumns).AddRow(recID, opened, pvzID, api.InProgress, nil, nil, nil, api.ReceptionKindInbound, nil, nil))
+				mock.ExpectExec("UPDATE products SET state").
+					WithArgs(api.ProductStateReceived, recID, api.ProductStateStored).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec("DELETE FROM reception_discrepancies WHERE reception_id = \\$1").
+					WithArgs(recID).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("INSERT INTO reception_reopens").
+					WithArgs(recID, &userID, reason, closed).
+					WillReturnRows(sqlmock.NewRows([]string{"reopened_at"}).AddRow(time.Now()))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "reception not found",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM receptions").
+					WithArgs(window.Seconds(), recID).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrReceptionNotFound,
+		},
+		{
+			name: "reception in progress",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM receptions").
+					WithArgs(window.Seconds(), recID).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(recID, opened, pvzID, api.InProgress, nil, nil, nil, api.ReceptionKindInbound, nil, nil, false))
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrReceptionNotClosed,
+		},
+		{
+			name: "window expired",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM receptions").
+					WithArgs(window.Seconds(), recID).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(recID, opened, pvzID, api.Close, closed, api.CloseReasonAutoClosed, nil, api.ReceptionKindInbound, nil, nil, false))
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrReopenWindowExpired,
+		},
+		{
+			name: "newer reception exists",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM receptions").
+					WithArgs(window.Seconds(), recID).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(recID, opened, pvzID, api.Close, closed, api.CloseReasonManual, nil, api.ReceptionKindInbound, nil, nil, true))
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\)>0 FROM receptions").
+					WithArgs(api.ReceptionKindInbound, pvzID, opened).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrNewerReceptionExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			result, err := repo.Reopen(context.Background(), recID, userID, reason, window)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, api.InProgress, result.Reception.Status)
+				assert.Nil(t, result.Reception.ClosedAt)
+				assert.Equal(t, closed, result.PreviousClosedAt)
+				assert.Equal(t, userID, *result.ReopenedBy)
 			}
 			assert.NoError(t, mock.ExpectationsWereMet())
 		})
 	}
 }
+
+func TestReceptionPostgres_CloseStale(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewReceptionPostgres(db)
+	idle := 12 * time.Hour
+	now := time.Now()
+	firstID, secondID := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name        string
+		mockSetup   func()
+		expectedLen int
+		expectedErr bool
+	}{
+		{
+			name: "closes stale receptions",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT pg_try_advisory_xact_lock\\(\\$1\\)").
+					WithArgs(staleReceptionsLockKey).
+					WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+				mock.ExpectQuery("UPDATE receptions r SET status = \\$1, closed_at = now\\(\\), close_reason = \\$2, summary = json_build_object\\((.+)\\) WHERE r.status = \\$3 AND GREATEST\\(r.date, (.+)\\) < now\\(\\) - make_interval\\(secs => \\$4\\) RETURNING").
+					WithArgs(api.Close, api.CloseReasonAutoClosed, api.InProgress, idle.Seconds()).
+					WillReturnRows(sqlmock.NewRows(receptionTestColumns).
+						AddRow(firstID, now, uuid.New(), api.Close, now, api.CloseReasonAutoClosed, nil, api.ReceptionKindInbound, nil, nil).
+						AddRow(secondID, now, uuid.New(), api.Close, now, api.CloseReasonAutoClosed, nil, api.ReceptionKindCustomerReturn, nil, nil))
+				mock.ExpectExec("UPDATE products SET state").
+					WithArgs(api.ProductStateStored, firstID, secondID, api.ProductStateReceived).
+					WillReturnResult(sqlmock.NewResult(0, 5))
+				expectDiscrepancyReports(mock, firstID, secondID)
+				// only the inbound reception gets pickup codes
+				mock.ExpectQuery("SELECT r.pvz_id, p.id, p.external_order_id FROM products p").
+					WithArgs(firstID, api.ProductStateStored).
+					WillReturnRows(sqlmock.NewRows([]string{"pvz_id", "id", "external_order_id"}))
+				mock.ExpectCommit()
+			},
+			expectedLen: 2,
+		},
+		{
+			name: "another replica holds the lock",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT pg_try_advisory_xact_lock").
+					WithArgs(staleReceptionsLockKey).
+					WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(false))
+				mock.ExpectRollback()
+			},
+			expectedLen: 0,
+		},
+		{
+			name: "database error",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT pg_try_advisory_xact_lock").
+					WithArgs(staleReceptionsLockKey).
+					WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+				mock.ExpectQuery("UPDATE receptions r").
+					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			result, codes, err := repo.CloseStale(context.Background(), idle, func(targets []api.PickupCode) ([]NewPickupCode, error) {
+				t.Fatal("nothing to mint")
+				return nil, nil
+			})
+
+			if tt.expectedErr {
+				assert.ErrorIs(t, err, sql.ErrConnDone)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, result, tt.expectedLen)
+				assert.Empty(t, codes)
+				for _, rec := range result {
+					assert.Equal(t, api.CloseReasonAutoClosed, *rec.CloseReason)
+				}
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReceptionPostgres_FlagStale(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewReceptionPostgres(db)
+	idle := time.Hour
+	now := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT pg_try_advisory_xact_lock").
+		WithArgs(staleReceptionsLockKey).
+		WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+	mock.ExpectQuery("UPDATE receptions r SET stale_flagged_at = now\\(\\) WHERE r.stale_flagged_at IS NULL AND r.status = \\$1 AND (.+) make_interval\\(secs => \\$2\\)").
+		WithArgs(api.InProgress, idle.Seconds()).
+		WillReturnRows(sqlmock.NewRows(receptionTestColumns).
+			AddRow(uuid.New(), now, uuid.New(), api.InProgress, nil, nil, now, api.ReceptionKindInbound, nil, nil))
+	mock.ExpectCommit()
+
+	result, err := repo.FlagStale(context.Background(), idle)
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Equal(t, api.InProgress, result[0].Status)
+	assert.NotNil(t, result[0].StaleFlaggedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReceptionPostgres_ReturnsReport(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewReceptionPostgres(db)
+	pvzID := uuid.New()
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	columns := []string{"pvz_id", "return_condition", "receptions", "products"}
+
+	tests := []struct {
+		name        string
+		params      api.GetReportsReturnsParams
+		mockSetup   func()
+		expected    []api.ReturnsReportRow
+		expectedErr error
+	}{
+		{
+			name:   "whole period",
+			params: api.GetReportsReturnsParams{},
+			mockSetup: func() {
+				mock.ExpectQuery("SELECT r.pvz_id, p.return_condition, COUNT\\(DISTINCT r.id\\), COUNT\\(\\*\\) FROM products p JOIN receptions r ON r.id = p.reception_id WHERE p.deleted_at IS NULL AND r.kind = \\$1 AND p.return_condition IS NOT NULL GROUP BY r.pvz_id, p.return_condition ORDER BY r.pvz_id, p.return_condition").
+					WithArgs(api.ReceptionKindCustomerReturn).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(pvzID, api.ReturnConditionOpened, 2, 5).
+						AddRow(pvzID, api.ReturnConditionUnopened, 1, 1))
+			},
+			expected: []api.ReturnsReportRow{
+				{PvzId: pvzID, Condition: api.ReturnConditionOpened, Receptions: 2, Products: 5},
+				{PvzId: pvzID, Condition: api.ReturnConditionUnopened, Receptions: 1, Products: 1},
+			},
+		},
+		{
+			name:   "filtered by period and pvz",
+			params: api.GetReportsReturnsParams{StartDate: &start, EndDate: &end, PvzId: &pvzID},
+			mockSetup: func() {
+				mock.ExpectQuery("WHERE p.deleted_at IS NULL AND r.kind = \\$1 AND p.return_condition IS NOT NULL AND r.date >= \\$2 AND r.date <= \\$3 AND r.pvz_id = \\$4 GROUP BY").
+					WithArgs(api.ReceptionKindCustomerReturn, start, end, pvzID).
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
+			expected: []api.ReturnsReportRow{},
+		},
+		{
+			name:   "database error",
+			params: api.GetReportsReturnsParams{},
+			mockSetup: func() {
+				mock.ExpectQuery("SELECT r.pvz_id").
+					WithArgs(api.ReceptionKindCustomerReturn).
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedErr: errors.New("repository.reception.ReturnsReport: sql: connection is already closed"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			result, err := repo.ReturnsReport(context.Background(), tt.params)
+
+			if tt.expectedErr != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReceptionPostgres_List(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewReceptionPostgres(db)
+	pvzID := uuid.New()
+	recID := uuid.New()
+	userID := uuid.New()
+	now := time.Now()
+	status := api.Close
+	page, limit := 3, 10
+
+	tests := []struct {
+		name        string
+		params      api.GetReceptionsParams
+		mockSetup   func()
+		expected    []api.Reception
+		expectedErr bool
+	}{
+		{
+			name:   "filtered page",
+			params: api.GetReceptionsParams{PvzId: &pvzID, Status: &status, CreatedBy: &userID, Page: &page, Limit: &limit},
+			mockSetup: func() {
+				mock.ExpectQuery("SELECT id, date, pvz_id, status, closed_at, close_reason, stale_flagged_at, kind, created_by, summary FROM receptions WHERE pvz_id = \\$1 AND status = \\$2 AND created_by = \\$3 ORDER BY date DESC, id LIMIT 10 OFFSET 20").
+					WithArgs(pvzID, status, userID).
+					WillReturnRows(sqlmock.NewRows(receptionTestColumns).
+						AddRow(recID, now, pvzID, api.Close, now, api.CloseReasonManual, nil, api.ReceptionKindInbound, userID, nil))
+			},
+			expected: []api.Reception{{
+				Id: &recID, DateTime: now, PvzId: pvzID, Status: api.Close, ClosedAt: &now,
+				CloseReason: ptrTo(api.CloseReasonManual), Kind: api.ReceptionKindInbound, CreatedBy: &userID,
+			}},
+		},
+		{
+			name:   "defaults",
+			params: api.GetReceptionsParams{},
+			mockSetup: func() {
+				mock.ExpectQuery("SELECT (.+) FROM receptions ORDER BY date DESC, id LIMIT 30 OFFSET 0").
+					WillReturnRows(sqlmock.NewRows(receptionTestColumns))
+			},
+			expected: []api.Reception{},
+		},
+		{
+			name:   "database error",
+			params: api.GetReceptionsParams{},
+			mockSetup: func() {
+				mock.ExpectQuery("SELECT (.+) FROM receptions").WillReturnError(sql.ErrConnDone)
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			result, err := repo.List(context.Background(), tt.params)
+
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReceptionPostgres_GetByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewReceptionPostgres(db)
+	recID := uuid.New()
+	pvzID := uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT (.+) FROM receptions WHERE id = \\$1").
+		WithArgs(recID).
+		WillReturnRows(sqlmock.NewRows(receptionTestColumns).
+			AddRow(recID, now, pvzID, api.InProgress, nil, nil, nil, api.ReceptionKindInbound, nil, nil))
+	rec, err := repo.GetByID(context.Background(), recID)
+	assert.NoError(t, err)
+	assert.Equal(t, api.Reception{Id: &recID, DateTime: now, PvzId: pvzID, Status: api.InProgress, Kind: api.ReceptionKindInbound}, rec)
+
+	mock.ExpectQuery("SELECT (.+) FROM receptions WHERE id = \\$1").
+		WithArgs(recID).
+		WillReturnError(sql.ErrNoRows)
+	_, err = repo.GetByID(context.Background(), recID)
+	assert.Equal(t, errs.ErrReceptionNotFound, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReceptionPostgres_GetProducts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewReceptionPostgres(db)
+	recID := uuid.New()
+	prodID := uuid.New()
+	now := time.Now()
+	page, limit := 2, 1
+	productCols := []string{"id", "date", "reception_id", "type", "barcode", "external_order_id", "state", "state_changed_at", "return_condition", "original_order_id", "cell_id", "scan_seq", "condition"}
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM products WHERE deleted_at IS NULL AND reception_id = \\$1").
+		WithArgs(recID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery("SELECT (.+) FROM products WHERE deleted_at IS NULL AND reception_id = \\$1 ORDER BY scan_seq LIMIT 1 OFFSET 1").
+		WithArgs(recID).
+		WillReturnRows(sqlmock.NewRows(productCols).
+			AddRow(prodID, now, recID, api.ProductTypeShoes, nil, nil, api.ProductStateStored, now, nil, nil, nil, 2, "ok"))
+
+	prods, total, err := repo.GetProducts(context.Background(), recID, api.GetReceptionsReceptionIdParams{Page: &page, Limit: &limit})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, []api.Product{{
+		Id: &prodID, DateTime: &now, ReceptionId: recID, Type: api.ProductTypeShoes,
+		State: api.ProductStateStored, StateChangedAt: &now, ScanSeq: ptrTo(int64(2)),
+		Condition: ptrTo(api.ConditionOk),
+	}}, prods)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// a scanner resumes after the last product it has seen
+	afterSeq := int64(1)
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM products WHERE deleted_at IS NULL AND reception_id = \\$1").
+		WithArgs(recID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery("SELECT (.+) FROM products WHERE deleted_at IS NULL AND reception_id = \\$1 AND scan_seq > \\$2 ORDER BY scan_seq LIMIT 30 OFFSET 0").
+		WithArgs(recID, afterSeq).
+		WillReturnRows(sqlmock.NewRows(productCols).
+			AddRow(prodID, now, recID, api.ProductTypeShoes, nil, nil, api.ProductStateStored, now, nil, nil, nil, 2, "ok"))
+
+	prods, total, err = repo.GetProducts(context.Background(), recID, api.GetReceptionsReceptionIdParams{AfterSeq: &afterSeq})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Len(t, prods, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/ST359/pvz-service/internal/api"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+type ReportPostgres struct {
+	db *sql.DB
+}
+
+func NewReportPostgres(db *sql.DB) *ReportPostgres {
+	return &ReportPostgres{db: db}
+}
+
+// Volume sums daily product counters of productVolumeDailyTable grouped by the period and the given fields,
+// rows are ordered by the period, city, PVZ and type. Without groups and period a single total row is returned
+func (r *ReportPostgres) Volume(ctx context.Context, params api.GetReportsVolumeParams) ([]api.VolumeReportRow, error) {
+	const op = "repository.report.Volume"
+
+	var groups []api.VolumeGroup
+	if params.GroupBy != nil {
+		groups = *params.GroupBy
+	}
+	// columns are added in the order of the result, row scans into the matching destinations
+	var (
+		row         api.VolumeReportRow
+		periodStart time.Time
+		columns     []string
+		dest        []any
+	)
+	if params.Period != nil {
+		// the period is one of the VolumePeriod values checked by the service, so it is safe to inline
+		columns = append(columns, fmt.Sprintf("date_trunc('%s', v.day::timestamp)::date", *params.Period))
+		dest = append(dest, &periodStart)
+	}
+	if slices.Contains(groups, api.VolumeGroupCity) {
+		columns = append(columns, "pv.city")
+		dest = append(dest, &row.City)
+	}
+	if slices.Contains(groups, api.VolumeGroupPVZ) {
+		columns = append(columns, "v.pvz_id")
+		dest = append(dest, &row.PvzId)
+	}
+	if slices.Contains(groups, api.VolumeGroupType) {
+		columns = append(columns, "v.type")
+		dest = append(dest, &row.Type)
+	}
+	grouped := len(columns) > 0
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	query := psql.Select(append(columns, "COALESCE(SUM(v.products), 0)")...).
+		From(productVolumeDailyTable + " v").
+		Join(pvzTable + " pv ON pv.id = v.pvz_id")
+	if params.StartDate != nil {
+		query = query.Where(squirrel.GtOrEq{"v.day": params.StartDate.String()})
+	}
+	if params.EndDate != nil {
+		query = query.Where(squirrel.LtOrEq{"v.day": params.EndDate.String()})
+	}
+	if params.City != nil {
+		query = query.Where(squirrel.Eq{"pv.city": *params.City})
+	}
+	if params.PvzId != nil {
+		query = query.Where(squirrel.Eq{"v.pvz_id": *params.PvzId})
+	}
+	if grouped {
+		// counters of deleted products stay at zero, such groups are left out
+		query = query.GroupBy(columns...).
+			Having("SUM(v.products) > 0").
+			OrderBy(columns...)
+	}
+	rows, err := query.RunWith(r.db).QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	res := []api.VolumeReportRow{}
+	for rows.Next() {
+		row = api.VolumeReportRow{}
+		if err := rows.Scan(append(dest, &row.Products)...); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if params.Period != nil {
+			row.PeriodStart = &openapi_types.Date{Time: periodStart}
+		}
+		res = append(res, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return res, nil
+}
+
+// timedReception holds for receptions aliased as r with a meaningful duration, auto closed ones were left idle
+const timedReception = "r.status = 'close' AND r.close_reason IS DISTINCT FROM 'auto_closed'"
+
+// receptionDuration is the number of seconds a reception aliased as r stayed open
+const receptionDuration = "EXTRACT(EPOCH FROM r.closed_at - r.date)"
+
+// Employees computes productivity of employees by PVZ over receptions they opened within the period,
+// rows are ordered by email and PVZ. Receptions without a creator are left out
+func (r *ReportPostgres) Employees(ctx context.Context, params api.GetReportsEmployeesParams) ([]api.EmployeeProductivityRow, error) {
+	const op = "repository.report.Employees"
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	query := psql.Select("r.created_by", "u.email", "r.pvz_id",
+		"COUNT(*)",
+		"COUNT(*) FILTER (WHERE r.status = 'close')",
+		"COALESCE(SUM(s.scanned), 0)",
+		"COALESCE(SUM(s.deleted), 0)",
+		"COALESCE(round(SUM(s.deleted)::numeric / NULLIF(SUM(s.scanned), 0), 4), 0)::float8",
+		"round(AVG("+receptionDuration+") FILTER (WHERE "+timedReception+"))::bigint",
+		"round((SUM(s.scanned) FILTER (WHERE "+timedReception+") * 3600 / "+
+			"NULLIF(SUM("+receptionDuration+") FILTER (WHERE "+timedReception+"), 0))::numeric, 2)::float8").
+		From(receptionsTable + " r").
+		Join(usersTable + " u ON u.id = r.created_by").
+		JoinClause("CROSS JOIN LATERAL (SELECT COUNT(*) AS scanned, COUNT(*) FILTER (WHERE p.deleted_at IS NOT NULL) AS deleted FROM " +
+			productsTable + " p WHERE p.reception_id = r.id) s")
+	if params.StartDate != nil {
+		query = query.Where(squirrel.GtOrEq{"r.date": *params.StartDate})
+	}
+	if params.EndDate != nil {
+		query = query.Where(squirrel.LtOrEq{"r.date": *params.EndDate})
+	}
+	if params.PvzId != nil {
+		query = query.Where(squirrel.Eq{"r.pvz_id": *params.PvzId})
+	}
+	if params.UserId != nil {
+		query = query.Where(squirrel.Eq{"r.created_by": *params.UserId})
+	}
+	rows, err := query.GroupBy("r.created_by", "u.email", "r.pvz_id").
+		OrderBy("u.email", "r.pvz_id").
+		RunWith(r.db).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	res := []api.EmployeeProductivityRow{}
+	for rows.Next() {
+		var row api.EmployeeProductivityRow
+		if err := rows.Scan(&row.UserId, &row.Email, &row.PvzId, &row.Receptions, &row.ClosedReceptions, &row.ProductsScanned,
+			&row.ProductsDeleted, &row.DeletionRate, &row.AvgReceptionDurationSeconds, &row.ProductsPerHour); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		res = append(res, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return res, nil
+}
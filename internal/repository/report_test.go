@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ST359/pvz-service/internal/api"
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportPostgres_Volume(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewReportPostgres(db)
+	pvzID := uuid.New()
+	city := "Казань"
+	clothes := "clothes"
+	week := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	start := openapi_types.Date{Time: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)}
+	end := openapi_types.Date{Time: time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)}
+	weekly := api.VolumePeriodWeek
+
+	tests := []struct {
+		name        string
+		params      api.GetReportsVolumeParams
+		mockSetup   func()
+		expected    []api.VolumeReportRow
+		expectedErr error
+	}{
+		{
+			name:   "total",
+			params: api.GetReportsVolumeParams{},
+			mockSetup: func() {
+				mock.ExpectQuery(`^SELECT COALESCE\(SUM\(v.products\), 0\) FROM product_volume_daily v JOIN pvzs pv ON pv.id = v.pvz_id$`).
+					WillReturnRows(sqlmock.NewRows([]string{"products"}).AddRow(42))
+			},
+			expected: []api.VolumeReportRow{{Products: 42}},
+		},
+		{
+			name: "weekly by city and type",
+			params: api.GetReportsVolumeParams{StartDate: &start, EndDate: &end, Period: &weekly,
+				GroupBy: &[]api.VolumeGroup{api.VolumeGroupType, api.VolumeGroupCity}},
+			mockSetup: func() {
+				mock.ExpectQuery(`SELECT date_trunc\('week', v.day::timestamp\)::date, pv.city, v.type, COALESCE\(SUM\(v.products\), 0\) `+
+					`FROM product_volume_daily v JOIN pvzs pv ON pv.id = v.pvz_id WHERE v.day >= \$1 AND v.day <= \$2 `+
+					`GROUP BY date_trunc\('week', v.day::timestamp\)::date, pv.city, v.type HAVING SUM\(v.products\) > 0 `+
+					`ORDER BY date_trunc\('week', v.day::timestamp\)::date, pv.city, v.type`).
+					WithArgs("2025-03-01", "2025-03-31").
+					WillReturnRows(sqlmock.NewRows([]string{"period_start", "city", "type", "products"}).
+						AddRow(week, city, clothes, 7).
+						AddRow(week.AddDate(0, 0, 7), city, clothes, 3))
+			},
+			expected: []api.VolumeReportRow{
+				{PeriodStart: &openapi_types.Date{Time: week}, City: &city, Type: &clothes, Products: 7},
+				{PeriodStart: &openapi_types.Date{Time: week.AddDate(0, 0, 7)}, City: &city, Type: &clothes, Products: 3},
+			},
+		},
+		{
+			name:   "by pvz filtered by city and pvz",
+			params: api.GetReportsVolumeParams{City: &city, PvzId: &pvzID, GroupBy: &[]api.VolumeGroup{api.VolumeGroupPVZ}},
+			mockSetup: func() {
+				mock.ExpectQuery(`SELECT v.pvz_id, COALESCE\(SUM\(v.products\), 0\) FROM .* WHERE pv.city = \$1 AND v.pvz_id = \$2 GROUP BY v.pvz_id`).
+					WithArgs(city, pvzID).
+					WillReturnRows(sqlmock.NewRows([]string{"pvz_id", "products"}))
+			},
+			expected: []api.VolumeReportRow{},
+		},
+		{
+			name:   "database error",
+			params: api.GetReportsVolumeParams{},
+			mockSetup: func() {
+				mock.ExpectQuery("SELECT COALESCE").WillReturnError(sql.ErrConnDone)
+			},
+			expectedErr: errors.New("repository.report.Volume: sql: connection is already closed"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			result, err := repo.Volume(context.Background(), tt.params)
+
+			if tt.expectedErr != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReportPostgres_Employees(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewReportPostgres(db)
+	userID := uuid.New()
+	pvzID := uuid.New()
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	columns := []string{"created_by", "email", "pvz_id", "receptions", "closed", "scanned", "deleted", "deletion_rate", "avg_duration", "per_hour"}
+	avg := int64(1800)
+	perHour := 40.0
+
+	tests := []struct {
+		name        string
+		params      api.GetReportsEmployeesParams
+		mockSetup   func()
+		expected    []api.EmployeeProductivityRow
+		expectedErr error
+	}{
+		{
+			name:   "filtered",
+			params: api.GetReportsEmployeesParams{StartDate: &start, PvzId: &pvzID, UserId: &userID},
+			mockSetup: func() {
+				mock.ExpectQuery(`SELECT r.created_by, u.email, r.pvz_id, COUNT\(\*\), .* FROM receptions r JOIN users u ON u.id = r.created_by `+
+					`CROSS JOIN LATERAL \(SELECT COUNT\(\*\) AS scanned, .* FROM products p WHERE p.reception_id = r.id\) s `+
+					`WHERE r.date >= \$1 AND r.pvz_id = \$2 AND r.created_by = \$3 GROUP BY r.created_by, u.email, r.pvz_id ORDER BY u.email, r.pvz_id`).
+					WithArgs(start, pvzID, userID).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(userID, "anna@example.com", pvzID, 3, 2, 41, 1, 0.0244, 1800, 40.0).
+						AddRow(userID, "anna@example.com", uuid.Nil, 1, 0, 0, 0, 0, nil, nil))
+			},
+			expected: []api.EmployeeProductivityRow{
+				{UserId: userID, Email: "anna@example.com", PvzId: pvzID, Receptions: 3, ClosedReceptions: 2, ProductsScanned: 41,
+					ProductsDeleted: 1, DeletionRate: 0.0244, AvgReceptionDurationSeconds: &avg, ProductsPerHour: &perHour},
+				{UserId: userID, Email: "anna@example.com", Receptions: 1},
+			},
+		},
+		{
+			name:   "database error",
+			params: api.GetReportsEmployeesParams{},
+			mockSetup: func() {
+				mock.ExpectQuery("SELECT r.created_by").WillReturnError(sql.ErrConnDone)
+			},
+			expectedErr: errors.New("repository.report.Employees: sql: connection is already closed"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			result, err := repo.Employees(context.Background(), tt.params)
+
+			if tt.expectedErr != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
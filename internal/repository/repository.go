@@ -1,7 +1,9 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"time"
 
 	"github.com/ST359/pvz-service/internal/api"
 	"github.com/google/uuid"
@@ -9,32 +11,167 @@ import (
 
 type User interface {
 	//Create creates a user and returns an id of the user
-	Create(email string, password_hash string, role string) (uuid.UUID, error)
-	//Login returns password hash and role of a user with given email
-	Login(email string) (string, string, error)
-	EmailExists(email string) (bool, error)
+	Create(ctx context.Context, email string, password_hash string, role string) (uuid.UUID, error)
+	//Login returns id, password hash and role of a user with given email
+	Login(ctx context.Context, email string) (uuid.UUID, string, string, error)
+	EmailExists(ctx context.Context, email string) (bool, error)
 }
 type PVZ interface {
-	Create(pvz api.PVZ) (api.PVZ, error)
-	GetByDate(params api.GetPvzParams) ([]api.PVZInfo, error)
+	Create(ctx context.Context, pvz api.PVZ) (api.PVZ, error)
+	GetByDate(ctx context.Context, params api.GetPvzParams) ([]api.PVZInfo, error)
+	//CreateBatch creates all given PVZs in one transaction
+	CreateBatch(ctx context.Context, pvzs []api.PVZ) ([]api.PVZ, error)
+	//GetByCities returns PVZs with an address in given cities
+	GetByCities(ctx context.Context, cities []api.PVZCity) ([]api.PVZ, error)
 }
 type Reception interface {
-	Create(pvzID uuid.UUID) (api.Reception, error)
-	AddProduct(recID uuid.UUID, product api.ProductType) (api.Product, error)
-	GetReceptionInProgress(pvzID uuid.UUID) (uuid.UUID, error)
-	DeleteLastProduct(recID uuid.UUID) error
-	CloseLastReception(recID uuid.UUID) (api.Reception, error)
+	//Create opens a reception of the given kind on behalf of the user, only one reception of each kind can be in progress at a PVZ
+	Create(ctx context.Context, pvzID uuid.UUID, kind api.ReceptionKind, userID uuid.UUID) (api.Reception, error)
+	AddProduct(ctx context.Context, recID uuid.UUID, product api.ProductInput) (api.Product, error)
+	//AddProducts inserts all products into the reception with a single statement
+	AddProducts(ctx context.Context, recID uuid.UUID, products []api.ProductInput) ([]api.Product, error)
+	GetReceptionInProgress(ctx context.Context, pvzID uuid.UUID, kind api.ReceptionKind) (uuid.UUID, error)
+	DeleteLastProduct(ctx context.Context, recID uuid.UUID) error
+	//DeleteProduct soft deletes a product of an in progress reception
+	DeleteProduct(ctx context.Context, recID, prodID uuid.UUID, reason api.ProductDeleteReason, comment *string) (api.ProductDeletion, error)
+	GetDeletedProducts(ctx context.Context, recID uuid.UUID) ([]api.ProductDeletion, error)
+	//CloseLastReception closes the reception, codes minted by mint for its stored products are stored in the same transaction
+	CloseLastReception(ctx context.Context, recID uuid.UUID, mint PickupCodeMinter) (api.Reception, []api.PickupCode, error)
+	//Reopen puts a recently closed reception back in progress
+	Reopen(ctx context.Context, recID, userID uuid.UUID, reason string, window time.Duration) (api.ReceptionReopen, error)
+	//CloseStale closes receptions idle for longer than idleFor along with their pickup codes, safe to call from several replicas
+	CloseStale(ctx context.Context, idleFor time.Duration, mint PickupCodeMinter) ([]api.Reception, []api.PickupCode, error)
+	//FlagStale marks receptions idle for longer than idleFor, safe to call from several replicas
+	FlagStale(ctx context.Context, idleFor time.Duration) ([]api.Reception, error)
+	//ReturnsReport aggregates customer returns by PVZ and return condition
+	ReturnsReport(ctx context.Context, params api.GetReportsReturnsParams) ([]api.ReturnsReportRow, error)
+	List(ctx context.Context, params api.GetReceptionsParams) ([]api.Reception, error)
+	GetByID(ctx context.Context, recID uuid.UUID) (api.Reception, error)
+	//GetProducts returns a page of not deleted products of the reception and their total count
+	GetProducts(ctx context.Context, recID uuid.UUID, params api.GetReceptionsReceptionIdParams) ([]api.Product, int, error)
+}
+type Product interface {
+	//FindByBarcode returns products with given barcode across all PVZs
+	FindByBarcode(ctx context.Context, barcode string) ([]api.ProductLocation, error)
+	//ChangeState moves products of the PVZ from one state to another in one transaction and revokes pickup codes left without stored products
+	ChangeState(ctx context.Context, pvzID uuid.UUID, ids []uuid.UUID, from, to api.ProductState) ([]api.Product, error)
+	GetInventory(ctx context.Context, pvzID uuid.UUID, params api.GetPvzPvzIdInventoryParams) ([]api.Product, error)
+}
+type PickupCode interface {
+	//RegenerationTarget returns stored products a new code of the product or order has to cover
+	RegenerationTarget(ctx context.Context, pvzID uuid.UUID, ref api.PickupCodeRef) (api.PickupCode, error)
+	//Create stores codes revoking active codes of their products
+	Create(ctx context.Context, codes []NewPickupCode) ([]api.PickupCode, error)
+	//Verify issues products of the active code with the given hash
+	Verify(ctx context.Context, pvzID uuid.UUID, ref api.PickupCodeRef, hash string, maxAttempts int) ([]api.Product, error)
+}
+type ProductType interface {
+	List(ctx context.Context, includeInactive bool) ([]api.ProductTypeInfo, error)
+	//Resolve finds a product type by its code or legacy name
+	Resolve(ctx context.Context, name string) (api.ProductTypeInfo, error)
+	Create(ctx context.Context, pt api.ProductTypeInput) (api.ProductTypeInfo, error)
+	Update(ctx context.Context, code string, pt api.ProductTypeUpdate) (api.ProductTypeInfo, error)
+	//Delete removes a product type no product refers to
+	Delete(ctx context.Context, code string) error
+}
+type Manifest interface {
+	//Replace stores the manifest of a reception in progress
+	Replace(ctx context.Context, recID uuid.UUID, items []api.ManifestItem) error
+	//Progress compares scanned products of the reception with its manifest
+	Progress(ctx context.Context, recID uuid.UUID) (api.ManifestProgress, error)
+	//Discrepancies returns the report stored on closing the reception
+	Discrepancies(ctx context.Context, recID uuid.UUID) ([]api.ReceptionDiscrepancy, error)
+}
+type StorageCell interface {
+	//List returns cells of the PVZ with the number of products in each
+	List(ctx context.Context, pvzID uuid.UUID) ([]api.StorageCell, error)
+	Create(ctx context.Context, pvzID uuid.UUID, cell api.StorageCellInput) (api.StorageCell, error)
+	Update(ctx context.Context, pvzID, cellID uuid.UUID, cell api.StorageCellUpdate) (api.StorageCell, error)
+	//Delete removes a cell without products
+	Delete(ctx context.Context, pvzID, cellID uuid.UUID) error
+	//Suggest returns the least occupied active cell with room for count products
+	Suggest(ctx context.Context, pvzID uuid.UUID, count int) (api.StorageCell, error)
+	//Place puts products of the PVZ into the cell in one transaction
+	Place(ctx context.Context, pvzID, cellID uuid.UUID, ids []uuid.UUID) ([]api.Product, error)
+}
+type Transfer interface {
+	//Create stores a transfer of stored products between PVZs
+	Create(ctx context.Context, transfer api.TransferInput, userID uuid.UUID) (api.Transfer, error)
+	//Dispatch takes products of the transfer out of the source PVZ inventory
+	Dispatch(ctx context.Context, transferID, userID uuid.UUID) (api.Transfer, error)
+	//Arrive receives products of the transfer into the reception in progress at the destination PVZ
+	Arrive(ctx context.Context, transferID, userID uuid.UUID) (api.Transfer, error)
+	List(ctx context.Context, params api.GetTransfersParams) ([]api.Transfer, error)
+	GetByID(ctx context.Context, transferID uuid.UUID) (api.Transfer, error)
+	//ProductHistory returns transfers of the product oldest first
+	ProductHistory(ctx context.Context, productID uuid.UUID) ([]api.Transfer, error)
+}
+type Idempotency interface {
+	//Begin claims the key or returns the response stored for it
+	Begin(ctx context.Context, scope, key, fingerprint string, ttl time.Duration) (*IdempotentResponse, error)
+	Complete(ctx context.Context, scope, key string, resp IdempotentResponse) error
+	//Release frees a claimed key without a stored response
+	Release(ctx context.Context, scope, key string) error
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+type Attachment interface {
+	Create(ctx context.Context, att api.Attachment, storageKey string) (api.Attachment, error)
+	ListByReception(ctx context.Context, recID uuid.UUID) ([]api.Attachment, error)
+	ListByProduct(ctx context.Context, prodID uuid.UUID) ([]api.Attachment, error)
+	//GetByID returns the attachment and the key of its file in the blob store
+	GetByID(ctx context.Context, id uuid.UUID) (api.Attachment, string, error)
+}
+type DamageReport interface {
+	//SetCondition flags a product of an in progress reception, a condition other than ok waits for a moderator
+	SetCondition(ctx context.Context, prodID uuid.UUID, input api.ProductConditionInput, userID uuid.UUID) (api.Product, error)
+	List(ctx context.Context, params api.GetDamageReportsParams) ([]api.DamageReport, error)
+	//Decide reviews a pending report, a rejected one puts the product back to ok
+	Decide(ctx context.Context, reportID uuid.UUID, decision api.DamageReportDecision, userID uuid.UUID) (api.DamageReport, error)
+}
+type Report interface {
+	//Volume sums daily product counters of PVZs grouped by the period and the given fields
+	Volume(ctx context.Context, params api.GetReportsVolumeParams) ([]api.VolumeReportRow, error)
+	//Employees computes productivity of employees by PVZ over receptions they opened
+	Employees(ctx context.Context, params api.GetReportsEmployeesParams) ([]api.EmployeeProductivityRow, error)
+}
+type Export interface {
+	//Receptions streams receptions matching the filter oldest first
+	Receptions(ctx context.Context, filter ExportFilter, fn func(ExportReception) error) error
+	//Products streams not deleted products of receptions matching the filter in scan order
+	Products(ctx context.Context, filter ExportFilter, fn func(ExportProduct) error) error
 }
 type Repository struct {
 	User
 	PVZ
 	Reception
+	Product
+	PickupCode
+	ProductType
+	Manifest
+	StorageCell
+	Transfer
+	Idempotency
+	Attachment
+	DamageReport
+	Report
+	Export
 }
 
 func NewRepository(db *sql.DB) *Repository {
 	return &Repository{
-		User:      NewUserPostgres(db),
-		PVZ:       NewPVZPostgres(db),
-		Reception: NewReceptionPostgres(db),
+		User:         NewUserPostgres(db),
+		PVZ:          NewPVZPostgres(db),
+		Reception:    NewReceptionPostgres(db),
+		Product:      NewProductPostgres(db),
+		PickupCode:   NewPickupCodePostgres(db),
+		ProductType:  NewProductTypePostgres(db),
+		Manifest:     NewManifestPostgres(db),
+		StorageCell:  NewStorageCellPostgres(db),
+		Transfer:     NewTransferPostgres(db),
+		Idempotency:  NewIdempotencyPostgres(db),
+		Attachment:   NewAttachmentPostgres(db),
+		DamageReport: NewDamageReportPostgres(db),
+		Report:       NewReportPostgres(db),
+		Export:       NewExportPostgres(db),
 	}
 }
@@ -0,0 +1,399 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/google/uuid"
+)
+
+const (
+	storageCellCodeKey = "storage_cells_pvz_code_key"
+	storageCellPVZKey  = "storage_cells_pvz_id_fkey"
+)
+
+// occupiedStates are states of products that physically lie in a cell
+var occupiedStates = []api.ProductState{api.ProductStateReceived, api.ProductStateStored}
+
+// cellOccupied counts products lying in a cell aliased as c
+const cellOccupied = "(SELECT COUNT(*) FROM " + productsTable + " p WHERE p.cell_id = c.id AND p.deleted_at IS NULL AND p.state IN ('received', 'stored'))"
+
+// storageCellColumns are selected or returned for a cell aliased as c, in order of storageCellFields
+const storageCellColumns = "c.id, c.pvz_id, c.code, c.capacity, " + cellOccupied + ", c.active, c.created_at"
+
+// storageCellFields returns scan destinations for storageCellColumns
+func storageCellFields(c *api.StorageCell) []any {
+	return []any{&c.Id, &c.PvzId, &c.Code, &c.Capacity, &c.Occupied, &c.Active, &c.CreatedAt}
+}
+
+type StorageCellPostgres struct {
+	db *sql.DB
+}
+
+func NewStorageCellPostgres(db *sql.DB) *StorageCellPostgres {
+	return &StorageCellPostgres{db: db}
+}
+
+// List returns cells of the PVZ ordered by code, can return ErrPVZNotFound
+func (s *StorageCellPostgres) List(ctx context.Context, pvzID uuid.UUID) ([]api.StorageCell, error) {
+	const op = "repository.storage_cell.List"
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	var exists bool
+	err := psql.Select("COUNT(*)>0").
+		From(pvzTable).
+		Where(squirrel.Eq{"id": pvzID}).
+		RunWith(s.db).
+		QueryRowContext(ctx).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !exists {
+		return nil, errs.ErrPVZNotFound
+	}
+
+	rows, err := psql.Select(storageCellColumns).
+		From(storageCellsTable + " c").
+		Where(squirrel.Eq{"c.pvz_id": pvzID}).
+		OrderBy("c.code").
+		RunWith(s.db).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	res := []api.StorageCell{}
+	for rows.Next() {
+		var cell api.StorageCell
+		if err := rows.Scan(storageCellFields(&cell)...); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		res = append(res, cell)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return res, nil
+}
+
+// Create can return ErrPVZNotFound and ErrStorageCellExists
+func (s *StorageCellPostgres) Create(ctx context.Context, pvzID uuid.UUID, cell api.StorageCellInput) (api.StorageCell, error) {
+	const op = "repository.storage_cell.Create"
+
+	var res api.StorageCell
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	err := psql.Insert(storageCellsTable+" AS c").
+		Columns("pvz_id", "code", "capacity").
+		Values(pvzID, cell.Code, cell.Capacity).
+		Suffix("RETURNING " + storageCellColumns).
+		RunWith(s.db).
+		QueryRowContext(ctx).Scan(storageCellFields(&res)...)
+	if err != nil {
+		if isUniqueViolation(err, storageCellCodeKey) {
+			return api.StorageCell{}, errs.ErrStorageCellExists
+		}
+		if isForeignKeyViolation(err, storageCellPVZKey) {
+			return api.StorageCell{}, errs.ErrPVZNotFound
+		}
+		return api.StorageCell{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return res, nil
+}
+
+// Update changes capacity and availability of the cell,
+// can return ErrStorageCellNotFound and ErrCapacityBelowOccupied
+func (s *StorageCellPostgres) Update(ctx context.Context, pvzID, cellID uuid.UUID, cell api.StorageCellUpdate) (api.StorageCell, error) {
+	const op = "repository.storage_cell.Update"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return api.StorageCell{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	occupied, err := lockCell(ctx, tx, pvzID, cellID)
+	if err != nil {
+		if errors.Is(err, errs.ErrStorageCellNotFound) {
+			return api.StorageCell{}, err
+		}
+		return api.StorageCell{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if cell.Capacity < occupied {
+		return api.StorageCell{}, errs.ErrCapacityBelowOccupied
+	}
+
+	var res api.StorageCell
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	err = psql.Update(storageCellsTable+" c").
+		Set("capacity", cell.Capacity).
+		Set("active", cell.Active).
+		Where(squirrel.Eq{"c.id": cellID}).
+		Suffix("RETURNING " + storageCellColumns).
+		RunWith(tx).
+		QueryRowContext(ctx).Scan(storageCellFields(&res)...)
+	if err != nil {
+		return api.StorageCell{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return api.StorageCell{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return res, nil
+}
+
+// Delete removes an empty cell, products that were taken from it forget the cell.
+// Can return ErrStorageCellNotFound and ErrStorageCellNotEmpty
+func (s *StorageCellPostgres) Delete(ctx context.Context, pvzID, cellID uuid.UUID) error {
+	const op = "repository.storage_cell.Delete"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	occupied, err := lockCell(ctx, tx, pvzID, cellID)
+	if err != nil {
+		if errors.Is(err, errs.ErrStorageCellNotFound) {
+			return err
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if occupied > 0 {
+		return errs.ErrStorageCellNotEmpty
+	}
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	_, err = psql.Delete(storageCellsTable).
+		Where(squirrel.Eq{"id": cellID}).
+		RunWith(tx).
+		ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// Suggest returns the least occupied active cell of the PVZ with room for count products,
+// can return ErrNoFreeStorageCell
+func (s *StorageCellPostgres) Suggest(ctx context.Context, pvzID uuid.UUID, count int) (api.StorageCell, error) {
+	const op = "repository.storage_cell.Suggest"
+
+	var cell api.StorageCell
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	err := psql.Select(storageCellColumns).
+		From(storageCellsTable+" c").
+		Where(squirrel.Eq{"c.pvz_id": pvzID, "c.active": true}).
+		Where("c.capacity - "+cellOccupied+" >= ?", count).
+		OrderBy(cellOccupied, "c.code").
+		Limit(1).
+		RunWith(s.db).
+		QueryRowContext(ctx).Scan(storageCellFields(&cell)...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return api.StorageCell{}, errs.ErrNoFreeStorageCell
+		}
+		return api.StorageCell{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return cell, nil
+}
+
+// Place puts received or stored products of the PVZ into the cell, moving them out of their previous cells,
+// either all of them or none. Can return ErrStorageCellNotFound, ErrStorageCellInactive, ErrStorageCellFull,
+// ErrProductNotFound and ErrInvalidProductState
+func (s *StorageCellPostgres) Place(ctx context.Context, pvzID, cellID uuid.UUID, ids []uuid.UUID) ([]api.Product, error) {
+	const op = "repository.storage_cell.Place"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if err := reserveCell(ctx, tx, pvzID, cellID, len(ids), ids); err != nil {
+		if isStorageCellError(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	rows, err := psql.Select("p.id", "p.state").
+		From(productsTable + " p").
+		Join(receptionsTable + " r ON r.id = p.current_reception_id").
+		Where(squirrel.Eq{"p.id": ids, "p.deleted_at": nil, "r.pvz_id": pvzID}).
+		Suffix("FOR UPDATE OF p").
+		RunWith(tx).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	states := make(map[uuid.UUID]api.ProductState, len(ids))
+	for rows.Next() {
+		var (
+			id    uuid.UUID
+			state api.ProductState
+		)
+		if err := rows.Scan(&id, &state); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		states[id] = state
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	for _, id := range ids {
+		state, ok := states[id]
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("%w: %s", errs.ErrProductNotFound, id)
+		case state != api.ProductStateReceived && state != api.ProductStateStored:
+			return nil, fmt.Errorf("%w: %s is %s", errs.ErrInvalidProductState, id, state)
+		}
+	}
+
+	rows, err = psql.Update(productsTable).
+		Set("cell_id", cellID).
+		Where(squirrel.Eq{"id": ids}).
+		Suffix("RETURNING " + productColumns).
+		RunWith(tx).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	res := make([]api.Product, 0, len(ids))
+	for rows.Next() {
+		var prod api.Product
+		if err := rows.Scan(productFields(&prod)...); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		res = append(res, prod)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return res, nil
+}
+
+// lockCell locks the cell of the PVZ until the end of the transaction and returns the number of products in it,
+// can return ErrStorageCellNotFound
+func lockCell(ctx context.Context, tx *sql.Tx, pvzID, cellID uuid.UUID) (int, error) {
+	var occupied int
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	err := psql.Select(cellOccupied).
+		From(storageCellsTable + " c").
+		Where(squirrel.Eq{"c.id": cellID, "c.pvz_id": pvzID}).
+		Suffix("FOR UPDATE").
+		RunWith(tx).
+		QueryRowContext(ctx).Scan(&occupied)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, errs.ErrStorageCellNotFound
+		}
+		return 0, err
+	}
+	return occupied, nil
+}
+
+// reserveCell locks the cell of the PVZ and checks that it is active and has room for n more products,
+// products listed in moving don't count as already lying in it.
+// Can return ErrStorageCellNotFound, ErrStorageCellInactive and ErrStorageCellFull
+func reserveCell(ctx context.Context, tx *sql.Tx, pvzID, cellID uuid.UUID, n int, moving []uuid.UUID) error {
+	var (
+		capacity int
+		active   bool
+	)
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	err := psql.Select("capacity", "active").
+		From(storageCellsTable).
+		Where(squirrel.Eq{"id": cellID, "pvz_id": pvzID}).
+		Suffix("FOR UPDATE").
+		RunWith(tx).
+		QueryRowContext(ctx).Scan(&capacity, &active)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errs.ErrStorageCellNotFound
+		}
+		return err
+	}
+	if !active {
+		return errs.ErrStorageCellInactive
+	}
+
+	query := psql.Select("COUNT(*)").
+		From(productsTable).
+		Where(squirrel.Eq{"cell_id": cellID, "deleted_at": nil, "state": occupiedStates})
+	if len(moving) > 0 {
+		query = query.Where(squirrel.NotEq{"id": moving})
+	}
+	var occupied int
+	if err := query.RunWith(tx).QueryRowContext(ctx).Scan(&occupied); err != nil {
+		return err
+	}
+	if occupied+n > capacity {
+		return errs.ErrStorageCellFull
+	}
+	return nil
+}
+
+// reserveScannedCells reserves room in cells products of the reception are scanned into,
+// cells are locked in a fixed order to avoid deadlocks between concurrent batches
+func reserveScannedCells(ctx context.Context, tx *sql.Tx, recID uuid.UUID, products []api.ProductInput) error {
+	counts := make(map[uuid.UUID]int)
+	for _, p := range products {
+		if p.CellId != nil {
+			counts[*p.CellId]++
+		}
+	}
+	if len(counts) == 0 {
+		return nil
+	}
+	cells := make([]uuid.UUID, 0, len(counts))
+	for id := range counts {
+		cells = append(cells, id)
+	}
+	slices.SortFunc(cells, func(a, b uuid.UUID) int { return strings.Compare(a.String(), b.String()) })
+
+	var pvzID uuid.UUID
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	err := psql.Select("pvz_id").
+		From(receptionsTable).
+		Where(squirrel.Eq{"id": recID}).
+		RunWith(tx).
+		QueryRowContext(ctx).Scan(&pvzID)
+	if err != nil {
+		return err
+	}
+	for _, id := range cells {
+		if err := reserveCell(ctx, tx, pvzID, id, counts[id], nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isStorageCellError(err error) bool {
+	return errors.Is(err, errs.ErrStorageCellNotFound) ||
+		errors.Is(err, errs.ErrStorageCellInactive) ||
+		errors.Is(err, errs.ErrStorageCellFull)
+}
@@ -0,0 +1,312 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var storageCellRowColumns = []string{"id", "pvz_id", "code", "capacity", "occupied", "active", "created_at"}
+
+func TestStorageCellPostgres_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewStorageCellPostgres(db)
+	pvzID := uuid.New()
+	cellID := uuid.New()
+	now := time.Now()
+	input := api.StorageCellInput{Code: "A-01", Capacity: 10}
+
+	tests := []struct {
+		name        string
+		mockSetup   func()
+		expected    api.StorageCell
+		expectedErr error
+	}{
+		{
+			name: "created",
+			mockSetup: func() {
+				rows := sqlmock.NewRows(storageCellRowColumns).
+					AddRow(cellID, pvzID, "A-01", 10, 0, true, now)
+				mock.ExpectQuery(`INSERT INTO storage_cells AS c \(pvz_id,code,capacity\) VALUES \(\$1,\$2,\$3\) RETURNING c.id`).
+					WithArgs(pvzID, "A-01", 10).
+					WillReturnRows(rows)
+			},
+			expected: api.StorageCell{Id: cellID, PvzId: pvzID, Code: "A-01", Capacity: 10, Active: true, CreatedAt: now},
+		},
+		{
+			name: "code taken in this pvz",
+			mockSetup: func() {
+				mock.ExpectQuery("INSERT INTO storage_cells").
+					WithArgs(pvzID, "A-01", 10).
+					WillReturnError(&pq.Error{Code: uniqueViolationCode, Constraint: storageCellCodeKey})
+			},
+			expectedErr: errs.ErrStorageCellExists,
+		},
+		{
+			name: "pvz not found",
+			mockSetup: func() {
+				mock.ExpectQuery("INSERT INTO storage_cells").
+					WithArgs(pvzID, "A-01", 10).
+					WillReturnError(&pq.Error{Code: foreignKeyViolationCode, Constraint: storageCellPVZKey})
+			},
+			expectedErr: errs.ErrPVZNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			result, err := repo.Create(context.Background(), pvzID, input)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStorageCellPostgres_Update(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewStorageCellPostgres(db)
+	pvzID := uuid.New()
+	cellID := uuid.New()
+	now := time.Now()
+	input := api.StorageCellUpdate{Capacity: 5, Active: false}
+
+	tests := []struct {
+		name        string
+		mockSetup   func()
+		expectedErr error
+	}{
+		{
+			name: "updated",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT \(SELECT COUNT\(\*\) FROM products p (.+)\) FROM storage_cells c WHERE c.id = \$1 AND c.pvz_id = \$2 FOR UPDATE`).
+					WithArgs(cellID, pvzID).
+					WillReturnRows(sqlmock.NewRows([]string{"occupied"}).AddRow(3))
+				mock.ExpectQuery(`UPDATE storage_cells c SET capacity = \$1, active = \$2 WHERE c.id = \$3 RETURNING`).
+					WithArgs(5, false, cellID).
+					WillReturnRows(sqlmock.NewRows(storageCellRowColumns).AddRow(cellID, pvzID, "A-01", 5, 3, false, now))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "capacity below occupied",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM storage_cells c").
+					WithArgs(cellID, pvzID).
+					WillReturnRows(sqlmock.NewRows([]string{"occupied"}).AddRow(6))
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrCapacityBelowOccupied,
+		},
+		{
+			name: "cell of another pvz",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM storage_cells c").
+					WithArgs(cellID, pvzID).
+					WillReturnRows(sqlmock.NewRows([]string{"occupied"}))
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrStorageCellNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			result, err := repo.Update(context.Background(), pvzID, cellID, input)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 5, result.Capacity)
+				assert.Equal(t, 3, result.Occupied)
+				assert.False(t, result.Active)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStorageCellPostgres_Suggest(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewStorageCellPostgres(db)
+	pvzID := uuid.New()
+	cellID := uuid.New()
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		mockSetup   func()
+		expectedErr error
+	}{
+		{
+			name: "least occupied cell",
+			mockSetup: func() {
+				mock.ExpectQuery(`SELECT (.+) FROM storage_cells c WHERE c.active = \$1 AND c.pvz_id = \$2 AND c.capacity - (.+) >= \$3 ORDER BY (.+), c.code LIMIT 1`).
+					WithArgs(true, pvzID, 2).
+					WillReturnRows(sqlmock.NewRows(storageCellRowColumns).AddRow(cellID, pvzID, "B-02", 10, 1, true, now))
+			},
+		},
+		{
+			name: "no room anywhere",
+			mockSetup: func() {
+				mock.ExpectQuery("SELECT (.+) FROM storage_cells c").
+					WithArgs(true, pvzID, 2).
+					WillReturnRows(sqlmock.NewRows(storageCellRowColumns))
+			},
+			expectedErr: errs.ErrNoFreeStorageCell,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			result, err := repo.Suggest(context.Background(), pvzID, 2)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, cellID, result.Id)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStorageCellPostgres_Place(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewStorageCellPostgres(db)
+	pvzID := uuid.New()
+	cellID := uuid.New()
+	prodID := uuid.New()
+	recID := uuid.New()
+	now := time.Now()
+	productColumnNames := []string{"id", "date", "reception_id", "type", "barcode", "external_order_id", "state", "state_changed_at", "return_condition", "original_order_id", "cell_id", "scan_seq", "condition"}
+
+	expectReserve := func(capacity int, active bool, occupied int) {
+		mock.ExpectQuery(`SELECT capacity, active FROM storage_cells WHERE id = \$1 AND pvz_id = \$2 FOR UPDATE`).
+			WithArgs(cellID, pvzID).
+			WillReturnRows(sqlmock.NewRows([]string{"capacity", "active"}).AddRow(capacity, active))
+		if !active {
+			return
+		}
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM products WHERE cell_id = \$1 AND deleted_at IS NULL AND state IN \(\$2,\$3\) AND id NOT IN \(\$4\)`).
+			WithArgs(cellID, api.ProductStateReceived, api.ProductStateStored, prodID).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(occupied))
+	}
+
+	tests := []struct {
+		name        string
+		mockSetup   func()
+		expectedErr error
+	}{
+		{
+			name: "moved into cell",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectReserve(2, true, 1)
+				mock.ExpectQuery(`SELECT p.id, p.state FROM products p JOIN receptions r ON r.id = p.current_reception_id WHERE p.deleted_at IS NULL AND p.id IN \(\$1\) AND r.pvz_id = \$2 FOR UPDATE OF p`).
+					WithArgs(prodID, pvzID).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "state"}).AddRow(prodID, api.ProductStateStored))
+				mock.ExpectQuery(`UPDATE products SET cell_id = \$1 WHERE id IN \(\$2\) RETURNING`).
+					WithArgs(cellID, prodID).
+					WillReturnRows(sqlmock.NewRows(productColumnNames).
+						AddRow(prodID, now, recID, "shoes", "123", nil, api.ProductStateStored, now, nil, nil, cellID, 1, "ok"))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "cell full",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectReserve(2, true, 2)
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrStorageCellFull,
+		},
+		{
+			name: "cell inactive",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectReserve(2, false, 0)
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrStorageCellInactive,
+		},
+		{
+			name: "product already issued",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectReserve(2, true, 0)
+				mock.ExpectQuery("SELECT p.id, p.state FROM products p").
+					WithArgs(prodID, pvzID).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "state"}).AddRow(prodID, api.ProductStateIssued))
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrInvalidProductState,
+		},
+		{
+			name: "product of another pvz",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectReserve(2, true, 0)
+				mock.ExpectQuery("SELECT p.id, p.state FROM products p").
+					WithArgs(prodID, pvzID).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "state"}))
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrProductNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			result, err := repo.Place(context.Background(), pvzID, cellID, []uuid.UUID{prodID})
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				require.Len(t, result, 1)
+				assert.Equal(t, &cellID, result[0].CellId)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
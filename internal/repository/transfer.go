@@ -0,0 +1,519 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	defaultTransfersLimit = 30
+
+	transferSourceKey      = "transfers_source_pvz_id_fkey"
+	transferDestinationKey = "transfers_destination_pvz_id_fkey"
+	transferItemActiveKey  = "idx_transfer_items_one_active"
+)
+
+// transferColumns are selected for a transfer aliased as t, in order of transferFields
+const transferColumns = "t.id, t.source_pvz_id, t.destination_pvz_id, t.status, " +
+	"ARRAY(SELECT i.product_id FROM " + transferItemsTable + " i WHERE i.transfer_id = t.id ORDER BY i.position), " +
+	"t.reception_id, t.created_by, t.created_at, t.dispatched_by, t.dispatched_at, t.arrived_by, t.arrived_at"
+
+// transferFields returns scan destinations for transferColumns
+func transferFields(t *api.Transfer) []any {
+	return []any{&t.Id, &t.SourcePvzId, &t.DestinationPvzId, &t.Status, pq.Array(&t.ProductIds),
+		&t.ReceptionId, &t.CreatedBy, &t.CreatedAt, &t.DispatchedBy, &t.DispatchedAt, &t.ArrivedBy, &t.ArrivedAt}
+}
+
+type TransferPostgres struct {
+	db *sql.DB
+}
+
+func NewTransferPostgres(db *sql.DB) *TransferPostgres {
+	return &TransferPostgres{db: db}
+}
+
+// Create stores a transfer of stored products of the source PVZ, userID is stored as the creator unless it is uuid.Nil.
+// Can return ErrPVZNotFound, ErrProductNotFound, ErrProductInOpenReception, ErrInvalidProductState and ErrProductInTransfer
+func (t *TransferPostgres) Create(ctx context.Context, transfer api.TransferInput, userID uuid.UUID) (api.Transfer, error) {
+	const op = "repository.transfer.Create"
+
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return api.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var id uuid.UUID
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	err = psql.Insert(transfersTable).
+		Columns("source_pvz_id", "destination_pvz_id", "created_by").
+		Values(transfer.SourcePvzId, transfer.DestinationPvzId, userOrNil(userID)).
+		Suffix("RETURNING id").
+		RunWith(tx).
+		QueryRowContext(ctx).Scan(&id)
+	if err != nil {
+		if isForeignKeyViolation(err, transferSourceKey) || isForeignKeyViolation(err, transferDestinationKey) {
+			return api.Transfer{}, errs.ErrPVZNotFound
+		}
+		return api.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// product rows are locked, so they can't be issued or returned while the transfer is created
+	rows, err := psql.Select("p.id", "p.state", "p.current_reception_id").
+		From(productsTable + " p").
+		Join(receptionsTable + " r ON r.id = p.current_reception_id").
+		Where(squirrel.Eq{"p.id": transfer.ProductIds, "p.deleted_at": nil, "r.pvz_id": transfer.SourcePvzId}).
+		Suffix("FOR UPDATE OF p").
+		RunWith(tx).
+		QueryContext(ctx)
+	if err != nil {
+		return api.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+	type stored struct {
+		state     api.ProductState
+		reception uuid.UUID
+	}
+	found := make(map[uuid.UUID]stored, len(transfer.ProductIds))
+	for rows.Next() {
+		var (
+			id uuid.UUID
+			p  stored
+		)
+		if err := rows.Scan(&id, &p.state, &p.reception); err != nil {
+			rows.Close()
+			return api.Transfer{}, fmt.Errorf("%s: %w", op, err)
+		}
+		found[id] = p
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return api.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	items := psql.Insert(transferItemsTable).
+		Columns("transfer_id", "product_id", "position", "source_reception_id")
+	for i, prodID := range transfer.ProductIds {
+		p, ok := found[prodID]
+		if err := checkStored(prodID, p.state, ok); err != nil {
+			return api.Transfer{}, err
+		}
+		items = items.Values(id, prodID, i, p.reception)
+	}
+	if _, err := items.RunWith(tx).ExecContext(ctx); err != nil {
+		if isUniqueViolation(err, transferItemActiveKey) {
+			return api.Transfer{}, errs.ErrProductInTransfer
+		}
+		return api.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	res, err := getTransfer(ctx, tx, id)
+	if err != nil {
+		return api.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return api.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return res, nil
+}
+
+// Dispatch takes products of a created transfer out of the source PVZ: they become in_transit,
+// leave their storage cells and lose their pickup codes. Can return ErrTransferNotFound, ErrInvalidTransferStatus,
+// ErrProductInOpenReception and ErrInvalidProductState if a product is no longer stored
+func (t *TransferPostgres) Dispatch(ctx context.Context, transferID, userID uuid.UUID) (api.Transfer, error) {
+	const op = "repository.transfer.Dispatch"
+
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return api.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := lockTransfer(ctx, tx, transferID, api.TransferStatusCreated); err != nil {
+		if errors.Is(err, errs.ErrTransferNotFound) || errors.Is(err, errs.ErrInvalidTransferStatus) {
+			return api.Transfer{}, err
+		}
+		return api.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	rows, err := psql.Select("p.id", "p.state").
+		From(productsTable + " p").
+		Join(transferItemsTable + " i ON i.product_id = p.id").
+		Where(squirrel.Eq{"i.transfer_id": transferID}).
+		OrderBy("i.position").
+		Suffix("FOR UPDATE OF p").
+		RunWith(tx).
+		QueryContext(ctx)
+	if err != nil {
+		return api.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+	for rows.Next() {
+		var (
+			id    uuid.UUID
+			state api.ProductState
+		)
+		if err := rows.Scan(&id, &state); err != nil {
+			rows.Close()
+			return api.Transfer{}, fmt.Errorf("%s: %w", op, err)
+		}
+		if err := checkStored(id, state, true); err != nil {
+			rows.Close()
+			return api.Transfer{}, err
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return api.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	items, args, err := squirrel.Select("product_id").
+		From(transferItemsTable).
+		Where(squirrel.Eq{"transfer_id": transferID}).
+		ToSql()
+	if err != nil {
+		return api.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+	// a code of an order split between PVZs can't be used anymore, new codes are sent after the arrival
+	codes, codeArgs, err := squirrel.Select("pickup_code_id").
+		From(productsTable).
+		Where("id IN ("+items+")", args...).
+		ToSql()
+	if err != nil {
+		return api.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+	_, err = psql.Update(pickupCodesTable).
+		Set("revoked_at", squirrel.Expr("now()")).
+		Where("id IN ("+codes+")", codeArgs...).
+		Where(squirrel.Eq{"used_at": nil, "revoked_at": nil}).
+		RunWith(tx).
+		ExecContext(ctx)
+	if err != nil {
+		return api.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+	_, err = psql.Update(productsTable).
+		Set("state", api.ProductStateInTransit).
+		Set("state_changed_at", squirrel.Expr("now()")).
+		Set("cell_id", nil).
+		Set("pickup_code_id", nil).
+		Where("id IN ("+items+")", args...).
+		RunWith(tx).
+		ExecContext(ctx)
+	if err != nil {
+		return api.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = psql.Update(transfersTable).
+		Set("status", api.TransferStatusDispatched).
+		Set("dispatched_by", userOrNil(userID)).
+		Set("dispatched_at", squirrel.Expr("now()")).
+		Where(squirrel.Eq{"id": transferID}).
+		RunWith(tx).
+		ExecContext(ctx)
+	if err != nil {
+		return api.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	res, err := getTransfer(ctx, tx, transferID)
+	if err != nil {
+		return api.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return api.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return res, nil
+}
+
+// Arrive puts products of a dispatched transfer under the inbound reception in progress at the destination PVZ,
+// the products stay in the history of the reception they were scanned into.
+// Can return ErrTransferNotFound, ErrInvalidTransferStatus, ErrNoReceptionsInProgress and ErrDuplicateBarcode
+func (t *TransferPostgres) Arrive(ctx context.Context, transferID, userID uuid.UUID) (api.Transfer, error) {
+	const op = "repository.transfer.Arrive"
+
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return api.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	destination, err := lockTransfer(ctx, tx, transferID, api.TransferStatusDispatched)
+	if err != nil {
+		if errors.Is(err, errs.ErrTransferNotFound) || errors.Is(err, errs.ErrInvalidTransferStatus) {
+			return api.Transfer{}, err
+		}
+		return api.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var recID uuid.UUID
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	err = psql.Select("id").
+		From(receptionsTable).
+		Where(squirrel.Eq{"pvz_id": destination, "status": api.InProgress, "kind": api.ReceptionKindInbound}).
+		RunWith(tx).
+		QueryRowContext(ctx).Scan(&recID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return api.Transfer{}, errs.ErrNoReceptionsInProgress
+		}
+		return api.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	items, args, err := squirrel.Select("product_id").
+		From(transferItemsTable).
+		Where(squirrel.Eq{"transfer_id": transferID}).
+		ToSql()
+	if err != nil {
+		return api.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+	rows, err := psql.Select("barcode").
+		From(productsTable).
+		Where("id IN ("+items+")", args...).
+		RunWith(tx).
+		QueryContext(ctx)
+	if err != nil {
+		return api.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+	var scanned []api.ProductInput
+	for rows.Next() {
+		var barcode *string
+		if err := rows.Scan(&barcode); err != nil {
+			rows.Close()
+			return api.Transfer{}, fmt.Errorf("%s: %w", op, err)
+		}
+		if barcode != nil {
+			scanned = append(scanned, api.ProductInput{Barcode: barcode})
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return api.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := lockReceptionInProgress(ctx, tx, recID); err != nil {
+		if errors.Is(err, errs.ErrNoReceptionsInProgress) {
+			return api.Transfer{}, err
+		}
+		return api.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := checkBarcodes(ctx, tx, recID, scanned); err != nil {
+		if errors.Is(err, errs.ErrDuplicateBarcode) {
+			return api.Transfer{}, err
+		}
+		return api.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// reception_id and scan_seq keep the reception the product was scanned into, only its location moves
+	_, err = psql.Update(productsTable).
+		Set("current_reception_id", recID).
+		Set("state", api.ProductStateReceived).
+		Set("state_changed_at", squirrel.Expr("now()")).
+		Where("id IN ("+items+")", args...).
+		RunWith(tx).
+		ExecContext(ctx)
+	if err != nil {
+		return api.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+	_, err = psql.Update(transferItemsTable).
+		Set("active", false).
+		Where(squirrel.Eq{"transfer_id": transferID}).
+		RunWith(tx).
+		ExecContext(ctx)
+	if err != nil {
+		return api.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+	_, err = psql.Update(transfersTable).
+		Set("status", api.TransferStatusArrived).
+		Set("reception_id", recID).
+		Set("arrived_by", userOrNil(userID)).
+		Set("arrived_at", squirrel.Expr("now()")).
+		Where(squirrel.Eq{"id": transferID}).
+		RunWith(tx).
+		ExecContext(ctx)
+	if err != nil {
+		return api.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	res, err := getTransfer(ctx, tx, transferID)
+	if err != nil {
+		return api.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return api.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return res, nil
+}
+
+// List returns transfers matching all given filters newest first, pvzId matches either end of a transfer
+func (t *TransferPostgres) List(ctx context.Context, params api.GetTransfersParams) ([]api.Transfer, error) {
+	const op = "repository.transfer.List"
+
+	limit := defaultTransfersLimit
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+	offset := 0
+	if params.Page != nil {
+		offset = (*params.Page - 1) * limit
+	}
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	query := psql.Select(transferColumns).From(transfersTable + " t")
+	if params.PvzId != nil {
+		query = query.Where(squirrel.Or{
+			squirrel.Eq{"t.source_pvz_id": *params.PvzId},
+			squirrel.Eq{"t.destination_pvz_id": *params.PvzId},
+		})
+	}
+	if params.Status != nil {
+		query = query.Where(squirrel.Eq{"t.status": *params.Status})
+	}
+	rows, err := query.OrderBy("t.created_at DESC", "t.id").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		RunWith(t.db).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	res, err := scanTransfers(rows)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return res, nil
+}
+
+// GetByID can return ErrTransferNotFound
+func (t *TransferPostgres) GetByID(ctx context.Context, transferID uuid.UUID) (api.Transfer, error) {
+	const op = "repository.transfer.GetByID"
+
+	var res api.Transfer
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	err := psql.Select(transferColumns).
+		From(transfersTable + " t").
+		Where(squirrel.Eq{"t.id": transferID}).
+		RunWith(t.db).
+		QueryRowContext(ctx).Scan(transferFields(&res)...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return api.Transfer{}, errs.ErrTransferNotFound
+		}
+		return api.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return res, nil
+}
+
+// ProductHistory returns transfers the product was included in oldest first, can return ErrProductNotFound
+func (t *TransferPostgres) ProductHistory(ctx context.Context, productID uuid.UUID) ([]api.Transfer, error) {
+	const op = "repository.transfer.ProductHistory"
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	var exists bool
+	err := psql.Select("COUNT(*)>0").
+		From(productsTable).
+		Where(squirrel.Eq{"id": productID, "deleted_at": nil}).
+		RunWith(t.db).
+		QueryRowContext(ctx).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !exists {
+		return nil, errs.ErrProductNotFound
+	}
+
+	rows, err := psql.Select(transferColumns).
+		From(transfersTable+" t").
+		Join(transferItemsTable+" ti ON ti.transfer_id = t.id").
+		Where(squirrel.Eq{"ti.product_id": productID}).
+		OrderBy("t.created_at", "t.id").
+		RunWith(t.db).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	res, err := scanTransfers(rows)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return res, nil
+}
+
+func scanTransfers(rows *sql.Rows) ([]api.Transfer, error) {
+	res := []api.Transfer{}
+	for rows.Next() {
+		var tr api.Transfer
+		if err := rows.Scan(transferFields(&tr)...); err != nil {
+			return nil, err
+		}
+		res = append(res, tr)
+	}
+	return res, rows.Err()
+}
+
+// lockTransfer locks the transfer until the end of the transaction, checks its status and returns the destination PVZ.
+// Can return ErrTransferNotFound and ErrInvalidTransferStatus
+func lockTransfer(ctx context.Context, tx *sql.Tx, transferID uuid.UUID, status api.TransferStatus) (uuid.UUID, error) {
+	var (
+		current     api.TransferStatus
+		destination uuid.UUID
+	)
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	err := psql.Select("status", "destination_pvz_id").
+		From(transfersTable).
+		Where(squirrel.Eq{"id": transferID}).
+		Suffix("FOR UPDATE").
+		RunWith(tx).
+		QueryRowContext(ctx).Scan(&current, &destination)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return uuid.Nil, errs.ErrTransferNotFound
+		}
+		return uuid.Nil, err
+	}
+	if current != status {
+		return uuid.Nil, fmt.Errorf("%w: transfer is %s", errs.ErrInvalidTransferStatus, current)
+	}
+	return destination, nil
+}
+
+func getTransfer(ctx context.Context, tx *sql.Tx, transferID uuid.UUID) (api.Transfer, error) {
+	var res api.Transfer
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	err := psql.Select(transferColumns).
+		From(transfersTable + " t").
+		Where(squirrel.Eq{"t.id": transferID}).
+		RunWith(tx).
+		QueryRowContext(ctx).Scan(transferFields(&res)...)
+	return res, err
+}
+
+// checkStored makes sure a product found in the PVZ is stored there, products of open receptions can't leave it yet
+func checkStored(id uuid.UUID, state api.ProductState, found bool) error {
+	switch {
+	case !found:
+		return fmt.Errorf("%w: %s", errs.ErrProductNotFound, id)
+	case state == api.ProductStateStored:
+		return nil
+	case state == api.ProductStateReceived:
+		return fmt.Errorf("%w: %s", errs.ErrProductInOpenReception, id)
+	default:
+		return fmt.Errorf("%w: %s is %s", errs.ErrInvalidProductState, id, state)
+	}
+}
+
+// userOrNil returns nil for uuid.Nil, tokens of /dummyLogin have no user
+func userOrNil(userID uuid.UUID) *uuid.UUID {
+	if userID == uuid.Nil {
+		return nil
+	}
+	return &userID
+}
@@ -0,0 +1,379 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var transferRowColumns = []string{"id", "source_pvz_id", "destination_pvz_id", "status", "product_ids", "reception_id", "created_by", "created_at", "dispatched_by", "dispatched_at", "arrived_by", "arrived_at"}
+
+func TestTransferPostgres_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewTransferPostgres(db)
+	transferID := uuid.New()
+	source, destination := uuid.New(), uuid.New()
+	prodID := uuid.New()
+	recID := uuid.New()
+	userID := uuid.New()
+	now := time.Now()
+	input := api.TransferInput{SourcePvzId: source, DestinationPvzId: destination, ProductIds: []uuid.UUID{prodID}}
+
+	expectInsert := func() {
+		mock.ExpectQuery(`INSERT INTO transfers \(source_pvz_id,destination_pvz_id,created_by\) VALUES \(\$1,\$2,\$3\) RETURNING id`).
+			WithArgs(source, destination, &userID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(transferID))
+	}
+	expectProducts := func(state api.ProductState) {
+		rows := sqlmock.NewRows([]string{"id", "state", "reception_id"})
+		if state != "" {
+			rows.AddRow(prodID, state, recID)
+		}
+		mock.ExpectQuery(`SELECT p.id, p.state, p.current_reception_id FROM products p JOIN receptions r ON r.id = p.current_reception_id WHERE p.deleted_at IS NULL AND p.id IN \(\$1\) AND r.pvz_id = \$2 FOR UPDATE OF p`).
+			WithArgs(prodID, source).
+			WillReturnRows(rows)
+	}
+
+	tests := []struct {
+		name        string
+		mockSetup   func()
+		expectedErr error
+	}{
+		{
+			name: "created",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectInsert()
+				expectProducts(api.ProductStateStored)
+				mock.ExpectExec(`INSERT INTO transfer_items \(transfer_id,product_id,position,source_reception_id\) VALUES \(\$1,\$2,\$3,\$4\)`).
+					WithArgs(transferID, prodID, 0, recID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`SELECT t.id, (.+) FROM transfers t WHERE t.id = \$1`).
+					WithArgs(transferID).
+					WillReturnRows(sqlmock.NewRows(transferRowColumns).
+						AddRow(transferID, source, destination, api.TransferStatusCreated, "{"+prodID.String()+"}", nil, userID, now, nil, nil, nil, nil))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "destination pvz not found",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO transfers").
+					WithArgs(source, destination, &userID).
+					WillReturnError(&pq.Error{Code: foreignKeyViolationCode, Constraint: transferDestinationKey})
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrPVZNotFound,
+		},
+		{
+			name: "product of another pvz",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectInsert()
+				expectProducts("")
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrProductNotFound,
+		},
+		{
+			name: "product in open reception",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectInsert()
+				expectProducts(api.ProductStateReceived)
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrProductInOpenReception,
+		},
+		{
+			name: "product already in transfer",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectInsert()
+				expectProducts(api.ProductStateStored)
+				mock.ExpectExec("INSERT INTO transfer_items").
+					WithArgs(transferID, prodID, 0, recID).
+					WillReturnError(&pq.Error{Code: uniqueViolationCode, Constraint: transferItemActiveKey})
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrProductInTransfer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			result, err := repo.Create(context.Background(), input, userID)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, transferID, result.Id)
+				assert.Equal(t, []uuid.UUID{prodID}, result.ProductIds)
+				assert.Equal(t, &userID, result.CreatedBy)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTransferPostgres_Dispatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewTransferPostgres(db)
+	transferID := uuid.New()
+	source, destination := uuid.New(), uuid.New()
+	prodID := uuid.New()
+	now := time.Now()
+
+	expectLock := func(status api.TransferStatus) {
+		mock.ExpectQuery(`SELECT status, destination_pvz_id FROM transfers WHERE id = \$1 FOR UPDATE`).
+			WithArgs(transferID).
+			WillReturnRows(sqlmock.NewRows([]string{"status", "destination_pvz_id"}).AddRow(status, destination))
+	}
+
+	tests := []struct {
+		name        string
+		mockSetup   func()
+		expectedErr error
+	}{
+		{
+			name: "dispatched",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectLock(api.TransferStatusCreated)
+				mock.ExpectQuery(`SELECT p.id, p.state FROM products p JOIN transfer_items i ON i.product_id = p.id WHERE i.transfer_id = \$1 ORDER BY i.position FOR UPDATE OF p`).
+					WithArgs(transferID).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "state"}).AddRow(prodID, api.ProductStateStored))
+				mock.ExpectExec(`UPDATE pickup_codes SET revoked_at = now\(\) WHERE id IN \(SELECT pickup_code_id FROM products WHERE id IN \(SELECT product_id FROM transfer_items WHERE transfer_id = \$1\)\) AND revoked_at IS NULL AND used_at IS NULL`).
+					WithArgs(transferID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE products SET state = \$1, state_changed_at = now\(\), cell_id = \$2, pickup_code_id = \$3 WHERE id IN \(SELECT product_id FROM transfer_items WHERE transfer_id = \$4\)`).
+					WithArgs(api.ProductStateInTransit, nil, nil, transferID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE transfers SET status = \$1, dispatched_by = \$2, dispatched_at = now\(\) WHERE id = \$3`).
+					WithArgs(api.TransferStatusDispatched, nil, transferID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`SELECT (.+) FROM transfers t WHERE t.id = \$1`).
+					WithArgs(transferID).
+					WillReturnRows(sqlmock.NewRows(transferRowColumns).
+						AddRow(transferID, source, destination, api.TransferStatusDispatched, "{"+prodID.String()+"}", nil, nil, now, nil, now, nil, nil))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "already dispatched",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectLock(api.TransferStatusDispatched)
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrInvalidTransferStatus,
+		},
+		{
+			name: "product issued in the meantime",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				expectLock(api.TransferStatusCreated)
+				mock.ExpectQuery("SELECT p.id, p.state FROM products p JOIN transfer_items i").
+					WithArgs(transferID).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "state"}).AddRow(prodID, api.ProductStateIssued))
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrInvalidProductState,
+		},
+		{
+			name: "transfer not found",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT status, destination_pvz_id FROM transfers").
+					WithArgs(transferID).
+					WillReturnRows(sqlmock.NewRows([]string{"status", "destination_pvz_id"}))
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrTransferNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			result, err := repo.Dispatch(context.Background(), transferID, uuid.Nil)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, api.TransferStatusDispatched, result.Status)
+				assert.Nil(t, result.DispatchedBy)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTransferPostgres_Arrive(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewTransferPostgres(db)
+	transferID := uuid.New()
+	source, destination := uuid.New(), uuid.New()
+	prodID := uuid.New()
+	recID := uuid.New()
+	userID := uuid.New()
+	now := time.Now()
+	barcode := "4607001234567"
+
+	expectStart := func() {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT status, destination_pvz_id FROM transfers").
+			WithArgs(transferID).
+			WillReturnRows(sqlmock.NewRows([]string{"status", "destination_pvz_id"}).AddRow(api.TransferStatusDispatched, destination))
+	}
+
+	tests := []struct {
+		name        string
+		mockSetup   func()
+		expectedErr error
+	}{
+		{
+			name: "received into destination reception",
+			mockSetup: func() {
+				expectStart()
+				mock.ExpectQuery(`SELECT id FROM receptions WHERE kind = \$1 AND pvz_id = \$2 AND status = \$3`).
+					WithArgs(api.ReceptionKindInbound, destination, api.InProgress).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(recID))
+				mock.ExpectQuery(`SELECT barcode FROM products WHERE id IN \(SELECT product_id FROM transfer_items WHERE transfer_id = \$1\)`).
+					WithArgs(transferID).
+					WillReturnRows(sqlmock.NewRows([]string{"barcode"}).AddRow(barcode).AddRow(nil))
+				expectReceptionLock(mock, recID, "FOR UPDATE", api.InProgress)
+				mock.ExpectExec("SELECT pg_advisory_xact_lock").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("SELECT p.barcode FROM products p JOIN receptions r").
+					WithArgs(barcode, api.ProductStateReceived, api.ProductStateStored, "in_progress", recID).
+					WillReturnRows(sqlmock.NewRows([]string{"barcode"}))
+				mock.ExpectExec(`UPDATE products SET current_reception_id = \$1, state = \$2, state_changed_at = now\(\) WHERE id IN \(SELECT product_id FROM transfer_items WHERE transfer_id = \$3\)`).
+					WithArgs(recID, api.ProductStateReceived, transferID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE transfer_items SET active = \$1 WHERE transfer_id = \$2`).
+					WithArgs(false, transferID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE transfers SET status = \$1, reception_id = \$2, arrived_by = \$3, arrived_at = now\(\) WHERE id = \$4`).
+					WithArgs(api.TransferStatusArrived, recID, &userID, transferID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`SELECT (.+) FROM transfers t WHERE t.id = \$1`).
+					WithArgs(transferID).
+					WillReturnRows(sqlmock.NewRows(transferRowColumns).
+						AddRow(transferID, source, destination, api.TransferStatusArrived, "{"+prodID.String()+"}", recID, nil, now, nil, now, userID, now))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "no open reception at destination",
+			mockSetup: func() {
+				expectStart()
+				mock.ExpectQuery("SELECT id FROM receptions").
+					WithArgs(api.ReceptionKindInbound, destination, api.InProgress).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrNoReceptionsInProgress,
+		},
+		{
+			name: "barcode already scanned at destination",
+			mockSetup: func() {
+				expectStart()
+				mock.ExpectQuery("SELECT id FROM receptions").
+					WithArgs(api.ReceptionKindInbound, destination, api.InProgress).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(recID))
+				mock.ExpectQuery("SELECT barcode FROM products").
+					WithArgs(transferID).
+					WillReturnRows(sqlmock.NewRows([]string{"barcode"}).AddRow(barcode))
+				expectReceptionLock(mock, recID, "FOR UPDATE", api.InProgress)
+				mock.ExpectExec("SELECT pg_advisory_xact_lock").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("SELECT p.barcode FROM products p JOIN receptions r").
+					WithArgs(barcode, api.ProductStateReceived, api.ProductStateStored, "in_progress", recID).
+					WillReturnRows(sqlmock.NewRows([]string{"barcode"}).AddRow(barcode))
+				mock.ExpectRollback()
+			},
+			expectedErr: errs.ErrDuplicateBarcode,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			result, err := repo.Arrive(context.Background(), transferID, userID)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, api.TransferStatusArrived, result.Status)
+				assert.Equal(t, &recID, result.ReceptionId)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTransferPostgres_ProductHistory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewTransferPostgres(db)
+	prodID := uuid.New()
+	first, second := uuid.New(), uuid.New()
+	pvz1, pvz2 := uuid.New(), uuid.New()
+	now := time.Now()
+
+	t.Run("transfers oldest first", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT COUNT\(\*\)>0 FROM products WHERE deleted_at IS NULL AND id = \$1`).
+			WithArgs(prodID).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectQuery(`SELECT (.+) FROM transfers t JOIN transfer_items ti ON ti.transfer_id = t.id WHERE ti.product_id = \$1 ORDER BY t.created_at, t.id`).
+			WithArgs(prodID).
+			WillReturnRows(sqlmock.NewRows(transferRowColumns).
+				AddRow(first, pvz1, pvz2, api.TransferStatusArrived, "{"+prodID.String()+"}", nil, nil, now.Add(-time.Hour), nil, nil, nil, nil).
+				AddRow(second, pvz2, pvz1, api.TransferStatusCreated, "{"+prodID.String()+"}", nil, nil, now, nil, nil, nil, nil))
+
+		result, err := repo.ProductHistory(context.Background(), prodID)
+		assert.NoError(t, err)
+		require.Len(t, result, 2)
+		assert.Equal(t, first, result[0].Id)
+		assert.Equal(t, pvz1, result[1].DestinationPvzId)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("product not found", func(t *testing.T) {
+		mock.ExpectQuery("SELECT COUNT").
+			WithArgs(prodID).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+		_, err := repo.ProductHistory(context.Background(), prodID)
+		assert.ErrorIs(t, err, errs.ErrProductNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
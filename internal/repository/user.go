@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -19,7 +20,7 @@ func NewUserPostgres(db *sql.DB) *UserPostgres {
 }
 
 // Create creates user and returns new user's ID(UUID)
-func (u *UserPostgres) Create(email string, password_hash string, role string) (uuid.UUID, error) {
+func (u *UserPostgres) Create(ctx context.Context, email string, password_hash string, role string) (uuid.UUID, error) {
 	const op = "repository.user.Create"
 
 	var id uuid.UUID
@@ -29,33 +30,34 @@ func (u *UserPostgres) Create(email string, password_hash string, role string) (
 		Values(email, password_hash, role).
 		Suffix("RETURNING id").
 		RunWith(u.db).
-		QueryRow().Scan(&id)
+		QueryRowContext(ctx).Scan(&id)
 	if err != nil {
 		return uuid.Nil, fmt.Errorf("%s: %w", op, err)
 	}
 	return id, nil
 }
 
-// Login returns password hash and role of a user with given email
-func (u *UserPostgres) Login(email string) (string, string, error) {
+// Login returns id, password hash and role of a user with given email
+func (u *UserPostgres) Login(ctx context.Context, email string) (uuid.UUID, string, string, error) {
 	const op = "repository.user.Login"
 
+	var id uuid.UUID
 	var passHash, role string
 	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
-	err := psql.Select("password_hash", "role").
+	err := psql.Select("id", "password_hash", "role").
 		From(usersTable).
 		Where(squirrel.Eq{"email": email}).
 		RunWith(u.db).
-		QueryRow().Scan(&passHash, &role)
+		QueryRowContext(ctx).Scan(&id, &passHash, &role)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return "", "", errs.ErrWrongCreds
+			return uuid.Nil, "", "", errs.ErrWrongCreds
 		}
-		return "", "", fmt.Errorf("%s: %w", op, err)
+		return uuid.Nil, "", "", fmt.Errorf("%s: %w", op, err)
 	}
-	return passHash, role, nil
+	return id, passHash, role, nil
 }
-func (u *UserPostgres) EmailExists(email string) (bool, error) {
+func (u *UserPostgres) EmailExists(ctx context.Context, email string) (bool, error) {
 	const op = "repository.user.EmailExists"
 
 	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
@@ -64,7 +66,7 @@ func (u *UserPostgres) EmailExists(email string) (bool, error) {
 		From(usersTable).
 		Where(squirrel.Eq{"email": email}).
 		RunWith(u.db).
-		QueryRow().Scan(&exists)
+		QueryRowContext(ctx).Scan(&exists)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return false, nil
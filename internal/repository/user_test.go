@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"testing"
@@ -64,7 +65,7 @@ func TestUserPostgres_Create(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
-			result, err := repo.Create(tt.email, tt.password, tt.role)
+			result, err := repo.Create(context.Background(), tt.email, tt.password, tt.role)
 
 			if tt.expectedErr != nil {
 				assert.Error(t, err)
@@ -87,6 +88,7 @@ func TestUserPostgres_Login(t *testing.T) {
 	email := "test@example.com"
 	passwordHash := "hashedpassword"
 	role := "admin"
+	userID := uuid.New()
 
 	tests := []struct {
 		name      string
@@ -102,9 +104,9 @@ func TestUserPostgres_Login(t *testing.T) {
 			name:  "successful login",
 			email: email,
 			mockSetup: func() {
-				rows := sqlmock.NewRows([]string{"password_hash", "role"}).
-					AddRow(passwordHash, role)
-				mock.ExpectQuery("SELECT password_hash, role FROM users").
+				rows := sqlmock.NewRows([]string{"id", "password_hash", "role"}).
+					AddRow(userID, passwordHash, role)
+				mock.ExpectQuery("SELECT id, password_hash, role FROM users").
 					WithArgs(email).
 					WillReturnRows(rows)
 			},
@@ -121,7 +123,7 @@ func TestUserPostgres_Login(t *testing.T) {
 			name:  "user not found",
 			email: email,
 			mockSetup: func() {
-				mock.ExpectQuery("SELECT password_hash, role FROM users").
+				mock.ExpectQuery("SELECT id, password_hash, role FROM users").
 					WithArgs(email).
 					WillReturnError(sql.ErrNoRows)
 			},
@@ -135,7 +137,7 @@ func TestUserPostgres_Login(t *testing.T) {
 			name:  "database error",
 			email: email,
 			mockSetup: func() {
-				mock.ExpectQuery("SELECT password_hash, role FROM users").
+				mock.ExpectQuery("SELECT id, password_hash, role FROM users").
 					WithArgs(email).
 					WillReturnError(sql.ErrConnDone)
 			},
@@ -150,7 +152,7 @@ func TestUserPostgres_Login(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
-			hash, role, err := repo.Login(tt.email)
+			id, hash, role, err := repo.Login(context.Background(), tt.email)
 
 			if tt.expectedErr != nil {
 				assert.Error(t, err)
@@ -161,6 +163,7 @@ func TestUserPostgres_Login(t *testing.T) {
 				}
 			} else {
 				assert.NoError(t, err)
+				assert.Equal(t, userID, id)
 				assert.Equal(t, tt.expected.hash, hash)
 				assert.Equal(t, tt.expected.role, role)
 			}
@@ -224,7 +227,7 @@ func TestUserPostgres_EmailExists(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
-			result, err := repo.EmailExists(tt.email)
+			result, err := repo.EmailExists(context.Background(), tt.email)
 
 			if tt.expectedErr != nil {
 				assert.Error(t, err)
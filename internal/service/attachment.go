@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/blobstore"
+	"github.com/ST359/pvz-service/internal/config"
+	"github.com/ST359/pvz-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	maxAttachmentNameLength = 255
+	// sniffLength is how many bytes http.DetectContentType looks at
+	sniffLength = 512
+)
+
+// attachmentTypes are accepted content types, the type is detected from the file itself and not taken from the client
+var attachmentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+}
+
+// AttachmentFile is an uploaded file, Body is read twice so it has to support seeking
+type AttachmentFile struct {
+	Name string
+	Size int64
+	Body io.ReadSeeker
+}
+
+type AttachmentService struct {
+	repo  repository.Attachment
+	blobs blobstore.BlobStore
+	cfg   *config.Config
+}
+
+func NewAttachmentService(repo repository.Attachment, blobs blobstore.BlobStore, cfg *config.Config) *AttachmentService {
+	return &AttachmentService{repo: repo, blobs: blobs, cfg: cfg}
+}
+
+// AttachToReception stores the file as an attachment of the reception on behalf of the user, uuid.Nil leaves the uploader empty.
+// Can return ErrEmptyAttachment, ErrAttachmentTooLarge, ErrUnsupportedAttachmentType and ErrReceptionNotFound
+func (a *AttachmentService) AttachToReception(ctx context.Context, recID uuid.UUID, file AttachmentFile, userID uuid.UUID) (api.Attachment, error) {
+	return a.upload(ctx, api.Attachment{ReceptionId: &recID}, file, userID)
+}
+
+// AttachToProduct stores the file as an attachment of the product on behalf of the user, uuid.Nil leaves the uploader empty.
+// Can return ErrEmptyAttachment, ErrAttachmentTooLarge, ErrUnsupportedAttachmentType and ErrProductNotFound
+func (a *AttachmentService) AttachToProduct(ctx context.Context, prodID uuid.UUID, file AttachmentFile, userID uuid.UUID) (api.Attachment, error) {
+	return a.upload(ctx, api.Attachment{ProductId: &prodID}, file, userID)
+}
+
+// upload puts the file into the blob store before its metadata is saved, so a listed attachment always has a file
+func (a *AttachmentService) upload(ctx context.Context, att api.Attachment, file AttachmentFile, userID uuid.UUID) (api.Attachment, error) {
+	const op = "service.attachment.upload"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	if file.Size <= 0 {
+		return api.Attachment{}, errs.ErrEmptyAttachment
+	}
+	if file.Size > a.cfg.AttachmentMaxSize {
+		return api.Attachment{}, errs.ErrAttachmentTooLarge
+	}
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(file.Body, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return api.Attachment{}, fmt.Errorf("%s:%w", op, err)
+	}
+	contentType := http.DetectContentType(head[:n])
+	if !attachmentTypes[contentType] {
+		return api.Attachment{}, errs.ErrUnsupportedAttachmentType
+	}
+	if _, err := file.Body.Seek(0, io.SeekStart); err != nil {
+		return api.Attachment{}, fmt.Errorf("%s:%w", op, err)
+	}
+	sum := sha256.New()
+	size, err := io.Copy(sum, io.LimitReader(file.Body, a.cfg.AttachmentMaxSize+1))
+	if err != nil {
+		return api.Attachment{}, fmt.Errorf("%s:%w", op, err)
+	}
+	if size > a.cfg.AttachmentMaxSize {
+		return api.Attachment{}, errs.ErrAttachmentTooLarge
+	}
+	if _, err := file.Body.Seek(0, io.SeekStart); err != nil {
+		return api.Attachment{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	att.Id = uuid.New()
+	att.FileName = attachmentName(file.Name)
+	att.ContentType = contentType
+	att.Size = size
+	att.Sha256 = hex.EncodeToString(sum.Sum(nil))
+	if userID != uuid.Nil {
+		att.UploadedBy = &userID
+	}
+	key := "attachments/" + att.Id.String()
+	err = a.blobs.Put(ctx, key, blobstore.Object{Body: file.Body, Size: size, ContentType: contentType, SHA256: att.Sha256})
+	if err != nil {
+		return api.Attachment{}, fmt.Errorf("%s:%w", op, err)
+	}
+
+	res, err := a.repo.Create(ctx, att, key)
+	if err != nil {
+		// the file would never be referenced otherwise, it is removed even if the request was cancelled
+		if delErr := a.blobs.Delete(context.WithoutCancel(ctx), key); delErr != nil {
+			err = errors.Join(err, delErr)
+		}
+		if errors.Is(err, errs.ErrReceptionNotFound) || errors.Is(err, errs.ErrProductNotFound) {
+			return api.Attachment{}, err
+		}
+		return api.Attachment{}, fmt.Errorf("%s:%w", op, err)
+	}
+	return withDownloadURL(res), nil
+}
+
+// ListByReception can return ErrReceptionNotFound
+func (a *AttachmentService) ListByReception(ctx context.Context, recID uuid.UUID) ([]api.Attachment, error) {
+	const op = "service.attachment.ListByReception"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	res, err := a.repo.ListByReception(ctx, recID)
+	if err != nil {
+		if errors.Is(err, errs.ErrReceptionNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+	for i := range res {
+		res[i] = withDownloadURL(res[i])
+	}
+	return res, nil
+}
+
+// ListByProduct can return ErrProductNotFound
+func (a *AttachmentService) ListByProduct(ctx context.Context, prodID uuid.UUID) ([]api.Attachment, error) {
+	const op = "service.attachment.ListByProduct"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	res, err := a.repo.ListByProduct(ctx, prodID)
+	if err != nil {
+		if errors.Is(err, errs.ErrProductNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+	for i := range res {
+		res[i] = withDownloadURL(res[i])
+	}
+	return res, nil
+}
+
+// Get can return ErrAttachmentNotFound
+func (a *AttachmentService) Get(ctx context.Context, id uuid.UUID) (api.Attachment, error) {
+	const op = "service.attachment.Get"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	res, _, err := a.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, errs.ErrAttachmentNotFound) {
+			return api.Attachment{}, err
+		}
+		return api.Attachment{}, fmt.Errorf("%s:%w", op, err)
+	}
+	return withDownloadURL(res), nil
+}
+
+// Open returns the attachment with its file, the caller closes the file. Can return ErrAttachmentNotFound
+func (a *AttachmentService) Open(ctx context.Context, id uuid.UUID) (api.Attachment, io.ReadCloser, error) {
+	const op = "service.attachment.Open"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	res, key, err := a.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, errs.ErrAttachmentNotFound) {
+			return api.Attachment{}, nil, err
+		}
+		return api.Attachment{}, nil, fmt.Errorf("%s:%w", op, err)
+	}
+	// a missing file of a stored attachment is a server side problem, so ErrBlobNotFound is not passed as is
+	body, err := a.blobs.Get(ctx, key)
+	if err != nil {
+		return api.Attachment{}, nil, fmt.Errorf("%s:%s: %s", op, key, err.Error())
+	}
+	return withDownloadURL(res), body, nil
+}
+
+// MaxSize is the largest accepted file in bytes
+func (a *AttachmentService) MaxSize() int64 {
+	return a.cfg.AttachmentMaxSize
+}
+
+func withDownloadURL(att api.Attachment) api.Attachment {
+	att.DownloadUrl = "/attachments/" + att.Id.String() + "/content"
+	return att
+}
+
+// attachmentName keeps the base name of the uploaded file without control characters, cut to the column size
+func attachmentName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == "/" {
+		return "file"
+	}
+	for len(name) > maxAttachmentNameLength {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
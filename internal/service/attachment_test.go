@@ -0,0 +1,252 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/blobstore"
+	"github.com/ST359/pvz-service/internal/config"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockAttachmentRepository is a mock implementation of repository.Attachment
+type MockAttachmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockAttachmentRepository) Create(ctx context.Context, att api.Attachment, storageKey string) (api.Attachment, error) {
+	args := m.Called(att, storageKey)
+	if fn, ok := args.Get(0).(func(api.Attachment, string) api.Attachment); ok {
+		return fn(att, storageKey), args.Error(1)
+	}
+	return args.Get(0).(api.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) ListByReception(ctx context.Context, recID uuid.UUID) ([]api.Attachment, error) {
+	args := m.Called(recID)
+	return args.Get(0).([]api.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) ListByProduct(ctx context.Context, prodID uuid.UUID) ([]api.Attachment, error) {
+	args := m.Called(prodID)
+	return args.Get(0).([]api.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) GetByID(ctx context.Context, id uuid.UUID) (api.Attachment, string, error) {
+	args := m.Called(id)
+	return args.Get(0).(api.Attachment), args.String(1), args.Error(2)
+}
+
+// pngHeader is enough for http.DetectContentType to recognize a png image
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestAttachmentService_AttachToReception(t *testing.T) {
+	recID := uuid.New()
+	userID := uuid.New()
+	cfg := &config.Config{AttachmentMaxSize: 64}
+
+	tests := []struct {
+		name        string
+		fileName    string
+		content     []byte
+		size        int64
+		mockSetup   func(*MockAttachmentRepository)
+		expectedErr error
+		stored      bool
+	}{
+		{
+			name:     "png stored",
+			fileName: `C:\photos\box.png`,
+			content:  pngHeader,
+			mockSetup: func(m *MockAttachmentRepository) {
+				m.On("Create", mock.MatchedBy(func(att api.Attachment) bool {
+					return *att.ReceptionId == recID && *att.UploadedBy == userID && att.FileName == "box.png" &&
+						att.ContentType == "image/png" && att.Size == int64(len(pngHeader)) && len(att.Sha256) == 64
+				}), mock.AnythingOfType("string")).
+					Return(func(att api.Attachment, _ string) api.Attachment { return att }, nil)
+			},
+			stored: true,
+		},
+		{
+			name:        "text rejected",
+			fileName:    "notes.txt",
+			content:     []byte("just some text"),
+			mockSetup:   func(m *MockAttachmentRepository) {},
+			expectedErr: errs.ErrUnsupportedAttachmentType,
+		},
+		{
+			name:        "too large",
+			fileName:    "big.png",
+			content:     append(pngHeader, make([]byte, 64)...),
+			mockSetup:   func(m *MockAttachmentRepository) {},
+			expectedErr: errs.ErrAttachmentTooLarge,
+		},
+		{
+			name:        "larger than declared",
+			fileName:    "big.png",
+			content:     append(pngHeader, make([]byte, 64)...),
+			size:        int64(len(pngHeader)),
+			mockSetup:   func(m *MockAttachmentRepository) {},
+			expectedErr: errs.ErrAttachmentTooLarge,
+		},
+		{
+			name:        "empty",
+			fileName:    "empty.png",
+			mockSetup:   func(m *MockAttachmentRepository) {},
+			expectedErr: errs.ErrEmptyAttachment,
+		},
+		{
+			name:     "reception not found",
+			fileName: "box.png",
+			content:  pngHeader,
+			mockSetup: func(m *MockAttachmentRepository) {
+				m.On("Create", mock.Anything, mock.Anything).Return(api.Attachment{}, errs.ErrReceptionNotFound)
+			},
+			expectedErr: errs.ErrReceptionNotFound,
+		},
+		{
+			name:     "repository error",
+			fileName: "box.png",
+			content:  pngHeader,
+			mockSetup: func(m *MockAttachmentRepository) {
+				m.On("Create", mock.Anything, mock.Anything).Return(api.Attachment{}, errors.New("db error"))
+			},
+			expectedErr: errors.New("service.attachment.upload:db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			blobs, err := blobstore.NewLocalStore(dir)
+			require.NoError(t, err)
+			mockRepo := new(MockAttachmentRepository)
+			tt.mockSetup(mockRepo)
+			svc := NewAttachmentService(mockRepo, blobs, cfg)
+
+			size := tt.size
+			if size == 0 {
+				size = int64(len(tt.content))
+			}
+			file := AttachmentFile{Name: tt.fileName, Size: size, Body: bytes.NewReader(tt.content)}
+			result, err := svc.AttachToReception(context.Background(), recID, file, userID)
+
+			if tt.expectedErr != nil {
+				assert.Error(t, err)
+				if errors.Is(err, tt.expectedErr) {
+					assert.ErrorIs(t, err, tt.expectedErr)
+				} else {
+					assert.EqualError(t, err, tt.expectedErr.Error())
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "/attachments/"+result.Id.String()+"/content", result.DownloadUrl)
+			}
+
+			// a failed upload leaves no file behind
+			var files []string
+			err = filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+				if err == nil && !d.IsDir() {
+					files = append(files, path)
+				}
+				return err
+			})
+			require.NoError(t, err)
+			if tt.stored {
+				require.Len(t, files, 1)
+				body, err := blobs.Get(context.Background(), "attachments/"+result.Id.String())
+				require.NoError(t, err)
+				defer body.Close()
+				content, err := io.ReadAll(body)
+				require.NoError(t, err)
+				assert.Equal(t, tt.content, content)
+			} else {
+				assert.Empty(t, files)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAttachmentService_Open(t *testing.T) {
+	attID := uuid.New()
+	key := "attachments/" + attID.String()
+	cfg := &config.Config{AttachmentMaxSize: 64}
+
+	tests := []struct {
+		name        string
+		stored      bool
+		mockSetup   func(*MockAttachmentRepository)
+		expectedErr error
+	}{
+		{
+			name:   "opened",
+			stored: true,
+			mockSetup: func(m *MockAttachmentRepository) {
+				m.On("GetByID", attID).Return(api.Attachment{Id: attID}, key, nil)
+			},
+		},
+		{
+			name: "not found",
+			mockSetup: func(m *MockAttachmentRepository) {
+				m.On("GetByID", attID).Return(api.Attachment{}, "", errs.ErrAttachmentNotFound)
+			},
+			expectedErr: errs.ErrAttachmentNotFound,
+		},
+		{
+			name: "file missing",
+			mockSetup: func(m *MockAttachmentRepository) {
+				m.On("GetByID", attID).Return(api.Attachment{Id: attID}, key, nil)
+			},
+			expectedErr: errors.New("service.attachment.Open:" + key + ": " + errs.ErrBlobNotFound.Error()),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blobs, err := blobstore.NewLocalStore(t.TempDir())
+			require.NoError(t, err)
+			if tt.stored {
+				sum := sha256.Sum256(pngHeader)
+				obj := blobstore.Object{Body: bytes.NewReader(pngHeader), Size: int64(len(pngHeader)), SHA256: hex.EncodeToString(sum[:])}
+				require.NoError(t, blobs.Put(context.Background(), key, obj))
+			}
+			mockRepo := new(MockAttachmentRepository)
+			tt.mockSetup(mockRepo)
+			svc := NewAttachmentService(mockRepo, blobs, cfg)
+
+			result, body, err := svc.Open(context.Background(), attID)
+
+			if tt.expectedErr != nil {
+				if errors.Is(tt.expectedErr, errs.ErrAttachmentNotFound) {
+					assert.ErrorIs(t, err, tt.expectedErr)
+				} else {
+					assert.EqualError(t, err, tt.expectedErr.Error())
+					assert.NotErrorIs(t, err, errs.ErrBlobNotFound)
+				}
+			} else {
+				require.NoError(t, err)
+				defer body.Close()
+				content, err := io.ReadAll(body)
+				require.NoError(t, err)
+				assert.Equal(t, pngHeader, content)
+				assert.Equal(t, "/attachments/"+attID.String()+"/content", result.DownloadUrl)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+const maxDamageCommentLength = 500
+
+type DamageReportService struct {
+	repo repository.DamageReport
+}
+
+func NewDamageReportService(repo repository.DamageReport) *DamageReportService {
+	return &DamageReportService{repo: repo}
+}
+
+// SetCondition flags a product scanned into a reception in progress. A condition other than ok needs a comment
+// and an attachment of the product and goes to the moderator review queue, ok withdraws a pending report.
+// Can return ErrInvalidProductCondition, ErrDamageCommentRequired, ErrDamageEvidenceRequired, ErrProductNotFound and ErrReceptionClosed
+func (d *DamageReportService) SetCondition(ctx context.Context, prodID uuid.UUID, input api.ProductConditionInput, userID uuid.UUID) (api.Product, error) {
+	const op = "service.damage_report.SetCondition"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	switch input.Condition {
+	case api.ConditionOk:
+		input.Comment = nil
+	case api.ConditionDamaged, api.ConditionOpened, api.ConditionWet:
+		if input.Comment == nil {
+			return api.Product{}, errs.ErrDamageCommentRequired
+		}
+		comment := strings.TrimSpace(*input.Comment)
+		if comment == "" || utf8.RuneCountInString(comment) > maxDamageCommentLength {
+			return api.Product{}, errs.ErrDamageCommentRequired
+		}
+		input.Comment = &comment
+	default:
+		return api.Product{}, errs.ErrInvalidProductCondition
+	}
+
+	prod, err := d.repo.SetCondition(ctx, prodID, input, userID)
+	if err != nil {
+		if errors.Is(err, errs.ErrProductNotFound) || errors.Is(err, errs.ErrReceptionClosed) ||
+			errors.Is(err, errs.ErrDamageEvidenceRequired) {
+			return api.Product{}, err
+		}
+		return api.Product{}, fmt.Errorf("%s:%w", op, err)
+	}
+	return prod, nil
+}
+
+func (d *DamageReportService) List(ctx context.Context, params api.GetDamageReportsParams) ([]api.DamageReport, error) {
+	const op = "service.damage_report.List"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	res, err := d.repo.List(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+	return res, nil
+}
+
+// Decide can return ErrInvalidDamageDecision, ErrReviewCommentTooLong, ErrDamageReportNotFound and ErrDamageReportDecided
+func (d *DamageReportService) Decide(ctx context.Context, reportID uuid.UUID, decision api.DamageReportDecision, userID uuid.UUID) (api.DamageReport, error) {
+	const op = "service.damage_report.Decide"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	if decision.Decision != api.DecisionAccepted && decision.Decision != api.DecisionRejected {
+		return api.DamageReport{}, errs.ErrInvalidDamageDecision
+	}
+	if decision.Comment != nil {
+		comment := strings.TrimSpace(*decision.Comment)
+		decision.Comment = &comment
+		if comment == "" {
+			decision.Comment = nil
+		} else if utf8.RuneCountInString(comment) > maxDamageCommentLength {
+			return api.DamageReport{}, errs.ErrReviewCommentTooLong
+		}
+	}
+
+	report, err := d.repo.Decide(ctx, reportID, decision, userID)
+	if err != nil {
+		if errors.Is(err, errs.ErrDamageReportNotFound) || errors.Is(err, errs.ErrDamageReportDecided) {
+			return api.DamageReport{}, err
+		}
+		return api.DamageReport{}, fmt.Errorf("%s:%w", op, err)
+	}
+	return report, nil
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockDamageReportRepository is a mock implementation of repository.DamageReport
+type MockDamageReportRepository struct {
+	mock.Mock
+}
+
+func (m *MockDamageReportRepository) SetCondition(ctx context.Context, prodID uuid.UUID, input api.ProductConditionInput, userID uuid.UUID) (api.Product, error) {
+	args := m.Called(prodID, input, userID)
+	return args.Get(0).(api.Product), args.Error(1)
+}
+
+func (m *MockDamageReportRepository) List(ctx context.Context, params api.GetDamageReportsParams) ([]api.DamageReport, error) {
+	args := m.Called(params)
+	return args.Get(0).([]api.DamageReport), args.Error(1)
+}
+
+func (m *MockDamageReportRepository) Decide(ctx context.Context, reportID uuid.UUID, decision api.DamageReportDecision, userID uuid.UUID) (api.DamageReport, error) {
+	args := m.Called(reportID, decision, userID)
+	return args.Get(0).(api.DamageReport), args.Error(1)
+}
+
+func TestDamageReportService_SetCondition(t *testing.T) {
+	prodID := uuid.New()
+	userID := uuid.New()
+	comment := "torn box"
+	padded := " torn box "
+	blank := "  "
+
+	tests := []struct {
+		name        string
+		input       api.ProductConditionInput
+		mockSetup   func(*MockDamageReportRepository)
+		expectedErr error
+	}{
+		{
+			name:  "damaged with a trimmed comment",
+			input: api.ProductConditionInput{Condition: api.ConditionDamaged, Comment: &padded},
+			mockSetup: func(m *MockDamageReportRepository) {
+				m.On("SetCondition", prodID, api.ProductConditionInput{Condition: api.ConditionDamaged, Comment: &comment}, userID).
+					Return(api.Product{Id: &prodID}, nil)
+			},
+		},
+		{
+			name:  "ok drops the comment",
+			input: api.ProductConditionInput{Condition: api.ConditionOk, Comment: &comment},
+			mockSetup: func(m *MockDamageReportRepository) {
+				m.On("SetCondition", prodID, api.ProductConditionInput{Condition: api.ConditionOk}, userID).
+					Return(api.Product{Id: &prodID}, nil)
+			},
+		},
+		{
+			name:        "no comment",
+			input:       api.ProductConditionInput{Condition: api.ConditionWet},
+			mockSetup:   func(m *MockDamageReportRepository) {},
+			expectedErr: errs.ErrDamageCommentRequired,
+		},
+		{
+			name:        "blank comment",
+			input:       api.ProductConditionInput{Condition: api.ConditionOpened, Comment: &blank},
+			mockSetup:   func(m *MockDamageReportRepository) {},
+			expectedErr: errs.ErrDamageCommentRequired,
+		},
+		{
+			name:        "unknown condition",
+			input:       api.ProductConditionInput{Condition: "broken", Comment: &comment},
+			mockSetup:   func(m *MockDamageReportRepository) {},
+			expectedErr: errs.ErrInvalidProductCondition,
+		},
+		{
+			name:  "no attachment",
+			input: api.ProductConditionInput{Condition: api.ConditionWet, Comment: &comment},
+			mockSetup: func(m *MockDamageReportRepository) {
+				m.On("SetCondition", prodID, mock.Anything, userID).Return(api.Product{}, errs.ErrDamageEvidenceRequired)
+			},
+			expectedErr: errs.ErrDamageEvidenceRequired,
+		},
+		{
+			name:  "repository error",
+			input: api.ProductConditionInput{Condition: api.ConditionWet, Comment: &comment},
+			mockSetup: func(m *MockDamageReportRepository) {
+				m.On("SetCondition", prodID, mock.Anything, userID).Return(api.Product{}, errors.New("db error"))
+			},
+			expectedErr: errors.New("service.damage_report.SetCondition:db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockDamageReportRepository)
+			tt.mockSetup(mockRepo)
+			svc := NewDamageReportService(mockRepo)
+
+			_, err := svc.SetCondition(context.Background(), prodID, tt.input, userID)
+
+			if tt.expectedErr != nil {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDamageReportService_Decide(t *testing.T) {
+	reportID := uuid.New()
+	userID := uuid.New()
+	space := " "
+	long := strings.Repeat("я", maxDamageCommentLength+1)
+
+	tests := []struct {
+		name        string
+		decision    api.DamageReportDecision
+		mockSetup   func(*MockDamageReportRepository)
+		expectedErr error
+	}{
+		{
+			name:     "rejected without a comment",
+			decision: api.DamageReportDecision{Decision: api.DecisionRejected, Comment: &space},
+			mockSetup: func(m *MockDamageReportRepository) {
+				m.On("Decide", reportID, api.DamageReportDecision{Decision: api.DecisionRejected}, userID).
+					Return(api.DamageReport{Id: reportID, Status: api.DamageReportRejected}, nil)
+			},
+		},
+		{
+			name:        "unknown decision",
+			decision:    api.DamageReportDecision{Decision: "pending"},
+			mockSetup:   func(m *MockDamageReportRepository) {},
+			expectedErr: errs.ErrInvalidDamageDecision,
+		},
+		{
+			name:        "comment too long",
+			decision:    api.DamageReportDecision{Decision: api.DecisionAccepted, Comment: &long},
+			mockSetup:   func(m *MockDamageReportRepository) {},
+			expectedErr: errs.ErrReviewCommentTooLong,
+		},
+		{
+			name:     "already decided",
+			decision: api.DamageReportDecision{Decision: api.DecisionAccepted},
+			mockSetup: func(m *MockDamageReportRepository) {
+				m.On("Decide", reportID, api.DamageReportDecision{Decision: api.DecisionAccepted}, userID).
+					Return(api.DamageReport{}, errs.ErrDamageReportDecided)
+			},
+			expectedErr: errs.ErrDamageReportDecided,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockDamageReportRepository)
+			tt.mockSetup(mockRepo)
+			svc := NewDamageReportService(mockRepo)
+
+			_, err := svc.Decide(context.Background(), reportID, tt.decision, userID)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
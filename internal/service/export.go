@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/repository"
+	"github.com/ST359/pvz-service/internal/spreadsheet"
+	"github.com/google/uuid"
+)
+
+// exportColumn is a named column of an export, value returns the cell of a record
+type exportColumn[T any] struct {
+	name  string
+	value func(T) any
+}
+
+var receptionExportColumns = []exportColumn[repository.ExportReception]{
+	{"receptionId", func(r repository.ExportReception) any { return deref(r.Reception.Id) }},
+	{"dateTime", func(r repository.ExportReception) any { return r.Reception.DateTime }},
+	{"pvzId", func(r repository.ExportReception) any { return r.Reception.PvzId }},
+	{"city", func(r repository.ExportReception) any { return r.City }},
+	{"address", func(r repository.ExportReception) any { return deref(r.Address) }},
+	{"kind", func(r repository.ExportReception) any { return r.Reception.Kind }},
+	{"status", func(r repository.ExportReception) any { return r.Reception.Status }},
+	{"closedAt", func(r repository.ExportReception) any { return deref(r.Reception.ClosedAt) }},
+	{"closeReason", func(r repository.ExportReception) any { return deref(r.Reception.CloseReason) }},
+	{"createdBy", func(r repository.ExportReception) any { return deref(r.Reception.CreatedBy) }},
+	{"products", func(r repository.ExportReception) any { return r.Products }},
+}
+
+var productExportColumns = []exportColumn[repository.ExportProduct]{
+	{"productId", func(p repository.ExportProduct) any { return deref(p.Product.Id) }},
+	{"dateTime", func(p repository.ExportProduct) any { return deref(p.Product.DateTime) }},
+	{"type", func(p repository.ExportProduct) any { return p.Product.Type }},
+	{"barcode", func(p repository.ExportProduct) any { return deref(p.Product.Barcode) }},
+	{"externalOrderId", func(p repository.ExportProduct) any { return deref(p.Product.ExternalOrderId) }},
+	{"state", func(p repository.ExportProduct) any { return p.Product.State }},
+	{"condition", func(p repository.ExportProduct) any { return deref(p.Product.Condition) }},
+	{"returnCondition", func(p repository.ExportProduct) any { return deref(p.Product.ReturnCondition) }},
+	{"originalOrderId", func(p repository.ExportProduct) any { return deref(p.Product.OriginalOrderId) }},
+	{"receptionId", func(p repository.ExportProduct) any { return p.Product.ReceptionId }},
+	{"receptionDateTime", func(p repository.ExportProduct) any { return p.Reception.DateTime }},
+	{"receptionKind", func(p repository.ExportProduct) any { return p.Reception.Kind }},
+	{"receptionStatus", func(p repository.ExportProduct) any { return p.Reception.Status }},
+	{"pvzId", func(p repository.ExportProduct) any { return p.Reception.PvzId }},
+	{"city", func(p repository.ExportProduct) any { return p.City }},
+	{"address", func(p repository.ExportProduct) any { return deref(p.Address) }},
+}
+
+// deref returns the value p points to or nil, so missing values give empty cells
+func deref[T any](p *T) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+type ExportService struct {
+	repo repository.Export
+}
+
+func NewExportService(repo repository.Export) *ExportService {
+	return &ExportService{repo: repo}
+}
+
+// Receptions writes receptions matching the params to w as CSV or XLSX while reading them from the database.
+// Nothing is written to w before the first reception is read, so errors of the query can still be reported.
+// Can return ErrInvalidReportPeriod, ErrUnsupportedExportFormat and ErrInvalidExportColumn
+func (e *ExportService) Receptions(ctx context.Context, params api.GetExportsReceptionsParams, w io.Writer) error {
+	const op = "service.export.Receptions"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	filter, err := exportFilter(params.StartDate, params.EndDate, params.PvzId, params.City)
+	if err != nil {
+		return err
+	}
+	columns, err := selectExportColumns(receptionExportColumns, params.Columns)
+	if err != nil {
+		return err
+	}
+	ew, err := newExportWriter(w, params.Format, "receptions", columns)
+	if err != nil {
+		return err
+	}
+	if err := e.repo.Receptions(ctx, filter, ew.write); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	if err := ew.close(); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	return nil
+}
+
+// Products writes not deleted products of receptions matching the params to w as CSV or XLSX while reading them
+// from the database. Nothing is written to w before the first product is read, so errors of the query can still be reported.
+// Can return ErrInvalidReportPeriod, ErrUnsupportedExportFormat and ErrInvalidExportColumn
+func (e *ExportService) Products(ctx context.Context, params api.GetExportsProductsParams, w io.Writer) error {
+	const op = "service.export.Products"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	filter, err := exportFilter(params.StartDate, params.EndDate, params.PvzId, params.City)
+	if err != nil {
+		return err
+	}
+	columns, err := selectExportColumns(productExportColumns, params.Columns)
+	if err != nil {
+		return err
+	}
+	ew, err := newExportWriter(w, params.Format, "products", columns)
+	if err != nil {
+		return err
+	}
+	if err := e.repo.Products(ctx, filter, ew.write); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	if err := ew.close(); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	return nil
+}
+
+// exportFilter can return ErrInvalidReportPeriod
+func exportFilter(start, end *time.Time, pvzID *uuid.UUID, city *string) (repository.ExportFilter, error) {
+	if start != nil && end != nil && start.After(*end) {
+		return repository.ExportFilter{}, errs.ErrInvalidReportPeriod
+	}
+	return repository.ExportFilter{StartDate: start, EndDate: end, PvzID: pvzID, City: city}, nil
+}
+
+// selectExportColumns picks columns by name in the requested order, all columns are exported by default.
+// Can return ErrInvalidExportColumn
+func selectExportColumns[T any](all []exportColumn[T], names *[]string) ([]exportColumn[T], error) {
+	if names == nil || len(*names) == 0 {
+		return all, nil
+	}
+	columns := make([]exportColumn[T], 0, len(*names))
+	for _, name := range *names {
+		found := false
+		for _, col := range all {
+			if col.name == name {
+				columns = append(columns, col)
+				found = true
+				break
+			}
+		}
+		if !found {
+			names := make([]string, len(all))
+			for i, col := range all {
+				names[i] = col.name
+			}
+			return nil, fmt.Errorf("%w %q, expected %s", errs.ErrInvalidExportColumn, name, strings.Join(names, ", "))
+		}
+	}
+	return columns, nil
+}
+
+// exportWriter creates the spreadsheet on the first record, so a failed query leaves the output untouched
+type exportWriter[T any] struct {
+	out     io.Writer
+	format  api.ExportFormat
+	name    string
+	columns []exportColumn[T]
+	sheet   spreadsheet.Writer
+}
+
+// newExportWriter defaults to CSV, can return ErrUnsupportedExportFormat
+func newExportWriter[T any](out io.Writer, format *api.ExportFormat, name string, columns []exportColumn[T]) (*exportWriter[T], error) {
+	ew := &exportWriter[T]{out: out, format: api.ExportCSV, name: name, columns: columns}
+	if format != nil {
+		ew.format = *format
+	}
+	if ew.format != api.ExportCSV && ew.format != api.ExportXLSX {
+		return nil, errs.ErrUnsupportedExportFormat
+	}
+	return ew, nil
+}
+
+func (ew *exportWriter[T]) open() error {
+	header := make([]string, len(ew.columns))
+	for i, col := range ew.columns {
+		header[i] = col.name
+	}
+	var err error
+	if ew.format == api.ExportXLSX {
+		ew.sheet, err = spreadsheet.NewXLSX(ew.out, ew.name, header)
+	} else {
+		ew.sheet, err = spreadsheet.NewCSV(ew.out, header)
+	}
+	return err
+}
+
+func (ew *exportWriter[T]) write(rec T) error {
+	if ew.sheet == nil {
+		if err := ew.open(); err != nil {
+			return err
+		}
+	}
+	cells := make([]any, len(ew.columns))
+	for i, col := range ew.columns {
+		cells[i] = col.value(rec)
+	}
+	return ew.sheet.WriteRow(cells)
+}
+
+// close finishes the file, an export without records still gets the header
+func (ew *exportWriter[T]) close() error {
+	if ew.sheet == nil {
+		if err := ew.open(); err != nil {
+			return err
+		}
+	}
+	return ew.sheet.Close()
+}
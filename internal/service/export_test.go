@@ -0,0 +1,162 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockExportRepository is a mock implementation of repository.Export, records given to Return are passed to the callback
+type MockExportRepository struct {
+	mock.Mock
+}
+
+func (m *MockExportRepository) Receptions(ctx context.Context, filter repository.ExportFilter, fn func(repository.ExportReception) error) error {
+	args := m.Called(filter)
+	for _, rec := range args.Get(0).([]repository.ExportReception) {
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockExportRepository) Products(ctx context.Context, filter repository.ExportFilter, fn func(repository.ExportProduct) error) error {
+	args := m.Called(filter)
+	for _, prod := range args.Get(0).([]repository.ExportProduct) {
+		if err := fn(prod); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func TestExportService_Receptions(t *testing.T) {
+	recID := uuid.MustParse("0b6f8a52-3c1e-4d2a-9f1b-7a5c2e9d4f10")
+	pvzID := uuid.MustParse("5d2c9e41-8b7a-4f3e-a6d1-2c4b8e7f9a03")
+	opened := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	closed := opened.Add(2 * time.Hour)
+	end := opened.Add(-time.Hour)
+	address := "ул. Баумана, 5"
+	records := []repository.ExportReception{
+		{Reception: api.Reception{Id: &recID, DateTime: opened, PvzId: pvzID, Kind: api.ReceptionKindInbound, Status: api.Close, ClosedAt: &closed},
+			City: "Казань", Address: &address, Products: 12},
+		{Reception: api.Reception{Id: &recID, DateTime: opened, PvzId: pvzID, Kind: api.ReceptionKindCustomerReturn, Status: api.InProgress},
+			City: "Казань"},
+	}
+	columns := []string{"receptionId", "status", "closedAt", "address", "products"}
+	badColumns := []string{"receptionId", "price"}
+	xlsx := api.ExportXLSX
+	pdf := api.ExportFormat("pdf")
+
+	tests := []struct {
+		name        string
+		params      api.GetExportsReceptionsParams
+		mockSetup   func(*MockExportRepository)
+		expected    string
+		expectedErr error
+	}{
+		{
+			name:   "selected columns",
+			params: api.GetExportsReceptionsParams{Columns: &columns, PvzId: &pvzID},
+			mockSetup: func(m *MockExportRepository) {
+				m.On("Receptions", repository.ExportFilter{PvzID: &pvzID}).Return(records, nil)
+			},
+			expected: "\ufeffreceptionId,status,closedAt,address,products\n" +
+				"0b6f8a52-3c1e-4d2a-9f1b-7a5c2e9d4f10,close,2025-03-01T11:00:00Z,\"ул. Баумана, 5\",12\n" +
+				"0b6f8a52-3c1e-4d2a-9f1b-7a5c2e9d4f10,in_progress,,,0\n",
+		},
+		{
+			name: "nothing found",
+			mockSetup: func(m *MockExportRepository) {
+				m.On("Receptions", repository.ExportFilter{}).Return([]repository.ExportReception{}, nil)
+			},
+			expected: "\ufeffreceptionId,dateTime,pvzId,city,address,kind,status,closedAt,closeReason,createdBy,products\n",
+		},
+		{
+			name:        "unknown column",
+			params:      api.GetExportsReceptionsParams{Columns: &badColumns},
+			mockSetup:   func(m *MockExportRepository) {},
+			expectedErr: errs.ErrInvalidExportColumn,
+		},
+		{
+			name:        "unknown format",
+			params:      api.GetExportsReceptionsParams{Format: &pdf},
+			mockSetup:   func(m *MockExportRepository) {},
+			expectedErr: errs.ErrUnsupportedExportFormat,
+		},
+		{
+			name:        "start after end",
+			params:      api.GetExportsReceptionsParams{Format: &xlsx, StartDate: &opened, EndDate: &end},
+			mockSetup:   func(m *MockExportRepository) {},
+			expectedErr: errs.ErrInvalidReportPeriod,
+		},
+		{
+			name: "repository error",
+			mockSetup: func(m *MockExportRepository) {
+				m.On("Receptions", repository.ExportFilter{}).Return([]repository.ExportReception{}, errors.New("db error"))
+			},
+			expectedErr: errors.New("service.export.Receptions:db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockExportRepository)
+			tt.mockSetup(mockRepo)
+			svc := NewExportService(mockRepo)
+
+			var buf bytes.Buffer
+			err := svc.Receptions(context.Background(), tt.params, &buf)
+
+			if tt.expectedErr != nil {
+				assert.Error(t, err)
+				if errors.Is(err, tt.expectedErr) {
+					assert.ErrorIs(t, err, tt.expectedErr)
+				} else {
+					assert.EqualError(t, err, tt.expectedErr.Error())
+				}
+				// nothing is written before the first record, so the handler can still answer with an error
+				assert.Zero(t, buf.Len())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, buf.String())
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestExportService_ProductsXLSX(t *testing.T) {
+	recID := uuid.New()
+	city := "Москва"
+	xlsx := api.ExportXLSX
+	records := []repository.ExportProduct{{Product: api.Product{ReceptionId: recID, Type: "shoes", State: api.ProductStateStored}, City: city}}
+
+	mockRepo := new(MockExportRepository)
+	mockRepo.On("Products", repository.ExportFilter{City: &city}).Return(records, nil)
+	svc := NewExportService(mockRepo)
+
+	var buf bytes.Buffer
+	require.NoError(t, svc.Products(context.Background(), api.GetExportsProductsParams{Format: &xlsx, City: &city}, &buf))
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	names := []string{}
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	assert.Contains(t, names, "xl/worksheets/sheet1.xml")
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/config"
+	"github.com/ST359/pvz-service/internal/repository"
+)
+
+const maxIdempotencyKeyLength = 255
+
+type IdempotencyService struct {
+	repo repository.Idempotency
+	cfg  *config.Config
+}
+
+func NewIdempotencyService(repo repository.Idempotency, cfg *config.Config) *IdempotencyService {
+	return &IdempotencyService{repo: repo, cfg: cfg}
+}
+
+// Begin claims the key of the caller for IdempotencyKeyTTL, nil response means the request has to be processed.
+// Can return ErrInvalidIdempotencyKey, ErrIdempotencyKeyReused and ErrIdempotencyKeyInProgress
+func (i *IdempotencyService) Begin(ctx context.Context, scope, key, fingerprint string) (*repository.IdempotentResponse, error) {
+	const op = "service.idempotency.Begin"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	if !validIdempotencyKey(key) {
+		return nil, errs.ErrInvalidIdempotencyKey
+	}
+	resp, err := i.repo.Begin(ctx, scope, key, fingerprint, i.cfg.IdempotencyKeyTTL)
+	if err != nil {
+		if errors.Is(err, errs.ErrIdempotencyKeyReused) || errors.Is(err, errs.ErrIdempotencyKeyInProgress) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+	return resp, nil
+}
+
+func (i *IdempotencyService) Complete(ctx context.Context, scope, key string, resp repository.IdempotentResponse) error {
+	const op = "service.idempotency.Complete"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	if err := i.repo.Complete(ctx, scope, key, resp); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	return nil
+}
+
+func (i *IdempotencyService) Release(ctx context.Context, scope, key string) error {
+	const op = "service.idempotency.Release"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	if err := i.repo.Release(ctx, scope, key); err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	return nil
+}
+
+// DeleteExpiredKeys removes keys older than IdempotencyKeyTTL and returns how many were removed
+func (i *IdempotencyService) DeleteExpiredKeys(ctx context.Context) (int64, error) {
+	const op = "service.idempotency.DeleteExpiredKeys"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	n, err := i.repo.DeleteExpired(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s:%w", op, err)
+	}
+	return n, nil
+}
+
+// validIdempotencyKey accepts printable ascii, keys are usually uuids generated by the client
+func validIdempotencyKey(key string) bool {
+	if len(key) == 0 || len(key) > maxIdempotencyKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
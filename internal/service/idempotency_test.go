@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/config"
+	"github.com/ST359/pvz-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockIdempotencyRepository is a mock implementation of repository.Idempotency
+type MockIdempotencyRepository struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyRepository) Begin(ctx context.Context, scope, key, fingerprint string, ttl time.Duration) (*repository.IdempotentResponse, error) {
+	args := m.Called(scope, key, fingerprint, ttl)
+	return args.Get(0).(*repository.IdempotentResponse), args.Error(1)
+}
+
+func (m *MockIdempotencyRepository) Complete(ctx context.Context, scope, key string, resp repository.IdempotentResponse) error {
+	args := m.Called(scope, key, resp)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) Release(ctx context.Context, scope, key string) error {
+	args := m.Called(scope, key)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestIdempotencyService_Begin(t *testing.T) {
+	ttl := time.Hour
+	stored := &repository.IdempotentResponse{StatusCode: 201, Body: []byte(`{}`)}
+
+	tests := []struct {
+		name        string
+		key         string
+		mockSetup   func(*MockIdempotencyRepository)
+		expected    *repository.IdempotentResponse
+		expectedErr error
+	}{
+		{
+			name: "new key",
+			key:  "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+			mockSetup: func(m *MockIdempotencyRepository) {
+				m.On("Begin", "user", "3fa85f64-5717-4562-b3fc-2c963f66afa6", "fp", ttl).
+					Return((*repository.IdempotentResponse)(nil), nil)
+			},
+		},
+		{
+			name: "replay",
+			key:  "key-1",
+			mockSetup: func(m *MockIdempotencyRepository) {
+				m.On("Begin", "user", "key-1", "fp", ttl).Return(stored, nil)
+			},
+			expected: stored,
+		},
+		{
+			name:        "key with spaces",
+			key:         "key 1",
+			mockSetup:   func(m *MockIdempotencyRepository) {},
+			expectedErr: errs.ErrInvalidIdempotencyKey,
+		},
+		{
+			name:        "key too long",
+			key:         strings.Repeat("k", 256),
+			mockSetup:   func(m *MockIdempotencyRepository) {},
+			expectedErr: errs.ErrInvalidIdempotencyKey,
+		},
+		{
+			name: "key reused",
+			key:  "key-1",
+			mockSetup: func(m *MockIdempotencyRepository) {
+				m.On("Begin", "user", "key-1", "fp", ttl).
+					Return((*repository.IdempotentResponse)(nil), errs.ErrIdempotencyKeyReused)
+			},
+			expectedErr: errs.ErrIdempotencyKeyReused,
+		},
+		{
+			name: "database error",
+			key:  "key-1",
+			mockSetup: func(m *MockIdempotencyRepository) {
+				m.On("Begin", "user", "key-1", "fp", ttl).
+					Return((*repository.IdempotentResponse)(nil), errors.New("db error"))
+			},
+			expectedErr: errors.New("db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockIdempotencyRepository)
+			tt.mockSetup(mockRepo)
+
+			service := NewIdempotencyService(mockRepo, &config.Config{IdempotencyKeyTTL: ttl})
+			result, err := service.Begin(context.Background(), "user", tt.key, "fp")
+
+			if tt.expectedErr != nil {
+				assert.ErrorContains(t, err, tt.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/config"
+	"github.com/ST359/pvz-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+type ManifestService struct {
+	repo  repository.Manifest
+	cfg   *config.Config
+	types ProductTypeResolver
+}
+
+func NewManifestService(repo repository.Manifest, cfg *config.Config, types ProductTypeResolver) *ManifestService {
+	return &ManifestService{repo: repo, cfg: cfg, types: types}
+}
+
+// ParseManifestCSV expects a header with barcode and type columns, order of columns does not matter.
+// Can return ErrInvalidManifest
+func ParseManifestCSV(r io.Reader) ([]api.ManifestItem, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: empty file", errs.ErrInvalidManifest)
+		}
+		return nil, fmt.Errorf("%w: %s", errs.ErrInvalidManifest, err.Error())
+	}
+	barcodeIdx, typeIdx := -1, -1
+	for i, col := range header {
+		switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(col, "\ufeff"))) {
+		case "barcode":
+			barcodeIdx = i
+		case "type":
+			typeIdx = i
+		}
+	}
+	if barcodeIdx == -1 || typeIdx == -1 {
+		return nil, fmt.Errorf("%w: header must contain barcode and type columns", errs.ErrInvalidManifest)
+	}
+
+	var items []api.ManifestItem
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", errs.ErrInvalidManifest, err.Error())
+		}
+		items = append(items, api.ManifestItem{
+			Barcode: strings.TrimSpace(csvField(record, barcodeIdx)),
+			Type:    strings.TrimSpace(csvField(record, typeIdx)),
+		})
+	}
+	return items, nil
+}
+
+// Upload replaces the manifest of the reception in progress and returns the progress against it.
+// Types are stored as catalog codes. Can return ErrInvalidManifest, ErrManifestTooLarge, ErrInvalidProductType,
+// ErrReceptionNotFound and ErrReceptionClosed
+func (m *ManifestService) Upload(ctx context.Context, recID uuid.UUID, items []api.ManifestItem) (api.ManifestProgress, error) {
+	const op = "service.manifest.Upload"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	if len(items) == 0 {
+		return api.ManifestProgress{}, fmt.Errorf("%w: no items", errs.ErrInvalidManifest)
+	}
+	if len(items) > m.cfg.ManifestMaxItems {
+		return api.ManifestProgress{}, errs.ErrManifestTooLarge
+	}
+	barcodes := make(map[string]struct{}, len(items))
+	codes := make(map[api.ProductType]api.ProductType)
+	resolved := make([]api.ManifestItem, len(items))
+	for i, item := range items {
+		if !barcodeRe.MatchString(item.Barcode) {
+			return api.ManifestProgress{}, fmt.Errorf("%w: invalid barcode %q", errs.ErrInvalidManifest, item.Barcode)
+		}
+		if _, ok := barcodes[item.Barcode]; ok {
+			return api.ManifestProgress{}, fmt.Errorf("%w: duplicate barcode %s", errs.ErrInvalidManifest, item.Barcode)
+		}
+		barcodes[item.Barcode] = struct{}{}
+
+		code, ok := codes[item.Type]
+		if !ok {
+			var err error
+			code, err = m.types.ResolveProductType(ctx, item.Type)
+			if err != nil {
+				if errors.Is(err, errs.ErrInvalidProductType) {
+					return api.ManifestProgress{}, err
+				}
+				return api.ManifestProgress{}, fmt.Errorf("%s:%w", op, err)
+			}
+			codes[item.Type] = code
+		}
+		resolved[i] = api.ManifestItem{Barcode: item.Barcode, Type: code}
+	}
+
+	if err := m.repo.Replace(ctx, recID, resolved); err != nil {
+		if errors.Is(err, errs.ErrReceptionNotFound) || errors.Is(err, errs.ErrReceptionClosed) {
+			return api.ManifestProgress{}, err
+		}
+		return api.ManifestProgress{}, fmt.Errorf("%s:%w", op, err)
+	}
+	return m.Progress(ctx, recID)
+}
+
+// Progress can return ErrReceptionNotFound and ErrManifestNotFound
+func (m *ManifestService) Progress(ctx context.Context, recID uuid.UUID) (api.ManifestProgress, error) {
+	const op = "service.manifest.Progress"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	progress, err := m.repo.Progress(ctx, recID)
+	if err != nil {
+		if errors.Is(err, errs.ErrReceptionNotFound) || errors.Is(err, errs.ErrManifestNotFound) {
+			return api.ManifestProgress{}, err
+		}
+		return api.ManifestProgress{}, fmt.Errorf("%s:%w", op, err)
+	}
+	return progress, nil
+}
+
+// Discrepancies can return ErrReceptionNotFound, ErrManifestNotFound and ErrDiscrepancyReportNotReady
+func (m *ManifestService) Discrepancies(ctx context.Context, recID uuid.UUID) ([]api.ReceptionDiscrepancy, error) {
+	const op = "service.manifest.Discrepancies"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	report, err := m.repo.Discrepancies(ctx, recID)
+	if err != nil {
+		if errors.Is(err, errs.ErrReceptionNotFound) || errors.Is(err, errs.ErrManifestNotFound) ||
+			errors.Is(err, errs.ErrDiscrepancyReportNotReady) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+	return report, nil
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/config"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockManifestRepository is a mock implementation of repository.Manifest
+type MockManifestRepository struct {
+	mock.Mock
+}
+
+func (m *MockManifestRepository) Replace(ctx context.Context, recID uuid.UUID, items []api.ManifestItem) error {
+	args := m.Called(recID, items)
+	return args.Error(0)
+}
+
+func (m *MockManifestRepository) Progress(ctx context.Context, recID uuid.UUID) (api.ManifestProgress, error) {
+	args := m.Called(recID)
+	return args.Get(0).(api.ManifestProgress), args.Error(1)
+}
+
+func (m *MockManifestRepository) Discrepancies(ctx context.Context, recID uuid.UUID) ([]api.ReceptionDiscrepancy, error) {
+	args := m.Called(recID)
+	return args.Get(0).([]api.ReceptionDiscrepancy), args.Error(1)
+}
+
+func TestParseManifestCSV(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    []api.ManifestItem
+		expectedErr error
+	}{
+		{
+			name:  "columns in any order",
+			input: "\ufefftype,barcode\nобувь, 4607001234567\nclothes,4607001234568\n",
+			expected: []api.ManifestItem{
+				{Barcode: "4607001234567", Type: "обувь"},
+				{Barcode: "4607001234568", Type: api.ProductTypeClothes},
+			},
+		},
+		{
+			name:        "missing type column",
+			input:       "barcode\n4607001234567\n",
+			expectedErr: errs.ErrInvalidManifest,
+		},
+		{
+			name:        "empty file",
+			input:       "",
+			expectedErr: errs.ErrInvalidManifest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseManifestCSV(strings.NewReader(tt.input))
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestManifestService_Upload(t *testing.T) {
+	recID := uuid.New()
+	items := []api.ManifestItem{
+		{Barcode: "4607001234567", Type: "обувь"},
+		{Barcode: "4607001234568", Type: api.ProductTypeShoes},
+	}
+	resolved := []api.ManifestItem{
+		{Barcode: "4607001234567", Type: api.ProductTypeShoes},
+		{Barcode: "4607001234568", Type: api.ProductTypeShoes},
+	}
+	progress := api.ManifestProgress{ReceptionId: recID, Expected: 2, Missing: 2}
+
+	tests := []struct {
+		name        string
+		items       []api.ManifestItem
+		mockSetup   func(*MockManifestRepository)
+		expected    api.ManifestProgress
+		expectedErr string
+	}{
+		{
+			name:  "types are stored as codes",
+			items: items,
+			mockSetup: func(m *MockManifestRepository) {
+				m.On("Replace", recID, resolved).Return(nil)
+				m.On("Progress", recID).Return(progress, nil)
+			},
+			expected: progress,
+		},
+		{
+			name:        "empty manifest",
+			mockSetup:   func(m *MockManifestRepository) {},
+			expectedErr: "invalid manifest: no items",
+		},
+		{
+			name:        "too many items",
+			items:       append(items, api.ManifestItem{Barcode: "4607001234569", Type: api.ProductTypeShoes}),
+			mockSetup:   func(m *MockManifestRepository) {},
+			expectedErr: errs.ErrManifestTooLarge.Error(),
+		},
+		{
+			name:        "duplicate barcode",
+			items:       []api.ManifestItem{items[0], items[0]},
+			mockSetup:   func(m *MockManifestRepository) {},
+			expectedErr: "invalid manifest: duplicate barcode 4607001234567",
+		},
+		{
+			name:        "invalid barcode",
+			items:       []api.ManifestItem{{Barcode: "", Type: api.ProductTypeShoes}},
+			mockSetup:   func(m *MockManifestRepository) {},
+			expectedErr: `invalid manifest: invalid barcode ""`,
+		},
+		{
+			name:        "unknown type",
+			items:       []api.ManifestItem{{Barcode: "4607001234567", Type: "мебель"}},
+			mockSetup:   func(m *MockManifestRepository) {},
+			expectedErr: "invalid product type: мебель",
+		},
+		{
+			name:  "reception closed",
+			items: items,
+			mockSetup: func(m *MockManifestRepository) {
+				m.On("Replace", recID, resolved).Return(errs.ErrReceptionClosed)
+			},
+			expectedErr: errs.ErrReceptionClosed.Error(),
+		},
+		{
+			name:  "repository error",
+			items: items,
+			mockSetup: func(m *MockManifestRepository) {
+				m.On("Replace", recID, resolved).Return(errors.New("db error"))
+			},
+			expectedErr: "service.manifest.Upload:db error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockManifestRepository)
+			tt.mockSetup(mockRepo)
+
+			service := NewManifestService(mockRepo, &config.Config{ManifestMaxItems: 2}, staticProductTypes{})
+			result, err := service.Upload(context.Background(), recID, tt.items)
+
+			if tt.expectedErr != "" {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedErr, err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
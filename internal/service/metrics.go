@@ -0,0 +1,21 @@
+package service
+
+import "github.com/ST359/pvz-service/internal/api"
+
+// MetricsRecorder counts business events for monitoring, services report them after successful changes
+type MetricsRecorder interface {
+	PVZsCreated(n int)
+	ReceptionOpened(kind api.ReceptionKind)
+	ReceptionClosed(kind api.ReceptionKind, reason api.ReceptionCloseReason)
+	ProductsAdded(kind api.ReceptionKind, n int)
+	ProductsDeleted(n int)
+}
+
+// nopMetrics is used when no recorder is given
+type nopMetrics struct{}
+
+func (nopMetrics) PVZsCreated(int)                                             {}
+func (nopMetrics) ReceptionOpened(api.ReceptionKind)                           {}
+func (nopMetrics) ReceptionClosed(api.ReceptionKind, api.ReceptionCloseReason) {}
+func (nopMetrics) ProductsAdded(api.ReceptionKind, int)                        {}
+func (nopMetrics) ProductsDeleted(int)                                         {}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/config"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// recordedMetrics keeps business events reported by services
+type recordedMetrics struct {
+	pvzs    int
+	opened  []api.ReceptionKind
+	closed  []api.ReceptionCloseReason
+	added   map[api.ReceptionKind]int
+	deleted int
+}
+
+func (m *recordedMetrics) PVZsCreated(n int)                      { m.pvzs += n }
+func (m *recordedMetrics) ReceptionOpened(kind api.ReceptionKind) { m.opened = append(m.opened, kind) }
+func (m *recordedMetrics) ReceptionClosed(_ api.ReceptionKind, reason api.ReceptionCloseReason) {
+	m.closed = append(m.closed, reason)
+}
+func (m *recordedMetrics) ProductsAdded(kind api.ReceptionKind, n int) {
+	if m.added == nil {
+		m.added = map[api.ReceptionKind]int{}
+	}
+	m.added[kind] += n
+}
+func (m *recordedMetrics) ProductsDeleted(n int) { m.deleted += n }
+
+func TestReceptionService_Metrics(t *testing.T) {
+	pvzID, recID := uuid.New(), uuid.New()
+	rec := api.Reception{Id: &recID, PvzId: pvzID, Kind: api.ReceptionKindInbound}
+	input := api.ProductInput{Type: api.ProductTypeShoes}
+
+	mockRepo := new(MockReceptionRepository)
+	mockRepo.On("GetReceptionInProgress", pvzID, api.ReceptionKindInbound).Return(uuid.Nil, errs.ErrNoReceptionsInProgress).Once()
+	mockRepo.On("Create", pvzID, api.ReceptionKindInbound, uuid.Nil).Return(rec, nil)
+	mockRepo.On("GetReceptionInProgress", pvzID, api.ReceptionKindInbound).Return(recID, nil)
+	mockRepo.On("AddProduct", recID, input).Return(api.Product{}, nil)
+	mockRepo.On("AddProducts", recID, []api.ProductInput{input, input}).Return([]api.Product{{}, {}}, nil)
+	mockRepo.On("AddProducts", recID, []api.ProductInput{input}).Return([]api.Product(nil), errs.ErrNoReceptionsInProgress)
+	mockRepo.On("DeleteLastProduct", recID).Return(nil)
+	mockRepo.On("CloseLastReception", recID, mock.Anything).Return(rec, []api.PickupCode(nil), nil)
+	mockRepo.On("CloseStale", time.Hour, mock.Anything).Return([]api.Reception{rec}, []api.PickupCode(nil), nil)
+
+	recorded := &recordedMetrics{}
+	cfg := &config.Config{ProductBatchMaxSize: 10, StaleReceptionTimeout: time.Hour, StaleReceptionAction: StaleActionClose}
+	service := NewReceptionService(mockRepo, cfg, nil, staticProductTypes{}, recorded)
+
+	_, err := service.Create(context.Background(), pvzID, api.ReceptionKindInbound, uuid.Nil)
+	assert.NoError(t, err)
+	_, err = service.AddProduct(context.Background(), pvzID, api.ReceptionKindInbound, input)
+	assert.NoError(t, err)
+	_, err = service.AddProducts(context.Background(), pvzID, api.ReceptionKindInbound, []api.ProductInput{input, input})
+	assert.NoError(t, err)
+	_, err = service.AddProducts(context.Background(), pvzID, api.ReceptionKindInbound, []api.ProductInput{input})
+	assert.ErrorIs(t, err, errs.ErrNoReceptionsInProgress)
+	assert.NoError(t, service.DeleteLastProduct(context.Background(), pvzID, api.ReceptionKindInbound))
+	_, err = service.CloseLastReception(context.Background(), pvzID, api.ReceptionKindInbound)
+	assert.NoError(t, err)
+	_, err = service.SweepStaleReceptions(context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, []api.ReceptionKind{api.ReceptionKindInbound}, recorded.opened)
+	assert.Equal(t, map[api.ReceptionKind]int{api.ReceptionKindInbound: 3}, recorded.added)
+	assert.Equal(t, 1, recorded.deleted)
+	assert.Equal(t, []api.ReceptionCloseReason{api.CloseReasonManual, api.CloseReasonAutoClosed}, recorded.closed)
+	mockRepo.AssertExpectations(t)
+}
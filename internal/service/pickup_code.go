@@ -0,0 +1,297 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/config"
+	"github.com/ST359/pvz-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	// PickupCodeSenderWebhook posts codes to a notification service
+	PickupCodeSenderWebhook = "webhook"
+	// PickupCodeSenderLog delivers nothing, it is meant for local development
+	PickupCodeSenderLog = "log"
+)
+
+// PickupCodeSender delivers a pickup code to the customer
+type PickupCodeSender interface {
+	SendPickupCode(ctx context.Context, code api.PickupCode, plain string) error
+}
+
+// NewPickupCodeSender creates the sender configured by PICKUP_CODE_SENDER, there is no default
+// so codes are never issued to nobody by accident
+func NewPickupCodeSender(cfg *config.PickupCode, logger *slog.Logger) (PickupCodeSender, error) {
+	const op = "service.NewPickupCodeSender"
+
+	switch cfg.Sender {
+	case PickupCodeSenderWebhook:
+		u, err := url.Parse(cfg.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("%s: pickup code webhook url %q must be an absolute http or https url", op, cfg.WebhookURL)
+		}
+		return NewWebhookPickupCodeSender(cfg.WebhookURL, cfg.WebhookToken, &http.Client{Timeout: cfg.WebhookTimeout}), nil
+	case PickupCodeSenderLog:
+		return NewLogPickupCodeSender(logger), nil
+	case "":
+		return nil, fmt.Errorf("%s: no pickup code sender configured, expected %s or %s", op, PickupCodeSenderWebhook, PickupCodeSenderLog)
+	}
+	return nil, fmt.Errorf("%s: unknown pickup code sender %q, expected %s or %s", op, cfg.Sender, PickupCodeSenderWebhook, PickupCodeSenderLog)
+}
+
+// WebhookPickupCodeSender posts every issued code to a notification service, which delivers it to the customer
+type WebhookPickupCodeSender struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+func NewWebhookPickupCodeSender(endpoint, token string, client *http.Client) *WebhookPickupCodeSender {
+	return &WebhookPickupCodeSender{url: endpoint, token: token, client: client}
+}
+
+// pickupCodeNotification is the body of a webhook request, the only place the plain code leaves the service
+type pickupCodeNotification struct {
+	api.PickupCode
+	Code string `json:"code"`
+}
+
+// SendPickupCode fails unless the receiver answers with 2xx, then the code is left for a moderator to regenerate
+func (s *WebhookPickupCodeSender) SendPickupCode(ctx context.Context, code api.PickupCode, plain string) error {
+	const op = "service.pickup_code.SendPickupCode"
+
+	body, err := json.Marshal(pickupCodeNotification{PickupCode: code, Code: plain})
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s:webhook responded with %s", op, resp.Status)
+	}
+	return nil
+}
+
+// LogPickupCodeSender only logs that a code was issued, customers get nothing and the code itself is never logged.
+// It is for local development until customer notifications are available
+type LogPickupCodeSender struct {
+	logger *slog.Logger
+}
+
+func NewLogPickupCodeSender(logger *slog.Logger) *LogPickupCodeSender {
+	return &LogPickupCodeSender{logger: logger}
+}
+
+func (s *LogPickupCodeSender) SendPickupCode(ctx context.Context, code api.PickupCode, plain string) error {
+	attrs := []any{
+		slog.String("pickup_code_id", code.Id.String()),
+		slog.String("pvz_id", code.PvzId.String()),
+		slog.Int("products", len(code.ProductIds)),
+	}
+	if code.ExternalOrderId != nil {
+		attrs = append(attrs, slog.String("external_order_id", *code.ExternalOrderId))
+	}
+	s.logger.InfoContext(ctx, "pickup code issued", attrs...)
+	return nil
+}
+
+type PickupCodeService struct {
+	repo   repository.PickupCode
+	cfg    *config.PickupCode
+	sender PickupCodeSender
+	logger *slog.Logger
+}
+
+func NewPickupCodeService(repo repository.PickupCode, cfg *config.PickupCode, sender PickupCodeSender, logger *slog.Logger) *PickupCodeService {
+	return &PickupCodeService{repo: repo, cfg: cfg, sender: sender, logger: logger}
+}
+
+// pickupCodeBatch keeps plain codes minted for a transaction in memory until it commits and they can be sent
+type pickupCodeBatch struct {
+	p     *PickupCodeService
+	plain []string
+}
+
+// NewBatch starts a batch of codes stored by one transaction
+func (p *PickupCodeService) NewBatch() PickupCodeBatch {
+	return &pickupCodeBatch{p: p}
+}
+
+// Mint generates codes for targets, only their hashes are handed over to be stored
+func (b *pickupCodeBatch) Mint(targets []api.PickupCode) ([]repository.NewPickupCode, error) {
+	plain := make([]string, len(targets))
+	codes := make([]repository.NewPickupCode, len(targets))
+	for i, target := range targets {
+		code, err := b.p.generate()
+		if err != nil {
+			return nil, err
+		}
+		plain[i] = code
+		codes[i] = repository.NewPickupCode{PickupCode: target, Hash: b.p.hash(code)}
+	}
+	b.plain = plain
+	return codes, nil
+}
+
+// Send delivers stored codes in the order they were minted. Codes that can't be sent are logged,
+// they stay stored and a moderator can regenerate them
+func (b *pickupCodeBatch) Send(ctx context.Context, codes []api.PickupCode) error {
+	var sendErrs []error
+	for i, code := range codes {
+		if i >= len(b.plain) {
+			sendErrs = append(sendErrs, fmt.Errorf("pickup code %s was not minted by the batch", code.Id))
+			continue
+		}
+		if err := b.p.sender.SendPickupCode(ctx, code, b.plain[i]); err != nil {
+			b.p.logger.ErrorContext(ctx, "failed to send pickup code", slog.String("pickup_code_id", code.Id.String()),
+				slog.String("error", err.Error()))
+			sendErrs = append(sendErrs, fmt.Errorf("pickup code %s: %w", code.Id, err))
+		}
+	}
+	return errors.Join(sendErrs...)
+}
+
+// Regenerate revokes active codes of the product or order and sends a new one,
+// can return ErrInvalidPickupCodeRef, ErrProductNotFound, ErrProductInOpenReception and ErrInvalidProductState
+func (p *PickupCodeService) Regenerate(ctx context.Context, pvzID uuid.UUID, ref api.PickupCodeRef) (api.PickupCode, error) {
+	const op = "service.pickup_code.Regenerate"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	ref, err := normalizePickupCodeRef(ref)
+	if err != nil {
+		return api.PickupCode{}, err
+	}
+	target, err := p.repo.RegenerationTarget(ctx, pvzID, ref)
+	if err != nil {
+		if errors.Is(err, errs.ErrProductNotFound) || errors.Is(err, errs.ErrProductInOpenReception) ||
+			errors.Is(err, errs.ErrInvalidProductState) {
+			return api.PickupCode{}, err
+		}
+		return api.PickupCode{}, fmt.Errorf("%s:%w", op, err)
+	}
+	codes, err := p.issue(ctx, []api.PickupCode{target})
+	if err != nil {
+		return api.PickupCode{}, fmt.Errorf("%s:%w", op, err)
+	}
+	return codes[0], nil
+}
+
+// Verify checks the code of the product or order and issues its products,
+// can return ErrInvalidPickupCodeRef, ErrInvalidPickupCode, ErrPickupCodeNotFound, ErrPickupCodeAttemptsExceeded,
+// ErrProductInOpenReception and ErrInvalidProductState
+func (p *PickupCodeService) Verify(ctx context.Context, pvzID uuid.UUID, req api.PickupRequest) ([]api.Product, error) {
+	const op = "service.pickup_code.Verify"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	ref, err := normalizePickupCodeRef(api.PickupCodeRef{ProductId: req.ProductId, ExternalOrderId: req.ExternalOrderId})
+	if err != nil {
+		return nil, err
+	}
+	code := strings.TrimSpace(req.Code)
+	if !p.validCode(code) {
+		return nil, errs.ErrInvalidPickupCode
+	}
+	prods, err := p.repo.Verify(ctx, pvzID, ref, p.hash(code), p.cfg.MaxAttempts)
+	if err != nil {
+		if errors.Is(err, errs.ErrInvalidPickupCode) || errors.Is(err, errs.ErrPickupCodeNotFound) ||
+			errors.Is(err, errs.ErrPickupCodeAttemptsExceeded) || errors.Is(err, errs.ErrProductInOpenReception) ||
+			errors.Is(err, errs.ErrInvalidProductState) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+	return prods, nil
+}
+
+// issue generates codes for targets, stores their hashes and sends the codes
+func (p *PickupCodeService) issue(ctx context.Context, targets []api.PickupCode) ([]api.PickupCode, error) {
+	batch := p.NewBatch()
+	codes, err := batch.Mint(targets)
+	if err != nil {
+		return nil, err
+	}
+	created, err := p.repo.Create(ctx, codes)
+	if err != nil {
+		return nil, err
+	}
+	return created, batch.Send(ctx, created)
+}
+
+// generate returns a random numeric code of cfg.Length digits
+func (p *PickupCodeService) generate() (string, error) {
+	var b strings.Builder
+	ten := big.NewInt(10)
+	for i := 0; i < p.cfg.Length; i++ {
+		d, err := rand.Int(rand.Reader, ten)
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(byte('0' + d.Int64()))
+	}
+	return b.String(), nil
+}
+
+func (p *PickupCodeService) hash(code string) string {
+	mac := hmac.New(sha256.New, []byte(p.cfg.Secret))
+	mac.Write([]byte(code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (p *PickupCodeService) validCode(code string) bool {
+	if len(code) != p.cfg.Length {
+		return false
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizePickupCodeRef makes sure exactly one of productId and externalOrderId is set,
+// can return ErrInvalidPickupCodeRef
+func normalizePickupCodeRef(ref api.PickupCodeRef) (api.PickupCodeRef, error) {
+	if ref.ExternalOrderId != nil {
+		order := strings.TrimSpace(*ref.ExternalOrderId)
+		ref.ExternalOrderId = &order
+		if order == "" {
+			ref.ExternalOrderId = nil
+		}
+	}
+	if (ref.ProductId == nil) == (ref.ExternalOrderId == nil) {
+		return api.PickupCodeRef{}, errs.ErrInvalidPickupCodeRef
+	}
+	return ref, nil
+}
@@ -0,0 +1,346 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/config"
+	"github.com/ST359/pvz-service/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockPickupCodeRepository is a mock implementation of repository.PickupCode
+type MockPickupCodeRepository struct {
+	mock.Mock
+}
+
+func (m *MockPickupCodeRepository) RegenerationTarget(ctx context.Context, pvzID uuid.UUID, ref api.PickupCodeRef) (api.PickupCode, error) {
+	args := m.Called(pvzID, ref)
+	return args.Get(0).(api.PickupCode), args.Error(1)
+}
+
+func (m *MockPickupCodeRepository) Create(ctx context.Context, codes []repository.NewPickupCode) ([]api.PickupCode, error) {
+	args := m.Called(codes)
+	return args.Get(0).([]api.PickupCode), args.Error(1)
+}
+
+func (m *MockPickupCodeRepository) Verify(ctx context.Context, pvzID uuid.UUID, ref api.PickupCodeRef, hash string, maxAttempts int) ([]api.Product, error) {
+	args := m.Called(pvzID, ref, hash, maxAttempts)
+	return args.Get(0).([]api.Product), args.Error(1)
+}
+
+// MockPickupCodeSender records sent codes
+type MockPickupCodeSender struct {
+	mock.Mock
+}
+
+func (m *MockPickupCodeSender) SendPickupCode(ctx context.Context, code api.PickupCode, plain string) error {
+	args := m.Called(code, plain)
+	return args.Error(0)
+}
+
+var pickupCodeTestConfig = config.PickupCode{Length: 6, MaxAttempts: 5, Secret: "secret"}
+
+func TestPickupCodeBatch(t *testing.T) {
+	recID, pvzID := uuid.New(), uuid.New()
+	first, second := uuid.New(), uuid.New()
+	order := "ORD-1"
+	targets := []api.PickupCode{
+		{PvzId: pvzID, ReceptionId: recID, ExternalOrderId: &order, ProductIds: []uuid.UUID{first}},
+		{PvzId: pvzID, ReceptionId: recID, ProductIds: []uuid.UUID{second}},
+	}
+
+	mockSender := new(MockPickupCodeSender)
+	service := NewPickupCodeService(nil, &pickupCodeTestConfig, mockSender, slog.Default())
+	batch := service.NewBatch()
+
+	stored, err := batch.Mint(targets)
+	require.NoError(t, err)
+	require.Len(t, stored, 2)
+	created := make([]api.PickupCode, len(stored))
+	for i, code := range stored {
+		created[i] = code.PickupCode
+		created[i].Id = uuid.New()
+	}
+
+	sent := map[uuid.UUID]string{}
+	mockSender.On("SendPickupCode", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		sent[args.Get(0).(api.PickupCode).ProductIds[0]] = args.String(1)
+	}).Return(nil)
+
+	require.NoError(t, batch.Send(context.Background(), created))
+	for i, code := range stored {
+		assert.Equal(t, targets[i], code.PickupCode)
+		plain := sent[code.ProductIds[0]]
+		assert.Len(t, plain, 6)
+		assert.True(t, service.validCode(plain))
+		assert.Equal(t, service.hash(plain), code.Hash)
+		assert.NotEqual(t, plain, code.Hash)
+	}
+	mockSender.AssertNumberOfCalls(t, "SendPickupCode", 2)
+}
+
+func TestPickupCodeBatch_SendFailure(t *testing.T) {
+	pvzID := uuid.New()
+	targets := []api.PickupCode{
+		{PvzId: pvzID, ProductIds: []uuid.UUID{uuid.New()}},
+		{PvzId: pvzID, ProductIds: []uuid.UUID{uuid.New()}},
+	}
+
+	var buf bytes.Buffer
+	mockSender := new(MockPickupCodeSender)
+	service := NewPickupCodeService(nil, &pickupCodeTestConfig, mockSender, slog.New(slog.NewJSONHandler(&buf, nil)))
+	batch := service.NewBatch()
+	stored, err := batch.Mint(targets)
+	require.NoError(t, err)
+	created := []api.PickupCode{stored[0].PickupCode, stored[1].PickupCode}
+	created[0].Id, created[1].Id = uuid.New(), uuid.New()
+
+	mockSender.On("SendPickupCode", created[0], mock.Anything).Return(errors.New("sms gateway is down"))
+	mockSender.On("SendPickupCode", created[1], mock.Anything).Return(nil)
+
+	err = batch.Send(context.Background(), created)
+
+	assert.ErrorContains(t, err, "sms gateway is down")
+	mockSender.AssertNumberOfCalls(t, "SendPickupCode", 2)
+	assert.Contains(t, buf.String(), created[0].Id.String())
+	assert.NotContains(t, buf.String(), created[1].Id.String())
+}
+
+func TestPickupCodeService_Regenerate(t *testing.T) {
+	pvzID, prodID := uuid.New(), uuid.New()
+	order := " ORD-1 "
+	trimmed := "ORD-1"
+	blank := " "
+	target := api.PickupCode{PvzId: pvzID, ReceptionId: uuid.New(), ExternalOrderId: &trimmed, ProductIds: []uuid.UUID{prodID}}
+
+	tests := []struct {
+		name        string
+		ref         api.PickupCodeRef
+		mockSetup   func(*MockPickupCodeRepository, *MockPickupCodeSender)
+		expectedErr error
+	}{
+		{
+			name: "order code regenerated",
+			ref:  api.PickupCodeRef{ExternalOrderId: &order},
+			mockSetup: func(r *MockPickupCodeRepository, s *MockPickupCodeSender) {
+				r.On("RegenerationTarget", pvzID, api.PickupCodeRef{ExternalOrderId: &trimmed}).Return(target, nil)
+				r.On("Create", mock.Anything).Return([]api.PickupCode{target}, nil)
+				s.On("SendPickupCode", target, mock.Anything).Return(nil)
+			},
+		},
+		{
+			name:        "neither product nor order",
+			ref:         api.PickupCodeRef{ExternalOrderId: &blank},
+			mockSetup:   func(r *MockPickupCodeRepository, s *MockPickupCodeSender) {},
+			expectedErr: errs.ErrInvalidPickupCodeRef,
+		},
+		{
+			name:        "both product and order",
+			ref:         api.PickupCodeRef{ProductId: &prodID, ExternalOrderId: &order},
+			mockSetup:   func(r *MockPickupCodeRepository, s *MockPickupCodeSender) {},
+			expectedErr: errs.ErrInvalidPickupCodeRef,
+		},
+		{
+			name: "product in open reception",
+			ref:  api.PickupCodeRef{ProductId: &prodID},
+			mockSetup: func(r *MockPickupCodeRepository, s *MockPickupCodeSender) {
+				r.On("RegenerationTarget", pvzID, api.PickupCodeRef{ProductId: &prodID}).Return(api.PickupCode{}, errs.ErrProductInOpenReception)
+			},
+			expectedErr: errs.ErrProductInOpenReception,
+		},
+		{
+			name: "sender error",
+			ref:  api.PickupCodeRef{ProductId: &prodID},
+			mockSetup: func(r *MockPickupCodeRepository, s *MockPickupCodeSender) {
+				r.On("RegenerationTarget", pvzID, api.PickupCodeRef{ProductId: &prodID}).Return(target, nil)
+				r.On("Create", mock.Anything).Return([]api.PickupCode{target}, nil)
+				s.On("SendPickupCode", target, mock.Anything).Return(errors.New("smtp error"))
+			},
+			expectedErr: errors.New("service.pickup_code.Regenerate:pickup code 00000000-0000-0000-0000-000000000000: smtp error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockPickupCodeRepository)
+			mockSender := new(MockPickupCodeSender)
+			tt.mockSetup(mockRepo, mockSender)
+
+			service := NewPickupCodeService(mockRepo, &pickupCodeTestConfig, mockSender, slog.Default())
+			result, err := service.Regenerate(context.Background(), pvzID, tt.ref)
+
+			if tt.expectedErr != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, target, result)
+			}
+			mockRepo.AssertExpectations(t)
+			mockSender.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPickupCodeService_Verify(t *testing.T) {
+	pvzID, prodID := uuid.New(), uuid.New()
+	ref := api.PickupCodeRef{ProductId: &prodID}
+	service := NewPickupCodeService(nil, &pickupCodeTestConfig, nil, slog.Default())
+	issued := []api.Product{{Id: &prodID, State: api.ProductStateIssued}}
+
+	tests := []struct {
+		name        string
+		req         api.PickupRequest
+		mockSetup   func(*MockPickupCodeRepository)
+		expectedErr error
+	}{
+		{
+			name: "correct code",
+			req:  api.PickupRequest{ProductId: &prodID, Code: " 012345 "},
+			mockSetup: func(m *MockPickupCodeRepository) {
+				m.On("Verify", pvzID, ref, service.hash("012345"), 5).Return(issued, nil)
+			},
+		},
+		{
+			name:        "malformed code",
+			req:         api.PickupRequest{ProductId: &prodID, Code: "12a45"},
+			mockSetup:   func(m *MockPickupCodeRepository) {},
+			expectedErr: errs.ErrInvalidPickupCode,
+		},
+		{
+			name:        "no reference",
+			req:         api.PickupRequest{Code: "012345"},
+			mockSetup:   func(m *MockPickupCodeRepository) {},
+			expectedErr: errs.ErrInvalidPickupCodeRef,
+		},
+		{
+			name: "attempts exceeded",
+			req:  api.PickupRequest{ProductId: &prodID, Code: "012345"},
+			mockSetup: func(m *MockPickupCodeRepository) {
+				m.On("Verify", pvzID, ref, service.hash("012345"), 5).Return([]api.Product(nil), errs.ErrPickupCodeAttemptsExceeded)
+			},
+			expectedErr: errs.ErrPickupCodeAttemptsExceeded,
+		},
+		{
+			name: "repository error",
+			req:  api.PickupRequest{ProductId: &prodID, Code: "012345"},
+			mockSetup: func(m *MockPickupCodeRepository) {
+				m.On("Verify", pvzID, ref, service.hash("012345"), 5).Return([]api.Product(nil), errors.New("db error"))
+			},
+			expectedErr: errors.New("service.pickup_code.Verify:db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockPickupCodeRepository)
+			tt.mockSetup(mockRepo)
+
+			service := NewPickupCodeService(mockRepo, &pickupCodeTestConfig, nil, slog.Default())
+			result, err := service.Verify(context.Background(), pvzID, tt.req)
+
+			if tt.expectedErr != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, issued, result)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLogPickupCodeSender(t *testing.T) {
+	var buf bytes.Buffer
+	sender := NewLogPickupCodeSender(slog.New(slog.NewJSONHandler(&buf, nil)))
+	order := "ORD-1"
+
+	err := sender.SendPickupCode(context.Background(), api.PickupCode{Id: uuid.New(), ExternalOrderId: &order, ProductIds: []uuid.UUID{uuid.New()}}, "123456")
+
+	assert.NoError(t, err)
+	assert.NotContains(t, buf.String(), "123456")
+	assert.Contains(t, buf.String(), `"external_order_id":"ORD-1"`)
+}
+
+func TestWebhookPickupCodeSender(t *testing.T) {
+	order := "ORD-1"
+	code := api.PickupCode{Id: uuid.New(), PvzId: uuid.New(), ReceptionId: uuid.New(), ExternalOrderId: &order, ProductIds: []uuid.UUID{uuid.New()}}
+
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "delivered", status: http.StatusAccepted},
+		{name: "receiver failed", status: http.StatusBadGateway, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				got  pickupCodeNotification
+				auth string
+			)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				auth = r.Header.Get("Authorization")
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			sender := NewWebhookPickupCodeSender(srv.URL, "token", srv.Client())
+			err := sender.SendPickupCode(context.Background(), code, "123456")
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, "Bearer token", auth)
+			assert.Equal(t, "123456", got.Code)
+			assert.Equal(t, code.Id, got.Id)
+			assert.Equal(t, code.ProductIds, got.ProductIds)
+		})
+	}
+}
+
+func TestNewPickupCodeSender(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.PickupCode
+		expected PickupCodeSender
+	}{
+		{name: "webhook", cfg: config.PickupCode{Sender: PickupCodeSenderWebhook, WebhookURL: "https://notify.local/pickup-codes"}, expected: &WebhookPickupCodeSender{}},
+		{name: "log", cfg: config.PickupCode{Sender: PickupCodeSenderLog}, expected: &LogPickupCodeSender{}},
+		{name: "webhook without url", cfg: config.PickupCode{Sender: PickupCodeSenderWebhook}},
+		{name: "webhook with relative url", cfg: config.PickupCode{Sender: PickupCodeSenderWebhook, WebhookURL: "/pickup-codes"}},
+		{name: "not configured", cfg: config.PickupCode{}},
+		{name: "unknown", cfg: config.PickupCode{Sender: "sms"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender, err := NewPickupCodeSender(&tt.cfg, slog.Default())
+			if tt.expected == nil {
+				assert.Error(t, err)
+				assert.Nil(t, sender)
+			} else {
+				assert.NoError(t, err)
+				assert.IsType(t, tt.expected, sender)
+			}
+		})
+	}
+}
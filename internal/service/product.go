@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/config"
+	"github.com/ST359/pvz-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+type ProductService struct {
+	repo repository.Product
+	cfg  *config.Config
+}
+
+func NewProductService(repo repository.Product, cfg *config.Config) *ProductService {
+	return &ProductService{repo: repo, cfg: cfg}
+}
+
+// FindByBarcode locates a parcel across all PVZs, can return ErrInvalidBarcode
+func (p *ProductService) FindByBarcode(ctx context.Context, barcode string) ([]api.ProductLocation, error) {
+	const op = "service.product.FindByBarcode"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	if !barcodeRe.MatchString(barcode) {
+		return nil, errs.ErrInvalidBarcode
+	}
+	res, err := p.repo.FindByBarcode(ctx, barcode)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+	return res, nil
+}
+
+// ReturnProducts marks stored products of the PVZ as returned to the sender, either all of them or none.
+// Can return ErrEmptyProductBatch, ErrProductBatchTooLarge, ErrProductNotFound, ErrProductInOpenReception
+// and ErrInvalidProductState
+func (p *ProductService) ReturnProducts(ctx context.Context, pvzID uuid.UUID, ids []uuid.UUID) ([]api.Product, error) {
+	const op = "service.product.ReturnProducts"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	unique, err := uniqueProductIDs(ids, p.cfg.ProductBatchMaxSize)
+	if err != nil {
+		return nil, err
+	}
+	res, err := p.repo.ChangeState(ctx, pvzID, unique, api.ProductStateStored, api.ProductStateReturnedToSender)
+	if err != nil {
+		if isProductStateError(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+	return res, nil
+}
+
+// uniqueProductIDs drops repeated ids keeping the order,
+// can return ErrEmptyProductBatch and ErrProductBatchTooLarge
+func uniqueProductIDs(ids []uuid.UUID, maxSize int) ([]uuid.UUID, error) {
+	if len(ids) == 0 {
+		return nil, errs.ErrEmptyProductBatch
+	}
+	unique := make([]uuid.UUID, 0, len(ids))
+	seen := make(map[uuid.UUID]struct{}, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		unique = append(unique, id)
+	}
+	if len(unique) > maxSize {
+		return nil, errs.ErrProductBatchTooLarge
+	}
+	return unique, nil
+}
+
+func isProductStateError(err error) bool {
+	return errors.Is(err, errs.ErrEmptyProductBatch) ||
+		errors.Is(err, errs.ErrProductBatchTooLarge) ||
+		errors.Is(err, errs.ErrProductNotFound) ||
+		errors.Is(err, errs.ErrProductInOpenReception) ||
+		errors.Is(err, errs.ErrInvalidProductState)
+}
+
+// GetInventory returns products of the PVZ, can return ErrPVZNotFound
+func (p *ProductService) GetInventory(ctx context.Context, pvzID uuid.UUID, params api.GetPvzPvzIdInventoryParams) ([]api.Product, error) {
+	const op = "service.product.GetInventory"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	res, err := p.repo.GetInventory(ctx, pvzID, params)
+	if err != nil {
+		if errors.Is(err, errs.ErrPVZNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+	return res, nil
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/config"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockProductRepository is a mock implementation of repository.Product
+type MockProductRepository struct {
+	mock.Mock
+}
+
+func (m *MockProductRepository) FindByBarcode(ctx context.Context, barcode string) ([]api.ProductLocation, error) {
+	args := m.Called(barcode)
+	return args.Get(0).([]api.ProductLocation), args.Error(1)
+}
+
+func (m *MockProductRepository) ChangeState(ctx context.Context, pvzID uuid.UUID, ids []uuid.UUID, from, to api.ProductState) ([]api.Product, error) {
+	args := m.Called(pvzID, ids, from, to)
+	return args.Get(0).([]api.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) GetInventory(ctx context.Context, pvzID uuid.UUID, params api.GetPvzPvzIdInventoryParams) ([]api.Product, error) {
+	args := m.Called(pvzID, params)
+	return args.Get(0).([]api.Product), args.Error(1)
+}
+
+func TestProductService_FindByBarcode(t *testing.T) {
+	barcode := "4607001234567"
+	locations := []api.ProductLocation{{
+		Product:         api.Product{ReceptionId: uuid.New(), Type: api.ProductTypeShoes, Barcode: &barcode},
+		PvzId:           uuid.New(),
+		ReceptionStatus: api.InProgress,
+	}}
+
+	tests := []struct {
+		name        string
+		barcode     string
+		mockSetup   func(*MockProductRepository)
+		expected    []api.ProductLocation
+		expectedErr error
+	}{
+		{
+			name:    "found",
+			barcode: barcode,
+			mockSetup: func(m *MockProductRepository) {
+				m.On("FindByBarcode", barcode).Return(locations, nil)
+			},
+			expected: locations,
+		},
+		{
+			name:        "invalid barcode",
+			barcode:     "with space",
+			mockSetup:   func(m *MockProductRepository) {},
+			expectedErr: errs.ErrInvalidBarcode,
+		},
+		{
+			name:    "repository error",
+			barcode: barcode,
+			mockSetup: func(m *MockProductRepository) {
+				m.On("FindByBarcode", barcode).Return([]api.ProductLocation(nil), errors.New("db error"))
+			},
+			expectedErr: errors.New("service.product.FindByBarcode:db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockProductRepository)
+			tt.mockSetup(mockRepo)
+
+			service := NewProductService(mockRepo, &config.Config{})
+			result, err := service.FindByBarcode(context.Background(), tt.barcode)
+
+			if tt.expectedErr != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProductService_ReturnProducts(t *testing.T) {
+	pvzID := uuid.New()
+	firstID, secondID, thirdID := uuid.New(), uuid.New(), uuid.New()
+	returned := []api.Product{
+		{Id: &firstID, ReceptionId: uuid.New(), Type: api.ProductTypeShoes, State: api.ProductStateReturnedToSender},
+		{Id: &secondID, ReceptionId: uuid.New(), Type: api.ProductTypeClothes, State: api.ProductStateReturnedToSender},
+	}
+
+	tests := []struct {
+		name        string
+		ids         []uuid.UUID
+		mockSetup   func(*MockProductRepository)
+		expected    []api.Product
+		expectedErr error
+	}{
+		{
+			name: "returned",
+			ids:  []uuid.UUID{firstID, secondID},
+			mockSetup: func(m *MockProductRepository) {
+				m.On("ChangeState", pvzID, []uuid.UUID{firstID, secondID}, api.ProductStateStored, api.ProductStateReturnedToSender).Return(returned, nil)
+			},
+			expected: returned,
+		},
+		{
+			name: "duplicate ids are returned once",
+			ids:  []uuid.UUID{firstID, secondID, firstID},
+			mockSetup: func(m *MockProductRepository) {
+				m.On("ChangeState", pvzID, []uuid.UUID{firstID, secondID}, api.ProductStateStored, api.ProductStateReturnedToSender).Return(returned, nil)
+			},
+			expected: returned,
+		},
+		{
+			name:        "empty list",
+			ids:         []uuid.UUID{},
+			mockSetup:   func(m *MockProductRepository) {},
+			expectedErr: errs.ErrEmptyProductBatch,
+		},
+		{
+			name:        "too many products",
+			ids:         []uuid.UUID{firstID, secondID, thirdID},
+			mockSetup:   func(m *MockProductRepository) {},
+			expectedErr: errs.ErrProductBatchTooLarge,
+		},
+		{
+			name: "product in open reception",
+			ids:  []uuid.UUID{firstID},
+			mockSetup: func(m *MockProductRepository) {
+				m.On("ChangeState", pvzID, []uuid.UUID{firstID}, api.ProductStateStored, api.ProductStateReturnedToSender).
+					Return([]api.Product(nil), fmt.Errorf("%w: %s", errs.ErrProductInOpenReception, firstID))
+			},
+			expectedErr: fmt.Errorf("%w: %s", errs.ErrProductInOpenReception, firstID),
+		},
+		{
+			name: "repository error",
+			ids:  []uuid.UUID{firstID},
+			mockSetup: func(m *MockProductRepository) {
+				m.On("ChangeState", pvzID, []uuid.UUID{firstID}, api.ProductStateStored, api.ProductStateReturnedToSender).
+					Return([]api.Product(nil), errors.New("db error"))
+			},
+			expectedErr: errors.New("service.product.ReturnProducts:db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockProductRepository)
+			tt.mockSetup(mockRepo)
+
+			service := NewProductService(mockRepo, &config.Config{ProductBatchMaxSize: 2})
+			result, err := service.ReturnProducts(context.Background(), pvzID, tt.ids)
+
+			if tt.expectedErr != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProductService_GetInventory(t *testing.T) {
+	pvzID := uuid.New()
+	stored := api.ProductStateStored
+	params := api.GetPvzPvzIdInventoryParams{State: &stored}
+	products := []api.Product{{ReceptionId: uuid.New(), Type: api.ProductTypeShoes, State: stored}}
+
+	tests := []struct {
+		name        string
+		mockSetup   func(*MockProductRepository)
+		expected    []api.Product
+		expectedErr error
+	}{
+		{
+			name: "found",
+			mockSetup: func(m *MockProductRepository) {
+				m.On("GetInventory", pvzID, params).Return(products, nil)
+			},
+			expected: products,
+		},
+		{
+			name: "pvz not found",
+			mockSetup: func(m *MockProductRepository) {
+				m.On("GetInventory", pvzID, params).Return([]api.Product(nil), errs.ErrPVZNotFound)
+			},
+			expectedErr: errs.ErrPVZNotFound,
+		},
+		{
+			name: "repository error",
+			mockSetup: func(m *MockProductRepository) {
+				m.On("GetInventory", pvzID, params).Return([]api.Product(nil), errors.New("db error"))
+			},
+			expectedErr: errors.New("service.product.GetInventory:db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockProductRepository)
+			tt.mockSetup(mockRepo)
+
+			service := NewProductService(mockRepo, &config.Config{})
+			result, err := service.GetInventory(context.Background(), pvzID, params)
+
+			if tt.expectedErr != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/repository"
+)
+
+var productTypeCodeRe = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+type ProductTypeService struct {
+	repo repository.ProductType
+}
+
+func NewProductTypeService(repo repository.ProductType) *ProductTypeService {
+	return &ProductTypeService{repo: repo}
+}
+
+func (p *ProductTypeService) List(ctx context.Context, includeInactive bool) ([]api.ProductTypeInfo, error) {
+	const op = "service.product_type.List"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	types, err := p.repo.List(ctx, includeInactive)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+	return types, nil
+}
+
+// Create can return ErrInvalidProductTypeCode, ErrProductTypeNamesEmpty and ErrProductTypeExists
+func (p *ProductTypeService) Create(ctx context.Context, pt api.ProductTypeInput) (api.ProductTypeInfo, error) {
+	const op = "service.product_type.Create"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	if !productTypeCodeRe.MatchString(pt.Code) {
+		return api.ProductTypeInfo{}, errs.ErrInvalidProductTypeCode
+	}
+	if err := validateProductTypeNames(pt.Names); err != nil {
+		return api.ProductTypeInfo{}, err
+	}
+	if pt.LegacyName != nil && strings.TrimSpace(*pt.LegacyName) == "" {
+		pt.LegacyName = nil
+	}
+	res, err := p.repo.Create(ctx, pt)
+	if err != nil {
+		if errors.Is(err, errs.ErrProductTypeExists) {
+			return api.ProductTypeInfo{}, err
+		}
+		return api.ProductTypeInfo{}, fmt.Errorf("%s:%w", op, err)
+	}
+	return res, nil
+}
+
+// Update can return ErrProductTypeNamesEmpty and ErrProductTypeNotFound
+func (p *ProductTypeService) Update(ctx context.Context, code string, pt api.ProductTypeUpdate) (api.ProductTypeInfo, error) {
+	const op = "service.product_type.Update"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	if err := validateProductTypeNames(pt.Names); err != nil {
+		return api.ProductTypeInfo{}, err
+	}
+	res, err := p.repo.Update(ctx, code, pt)
+	if err != nil {
+		if errors.Is(err, errs.ErrProductTypeNotFound) {
+			return api.ProductTypeInfo{}, err
+		}
+		return api.ProductTypeInfo{}, fmt.Errorf("%s:%w", op, err)
+	}
+	return res, nil
+}
+
+// Delete can return ErrProductTypeNotFound and ErrProductTypeInUse
+func (p *ProductTypeService) Delete(ctx context.Context, code string) error {
+	const op = "service.product_type.Delete"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	if err := p.repo.Delete(ctx, code); err != nil {
+		if errors.Is(err, errs.ErrProductTypeNotFound) || errors.Is(err, errs.ErrProductTypeInUse) {
+			return err
+		}
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	return nil
+}
+
+// ResolveProductType returns the code of an active product type given by its code or legacy name,
+// can return ErrInvalidProductType
+func (p *ProductTypeService) ResolveProductType(ctx context.Context, name string) (api.ProductType, error) {
+	const op = "service.product_type.ResolveProductType"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	if name == "" {
+		return "", errs.ErrInvalidProductType
+	}
+	pt, err := p.repo.Resolve(ctx, name)
+	if err != nil {
+		if errors.Is(err, errs.ErrProductTypeNotFound) {
+			return "", fmt.Errorf("%w: %s", errs.ErrInvalidProductType, name)
+		}
+		return "", fmt.Errorf("%s:%w", op, err)
+	}
+	if !pt.Active {
+		return "", fmt.Errorf("%w: %s is not active", errs.ErrInvalidProductType, pt.Code)
+	}
+	return pt.Code, nil
+}
+
+// validateProductTypeNames requires at least one display name and no blank ones
+func validateProductTypeNames(names map[string]string) error {
+	if len(names) == 0 {
+		return errs.ErrProductTypeNamesEmpty
+	}
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
+			return errs.ErrProductTypeNamesEmpty
+		}
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockProductTypeRepository is a mock implementation of repository.ProductType
+type MockProductTypeRepository struct {
+	mock.Mock
+}
+
+func (m *MockProductTypeRepository) List(ctx context.Context, includeInactive bool) ([]api.ProductTypeInfo, error) {
+	args := m.Called(includeInactive)
+	return args.Get(0).([]api.ProductTypeInfo), args.Error(1)
+}
+
+func (m *MockProductTypeRepository) Resolve(ctx context.Context, name string) (api.ProductTypeInfo, error) {
+	args := m.Called(name)
+	return args.Get(0).(api.ProductTypeInfo), args.Error(1)
+}
+
+func (m *MockProductTypeRepository) Create(ctx context.Context, pt api.ProductTypeInput) (api.ProductTypeInfo, error) {
+	args := m.Called(pt)
+	return args.Get(0).(api.ProductTypeInfo), args.Error(1)
+}
+
+func (m *MockProductTypeRepository) Update(ctx context.Context, code string, pt api.ProductTypeUpdate) (api.ProductTypeInfo, error) {
+	args := m.Called(code, pt)
+	return args.Get(0).(api.ProductTypeInfo), args.Error(1)
+}
+
+func (m *MockProductTypeRepository) Delete(ctx context.Context, code string) error {
+	args := m.Called(code)
+	return args.Error(0)
+}
+
+func TestProductTypeService_Create(t *testing.T) {
+	names := map[string]string{"ru": "Мебель", "en": "Furniture"}
+	blank := " "
+
+	tests := []struct {
+		name        string
+		input       api.ProductTypeInput
+		mockSetup   func(*MockProductTypeRepository)
+		expected    api.ProductTypeInfo
+		expectedErr error
+	}{
+		{
+			name:  "successful creation",
+			input: api.ProductTypeInput{Code: "furniture", Names: names, LegacyName: &blank},
+			mockSetup: func(m *MockProductTypeRepository) {
+				m.On("Create", api.ProductTypeInput{Code: "furniture", Names: names}).
+					Return(api.ProductTypeInfo{Code: "furniture", Names: names, Active: true}, nil)
+			},
+			expected: api.ProductTypeInfo{Code: "furniture", Names: names, Active: true},
+		},
+		{
+			name:        "cyrillic code",
+			input:       api.ProductTypeInput{Code: "мебель", Names: names},
+			mockSetup:   func(m *MockProductTypeRepository) {},
+			expectedErr: errs.ErrInvalidProductTypeCode,
+		},
+		{
+			name:        "code starting with digit",
+			input:       api.ProductTypeInput{Code: "1st", Names: names},
+			mockSetup:   func(m *MockProductTypeRepository) {},
+			expectedErr: errs.ErrInvalidProductTypeCode,
+		},
+		{
+			name:        "no names",
+			input:       api.ProductTypeInput{Code: "furniture"},
+			mockSetup:   func(m *MockProductTypeRepository) {},
+			expectedErr: errs.ErrProductTypeNamesEmpty,
+		},
+		{
+			name:        "blank name",
+			input:       api.ProductTypeInput{Code: "furniture", Names: map[string]string{"ru": ""}},
+			mockSetup:   func(m *MockProductTypeRepository) {},
+			expectedErr: errs.ErrProductTypeNamesEmpty,
+		},
+		{
+			name:  "code already exists",
+			input: api.ProductTypeInput{Code: "shoes", Names: names},
+			mockSetup: func(m *MockProductTypeRepository) {
+				m.On("Create", api.ProductTypeInput{Code: "shoes", Names: names}).
+					Return(api.ProductTypeInfo{}, errs.ErrProductTypeExists)
+			},
+			expectedErr: errs.ErrProductTypeExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockProductTypeRepository)
+			tt.mockSetup(mockRepo)
+
+			service := NewProductTypeService(mockRepo)
+			result, err := service.Create(context.Background(), tt.input)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProductTypeService_Delete(t *testing.T) {
+	tests := []struct {
+		name        string
+		mockErr     error
+		expectedErr error
+	}{
+		{name: "successful deletion"},
+		{name: "type in use", mockErr: errs.ErrProductTypeInUse, expectedErr: errs.ErrProductTypeInUse},
+		{name: "type not found", mockErr: errs.ErrProductTypeNotFound, expectedErr: errs.ErrProductTypeNotFound},
+		{name: "repository error", mockErr: errors.New("db error"), expectedErr: errors.New("service.product_type.Delete:db error")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockProductTypeRepository)
+			mockRepo.On("Delete", "furniture").Return(tt.mockErr)
+
+			service := NewProductTypeService(mockRepo)
+			err := service.Delete(context.Background(), "furniture")
+
+			if tt.expectedErr != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProductTypeService_ResolveProductType(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		mockSetup   func(*MockProductTypeRepository)
+		expected    api.ProductType
+		expectedErr string
+	}{
+		{
+			name:  "by code",
+			input: "shoes",
+			mockSetup: func(m *MockProductTypeRepository) {
+				m.On("Resolve", "shoes").Return(api.ProductTypeInfo{Code: "shoes", Active: true}, nil)
+			},
+			expected: api.ProductTypeShoes,
+		},
+		{
+			name:  "by legacy name",
+			input: "обувь",
+			mockSetup: func(m *MockProductTypeRepository) {
+				m.On("Resolve", "обувь").Return(api.ProductTypeInfo{Code: "shoes", Active: true}, nil)
+			},
+			expected: api.ProductTypeShoes,
+		},
+		{
+			name:  "inactive type",
+			input: "furniture",
+			mockSetup: func(m *MockProductTypeRepository) {
+				m.On("Resolve", "furniture").Return(api.ProductTypeInfo{Code: "furniture"}, nil)
+			},
+			expectedErr: "invalid product type: furniture is not active",
+		},
+		{
+			name:  "unknown type",
+			input: "мебель",
+			mockSetup: func(m *MockProductTypeRepository) {
+				m.On("Resolve", "мебель").Return(api.ProductTypeInfo{}, errs.ErrProductTypeNotFound)
+			},
+			expectedErr: "invalid product type: мебель",
+		},
+		{
+			name:        "empty type",
+			mockSetup:   func(m *MockProductTypeRepository) {},
+			expectedErr: errs.ErrInvalidProductType.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockProductTypeRepository)
+			tt.mockSetup(mockRepo)
+
+			service := NewProductTypeService(mockRepo)
+			result, err := service.ResolveProductType(context.Background(), tt.input)
+
+			if tt.expectedErr != "" {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedErr, err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -1,32 +1,57 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
 	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
 	"github.com/ST359/pvz-service/internal/repository"
 )
 
 type PVZService struct {
-	repo repository.PVZ
+	repo    repository.PVZ
+	metrics MetricsRecorder
 }
 
-func NewPVZService(repo repository.PVZ) *PVZService {
-	return &PVZService{repo: repo}
+// NewPVZService creates the service, metrics may be nil if created PVZs aren't counted
+func NewPVZService(repo repository.PVZ, metrics MetricsRecorder) *PVZService {
+	if metrics == nil {
+		metrics = nopMetrics{}
+	}
+	return &PVZService{repo: repo, metrics: metrics}
 }
-func (p *PVZService) Create(pvz api.PVZ) (api.PVZ, error) {
+
+// Create can return ErrPVZAddressExists
+func (p *PVZService) Create(ctx context.Context, pvz api.PVZ) (api.PVZ, error) {
 	const op = "service.pvz.Create"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
 
-	res, err := p.repo.Create(pvz)
+	if pvz.Address != nil {
+		address := normalizeAddress(*pvz.Address)
+		pvz.Address = &address
+		if address == "" {
+			pvz.Address = nil
+		}
+	}
+	res, err := p.repo.Create(ctx, pvz)
 	if err != nil {
+		if errors.Is(err, errs.ErrPVZAddressExists) {
+			return api.PVZ{}, err
+		}
 		return api.PVZ{}, fmt.Errorf("%s:%w", op, err)
 	}
+	p.metrics.PVZsCreated(1)
 	return res, nil
 }
-func (p *PVZService) GetByDate(params api.GetPvzParams) ([]api.PVZInfo, error) {
+func (p *PVZService) GetByDate(ctx context.Context, params api.GetPvzParams) ([]api.PVZInfo, error) {
 	const op = "service.pvz.GetByDate"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
 
-	resp, err := p.repo.GetByDate(params)
+	resp, err := p.repo.GetByDate(ctx, params)
 	if err != nil {
 		return []api.PVZInfo{}, fmt.Errorf("%s:%w", op, err)
 	}
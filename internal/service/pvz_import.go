@@ -0,0 +1,249 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+)
+
+const (
+	maxAddressLength = 255
+	maxNDJSONLineLen = 64 * 1024
+)
+
+var supportedCities = map[api.PVZCity]struct{}{
+	api.Moscow:          {},
+	api.SaintPetersburg: {},
+	api.Kazan:           {},
+}
+
+// PVZImportRow is a single record read from a PVZ import file
+type PVZImportRow struct {
+	Line    int
+	City    string
+	Address string
+}
+
+// ParsePVZImport reads PVZ records from r in the given format.
+// Can return ErrInvalidImportFile or ErrUnsupportedFormat
+func ParsePVZImport(r io.Reader, format api.PVZImportFormat) ([]PVZImportRow, error) {
+	var (
+		rows []PVZImportRow
+		err  error
+	)
+	switch format {
+	case api.PVZImportCSV:
+		rows, err = parsePVZImportCSV(r)
+	case api.PVZImportNDJSON:
+		rows, err = parsePVZImportNDJSON(r)
+	default:
+		return nil, errs.ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: no records found", errs.ErrInvalidImportFile)
+	}
+	return rows, nil
+}
+
+// parsePVZImportCSV expects a header with city and address columns, order of columns does not matter
+func parsePVZImportCSV(r io.Reader) ([]PVZImportRow, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: empty file", errs.ErrInvalidImportFile)
+		}
+		return nil, fmt.Errorf("%w: %s", errs.ErrInvalidImportFile, err.Error())
+	}
+	cityIdx, addressIdx := -1, -1
+	for i, col := range header {
+		switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(col, "\ufeff"))) {
+		case "city":
+			cityIdx = i
+		case "address":
+			addressIdx = i
+		}
+	}
+	if cityIdx == -1 || addressIdx == -1 {
+		return nil, fmt.Errorf("%w: header must contain city and address columns", errs.ErrInvalidImportFile)
+	}
+
+	var rows []PVZImportRow
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", errs.ErrInvalidImportFile, err.Error())
+		}
+		line, _ := cr.FieldPos(0)
+		rows = append(rows, PVZImportRow{
+			Line:    line,
+			City:    csvField(record, cityIdx),
+			Address: csvField(record, addressIdx),
+		})
+	}
+	return rows, nil
+}
+
+func csvField(record []string, idx int) string {
+	if idx >= len(record) {
+		return ""
+	}
+	return record[idx]
+}
+
+// parsePVZImportNDJSON expects one {"city": ..., "address": ...} object per line, blank lines are skipped
+func parsePVZImportNDJSON(r io.Reader) ([]PVZImportRow, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 4096), maxNDJSONLineLen)
+
+	var rows []PVZImportRow
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" {
+			continue
+		}
+		var rec struct {
+			City    string `json:"city"`
+			Address string `json:"address"`
+		}
+		if err := json.Unmarshal([]byte(text), &rec); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %s", errs.ErrInvalidImportFile, line, err.Error())
+		}
+		rows = append(rows, PVZImportRow{Line: line, City: rec.City, Address: rec.Address})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %s", errs.ErrInvalidImportFile, err.Error())
+	}
+	return rows, nil
+}
+
+// Import validates every row and, unless dryRun is set or some rows are invalid,
+// creates all PVZs in a single transaction.
+// Can return ErrPVZAddressExists if a PVZ with the same address was created concurrently
+func (p *PVZService) Import(ctx context.Context, rows []PVZImportRow, dryRun bool) (api.PVZImportReport, error) {
+	const op = "service.pvz.Import"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	report := api.PVZImportReport{
+		DryRun: dryRun,
+		Total:  len(rows),
+		Rows:   make([]api.PVZImportRowResult, len(rows)),
+	}
+
+	cities := make([]api.PVZCity, 0, len(supportedCities))
+	seenCities := make(map[api.PVZCity]struct{})
+	firstLine := make(map[string]int, len(rows))
+	for i, row := range rows {
+		city := strings.TrimSpace(row.City)
+		address := normalizeAddress(row.Address)
+		res := api.PVZImportRowResult{Line: row.Line, City: city, Address: address}
+
+		var rowErrs []string
+		if _, ok := supportedCities[api.PVZCity(city)]; !ok {
+			rowErrs = append(rowErrs, "unsupported city")
+		} else if _, ok := seenCities[api.PVZCity(city)]; !ok {
+			seenCities[api.PVZCity(city)] = struct{}{}
+			cities = append(cities, api.PVZCity(city))
+		}
+		switch {
+		case address == "":
+			rowErrs = append(rowErrs, "address is required")
+		case len(address) > maxAddressLength:
+			rowErrs = append(rowErrs, fmt.Sprintf("address is longer than %d bytes", maxAddressLength))
+		default:
+			key := addressKey(city, address)
+			if line, ok := firstLine[key]; ok {
+				rowErrs = append(rowErrs, fmt.Sprintf("duplicate of line %d", line))
+			} else {
+				firstLine[key] = row.Line
+			}
+		}
+		if len(rowErrs) > 0 {
+			res.Errors = &rowErrs
+		}
+		report.Rows[i] = res
+	}
+
+	if len(cities) > 0 {
+		existing, err := p.repo.GetByCities(ctx, cities)
+		if err != nil {
+			return api.PVZImportReport{}, fmt.Errorf("%s:%w", op, err)
+		}
+		registered := make(map[string]struct{}, len(existing))
+		for _, pvz := range existing {
+			if pvz.Address != nil {
+				registered[addressKey(string(pvz.City), normalizeAddress(*pvz.Address))] = struct{}{}
+			}
+		}
+		for i, res := range report.Rows {
+			if _, ok := registered[addressKey(res.City, res.Address)]; !ok {
+				continue
+			}
+			var rowErrs []string
+			if res.Errors != nil {
+				rowErrs = *res.Errors
+			}
+			rowErrs = append(rowErrs, errs.ErrPVZAddressExists.Error())
+			report.Rows[i].Errors = &rowErrs
+		}
+	}
+
+	pvzs := make([]api.PVZ, 0, len(rows))
+	for i, res := range report.Rows {
+		if res.Errors != nil {
+			report.Rows[i].Status = api.PVZImportRowInvalid
+			report.Invalid++
+			continue
+		}
+		report.Rows[i].Status = api.PVZImportRowValid
+		report.Valid++
+		address := res.Address
+		pvzs = append(pvzs, api.PVZ{City: api.PVZCity(res.City), Address: &address})
+	}
+	if dryRun || report.Invalid > 0 {
+		return report, nil
+	}
+
+	created, err := p.repo.CreateBatch(ctx, pvzs)
+	if err != nil {
+		if errors.Is(err, errs.ErrPVZAddressExists) {
+			return api.PVZImportReport{}, err
+		}
+		return api.PVZImportReport{}, fmt.Errorf("%s:%w", op, err)
+	}
+	// every row is valid at this point, so created PVZs follow the order of rows
+	for i := range report.Rows {
+		report.Rows[i].Status = api.PVZImportRowCreated
+		report.Rows[i].Pvz = &created[i]
+	}
+	report.Created = len(created)
+	p.metrics.PVZsCreated(len(created))
+	return report, nil
+}
+
+// normalizeAddress trims address and collapses inner whitespace
+func normalizeAddress(address string) string {
+	return strings.Join(strings.Fields(address), " ")
+}
+
+func addressKey(city, address string) string {
+	return city + "\x00" + strings.ToLower(address)
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestParsePVZImport(t *testing.T) {
+	tests := []struct {
+		name        string
+		format      api.PVZImportFormat
+		input       string
+		expected    []PVZImportRow
+		expectedErr error
+	}{
+		{
+			name:   "csv with reordered columns",
+			format: api.PVZImportCSV,
+			input:  "address,city\n\"ул. Баумана, 5\",Казань\nНевский пр. 10,Санкт-Петербург\n",
+			expected: []PVZImportRow{
+				{Line: 2, City: "Казань", Address: "ул. Баумана, 5"},
+				{Line: 3, City: "Санкт-Петербург", Address: "Невский пр. 10"},
+			},
+		},
+		{
+			name:   "csv with missing field",
+			format: api.PVZImportCSV,
+			input:  "city,address\nМосква\n",
+			expected: []PVZImportRow{
+				{Line: 2, City: "Москва", Address: ""},
+			},
+		},
+		{
+			name:        "csv without address column",
+			format:      api.PVZImportCSV,
+			input:       "city\nМосква\n",
+			expectedErr: errs.ErrInvalidImportFile,
+		},
+		{
+			name:        "csv with header only",
+			format:      api.PVZImportCSV,
+			input:       "city,address\n",
+			expectedErr: errs.ErrInvalidImportFile,
+		},
+		{
+			name:   "ndjson skips blank lines",
+			format: api.PVZImportNDJSON,
+			input:  "{\"city\":\"Москва\",\"address\":\"Тверская 1\"}\n\n{\"city\":\"Казань\",\"address\":\"Баумана 5\"}\n",
+			expected: []PVZImportRow{
+				{Line: 1, City: "Москва", Address: "Тверская 1"},
+				{Line: 3, City: "Казань", Address: "Баумана 5"},
+			},
+		},
+		{
+			name:        "ndjson with malformed line",
+			format:      api.PVZImportNDJSON,
+			input:       "{\"city\":\"Москва\",\"address\":\"Тверская 1\"}\n{city}\n",
+			expectedErr: errs.ErrInvalidImportFile,
+		},
+		{
+			name:        "unsupported format",
+			format:      "xml",
+			input:       "<pvz/>",
+			expectedErr: errs.ErrUnsupportedFormat,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParsePVZImport(strings.NewReader(tt.input), tt.format)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestPVZService_Import(t *testing.T) {
+	existing := "Тверская 1"
+	firstID, secondID := uuid.New(), uuid.New()
+
+	validRows := []PVZImportRow{
+		{Line: 2, City: "Москва", Address: "  Арбат   10 "},
+		{Line: 3, City: "Казань", Address: "Баумана 5"},
+	}
+	arbat, baumana := "Арбат 10", "Баумана 5"
+	toCreate := []api.PVZ{
+		{City: api.Moscow, Address: &arbat},
+		{City: api.Kazan, Address: &baumana},
+	}
+
+	tests := []struct {
+		name        string
+		rows        []PVZImportRow
+		dryRun      bool
+		mockSetup   func(*MockPVZRepository)
+		check       func(t *testing.T, report api.PVZImportReport)
+		expectedErr string
+	}{
+		{
+			name: "all rows created",
+			rows: validRows,
+			mockSetup: func(m *MockPVZRepository) {
+				m.On("GetByCities", []api.PVZCity{api.Moscow, api.Kazan}).Return([]api.PVZ{}, nil)
+				m.On("CreateBatch", toCreate).Return([]api.PVZ{
+					{Id: &firstID, City: api.Moscow, Address: &arbat},
+					{Id: &secondID, City: api.Kazan, Address: &baumana},
+				}, nil)
+			},
+			check: func(t *testing.T, report api.PVZImportReport) {
+				assert.Equal(t, 2, report.Total)
+				assert.Equal(t, 2, report.Valid)
+				assert.Equal(t, 2, report.Created)
+				assert.Equal(t, api.PVZImportRowCreated, report.Rows[0].Status)
+				assert.Equal(t, "Арбат 10", report.Rows[0].Address)
+				assert.Equal(t, firstID, *report.Rows[0].Pvz.Id)
+				assert.Equal(t, secondID, *report.Rows[1].Pvz.Id)
+			},
+		},
+		{
+			name:   "dry run does not create",
+			rows:   validRows,
+			dryRun: true,
+			mockSetup: func(m *MockPVZRepository) {
+				m.On("GetByCities", []api.PVZCity{api.Moscow, api.Kazan}).Return([]api.PVZ{}, nil)
+			},
+			check: func(t *testing.T, report api.PVZImportReport) {
+				assert.True(t, report.DryRun)
+				assert.Equal(t, 2, report.Valid)
+				assert.Equal(t, 0, report.Created)
+				assert.Equal(t, api.PVZImportRowValid, report.Rows[1].Status)
+			},
+		},
+		{
+			name: "invalid rows prevent creation",
+			rows: []PVZImportRow{
+				{Line: 2, City: "Тверь", Address: "Советская 1"},
+				{Line: 3, City: "Москва", Address: ""},
+				{Line: 4, City: "Москва", Address: "арбат 10"},
+				{Line: 5, City: "Москва", Address: "Арбат  10"},
+				{Line: 6, City: "Москва", Address: "тверская 1"},
+			},
+			mockSetup: func(m *MockPVZRepository) {
+				m.On("GetByCities", []api.PVZCity{api.Moscow}).Return([]api.PVZ{
+					{City: api.Moscow, Address: &existing},
+				}, nil)
+			},
+			check: func(t *testing.T, report api.PVZImportReport) {
+				assert.Equal(t, 5, report.Total)
+				assert.Equal(t, 1, report.Valid)
+				assert.Equal(t, 4, report.Invalid)
+				assert.Equal(t, 0, report.Created)
+				assert.Equal(t, []string{"unsupported city"}, *report.Rows[0].Errors)
+				assert.Equal(t, []string{"address is required"}, *report.Rows[1].Errors)
+				assert.Nil(t, report.Rows[2].Errors)
+				assert.Equal(t, []string{"duplicate of line 4"}, *report.Rows[3].Errors)
+				assert.Equal(t, []string{errs.ErrPVZAddressExists.Error()}, *report.Rows[4].Errors)
+			},
+		},
+		{
+			name: "address taken concurrently",
+			rows: validRows,
+			mockSetup: func(m *MockPVZRepository) {
+				m.On("GetByCities", mock.Anything).Return([]api.PVZ{}, nil)
+				m.On("CreateBatch", toCreate).Return([]api.PVZ{}, errs.ErrPVZAddressExists)
+			},
+			expectedErr: errs.ErrPVZAddressExists.Error(),
+		},
+		{
+			name: "repository error",
+			rows: validRows,
+			mockSetup: func(m *MockPVZRepository) {
+				m.On("GetByCities", mock.Anything).Return([]api.PVZ{}, errors.New("db error"))
+			},
+			expectedErr: "service.pvz.Import:db error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockPVZRepository)
+			tt.mockSetup(mockRepo)
+
+			service := NewPVZService(mockRepo, nil)
+			report, err := service.Import(context.Background(), tt.rows, tt.dryRun)
+
+			if tt.expectedErr != "" {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedErr, err.Error())
+			} else {
+				assert.NoError(t, err)
+				tt.check(t, report)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -16,16 +17,26 @@ type MockPVZRepository struct {
 	mock.Mock
 }
 
-func (m *MockPVZRepository) Create(pvz api.PVZ) (api.PVZ, error) {
+func (m *MockPVZRepository) Create(ctx context.Context, pvz api.PVZ) (api.PVZ, error) {
 	args := m.Called(pvz)
 	return args.Get(0).(api.PVZ), args.Error(1)
 }
 
-func (m *MockPVZRepository) GetByDate(params api.GetPvzParams) ([]api.PVZInfo, error) {
+func (m *MockPVZRepository) GetByDate(ctx context.Context, params api.GetPvzParams) ([]api.PVZInfo, error) {
 	args := m.Called(params)
 	return args.Get(0).([]api.PVZInfo), args.Error(1)
 }
 
+func (m *MockPVZRepository) CreateBatch(ctx context.Context, pvzs []api.PVZ) ([]api.PVZ, error) {
+	args := m.Called(pvzs)
+	return args.Get(0).([]api.PVZ), args.Error(1)
+}
+
+func (m *MockPVZRepository) GetByCities(ctx context.Context, cities []api.PVZCity) ([]api.PVZ, error) {
+	args := m.Called(cities)
+	return args.Get(0).([]api.PVZ), args.Error(1)
+}
+
 func TestPVZService_Create(t *testing.T) {
 	now := time.Now()
 	testUUID := uuid.New()
@@ -75,8 +86,8 @@ func TestPVZService_Create(t *testing.T) {
 			mockRepo := new(MockPVZRepository)
 			tt.mockSetup(mockRepo)
 
-			service := NewPVZService(mockRepo)
-			result, err := service.Create(tt.input)
+			service := NewPVZService(mockRepo, nil)
+			result, err := service.Create(context.Background(), tt.input)
 
 			if tt.expectedErr != nil {
 				assert.Error(t, err)
@@ -165,8 +176,8 @@ func TestPVZService_GetByDate(t *testing.T) {
 			mockRepo := new(MockPVZRepository)
 			tt.mockSetup(mockRepo)
 
-			service := NewPVZService(mockRepo)
-			result, err := service.GetByDate(tt.input)
+			service := NewPVZService(mockRepo, nil)
+			result, err := service.GetByDate(context.Background(), tt.input)
 
 			if tt.expectedErr != nil {
 				assert.Error(t, err)
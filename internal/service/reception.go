@@ -1,27 +1,63 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/ST359/pvz-service/internal/api"
 	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/config"
 	"github.com/ST359/pvz-service/internal/repository"
 	"github.com/google/uuid"
 )
 
+// PickupCodeIssuer creates pickup codes for products of closed receptions
+type PickupCodeIssuer interface {
+	NewBatch() PickupCodeBatch
+}
+
+// PickupCodeBatch mints codes stored by the transaction closing receptions and sends them once it has committed
+type PickupCodeBatch interface {
+	Mint(targets []api.PickupCode) ([]repository.NewPickupCode, error)
+	Send(ctx context.Context, codes []api.PickupCode) error
+}
+
+// ProductTypeResolver maps a product type given by a client to the code of an active catalog type
+type ProductTypeResolver interface {
+	ResolveProductType(ctx context.Context, name string) (api.ProductType, error)
+}
+
 type ReceptionService struct {
-	repo repository.Reception
+	repo    repository.Reception
+	cfg     *config.Config
+	codes   PickupCodeIssuer
+	types   ProductTypeResolver
+	metrics MetricsRecorder
 }
 
-func NewReceptionService(repo repository.Reception) *ReceptionService {
-	return &ReceptionService{repo: repo}
+// NewReceptionService creates the service, codes may be nil if pickup codes aren't needed and metrics if events aren't counted
+func NewReceptionService(repo repository.Reception, cfg *config.Config, codes PickupCodeIssuer, types ProductTypeResolver, metrics MetricsRecorder) *ReceptionService {
+	if metrics == nil {
+		metrics = nopMetrics{}
+	}
+	return &ReceptionService{repo: repo, cfg: cfg, codes: codes, types: types, metrics: metrics}
 }
 
-func (r *ReceptionService) Create(pvzID uuid.UUID) (api.Reception, error) {
+// Create opens a reception of the given kind on behalf of the user, uuid.Nil leaves the creator empty.
+// Can return ErrInvalidReceptionKind and ErrReceptionNotClosed if the PVZ already has a reception of this kind in progress
+func (r *ReceptionService) Create(ctx context.Context, pvzID uuid.UUID, kind api.ReceptionKind, userID uuid.UUID) (api.Reception, error) {
 	const op = "service.reception.Create"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
 
-	id, err := r.GetReceptionInProgress(pvzID)
+	if err := validateReceptionKind(kind); err != nil {
+		return api.Reception{}, err
+	}
+	id, err := r.GetReceptionInProgress(ctx, pvzID, kind)
 	if err != nil {
 		if !errors.Is(err, errs.ErrNoReceptionsInProgress) {
 			return api.Reception{}, fmt.Errorf("%s:%w", op, err)
@@ -31,16 +67,42 @@ func (r *ReceptionService) Create(pvzID uuid.UUID) (api.Reception, error) {
 		return api.Reception{}, errs.ErrReceptionNotClosed
 	}
 
-	rec, err := r.repo.Create(pvzID)
+	// the check above is only a fast path, the database rejects a concurrent second reception
+	rec, err := r.repo.Create(ctx, pvzID, kind, userID)
 	if err != nil {
+		if errors.Is(err, errs.ErrReceptionNotClosed) {
+			return api.Reception{}, err
+		}
 		return api.Reception{}, fmt.Errorf("%s:%w", op, err)
 	}
+	r.metrics.ReceptionOpened(kind)
 	return rec, nil
 }
-func (r *ReceptionService) AddProduct(pvzID uuid.UUID, product api.ProductType) (api.Product, error) {
+
+// AddProduct adds the product to the reception of the given kind in progress,
+// can return ErrInvalidReceptionKind, ErrInvalidProductType, ErrInvalidBarcode, ErrInvalidExternalOrderID,
+// return fields errors, ErrDuplicateBarcode, ErrNoReceptionsInProgress and storage cell errors
+func (r *ReceptionService) AddProduct(ctx context.Context, pvzID uuid.UUID, kind api.ReceptionKind, product api.ProductInput) (api.Product, error) {
 	const op = "service.reception.AddProduct"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
 
-	recID, err := r.GetReceptionInProgress(pvzID)
+	if err := validateReceptionKind(kind); err != nil {
+		return api.Product{}, err
+	}
+	if err := validateProductInput(product, kind); err != nil {
+		return api.Product{}, err
+	}
+	resolved, err := r.resolveProductTypes(ctx, []api.ProductInput{product})
+	if err != nil {
+		if errors.Is(err, errs.ErrInvalidProductType) {
+			return api.Product{}, err
+		}
+		return api.Product{}, fmt.Errorf("%s:%w", op, err)
+	}
+	product = resolved[0]
+
+	recID, err := r.GetReceptionInProgress(ctx, pvzID, kind)
 	if err != nil {
 		if errors.Is(err, errs.ErrNoReceptionsInProgress) {
 			return api.Product{}, err
@@ -51,16 +113,79 @@ func (r *ReceptionService) AddProduct(pvzID uuid.UUID, product api.ProductType)
 		return api.Product{}, errs.ErrNoReceptionsInProgress
 	}
 
-	prod, err := r.repo.AddProduct(recID, product)
+	prod, err := r.repo.AddProduct(ctx, recID, product)
 	if err != nil {
+		if errors.Is(err, errs.ErrDuplicateBarcode) || errors.Is(err, errs.ErrNoReceptionsInProgress) || isStorageCellError(err) {
+			return api.Product{}, err
+		}
 		return api.Product{}, fmt.Errorf("%s:%w", op, err)
 	}
+	r.metrics.ProductsAdded(kind, 1)
 	return prod, nil
 }
-func (r *ReceptionService) GetReceptionInProgress(pvzID uuid.UUID) (uuid.UUID, error) {
+
+// AddProducts adds all products to the reception of the given kind in progress at once,
+// can return ErrInvalidReceptionKind, ErrEmptyProductBatch, ErrProductBatchTooLarge, ErrInvalidProductType, ErrInvalidBarcode,
+// ErrInvalidExternalOrderID, return fields errors, ErrDuplicateBarcode, ErrNoReceptionsInProgress and storage cell errors
+func (r *ReceptionService) AddProducts(ctx context.Context, pvzID uuid.UUID, kind api.ReceptionKind, products []api.ProductInput) ([]api.Product, error) {
+	const op = "service.reception.AddProducts"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	if err := validateReceptionKind(kind); err != nil {
+		return nil, err
+	}
+	if len(products) == 0 {
+		return nil, errs.ErrEmptyProductBatch
+	}
+	if len(products) > r.cfg.ProductBatchMaxSize {
+		return nil, errs.ErrProductBatchTooLarge
+	}
+	barcodes := make(map[string]struct{}, len(products))
+	for _, p := range products {
+		if err := validateProductInput(p, kind); err != nil {
+			return nil, err
+		}
+		if p.Barcode == nil {
+			continue
+		}
+		if _, ok := barcodes[*p.Barcode]; ok {
+			return nil, fmt.Errorf("%w: %s", errs.ErrDuplicateBarcode, *p.Barcode)
+		}
+		barcodes[*p.Barcode] = struct{}{}
+	}
+	products, err := r.resolveProductTypes(ctx, products)
+	if err != nil {
+		if errors.Is(err, errs.ErrInvalidProductType) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	recID, err := r.GetReceptionInProgress(ctx, pvzID, kind)
+	if err != nil {
+		if errors.Is(err, errs.ErrNoReceptionsInProgress) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+
+	prods, err := r.repo.AddProducts(ctx, recID, products)
+	if err != nil {
+		if errors.Is(err, errs.ErrDuplicateBarcode) || errors.Is(err, errs.ErrNoReceptionsInProgress) || isStorageCellError(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+	r.metrics.ProductsAdded(kind, len(prods))
+	return prods, nil
+}
+func (r *ReceptionService) GetReceptionInProgress(ctx context.Context, pvzID uuid.UUID, kind api.ReceptionKind) (uuid.UUID, error) {
 	const op = "service.reception.GetReceptionInProgress"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
 
-	id, err := r.repo.GetReceptionInProgress(pvzID)
+	id, err := r.repo.GetReceptionInProgress(ctx, pvzID, kind)
 	if err != nil {
 		if errors.Is(err, errs.ErrNoReceptionsInProgress) {
 			return id, err
@@ -69,29 +194,83 @@ func (r *ReceptionService) GetReceptionInProgress(pvzID uuid.UUID) (uuid.UUID, e
 	}
 	return id, nil
 }
-func (r *ReceptionService) DeleteLastProduct(pvzID uuid.UUID) error {
+func (r *ReceptionService) DeleteLastProduct(ctx context.Context, pvzID uuid.UUID, kind api.ReceptionKind) error {
 	const op = "service.reception.DeleteLastProduct"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
 
-	recID, err := r.repo.GetReceptionInProgress(pvzID)
+	if err := validateReceptionKind(kind); err != nil {
+		return err
+	}
+	recID, err := r.repo.GetReceptionInProgress(ctx, pvzID, kind)
 	if err != nil {
 		if errors.Is(err, errs.ErrNoReceptionsInProgress) {
 			return err
 		}
 		return fmt.Errorf("%s:%w", op, err)
 	}
-	err = r.repo.DeleteLastProduct(recID)
+	err = r.repo.DeleteLastProduct(ctx, recID)
 	if err != nil {
-		if errors.Is(err, errs.ErrNoProductsInReception) {
+		if errors.Is(err, errs.ErrNoProductsInReception) || errors.Is(err, errs.ErrNoReceptionsInProgress) {
 			return err
 		}
 		return fmt.Errorf("%s:%w", op, err)
 	}
+	r.metrics.ProductsDeleted(1)
 	return nil
 }
-func (r *ReceptionService) CloseLastReception(pvzID uuid.UUID) (api.Reception, error) {
-	const op = "service.reception.AddProduct"
 
-	recID, err := r.GetReceptionInProgress(pvzID)
+// DeleteProduct removes a specific product from an in progress reception, keeping it for the audit view
+func (r *ReceptionService) DeleteProduct(ctx context.Context, recID, prodID uuid.UUID, reason api.ProductDeleteReason, comment *string) (api.ProductDeletion, error) {
+	const op = "service.reception.DeleteProduct"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	if comment != nil {
+		trimmed := strings.TrimSpace(*comment)
+		comment = &trimmed
+		if trimmed == "" {
+			comment = nil
+		}
+	}
+	if err := validateDeleteReason(reason, comment); err != nil {
+		return api.ProductDeletion{}, err
+	}
+
+	del, err := r.repo.DeleteProduct(ctx, recID, prodID, reason, comment)
+	if err != nil {
+		if errors.Is(err, errs.ErrReceptionNotFound) || errors.Is(err, errs.ErrReceptionClosed) ||
+			errors.Is(err, errs.ErrProductNotFound) {
+			return api.ProductDeletion{}, err
+		}
+		return api.ProductDeletion{}, fmt.Errorf("%s:%w", op, err)
+	}
+	r.metrics.ProductsDeleted(1)
+	return del, nil
+}
+func (r *ReceptionService) GetDeletedProducts(ctx context.Context, recID uuid.UUID) ([]api.ProductDeletion, error) {
+	const op = "service.reception.GetDeletedProducts"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	dels, err := r.repo.GetDeletedProducts(ctx, recID)
+	if err != nil {
+		if errors.Is(err, errs.ErrReceptionNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+	return dels, nil
+}
+func (r *ReceptionService) CloseLastReception(ctx context.Context, pvzID uuid.UUID, kind api.ReceptionKind) (api.Reception, error) {
+	const op = "service.reception.CloseLastReception"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	if err := validateReceptionKind(kind); err != nil {
+		return api.Reception{}, err
+	}
+	recID, err := r.GetReceptionInProgress(ctx, pvzID, kind)
 	if err != nil {
 		if errors.Is(err, errs.ErrNoReceptionsInProgress) {
 			return api.Reception{}, err
@@ -102,9 +281,241 @@ func (r *ReceptionService) CloseLastReception(pvzID uuid.UUID) (api.Reception, e
 		return api.Reception{}, errs.ErrNoReceptionsInProgress
 	}
 
-	rec, err := r.repo.CloseLastReception(recID)
+	batch, mint := r.pickupCodeBatch()
+	rec, codes, err := r.repo.CloseLastReception(ctx, recID, mint)
 	if err != nil {
+		if errors.Is(err, errs.ErrNoReceptionsInProgress) {
+			return api.Reception{}, err
+		}
 		return api.Reception{}, fmt.Errorf("%s:%w", op, err)
 	}
+	r.metrics.ReceptionClosed(rec.Kind, api.CloseReasonManual)
+	sendPickupCodes(ctx, batch, codes)
 	return rec, nil
 }
+
+// pickupCodeBatch starts a batch of codes for receptions closed by one transaction, both are nil if codes aren't issued
+func (r *ReceptionService) pickupCodeBatch() (PickupCodeBatch, repository.PickupCodeMinter) {
+	if r.codes == nil {
+		return nil, nil
+	}
+	batch := r.codes.NewBatch()
+	return batch, batch.Mint
+}
+
+// sendPickupCodes sends codes stored along with closed receptions. The receptions are closed already,
+// so failures don't fail the request, the batch logs them and a moderator can regenerate such codes
+func sendPickupCodes(ctx context.Context, batch PickupCodeBatch, codes []api.PickupCode) {
+	if batch == nil || len(codes) == 0 {
+		return
+	}
+	_ = batch.Send(ctx, codes)
+}
+
+var barcodeRe = regexp.MustCompile(`^[0-9A-Za-z._-]{1,64}$`)
+
+const maxExternalOrderIDLength = 64
+
+func validateReceptionKind(kind api.ReceptionKind) error {
+	switch kind {
+	case api.ReceptionKindInbound, api.ReceptionKindCustomerReturn:
+		return nil
+	default:
+		return errs.ErrInvalidReceptionKind
+	}
+}
+
+// resolveProductTypes returns a copy of products with types replaced by catalog codes,
+// each distinct type is looked up once. Can return ErrInvalidProductType
+func (r *ReceptionService) resolveProductTypes(ctx context.Context, products []api.ProductInput) ([]api.ProductInput, error) {
+	codes := make(map[api.ProductType]api.ProductType)
+	res := make([]api.ProductInput, len(products))
+	for i, p := range products {
+		code, ok := codes[p.Type]
+		if !ok {
+			var err error
+			code, err = r.types.ResolveProductType(ctx, p.Type)
+			if err != nil {
+				return nil, err
+			}
+			codes[p.Type] = code
+		}
+		p.Type = code
+		res[i] = p
+	}
+	return res, nil
+}
+
+// validateProductInput checks the product for a reception of the given kind,
+// customer returns require the return condition and the original order, inbound products must have neither
+func validateProductInput(p api.ProductInput, kind api.ReceptionKind) error {
+	if p.Type == "" {
+		return errs.ErrInvalidProductType
+	}
+	if p.Barcode != nil && !barcodeRe.MatchString(*p.Barcode) {
+		return errs.ErrInvalidBarcode
+	}
+	if p.ExternalOrderId != nil && (*p.ExternalOrderId == "" || len(*p.ExternalOrderId) > maxExternalOrderIDLength) {
+		return errs.ErrInvalidExternalOrderID
+	}
+	if kind != api.ReceptionKindCustomerReturn {
+		if p.ReturnCondition != nil || p.OriginalOrderId != nil {
+			return errs.ErrUnexpectedReturnFields
+		}
+		return nil
+	}
+	if p.ReturnCondition == nil {
+		return errs.ErrInvalidReturnCondition
+	}
+	switch *p.ReturnCondition {
+	case api.ReturnConditionUnopened, api.ReturnConditionOpened, api.ReturnConditionDefective:
+	default:
+		return errs.ErrInvalidReturnCondition
+	}
+	if p.OriginalOrderId == nil || *p.OriginalOrderId == "" || len(*p.OriginalOrderId) > maxExternalOrderIDLength {
+		return errs.ErrInvalidOriginalOrderID
+	}
+	return nil
+}
+
+const maxDeleteCommentLength = 500
+
+// validateDeleteReason accepts reasons that can be chosen by an employee,
+// undo_last is reserved for DeleteLastProduct and other needs an explanation
+func validateDeleteReason(reason api.ProductDeleteReason, comment *string) error {
+	switch reason {
+	case api.DeleteReasonMistakenScan, api.DeleteReasonDuplicateScan, api.DeleteReasonWrongPVZ, api.DeleteReasonDamaged:
+	case api.DeleteReasonOther:
+		if comment == nil {
+			return errs.ErrDeleteCommentRequired
+		}
+	default:
+		return errs.ErrInvalidDeleteReason
+	}
+	if comment != nil && utf8.RuneCountInString(*comment) > maxDeleteCommentLength {
+		return errs.ErrDeleteCommentTooLong
+	}
+	return nil
+}
+
+const maxReopenReasonLength = 500
+
+// Reopen puts a closed reception back in progress, allowed within cfg.ReceptionReopenWindow after closing
+func (r *ReceptionService) Reopen(ctx context.Context, recID, userID uuid.UUID, reason string) (api.ReceptionReopen, error) {
+	const op = "service.reception.Reopen"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	reason = strings.TrimSpace(reason)
+	if reason == "" || utf8.RuneCountInString(reason) > maxReopenReasonLength {
+		return api.ReceptionReopen{}, errs.ErrInvalidReopenReason
+	}
+	if r.cfg.ReceptionReopenWindow <= 0 {
+		return api.ReceptionReopen{}, errs.ErrReopenWindowExpired
+	}
+
+	reopen, err := r.repo.Reopen(ctx, recID, userID, reason, r.cfg.ReceptionReopenWindow)
+	if err != nil {
+		if errors.Is(err, errs.ErrReceptionNotFound) || errors.Is(err, errs.ErrReceptionNotClosed) ||
+			errors.Is(err, errs.ErrReopenWindowExpired) || errors.Is(err, errs.ErrNewerReceptionExists) {
+			return api.ReceptionReopen{}, err
+		}
+		return api.ReceptionReopen{}, fmt.Errorf("%s:%w", op, err)
+	}
+	return reopen, nil
+}
+
+// Actions applied to stale receptions, see config.Config.StaleReceptionAction
+const (
+	StaleActionClose = "close"
+	StaleActionFlag  = "flag"
+)
+
+// SweepStaleReceptions closes or flags receptions idle for longer than cfg.StaleReceptionTimeout
+// and returns the affected ones
+func (r *ReceptionService) SweepStaleReceptions(ctx context.Context) ([]api.Reception, error) {
+	const op = "service.reception.SweepStaleReceptions"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	if r.cfg.StaleReceptionTimeout <= 0 {
+		return nil, nil
+	}
+	var (
+		recs  []api.Reception
+		codes []api.PickupCode
+		err   error
+	)
+	batch, mint := r.pickupCodeBatch()
+	switch r.cfg.StaleReceptionAction {
+	case StaleActionClose:
+		recs, codes, err = r.repo.CloseStale(ctx, r.cfg.StaleReceptionTimeout, mint)
+	case StaleActionFlag:
+		recs, err = r.repo.FlagStale(ctx, r.cfg.StaleReceptionTimeout)
+	default:
+		return nil, fmt.Errorf("%s:%w", op, errs.ErrUnknownStaleAction)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+	if r.cfg.StaleReceptionAction == StaleActionClose {
+		for _, rec := range recs {
+			r.metrics.ReceptionClosed(rec.Kind, api.CloseReasonAutoClosed)
+		}
+		sendPickupCodes(ctx, batch, codes)
+	}
+	return recs, nil
+}
+
+// ReturnsReport aggregates customer returns opened within the period by PVZ and return condition,
+// can return ErrInvalidReportPeriod
+func (r *ReceptionService) ReturnsReport(ctx context.Context, params api.GetReportsReturnsParams) ([]api.ReturnsReportRow, error) {
+	const op = "service.reception.ReturnsReport"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	if params.StartDate != nil && params.EndDate != nil && params.StartDate.After(*params.EndDate) {
+		return nil, errs.ErrInvalidReportPeriod
+	}
+	rows, err := r.repo.ReturnsReport(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+	return rows, nil
+}
+
+// List can return ErrInvalidReportPeriod
+func (r *ReceptionService) List(ctx context.Context, params api.GetReceptionsParams) ([]api.Reception, error) {
+	const op = "service.reception.List"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	if params.StartDate != nil && params.EndDate != nil && params.StartDate.After(*params.EndDate) {
+		return nil, errs.ErrInvalidReportPeriod
+	}
+	recs, err := r.repo.List(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+	return recs, nil
+}
+
+// Get returns the reception with a page of its products, can return ErrReceptionNotFound
+func (r *ReceptionService) Get(ctx context.Context, recID uuid.UUID, params api.GetReceptionsReceptionIdParams) (api.ReceptionDetails, error) {
+	const op = "service.reception.Get"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	rec, err := r.repo.GetByID(ctx, recID)
+	if err != nil {
+		if errors.Is(err, errs.ErrReceptionNotFound) {
+			return api.ReceptionDetails{}, err
+		}
+		return api.ReceptionDetails{}, fmt.Errorf("%s:%w", op, err)
+	}
+	prods, total, err := r.repo.GetProducts(ctx, recID, params)
+	if err != nil {
+		return api.ReceptionDetails{}, fmt.Errorf("%s:%w", op, err)
+	}
+	return api.ReceptionDetails{Reception: rec, Products: prods, ProductsTotal: total}, nil
+}
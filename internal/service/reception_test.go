@@ -1,49 +1,120 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/ST359/pvz-service/internal/api"
 	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/config"
+	"github.com/ST359/pvz-service/internal/repository"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
+// staticProductTypes resolves the seeded catalog types by code or legacy name
+type staticProductTypes struct{}
+
+func (staticProductTypes) ResolveProductType(ctx context.Context, name string) (api.ProductType, error) {
+	switch name {
+	case api.ProductTypeElectronics, "электроника":
+		return api.ProductTypeElectronics, nil
+	case api.ProductTypeClothes, "одежда":
+		return api.ProductTypeClothes, nil
+	case api.ProductTypeShoes, "обувь":
+		return api.ProductTypeShoes, nil
+	}
+	return "", fmt.Errorf("%w: %s", errs.ErrInvalidProductType, name)
+}
+
 type MockReceptionRepository struct {
 	mock.Mock
 }
 
-func (m *MockReceptionRepository) Create(pvzID uuid.UUID) (api.Reception, error) {
-	args := m.Called(pvzID)
+func (m *MockReceptionRepository) Create(ctx context.Context, pvzID uuid.UUID, kind api.ReceptionKind, userID uuid.UUID) (api.Reception, error) {
+	args := m.Called(pvzID, kind, userID)
 	return args.Get(0).(api.Reception), args.Error(1)
 }
 
-func (m *MockReceptionRepository) AddProduct(receptionID uuid.UUID, product api.ProductType) (api.Product, error) {
+func (m *MockReceptionRepository) List(ctx context.Context, params api.GetReceptionsParams) ([]api.Reception, error) {
+	args := m.Called(params)
+	return args.Get(0).([]api.Reception), args.Error(1)
+}
+
+func (m *MockReceptionRepository) GetByID(ctx context.Context, receptionID uuid.UUID) (api.Reception, error) {
+	args := m.Called(receptionID)
+	return args.Get(0).(api.Reception), args.Error(1)
+}
+
+func (m *MockReceptionRepository) GetProducts(ctx context.Context, receptionID uuid.UUID, params api.GetReceptionsReceptionIdParams) ([]api.Product, int, error) {
+	args := m.Called(receptionID, params)
+	return args.Get(0).([]api.Product), args.Int(1), args.Error(2)
+}
+
+func (m *MockReceptionRepository) AddProduct(ctx context.Context, receptionID uuid.UUID, product api.ProductInput) (api.Product, error) {
 	args := m.Called(receptionID, product)
 	return args.Get(0).(api.Product), args.Error(1)
 }
 
-func (m *MockReceptionRepository) GetReceptionInProgress(pvzID uuid.UUID) (uuid.UUID, error) {
-	args := m.Called(pvzID)
+func (m *MockReceptionRepository) AddProducts(ctx context.Context, receptionID uuid.UUID, products []api.ProductInput) ([]api.Product, error) {
+	args := m.Called(receptionID, products)
+	return args.Get(0).([]api.Product), args.Error(1)
+}
+
+func (m *MockReceptionRepository) GetReceptionInProgress(ctx context.Context, pvzID uuid.UUID, kind api.ReceptionKind) (uuid.UUID, error) {
+	args := m.Called(pvzID, kind)
 	return args.Get(0).(uuid.UUID), args.Error(1)
 }
 
-func (m *MockReceptionRepository) DeleteLastProduct(receptionID uuid.UUID) error {
+func (m *MockReceptionRepository) DeleteLastProduct(ctx context.Context, receptionID uuid.UUID) error {
 	args := m.Called(receptionID)
 	return args.Error(0)
 }
 
-func (m *MockReceptionRepository) CloseLastReception(receptionID uuid.UUID) (api.Reception, error) {
+func (m *MockReceptionRepository) DeleteProduct(ctx context.Context, receptionID, productID uuid.UUID, reason api.ProductDeleteReason, comment *string) (api.ProductDeletion, error) {
+	args := m.Called(receptionID, productID, reason, comment)
+	return args.Get(0).(api.ProductDeletion), args.Error(1)
+}
+
+func (m *MockReceptionRepository) GetDeletedProducts(ctx context.Context, receptionID uuid.UUID) ([]api.ProductDeletion, error) {
 	args := m.Called(receptionID)
-	return args.Get(0).(api.Reception), args.Error(1)
+	return args.Get(0).([]api.ProductDeletion), args.Error(1)
+}
+
+func (m *MockReceptionRepository) Reopen(ctx context.Context, receptionID, userID uuid.UUID, reason string, window time.Duration) (api.ReceptionReopen, error) {
+	args := m.Called(receptionID, userID, reason, window)
+	return args.Get(0).(api.ReceptionReopen), args.Error(1)
+}
+
+func (m *MockReceptionRepository) CloseStale(ctx context.Context, idleFor time.Duration, mint repository.PickupCodeMinter) ([]api.Reception, []api.PickupCode, error) {
+	args := m.Called(idleFor, mint)
+	return args.Get(0).([]api.Reception), args.Get(1).([]api.PickupCode), args.Error(2)
+}
+
+func (m *MockReceptionRepository) FlagStale(ctx context.Context, idleFor time.Duration) ([]api.Reception, error) {
+	args := m.Called(idleFor)
+	return args.Get(0).([]api.Reception), args.Error(1)
+}
+
+func (m *MockReceptionRepository) CloseLastReception(ctx context.Context, receptionID uuid.UUID, mint repository.PickupCodeMinter) (api.Reception, []api.PickupCode, error) {
+	args := m.Called(receptionID, mint)
+	return args.Get(0).(api.Reception), args.Get(1).([]api.PickupCode), args.Error(2)
+}
+
+func (m *MockReceptionRepository) ReturnsReport(ctx context.Context, params api.GetReportsReturnsParams) ([]api.ReturnsReportRow, error) {
+	args := m.Called(params)
+	return args.Get(0).([]api.ReturnsReportRow), args.Error(1)
 }
 
 func TestReceptionService_Create(t *testing.T) {
 	pvzID := uuid.New()
 	receptionID := uuid.New()
+	userID := uuid.New()
 
 	tests := []struct {
 		name        string
@@ -56,8 +127,8 @@ func TestReceptionService_Create(t *testing.T) {
 			name:  "successful creation",
 			pvzID: pvzID,
 			mockSetup: func(m *MockReceptionRepository) {
-				m.On("GetReceptionInProgress", pvzID).Return(uuid.Nil, errs.ErrNoReceptionsInProgress)
-				m.On("Create", pvzID).Return(api.Reception{
+				m.On("GetReceptionInProgress", pvzID, api.ReceptionKindInbound).Return(uuid.Nil, errs.ErrNoReceptionsInProgress)
+				m.On("Create", pvzID, api.ReceptionKindInbound, userID).Return(api.Reception{
 					Id:     &receptionID,
 					PvzId:  pvzID,
 					Status: api.InProgress,
@@ -74,7 +145,7 @@ func TestReceptionService_Create(t *testing.T) {
 			name:  "existing reception in progress",
 			pvzID: pvzID,
 			mockSetup: func(m *MockReceptionRepository) {
-				m.On("GetReceptionInProgress", pvzID).Return(receptionID, nil)
+				m.On("GetReceptionInProgress", pvzID, api.ReceptionKindInbound).Return(receptionID, nil)
 			},
 			expected:    api.Reception{},
 			expectedErr: errs.ErrReceptionNotClosed.Error(),
@@ -83,7 +154,7 @@ func TestReceptionService_Create(t *testing.T) {
 			name:  "repository error on check",
 			pvzID: pvzID,
 			mockSetup: func(m *MockReceptionRepository) {
-				m.On("GetReceptionInProgress", pvzID).Return(uuid.Nil, errors.New("db error"))
+				m.On("GetReceptionInProgress", pvzID, api.ReceptionKindInbound).Return(uuid.Nil, errors.New("db error"))
 			},
 			expected:    api.Reception{},
 			expectedErr: "service.reception.Create:service.reception.GetReceptionInProgress:db error",
@@ -92,12 +163,22 @@ func TestReceptionService_Create(t *testing.T) {
 			name:  "repository error on create",
 			pvzID: pvzID,
 			mockSetup: func(m *MockReceptionRepository) {
-				m.On("GetReceptionInProgress", pvzID).Return(uuid.Nil, errs.ErrNoReceptionsInProgress)
-				m.On("Create", pvzID).Return(api.Reception{}, errors.New("db error"))
+				m.On("GetReceptionInProgress", pvzID, api.ReceptionKindInbound).Return(uuid.Nil, errs.ErrNoReceptionsInProgress)
+				m.On("Create", pvzID, api.ReceptionKindInbound, userID).Return(api.Reception{}, errors.New("db error"))
 			},
 			expected:    api.Reception{},
 			expectedErr: "service.reception.Create:db error",
 		},
+		{
+			name:  "reception opened concurrently",
+			pvzID: pvzID,
+			mockSetup: func(m *MockReceptionRepository) {
+				m.On("GetReceptionInProgress", pvzID, api.ReceptionKindInbound).Return(uuid.Nil, errs.ErrNoReceptionsInProgress)
+				m.On("Create", pvzID, api.ReceptionKindInbound, userID).Return(api.Reception{}, errs.ErrReceptionNotClosed)
+			},
+			expected:    api.Reception{},
+			expectedErr: errs.ErrReceptionNotClosed.Error(),
+		},
 	}
 
 	for _, tt := range tests {
@@ -105,8 +186,8 @@ func TestReceptionService_Create(t *testing.T) {
 			mockRepo := new(MockReceptionRepository)
 			tt.mockSetup(mockRepo)
 
-			service := NewReceptionService(mockRepo)
-			result, err := service.Create(tt.pvzID)
+			service := NewReceptionService(mockRepo, &config.Config{}, nil, staticProductTypes{}, nil)
+			result, err := service.Create(context.Background(), tt.pvzID, api.ReceptionKindInbound, userID)
 
 			if tt.expectedErr != "" {
 				assert.Error(t, err)
@@ -125,11 +206,15 @@ func TestReceptionService_AddProduct(t *testing.T) {
 	pvzID := uuid.New()
 	receptionID := uuid.New()
 	productType := api.ProductTypeElectronics
+	product := api.ProductInput{Type: productType}
+	barcode := "4607001234567"
+	withBarcode := api.ProductInput{Type: productType, Barcode: &barcode}
+	badBarcode := "bad barcode"
 
 	tests := []struct {
 		name        string
 		pvzID       uuid.UUID
-		product     api.ProductType
+		product     api.ProductInput
 		mockSetup   func(*MockReceptionRepository)
 		expected    api.Product
 		expectedErr error
@@ -137,10 +222,10 @@ func TestReceptionService_AddProduct(t *testing.T) {
 		{
 			name:    "successful add product",
 			pvzID:   pvzID,
-			product: productType,
+			product: product,
 			mockSetup: func(m *MockReceptionRepository) {
-				m.On("GetReceptionInProgress", pvzID).Return(receptionID, nil)
-				m.On("AddProduct", receptionID, productType).Return(api.Product{
+				m.On("GetReceptionInProgress", pvzID, api.ReceptionKindInbound).Return(receptionID, nil)
+				m.On("AddProduct", receptionID, product).Return(api.Product{
 					Id:          &receptionID,
 					ReceptionId: receptionID,
 					Type:        productType,
@@ -156,9 +241,9 @@ func TestReceptionService_AddProduct(t *testing.T) {
 		{
 			name:    "no reception in progress",
 			pvzID:   pvzID,
-			product: productType,
+			product: product,
 			mockSetup: func(m *MockReceptionRepository) {
-				m.On("GetReceptionInProgress", pvzID).Return(uuid.Nil, errs.ErrNoReceptionsInProgress)
+				m.On("GetReceptionInProgress", pvzID, api.ReceptionKindInbound).Return(uuid.Nil, errs.ErrNoReceptionsInProgress)
 			},
 			expected:    api.Product{},
 			expectedErr: errs.ErrNoReceptionsInProgress,
@@ -166,14 +251,62 @@ func TestReceptionService_AddProduct(t *testing.T) {
 		{
 			name:    "repository error on add",
 			pvzID:   pvzID,
-			product: productType,
+			product: product,
 			mockSetup: func(m *MockReceptionRepository) {
-				m.On("GetReceptionInProgress", pvzID).Return(receptionID, nil)
-				m.On("AddProduct", receptionID, productType).Return(api.Product{}, errors.New("db error"))
+				m.On("GetReceptionInProgress", pvzID, api.ReceptionKindInbound).Return(receptionID, nil)
+				m.On("AddProduct", receptionID, product).Return(api.Product{}, errors.New("db error"))
 			},
 			expected:    api.Product{},
 			expectedErr: errors.New("service.reception.AddProduct:db error"),
 		},
+		{
+			name:    "barcode already scanned",
+			pvzID:   pvzID,
+			product: withBarcode,
+			mockSetup: func(m *MockReceptionRepository) {
+				m.On("GetReceptionInProgress", pvzID, api.ReceptionKindInbound).Return(receptionID, nil)
+				m.On("AddProduct", receptionID, withBarcode).Return(api.Product{}, fmt.Errorf("%w: %s", errs.ErrDuplicateBarcode, barcode))
+			},
+			expected:    api.Product{},
+			expectedErr: errors.New("product with this barcode is already scanned: 4607001234567"),
+		},
+		{
+			name:    "reception closed concurrently",
+			pvzID:   pvzID,
+			product: api.ProductInput{Type: productType},
+			mockSetup: func(m *MockReceptionRepository) {
+				m.On("GetReceptionInProgress", pvzID, api.ReceptionKindInbound).Return(receptionID, nil)
+				m.On("AddProduct", receptionID, api.ProductInput{Type: productType}).Return(api.Product{}, errs.ErrNoReceptionsInProgress)
+			},
+			expected:    api.Product{},
+			expectedErr: errs.ErrNoReceptionsInProgress,
+		},
+		{
+			name:        "invalid barcode",
+			pvzID:       pvzID,
+			product:     api.ProductInput{Type: productType, Barcode: &badBarcode},
+			mockSetup:   func(m *MockReceptionRepository) {},
+			expected:    api.Product{},
+			expectedErr: errs.ErrInvalidBarcode,
+		},
+		{
+			name:        "invalid product type",
+			pvzID:       pvzID,
+			product:     api.ProductInput{Type: "мебель"},
+			mockSetup:   func(m *MockReceptionRepository) {},
+			expected:    api.Product{},
+			expectedErr: fmt.Errorf("%w: мебель", errs.ErrInvalidProductType),
+		},
+		{
+			name:    "legacy type name is stored as code",
+			pvzID:   pvzID,
+			product: api.ProductInput{Type: "электроника"},
+			mockSetup: func(m *MockReceptionRepository) {
+				m.On("GetReceptionInProgress", pvzID, api.ReceptionKindInbound).Return(receptionID, nil)
+				m.On("AddProduct", receptionID, api.ProductInput{Type: productType}).Return(api.Product{ReceptionId: receptionID, Type: productType}, nil)
+			},
+			expected: api.Product{ReceptionId: receptionID, Type: productType},
+		},
 	}
 
 	for _, tt := range tests {
@@ -181,8 +314,8 @@ func TestReceptionService_AddProduct(t *testing.T) {
 			mockRepo := new(MockReceptionRepository)
 			tt.mockSetup(mockRepo)
 
-			service := NewReceptionService(mockRepo)
-			result, err := service.AddProduct(tt.pvzID, tt.product)
+			service := NewReceptionService(mockRepo, &config.Config{}, nil, staticProductTypes{}, nil)
+			result, err := service.AddProduct(context.Background(), tt.pvzID, api.ReceptionKindInbound, tt.product)
 
 			if tt.expectedErr != nil {
 				assert.Error(t, err)
@@ -197,6 +330,85 @@ func TestReceptionService_AddProduct(t *testing.T) {
 	}
 }
 
+func TestReceptionService_AddProduct_ReceptionKinds(t *testing.T) {
+	pvzID := uuid.New()
+	receptionID := uuid.New()
+	order := "ORD-1"
+	opened := api.ReturnConditionOpened
+	unknown := api.ReturnCondition("broken")
+	returned := api.ProductInput{Type: api.ProductTypeShoes, ReturnCondition: &opened, OriginalOrderId: &order}
+
+	tests := []struct {
+		name        string
+		kind        api.ReceptionKind
+		product     api.ProductInput
+		mockSetup   func(*MockReceptionRepository)
+		expectedErr error
+	}{
+		{
+			name:    "customer return",
+			kind:    api.ReceptionKindCustomerReturn,
+			product: returned,
+			mockSetup: func(m *MockReceptionRepository) {
+				m.On("GetReceptionInProgress", pvzID, api.ReceptionKindCustomerReturn).Return(receptionID, nil)
+				m.On("AddProduct", receptionID, returned).Return(api.Product{ReceptionId: receptionID, ReturnCondition: &opened, OriginalOrderId: &order}, nil)
+			},
+		},
+		{
+			name:        "return without condition",
+			kind:        api.ReceptionKindCustomerReturn,
+			product:     api.ProductInput{Type: api.ProductTypeShoes, OriginalOrderId: &order},
+			mockSetup:   func(m *MockReceptionRepository) {},
+			expectedErr: errs.ErrInvalidReturnCondition,
+		},
+		{
+			name:        "return with unknown condition",
+			kind:        api.ReceptionKindCustomerReturn,
+			product:     api.ProductInput{Type: api.ProductTypeShoes, ReturnCondition: &unknown, OriginalOrderId: &order},
+			mockSetup:   func(m *MockReceptionRepository) {},
+			expectedErr: errs.ErrInvalidReturnCondition,
+		},
+		{
+			name:        "return without original order",
+			kind:        api.ReceptionKindCustomerReturn,
+			product:     api.ProductInput{Type: api.ProductTypeShoes, ReturnCondition: &opened},
+			mockSetup:   func(m *MockReceptionRepository) {},
+			expectedErr: errs.ErrInvalidOriginalOrderID,
+		},
+		{
+			name:        "return fields on inbound",
+			kind:        api.ReceptionKindInbound,
+			product:     returned,
+			mockSetup:   func(m *MockReceptionRepository) {},
+			expectedErr: errs.ErrUnexpectedReturnFields,
+		},
+		{
+			name:        "unknown kind",
+			kind:        "exchange",
+			product:     api.ProductInput{Type: api.ProductTypeShoes},
+			mockSetup:   func(m *MockReceptionRepository) {},
+			expectedErr: errs.ErrInvalidReceptionKind,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockReceptionRepository)
+			tt.mockSetup(mockRepo)
+
+			service := NewReceptionService(mockRepo, &config.Config{}, nil, staticProductTypes{}, nil)
+			_, err := service.AddProduct(context.Background(), pvzID, tt.kind, tt.product)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
 func TestReceptionService_DeleteLastProduct(t *testing.T) {
 	pvzID := uuid.New()
 	receptionID := uuid.New()
@@ -211,7 +423,7 @@ func TestReceptionService_DeleteLastProduct(t *testing.T) {
 			name:  "successful delete",
 			pvzID: pvzID,
 			mockSetup: func(m *MockReceptionRepository) {
-				m.On("GetReceptionInProgress", pvzID).Return(receptionID, nil)
+				m.On("GetReceptionInProgress", pvzID, api.ReceptionKindInbound).Return(receptionID, nil)
 				m.On("DeleteLastProduct", receptionID).Return(nil)
 			},
 			expectedErr: nil,
@@ -220,7 +432,7 @@ func TestReceptionService_DeleteLastProduct(t *testing.T) {
 			name:  "no reception in progress",
 			pvzID: pvzID,
 			mockSetup: func(m *MockReceptionRepository) {
-				m.On("GetReceptionInProgress", pvzID).Return(uuid.Nil, errs.ErrNoReceptionsInProgress)
+				m.On("GetReceptionInProgress", pvzID, api.ReceptionKindInbound).Return(uuid.Nil, errs.ErrNoReceptionsInProgress)
 			},
 			expectedErr: errs.ErrNoReceptionsInProgress,
 		},
@@ -228,7 +440,7 @@ func TestReceptionService_DeleteLastProduct(t *testing.T) {
 			name:  "no products in reception",
 			pvzID: pvzID,
 			mockSetup: func(m *MockReceptionRepository) {
-				m.On("GetReceptionInProgress", pvzID).Return(receptionID, nil)
+				m.On("GetReceptionInProgress", pvzID, api.ReceptionKindInbound).Return(receptionID, nil)
 				m.On("DeleteLastProduct", receptionID).Return(errs.ErrNoProductsInReception)
 			},
 			expectedErr: errs.ErrNoProductsInReception,
@@ -237,7 +449,7 @@ func TestReceptionService_DeleteLastProduct(t *testing.T) {
 			name:  "repository error",
 			pvzID: pvzID,
 			mockSetup: func(m *MockReceptionRepository) {
-				m.On("GetReceptionInProgress", pvzID).Return(receptionID, nil)
+				m.On("GetReceptionInProgress", pvzID, api.ReceptionKindInbound).Return(receptionID, nil)
 				m.On("DeleteLastProduct", receptionID).Return(errors.New("db error"))
 			},
 			expectedErr: errors.New("service.reception.DeleteLastProduct:db error"),
@@ -249,14 +461,161 @@ func TestReceptionService_DeleteLastProduct(t *testing.T) {
 			mockRepo := new(MockReceptionRepository)
 			tt.mockSetup(mockRepo)
 
-			service := NewReceptionService(mockRepo)
-			err := service.DeleteLastProduct(tt.pvzID)
+			service := NewReceptionService(mockRepo, &config.Config{}, nil, staticProductTypes{}, nil)
+			err := service.DeleteLastProduct(context.Background(), tt.pvzID, api.ReceptionKindInbound)
+
+			if tt.expectedErr != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReceptionService_DeleteProduct(t *testing.T) {
+	receptionID := uuid.New()
+	productID := uuid.New()
+	comment := "  wrong parcel  "
+	trimmed := "wrong parcel"
+	blank := "   "
+	long := strings.Repeat("я", 501)
+
+	tests := []struct {
+		name        string
+		reason      api.ProductDeleteReason
+		comment     *string
+		mockSetup   func(*MockReceptionRepository)
+		expectedErr error
+	}{
+		{
+			name:    "successful delete with trimmed comment",
+			reason:  api.DeleteReasonMistakenScan,
+			comment: &comment,
+			mockSetup: func(m *MockReceptionRepository) {
+				m.On("DeleteProduct", receptionID, productID, api.DeleteReasonMistakenScan, &trimmed).
+					Return(api.ProductDeletion{Reason: api.DeleteReasonMistakenScan, Comment: &trimmed}, nil)
+			},
+		},
+		{
+			name:        "undo_last is reserved",
+			reason:      api.DeleteReasonUndoLast,
+			mockSetup:   func(m *MockReceptionRepository) {},
+			expectedErr: errs.ErrInvalidDeleteReason,
+		},
+		{
+			name:        "unknown reason",
+			reason:      api.ProductDeleteReason("lost"),
+			mockSetup:   func(m *MockReceptionRepository) {},
+			expectedErr: errs.ErrInvalidDeleteReason,
+		},
+		{
+			name:        "other without comment",
+			reason:      api.DeleteReasonOther,
+			comment:     &blank,
+			mockSetup:   func(m *MockReceptionRepository) {},
+			expectedErr: errs.ErrDeleteCommentRequired,
+		},
+		{
+			name:        "comment too long",
+			reason:      api.DeleteReasonDamaged,
+			comment:     &long,
+			mockSetup:   func(m *MockReceptionRepository) {},
+			expectedErr: errs.ErrDeleteCommentTooLong,
+		},
+		{
+			name:   "reception closed",
+			reason: api.DeleteReasonDuplicateScan,
+			mockSetup: func(m *MockReceptionRepository) {
+				m.On("DeleteProduct", receptionID, productID, api.DeleteReasonDuplicateScan, (*string)(nil)).
+					Return(api.ProductDeletion{}, errs.ErrReceptionClosed)
+			},
+			expectedErr: errs.ErrReceptionClosed,
+		},
+		{
+			name:   "repository error",
+			reason: api.DeleteReasonWrongPVZ,
+			mockSetup: func(m *MockReceptionRepository) {
+				m.On("DeleteProduct", receptionID, productID, api.DeleteReasonWrongPVZ, (*string)(nil)).
+					Return(api.ProductDeletion{}, errors.New("db error"))
+			},
+			expectedErr: errors.New("service.reception.DeleteProduct:db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockReceptionRepository)
+			tt.mockSetup(mockRepo)
+
+			service := NewReceptionService(mockRepo, &config.Config{}, nil, staticProductTypes{}, nil)
+			result, err := service.DeleteProduct(context.Background(), receptionID, productID, tt.reason, tt.comment)
+
+			if tt.expectedErr != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, trimmed, *result.Comment)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReceptionService_GetDeletedProducts(t *testing.T) {
+	receptionID := uuid.New()
+
+	tests := []struct {
+		name        string
+		mockSetup   func(*MockReceptionRepository)
+		expectedLen int
+		expectedErr error
+	}{
+		{
+			name: "successful get",
+			mockSetup: func(m *MockReceptionRepository) {
+				m.On("GetDeletedProducts", receptionID).
+					Return([]api.ProductDeletion{{Reason: api.DeleteReasonUndoLast}}, nil)
+			},
+			expectedLen: 1,
+		},
+		{
+			name: "reception not found",
+			mockSetup: func(m *MockReceptionRepository) {
+				m.On("GetDeletedProducts", receptionID).
+					Return([]api.ProductDeletion(nil), errs.ErrReceptionNotFound)
+			},
+			expectedErr: errs.ErrReceptionNotFound,
+		},
+		{
+			name: "repository error",
+			mockSetup: func(m *MockReceptionRepository) {
+				m.On("GetDeletedProducts", receptionID).
+					Return([]api.ProductDeletion(nil), errors.New("db error"))
+			},
+			expectedErr: errors.New("service.reception.GetDeletedProducts:db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockReceptionRepository)
+			tt.mockSetup(mockRepo)
+
+			service := NewReceptionService(mockRepo, &config.Config{}, nil, staticProductTypes{}, nil)
+			result, err := service.GetDeletedProducts(context.Background(), receptionID)
 
 			if tt.expectedErr != nil {
 				assert.Error(t, err)
 				assert.Equal(t, tt.expectedErr.Error(), err.Error())
 			} else {
 				assert.NoError(t, err)
+				assert.Len(t, result, tt.expectedLen)
 			}
 
 			mockRepo.AssertExpectations(t)
@@ -280,13 +639,13 @@ func TestReceptionService_CloseLastReception(t *testing.T) {
 			name:  "successful close",
 			pvzID: pvzID,
 			mockSetup: func(m *MockReceptionRepository) {
-				m.On("GetReceptionInProgress", pvzID).Return(receptionID, nil)
-				m.On("CloseLastReception", receptionID).Return(api.Reception{
+				m.On("GetReceptionInProgress", pvzID, api.ReceptionKindInbound).Return(receptionID, nil)
+				m.On("CloseLastReception", receptionID, mock.Anything).Return(api.Reception{
 					Id:       &receptionID,
 					PvzId:    pvzID,
 					Status:   api.Close,
 					DateTime: now,
-				}, nil)
+				}, []api.PickupCode(nil), nil)
 			},
 			expected: api.Reception{
 				Id:       &receptionID,
@@ -300,7 +659,7 @@ func TestReceptionService_CloseLastReception(t *testing.T) {
 			name:  "no reception in progress",
 			pvzID: pvzID,
 			mockSetup: func(m *MockReceptionRepository) {
-				m.On("GetReceptionInProgress", pvzID).Return(uuid.Nil, errs.ErrNoReceptionsInProgress)
+				m.On("GetReceptionInProgress", pvzID, api.ReceptionKindInbound).Return(uuid.Nil, errs.ErrNoReceptionsInProgress)
 			},
 			expected:    api.Reception{},
 			expectedErr: errs.ErrNoReceptionsInProgress,
@@ -309,11 +668,11 @@ func TestReceptionService_CloseLastReception(t *testing.T) {
 			name:  "repository error on close",
 			pvzID: pvzID,
 			mockSetup: func(m *MockReceptionRepository) {
-				m.On("GetReceptionInProgress", pvzID).Return(receptionID, nil)
-				m.On("CloseLastReception", receptionID).Return(api.Reception{}, errors.New("db error"))
+				m.On("GetReceptionInProgress", pvzID, api.ReceptionKindInbound).Return(receptionID, nil)
+				m.On("CloseLastReception", receptionID, mock.Anything).Return(api.Reception{}, []api.PickupCode(nil), errors.New("db error"))
 			},
 			expected:    api.Reception{},
-			expectedErr: errors.New("service.reception.AddProduct:db error"),
+			expectedErr: errors.New("service.reception.CloseLastReception:db error"),
 		},
 	}
 
@@ -322,8 +681,8 @@ func TestReceptionService_CloseLastReception(t *testing.T) {
 			mockRepo := new(MockReceptionRepository)
 			tt.mockSetup(mockRepo)
 
-			service := NewReceptionService(mockRepo)
-			result, err := service.CloseLastReception(tt.pvzID)
+			service := NewReceptionService(mockRepo, &config.Config{}, nil, staticProductTypes{}, nil)
+			result, err := service.CloseLastReception(context.Background(), tt.pvzID, api.ReceptionKindInbound)
 
 			if tt.expectedErr != nil {
 				assert.Error(t, err)
@@ -337,3 +696,517 @@ func TestReceptionService_CloseLastReception(t *testing.T) {
 		})
 	}
 }
+
+func TestReceptionService_AddProducts(t *testing.T) {
+	pvzID := uuid.New()
+	receptionID := uuid.New()
+	barcode := "4607001234567"
+	items := []api.ProductInput{{Type: api.ProductTypeElectronics}, {Type: api.ProductTypeShoes}}
+	products := []api.Product{
+		{ReceptionId: receptionID, Type: api.ProductTypeElectronics},
+		{ReceptionId: receptionID, Type: api.ProductTypeShoes},
+	}
+
+	tests := []struct {
+		name        string
+		items       []api.ProductInput
+		mockSetup   func(*MockReceptionRepository)
+		expected    []api.Product
+		expectedErr string
+	}{
+		{
+			name:  "successful batch",
+			items: items,
+			mockSetup: func(m *MockReceptionRepository) {
+				m.On("GetReceptionInProgress", pvzID, api.ReceptionKindInbound).Return(receptionID, nil).Once()
+				m.On("AddProducts", receptionID, items).Return(products, nil)
+			},
+			expected: products,
+		},
+		{
+			name:        "empty batch",
+			items:       []api.ProductInput{},
+			mockSetup:   func(m *MockReceptionRepository) {},
+			expectedErr: errs.ErrEmptyProductBatch.Error(),
+		},
+		{
+			name:        "batch over the limit",
+			items:       append(items, api.ProductInput{Type: api.ProductTypeClothes}),
+			mockSetup:   func(m *MockReceptionRepository) {},
+			expectedErr: errs.ErrProductBatchTooLarge.Error(),
+		},
+		{
+			name:        "unknown product type",
+			items:       []api.ProductInput{{Type: api.ProductTypeShoes}, {Type: "мебель"}},
+			mockSetup:   func(m *MockReceptionRepository) {},
+			expectedErr: "invalid product type: мебель",
+		},
+		{
+			name: "same barcode twice in batch",
+			items: []api.ProductInput{
+				{Type: api.ProductTypeShoes, Barcode: &barcode},
+				{Type: api.ProductTypeShoes, Barcode: &barcode},
+			},
+			mockSetup:   func(m *MockReceptionRepository) {},
+			expectedErr: "product with this barcode is already scanned: 4607001234567",
+		},
+		{
+			name:  "no reception in progress",
+			items: items,
+			mockSetup: func(m *MockReceptionRepository) {
+				m.On("GetReceptionInProgress", pvzID, api.ReceptionKindInbound).Return(uuid.Nil, errs.ErrNoReceptionsInProgress)
+			},
+			expectedErr: errs.ErrNoReceptionsInProgress.Error(),
+		},
+		{
+			name:  "repository error on insert",
+			items: items,
+			mockSetup: func(m *MockReceptionRepository) {
+				m.On("GetReceptionInProgress", pvzID, api.ReceptionKindInbound).Return(receptionID, nil)
+				m.On("AddProducts", receptionID, items).Return([]api.Product(nil), errors.New("db error"))
+			},
+			expectedErr: "service.reception.AddProducts:db error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockReceptionRepository)
+			tt.mockSetup(mockRepo)
+
+			service := NewReceptionService(mockRepo, &config.Config{ProductBatchMaxSize: 2}, nil, staticProductTypes{}, nil)
+			result, err := service.AddProducts(context.Background(), pvzID, api.ReceptionKindInbound, tt.items)
+
+			if tt.expectedErr != "" {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedErr, err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReceptionService_Reopen(t *testing.T) {
+	receptionID := uuid.New()
+	userID := uuid.New()
+	window := 15 * time.Minute
+
+	tests := []struct {
+		name        string
+		reason      string
+		window      time.Duration
+		mockSetup   func(*MockReceptionRepository)
+		expectedErr error
+	}{
+		{
+			name:   "successful reopen",
+			reason: " closed by mistake ",
+			window: window,
+			mockSetup: func(m *MockReceptionRepository) {
+				m.On("Reopen", receptionID, userID, "closed by mistake", window).
+					Return(api.ReceptionReopen{Reason: "closed by mistake"}, nil)
+			},
+		},
+		{
+			name:        "empty reason",
+			reason:      "   ",
+			window:      window,
+			mockSetup:   func(m *MockReceptionRepository) {},
+			expectedErr: errs.ErrInvalidReopenReason,
+		},
+		{
+			name:        "reopening disabled",
+			reason:      "closed by mistake",
+			window:      0,
+			mockSetup:   func(m *MockReceptionRepository) {},
+			expectedErr: errs.ErrReopenWindowExpired,
+		},
+		{
+			name:   "newer reception exists",
+			reason: "closed by mistake",
+			window: window,
+			mockSetup: func(m *MockReceptionRepository) {
+				m.On("Reopen", receptionID, userID, "closed by mistake", window).
+					Return(api.ReceptionReopen{}, errs.ErrNewerReceptionExists)
+			},
+			expectedErr: errs.ErrNewerReceptionExists,
+		},
+		{
+			name:   "repository error",
+			reason: "closed by mistake",
+			window: window,
+			mockSetup: func(m *MockReceptionRepository) {
+				m.On("Reopen", receptionID, userID, "closed by mistake", window).
+					Return(api.ReceptionReopen{}, errors.New("db error"))
+			},
+			expectedErr: errors.New("service.reception.Reopen:db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockReceptionRepository)
+			tt.mockSetup(mockRepo)
+
+			service := NewReceptionService(mockRepo, &config.Config{ReceptionReopenWindow: tt.window}, nil, staticProductTypes{}, nil)
+			result, err := service.Reopen(context.Background(), receptionID, userID, tt.reason)
+
+			if tt.expectedErr != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "closed by mistake", result.Reason)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReceptionService_SweepStaleReceptions(t *testing.T) {
+	timeout := 2 * time.Hour
+	recID := uuid.New()
+
+	tests := []struct {
+		name        string
+		cfg         config.Config
+		mockSetup   func(*MockReceptionRepository)
+		expectedLen int
+		expectedErr error
+	}{
+		{
+			name: "close stale receptions",
+			cfg:  config.Config{StaleReceptionTimeout: timeout, StaleReceptionAction: StaleActionClose},
+			mockSetup: func(m *MockReceptionRepository) {
+				m.On("CloseStale", timeout, mock.Anything).Return([]api.Reception{{Id: &recID, Status: api.Close}}, []api.PickupCode(nil), nil)
+			},
+			expectedLen: 1,
+		},
+		{
+			name: "flag stale receptions",
+			cfg:  config.Config{StaleReceptionTimeout: timeout, StaleReceptionAction: StaleActionFlag},
+			mockSetup: func(m *MockReceptionRepository) {
+				m.On("FlagStale", timeout).Return([]api.Reception{{Id: &recID, Status: api.InProgress}}, nil)
+			},
+			expectedLen: 1,
+		},
+		{
+			name:      "disabled",
+			cfg:       config.Config{StaleReceptionAction: StaleActionClose},
+			mockSetup: func(m *MockReceptionRepository) {},
+		},
+		{
+			name:        "unknown action",
+			cfg:         config.Config{StaleReceptionTimeout: timeout, StaleReceptionAction: "delete"},
+			mockSetup:   func(m *MockReceptionRepository) {},
+			expectedErr: errs.ErrUnknownStaleAction,
+		},
+		{
+			name: "repository error",
+			cfg:  config.Config{StaleReceptionTimeout: timeout, StaleReceptionAction: StaleActionClose},
+			mockSetup: func(m *MockReceptionRepository) {
+				m.On("CloseStale", timeout, mock.Anything).Return([]api.Reception(nil), []api.PickupCode(nil), errors.New("db error"))
+			},
+			expectedErr: errors.New("service.reception.SweepStaleReceptions:db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockReceptionRepository)
+			tt.mockSetup(mockRepo)
+
+			service := NewReceptionService(mockRepo, &tt.cfg, nil, staticProductTypes{}, nil)
+			result, err := service.SweepStaleReceptions(context.Background())
+
+			if tt.expectedErr != nil {
+				assert.Error(t, err)
+				assert.ErrorContains(t, err, tt.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, result, tt.expectedLen)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+// MockPickupCodeIssuer is a mock implementation of PickupCodeIssuer, its batches are the issuer itself
+type MockPickupCodeIssuer struct {
+	mock.Mock
+}
+
+func (m *MockPickupCodeIssuer) NewBatch() PickupCodeBatch {
+	return m
+}
+
+func (m *MockPickupCodeIssuer) Mint(targets []api.PickupCode) ([]repository.NewPickupCode, error) {
+	args := m.Called(targets)
+	return args.Get(0).([]repository.NewPickupCode), args.Error(1)
+}
+
+func (m *MockPickupCodeIssuer) Send(ctx context.Context, codes []api.PickupCode) error {
+	args := m.Called(codes)
+	return args.Error(0)
+}
+
+// mintWith makes a mocked repository call the minter it got with targets, like the close transaction does
+func mintWith(targets []api.PickupCode, mintArg int) func(mock.Arguments) {
+	return func(args mock.Arguments) {
+		mint := args.Get(mintArg).(repository.PickupCodeMinter)
+		_, _ = mint(targets)
+	}
+}
+
+func TestReceptionService_CloseLastReception_IssuesPickupCodes(t *testing.T) {
+	pvzID, recID := uuid.New(), uuid.New()
+	closed := api.Reception{Id: &recID, PvzId: pvzID, Status: api.Close}
+	targets := []api.PickupCode{{PvzId: pvzID, ReceptionId: recID, ProductIds: []uuid.UUID{uuid.New()}}}
+	codes := []api.PickupCode{{Id: uuid.New(), PvzId: pvzID, ReceptionId: recID, ProductIds: targets[0].ProductIds}}
+
+	tests := []struct {
+		name    string
+		sendErr error
+	}{
+		{
+			name: "codes sent",
+		},
+		{
+			name:    "codes not sent",
+			sendErr: errors.New("sms gateway is down"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockReceptionRepository)
+			mockRepo.On("GetReceptionInProgress", pvzID, api.ReceptionKindInbound).Return(recID, nil)
+			mockRepo.On("CloseLastReception", recID, mock.Anything).Run(mintWith(targets, 1)).Return(closed, codes, nil)
+			mockCodes := new(MockPickupCodeIssuer)
+			mockCodes.On("Mint", targets).Return([]repository.NewPickupCode{{PickupCode: targets[0], Hash: "hash"}}, nil)
+			mockCodes.On("Send", codes).Return(tt.sendErr)
+
+			service := NewReceptionService(mockRepo, &config.Config{}, mockCodes, staticProductTypes{}, nil)
+			result, err := service.CloseLastReception(context.Background(), pvzID, api.ReceptionKindInbound)
+
+			// the reception is closed with its codes stored whether they could be sent or not
+			assert.NoError(t, err)
+			assert.Equal(t, closed, result)
+			mockRepo.AssertExpectations(t)
+			mockCodes.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReceptionService_SweepStaleReceptions_IssuesPickupCodes(t *testing.T) {
+	firstID, secondID := uuid.New(), uuid.New()
+	recs := []api.Reception{{Id: &firstID, Status: api.Close}, {Id: &secondID, Status: api.Close}}
+	targets := []api.PickupCode{{ReceptionId: firstID}, {ReceptionId: secondID}}
+	codes := []api.PickupCode{{Id: uuid.New(), ReceptionId: firstID}, {Id: uuid.New(), ReceptionId: secondID}}
+	cfg := config.Config{StaleReceptionTimeout: time.Hour, StaleReceptionAction: StaleActionClose}
+
+	mockRepo := new(MockReceptionRepository)
+	mockRepo.On("CloseStale", time.Hour, mock.Anything).Run(mintWith(targets, 1)).Return(recs, codes, nil)
+	mockCodes := new(MockPickupCodeIssuer)
+	mockCodes.On("Mint", targets).Return([]repository.NewPickupCode{{PickupCode: targets[0]}, {PickupCode: targets[1]}}, nil)
+	mockCodes.On("Send", codes).Return(errors.New("sms gateway is down"))
+
+	service := NewReceptionService(mockRepo, &cfg, mockCodes, staticProductTypes{}, nil)
+	result, err := service.SweepStaleReceptions(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, recs, result)
+	mockRepo.AssertExpectations(t)
+	mockCodes.AssertExpectations(t)
+}
+
+func TestReceptionService_CloseLastReception_NoPickupCodes(t *testing.T) {
+	pvzID, recID := uuid.New(), uuid.New()
+	closed := api.Reception{Id: &recID, PvzId: pvzID, Status: api.Close, Kind: api.ReceptionKindCustomerReturn}
+
+	mockRepo := new(MockReceptionRepository)
+	mockRepo.On("GetReceptionInProgress", pvzID, api.ReceptionKindCustomerReturn).Return(recID, nil)
+	mockRepo.On("CloseLastReception", recID, mock.Anything).Return(closed, []api.PickupCode{}, nil)
+	mockCodes := new(MockPickupCodeIssuer)
+
+	service := NewReceptionService(mockRepo, &config.Config{}, mockCodes, staticProductTypes{}, nil)
+	result, err := service.CloseLastReception(context.Background(), pvzID, api.ReceptionKindCustomerReturn)
+
+	assert.NoError(t, err)
+	assert.Equal(t, closed, result)
+	mockRepo.AssertExpectations(t)
+	mockCodes.AssertNotCalled(t, "Send", mock.Anything)
+}
+
+func TestReceptionService_ReturnsReport(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	rows := []api.ReturnsReportRow{{PvzId: uuid.New(), Condition: api.ReturnConditionDefective, Receptions: 1, Products: 3}}
+
+	tests := []struct {
+		name        string
+		params      api.GetReportsReturnsParams
+		mockSetup   func(*MockReceptionRepository)
+		expectedErr error
+	}{
+		{
+			name:   "period",
+			params: api.GetReportsReturnsParams{StartDate: &start, EndDate: &end},
+			mockSetup: func(m *MockReceptionRepository) {
+				m.On("ReturnsReport", api.GetReportsReturnsParams{StartDate: &start, EndDate: &end}).Return(rows, nil)
+			},
+		},
+		{
+			name:        "start after end",
+			params:      api.GetReportsReturnsParams{StartDate: &end, EndDate: &start},
+			mockSetup:   func(m *MockReceptionRepository) {},
+			expectedErr: errs.ErrInvalidReportPeriod,
+		},
+		{
+			name:   "repository error",
+			params: api.GetReportsReturnsParams{},
+			mockSetup: func(m *MockReceptionRepository) {
+				m.On("ReturnsReport", api.GetReportsReturnsParams{}).Return([]api.ReturnsReportRow(nil), errors.New("db error"))
+			},
+			expectedErr: errors.New("service.reception.ReturnsReport:db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockReceptionRepository)
+			tt.mockSetup(mockRepo)
+
+			service := NewReceptionService(mockRepo, &config.Config{}, nil, staticProductTypes{}, nil)
+			result, err := service.ReturnsReport(context.Background(), tt.params)
+
+			if tt.expectedErr != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, rows, result)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReceptionService_List(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	recID := uuid.New()
+	recs := []api.Reception{{Id: &recID, PvzId: uuid.New(), Status: api.Close}}
+	status := api.Close
+
+	tests := []struct {
+		name        string
+		params      api.GetReceptionsParams
+		mockSetup   func(*MockReceptionRepository)
+		expectedErr error
+	}{
+		{
+			name:   "filtered",
+			params: api.GetReceptionsParams{Status: &status, StartDate: &start, EndDate: &end},
+			mockSetup: func(m *MockReceptionRepository) {
+				m.On("List", api.GetReceptionsParams{Status: &status, StartDate: &start, EndDate: &end}).Return(recs, nil)
+			},
+		},
+		{
+			name:        "start after end",
+			params:      api.GetReceptionsParams{StartDate: &end, EndDate: &start},
+			mockSetup:   func(m *MockReceptionRepository) {},
+			expectedErr: errs.ErrInvalidReportPeriod,
+		},
+		{
+			name:   "repository error",
+			params: api.GetReceptionsParams{},
+			mockSetup: func(m *MockReceptionRepository) {
+				m.On("List", api.GetReceptionsParams{}).Return([]api.Reception(nil), errors.New("db error"))
+			},
+			expectedErr: errors.New("service.reception.List:db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockReceptionRepository)
+			tt.mockSetup(mockRepo)
+
+			service := NewReceptionService(mockRepo, &config.Config{}, nil, staticProductTypes{}, nil)
+			result, err := service.List(context.Background(), tt.params)
+
+			if tt.expectedErr != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, recs, result)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReceptionService_Get(t *testing.T) {
+	recID := uuid.New()
+	rec := api.Reception{Id: &recID, PvzId: uuid.New(), Status: api.InProgress}
+	prods := []api.Product{{ReceptionId: recID, Type: api.ProductTypeShoes}}
+	params := api.GetReceptionsReceptionIdParams{}
+
+	tests := []struct {
+		name        string
+		mockSetup   func(*MockReceptionRepository)
+		expected    api.ReceptionDetails
+		expectedErr error
+	}{
+		{
+			name: "reception with products",
+			mockSetup: func(m *MockReceptionRepository) {
+				m.On("GetByID", recID).Return(rec, nil)
+				m.On("GetProducts", recID, params).Return(prods, 41, nil)
+			},
+			expected: api.ReceptionDetails{Reception: rec, Products: prods, ProductsTotal: 41},
+		},
+		{
+			name: "not found",
+			mockSetup: func(m *MockReceptionRepository) {
+				m.On("GetByID", recID).Return(api.Reception{}, errs.ErrReceptionNotFound)
+			},
+			expectedErr: errs.ErrReceptionNotFound,
+		},
+		{
+			name: "products error",
+			mockSetup: func(m *MockReceptionRepository) {
+				m.On("GetByID", recID).Return(rec, nil)
+				m.On("GetProducts", recID, params).Return([]api.Product(nil), 0, errors.New("db error"))
+			},
+			expectedErr: errors.New("service.reception.Get:db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockReceptionRepository)
+			tt.mockSetup(mockRepo)
+
+			service := NewReceptionService(mockRepo, &config.Config{}, nil, staticProductTypes{}, nil)
+			result, err := service.Get(context.Background(), recID, params)
+
+			if tt.expectedErr != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
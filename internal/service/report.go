@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+type ReportService struct {
+	repo repository.Report
+}
+
+func NewReportService(repo repository.Report) *ReportService {
+	return &ReportService{repo: repo}
+}
+
+// Volume counts products accepted within the period by day, week or month and by city, PVZ or type,
+// can return ErrInvalidReportPeriod, ErrInvalidVolumePeriod and ErrInvalidVolumeGroup
+func (r *ReportService) Volume(ctx context.Context, params api.GetReportsVolumeParams) ([]api.VolumeReportRow, error) {
+	const op = "service.report.Volume"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	if params.StartDate != nil && params.EndDate != nil && params.StartDate.After(params.EndDate.Time) {
+		return nil, errs.ErrInvalidReportPeriod
+	}
+	if params.Period != nil {
+		switch *params.Period {
+		case api.VolumePeriodDay, api.VolumePeriodWeek, api.VolumePeriodMonth:
+		default:
+			return nil, errs.ErrInvalidVolumePeriod
+		}
+	}
+	if params.GroupBy != nil {
+		for _, group := range *params.GroupBy {
+			switch group {
+			case api.VolumeGroupCity, api.VolumeGroupPVZ, api.VolumeGroupType:
+			default:
+				return nil, errs.ErrInvalidVolumeGroup
+			}
+		}
+	}
+
+	rows, err := r.repo.Volume(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+	return rows, nil
+}
+
+// Employees computes productivity of employees by PVZ over receptions they opened within the period,
+// can return ErrInvalidReportPeriod
+func (r *ReportService) Employees(ctx context.Context, params api.GetReportsEmployeesParams) ([]api.EmployeeProductivityRow, error) {
+	const op = "service.report.Employees"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	if params.StartDate != nil && params.EndDate != nil && params.StartDate.After(*params.EndDate) {
+		return nil, errs.ErrInvalidReportPeriod
+	}
+	rows, err := r.repo.Employees(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+	return rows, nil
+}
+
+// OwnProductivity computes productivity of the user by PVZ, can return ErrInvalidReportPeriod
+func (r *ReportService) OwnProductivity(ctx context.Context, userID uuid.UUID, params api.GetReportsEmployeesMeParams) ([]api.EmployeeProductivityRow, error) {
+	return r.Employees(ctx, api.GetReportsEmployeesParams{StartDate: params.StartDate, EndDate: params.EndDate, PvzId: params.PvzId, UserId: &userID})
+}
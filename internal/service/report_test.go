@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockReportRepository is a mock implementation of repository.Report
+type MockReportRepository struct {
+	mock.Mock
+}
+
+func (m *MockReportRepository) Volume(ctx context.Context, params api.GetReportsVolumeParams) ([]api.VolumeReportRow, error) {
+	args := m.Called(params)
+	return args.Get(0).([]api.VolumeReportRow), args.Error(1)
+}
+
+func (m *MockReportRepository) Employees(ctx context.Context, params api.GetReportsEmployeesParams) ([]api.EmployeeProductivityRow, error) {
+	args := m.Called(params)
+	return args.Get(0).([]api.EmployeeProductivityRow), args.Error(1)
+}
+
+func TestReportService_Volume(t *testing.T) {
+	start := openapi_types.Date{Time: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)}
+	end := openapi_types.Date{Time: time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)}
+	monthly := api.VolumePeriodMonth
+	yearly := api.VolumePeriod("year")
+	groups := []api.VolumeGroup{api.VolumeGroupCity, api.VolumeGroupType}
+	badGroups := []api.VolumeGroup{api.VolumeGroupCity, "region"}
+	rows := []api.VolumeReportRow{{Products: 3}}
+
+	tests := []struct {
+		name        string
+		params      api.GetReportsVolumeParams
+		mockSetup   func(*MockReportRepository)
+		expected    []api.VolumeReportRow
+		expectedErr error
+	}{
+		{
+			name:   "report",
+			params: api.GetReportsVolumeParams{StartDate: &start, EndDate: &end, Period: &monthly, GroupBy: &groups},
+			mockSetup: func(m *MockReportRepository) {
+				m.On("Volume", api.GetReportsVolumeParams{StartDate: &start, EndDate: &end, Period: &monthly, GroupBy: &groups}).
+					Return(rows, nil)
+			},
+			expected: rows,
+		},
+		{
+			name:        "start after end",
+			params:      api.GetReportsVolumeParams{StartDate: &end, EndDate: &start},
+			mockSetup:   func(m *MockReportRepository) {},
+			expectedErr: errs.ErrInvalidReportPeriod,
+		},
+		{
+			name:        "unknown period",
+			params:      api.GetReportsVolumeParams{Period: &yearly},
+			mockSetup:   func(m *MockReportRepository) {},
+			expectedErr: errs.ErrInvalidVolumePeriod,
+		},
+		{
+			name:        "unknown group",
+			params:      api.GetReportsVolumeParams{GroupBy: &badGroups},
+			mockSetup:   func(m *MockReportRepository) {},
+			expectedErr: errs.ErrInvalidVolumeGroup,
+		},
+		{
+			name:   "repository error",
+			params: api.GetReportsVolumeParams{},
+			mockSetup: func(m *MockReportRepository) {
+				m.On("Volume", api.GetReportsVolumeParams{}).Return([]api.VolumeReportRow(nil), errors.New("db error"))
+			},
+			expectedErr: errors.New("service.report.Volume:db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockReportRepository)
+			tt.mockSetup(mockRepo)
+			svc := NewReportService(mockRepo)
+
+			result, err := svc.Volume(context.Background(), tt.params)
+
+			if tt.expectedErr != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReportService_OwnProductivity(t *testing.T) {
+	userID := uuid.New()
+	pvzID := uuid.New()
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	rows := []api.EmployeeProductivityRow{{UserId: userID, PvzId: pvzID, Receptions: 2, ProductsScanned: 40}}
+
+	tests := []struct {
+		name        string
+		params      api.GetReportsEmployeesMeParams
+		mockSetup   func(*MockReportRepository)
+		expected    []api.EmployeeProductivityRow
+		expectedErr error
+	}{
+		{
+			name:   "only the user",
+			params: api.GetReportsEmployeesMeParams{StartDate: &start, EndDate: &end, PvzId: &pvzID},
+			mockSetup: func(m *MockReportRepository) {
+				m.On("Employees", api.GetReportsEmployeesParams{StartDate: &start, EndDate: &end, PvzId: &pvzID, UserId: &userID}).
+					Return(rows, nil)
+			},
+			expected: rows,
+		},
+		{
+			name:        "start after end",
+			params:      api.GetReportsEmployeesMeParams{StartDate: &end, EndDate: &start},
+			mockSetup:   func(m *MockReportRepository) {},
+			expectedErr: errs.ErrInvalidReportPeriod,
+		},
+		{
+			name:   "repository error",
+			params: api.GetReportsEmployeesMeParams{},
+			mockSetup: func(m *MockReportRepository) {
+				m.On("Employees", api.GetReportsEmployeesParams{UserId: &userID}).
+					Return([]api.EmployeeProductivityRow(nil), errors.New("db error"))
+			},
+			expectedErr: errors.New("service.report.Employees:db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockReportRepository)
+			tt.mockSetup(mockRepo)
+			svc := NewReportService(mockRepo)
+
+			result, err := svc.OwnProductivity(context.Background(), userID, tt.params)
+
+			if tt.expectedErr != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
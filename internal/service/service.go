@@ -1,40 +1,150 @@
 package service
 
 import (
+	"context"
+	"io"
+	"log/slog"
+
 	"github.com/ST359/pvz-service/internal/api"
+	"github.com/ST359/pvz-service/internal/blobstore"
+	"github.com/ST359/pvz-service/internal/config"
 	"github.com/ST359/pvz-service/internal/repository"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
 )
 
 type User interface {
-	CreateUser(usr api.PostRegisterJSONBody) (api.User, error)
-	Login(creds api.PostLoginJSONBody) (string, error)
-	ParseToken(tok string) (api.UserRole, error)
+	CreateUser(ctx context.Context, usr api.PostRegisterJSONBody) (api.User, error)
+	Login(ctx context.Context, creds api.PostLoginJSONBody) (string, error)
+	ParseToken(tok string) (api.UserRole, uuid.UUID, error)
 	GenerateToken(role string) (string, error)
 }
 
 type Reception interface {
-	Create(pvzID uuid.UUID) (api.Reception, error)
-	AddProduct(pvzID uuid.UUID, product api.ProductType) (api.Product, error)
-	GetReceptionInProgress(pvzID uuid.UUID) (uuid.UUID, error)
-	DeleteLastProduct(pvzID uuid.UUID) error
-	CloseLastReception(pvzID uuid.UUID) (api.Reception, error)
+	Create(ctx context.Context, pvzID uuid.UUID, kind api.ReceptionKind, userID uuid.UUID) (api.Reception, error)
+	AddProduct(ctx context.Context, pvzID uuid.UUID, kind api.ReceptionKind, product api.ProductInput) (api.Product, error)
+	AddProducts(ctx context.Context, pvzID uuid.UUID, kind api.ReceptionKind, products []api.ProductInput) ([]api.Product, error)
+	GetReceptionInProgress(ctx context.Context, pvzID uuid.UUID, kind api.ReceptionKind) (uuid.UUID, error)
+	DeleteLastProduct(ctx context.Context, pvzID uuid.UUID, kind api.ReceptionKind) error
+	DeleteProduct(ctx context.Context, recID, prodID uuid.UUID, reason api.ProductDeleteReason, comment *string) (api.ProductDeletion, error)
+	GetDeletedProducts(ctx context.Context, recID uuid.UUID) ([]api.ProductDeletion, error)
+	CloseLastReception(ctx context.Context, pvzID uuid.UUID, kind api.ReceptionKind) (api.Reception, error)
+	Reopen(ctx context.Context, recID, userID uuid.UUID, reason string) (api.ReceptionReopen, error)
+	SweepStaleReceptions(ctx context.Context) ([]api.Reception, error)
+	ReturnsReport(ctx context.Context, params api.GetReportsReturnsParams) ([]api.ReturnsReportRow, error)
+	List(ctx context.Context, params api.GetReceptionsParams) ([]api.Reception, error)
+	Get(ctx context.Context, recID uuid.UUID, params api.GetReceptionsReceptionIdParams) (api.ReceptionDetails, error)
 }
 
 type PVZ interface {
-	Create(pvz api.PVZ) (api.PVZ, error)
-	GetByDate(params api.GetPvzParams) ([]api.PVZInfo, error)
+	Create(ctx context.Context, pvz api.PVZ) (api.PVZ, error)
+	GetByDate(ctx context.Context, params api.GetPvzParams) ([]api.PVZInfo, error)
+	Import(ctx context.Context, rows []PVZImportRow, dryRun bool) (api.PVZImportReport, error)
+}
+type Product interface {
+	FindByBarcode(ctx context.Context, barcode string) ([]api.ProductLocation, error)
+	ReturnProducts(ctx context.Context, pvzID uuid.UUID, ids []uuid.UUID) ([]api.Product, error)
+	GetInventory(ctx context.Context, pvzID uuid.UUID, params api.GetPvzPvzIdInventoryParams) ([]api.Product, error)
+}
+type PickupCode interface {
+	Regenerate(ctx context.Context, pvzID uuid.UUID, ref api.PickupCodeRef) (api.PickupCode, error)
+	Verify(ctx context.Context, pvzID uuid.UUID, req api.PickupRequest) ([]api.Product, error)
+}
+type ProductType interface {
+	List(ctx context.Context, includeInactive bool) ([]api.ProductTypeInfo, error)
+	Create(ctx context.Context, pt api.ProductTypeInput) (api.ProductTypeInfo, error)
+	Update(ctx context.Context, code string, pt api.ProductTypeUpdate) (api.ProductTypeInfo, error)
+	Delete(ctx context.Context, code string) error
+	ResolveProductType(ctx context.Context, name string) (api.ProductType, error)
+}
+type Manifest interface {
+	Upload(ctx context.Context, recID uuid.UUID, items []api.ManifestItem) (api.ManifestProgress, error)
+	Progress(ctx context.Context, recID uuid.UUID) (api.ManifestProgress, error)
+	Discrepancies(ctx context.Context, recID uuid.UUID) ([]api.ReceptionDiscrepancy, error)
+}
+type StorageCell interface {
+	List(ctx context.Context, pvzID uuid.UUID) ([]api.StorageCell, error)
+	Create(ctx context.Context, pvzID uuid.UUID, cell api.StorageCellInput) (api.StorageCell, error)
+	Update(ctx context.Context, pvzID, cellID uuid.UUID, cell api.StorageCellUpdate) (api.StorageCell, error)
+	Delete(ctx context.Context, pvzID, cellID uuid.UUID) error
+	Suggest(ctx context.Context, pvzID uuid.UUID, count *int) (api.StorageCell, error)
+	Place(ctx context.Context, pvzID, cellID uuid.UUID, ids []uuid.UUID) ([]api.Product, error)
+}
+type Transfer interface {
+	Create(ctx context.Context, transfer api.TransferInput, userID uuid.UUID) (api.Transfer, error)
+	Dispatch(ctx context.Context, transferID, userID uuid.UUID) (api.Transfer, error)
+	Arrive(ctx context.Context, transferID, userID uuid.UUID) (api.Transfer, error)
+	List(ctx context.Context, params api.GetTransfersParams) ([]api.Transfer, error)
+	Get(ctx context.Context, transferID uuid.UUID) (api.Transfer, error)
+	ProductHistory(ctx context.Context, productID uuid.UUID) ([]api.Transfer, error)
 }
+type Idempotency interface {
+	Begin(ctx context.Context, scope, key, fingerprint string) (*repository.IdempotentResponse, error)
+	Complete(ctx context.Context, scope, key string, resp repository.IdempotentResponse) error
+	Release(ctx context.Context, scope, key string) error
+	DeleteExpiredKeys(ctx context.Context) (int64, error)
+}
+type Attachment interface {
+	AttachToReception(ctx context.Context, recID uuid.UUID, file AttachmentFile, userID uuid.UUID) (api.Attachment, error)
+	AttachToProduct(ctx context.Context, prodID uuid.UUID, file AttachmentFile, userID uuid.UUID) (api.Attachment, error)
+	ListByReception(ctx context.Context, recID uuid.UUID) ([]api.Attachment, error)
+	ListByProduct(ctx context.Context, prodID uuid.UUID) ([]api.Attachment, error)
+	Get(ctx context.Context, id uuid.UUID) (api.Attachment, error)
+	Open(ctx context.Context, id uuid.UUID) (api.Attachment, io.ReadCloser, error)
+	MaxSize() int64
+}
+type DamageReport interface {
+	SetCondition(ctx context.Context, prodID uuid.UUID, input api.ProductConditionInput, userID uuid.UUID) (api.Product, error)
+	List(ctx context.Context, params api.GetDamageReportsParams) ([]api.DamageReport, error)
+	Decide(ctx context.Context, reportID uuid.UUID, decision api.DamageReportDecision, userID uuid.UUID) (api.DamageReport, error)
+}
+type Report interface {
+	Volume(ctx context.Context, params api.GetReportsVolumeParams) ([]api.VolumeReportRow, error)
+	Employees(ctx context.Context, params api.GetReportsEmployeesParams) ([]api.EmployeeProductivityRow, error)
+	OwnProductivity(ctx context.Context, userID uuid.UUID, params api.GetReportsEmployeesMeParams) ([]api.EmployeeProductivityRow, error)
+}
+type Export interface {
+	Receptions(ctx context.Context, params api.GetExportsReceptionsParams, w io.Writer) error
+	Products(ctx context.Context, params api.GetExportsProductsParams, w io.Writer) error
+}
+
+// tracer starts spans of service methods, they are named after op of the method
+var tracer = otel.Tracer("github.com/ST359/pvz-service/internal/service")
+
 type Service struct {
 	User
 	PVZ
 	Reception
+	Product
+	PickupCode
+	ProductType
+	Manifest
+	StorageCell
+	Transfer
+	Idempotency
+	Attachment
+	DamageReport
+	Report
+	Export
 }
 
-func NewService(repo *repository.Repository) *Service {
+func NewService(repo *repository.Repository, cfg *config.Config, pickupCfg *config.PickupCode, sender PickupCodeSender, blobs blobstore.BlobStore, metrics MetricsRecorder, logger *slog.Logger) *Service {
+	pickupCodes := NewPickupCodeService(repo.PickupCode, pickupCfg, sender, logger)
+	productTypes := NewProductTypeService(repo.ProductType)
 	return &Service{
-		User:      NewUserService(repo.User),
-		PVZ:       NewPVZService(repo.PVZ),
-		Reception: NewReceptionService(repo.Reception),
+		User:         NewUserService(repo.User),
+		PVZ:          NewPVZService(repo.PVZ, metrics),
+		Reception:    NewReceptionService(repo.Reception, cfg, pickupCodes, productTypes, metrics),
+		Product:      NewProductService(repo.Product, cfg),
+		PickupCode:   pickupCodes,
+		ProductType:  productTypes,
+		Manifest:     NewManifestService(repo.Manifest, cfg, productTypes),
+		StorageCell:  NewStorageCellService(repo.StorageCell, cfg),
+		Transfer:     NewTransferService(repo.Transfer, cfg),
+		Idempotency:  NewIdempotencyService(repo.Idempotency, cfg),
+		Attachment:   NewAttachmentService(repo.Attachment, blobs, cfg),
+		DamageReport: NewDamageReportService(repo.DamageReport),
+		Report:       NewReportService(repo.Report),
+		Export:       NewExportService(repo.Export),
 	}
 }
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/config"
+	"github.com/ST359/pvz-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+var storageCellCodeRe = regexp.MustCompile(`^[A-Za-z0-9.-]{1,32}$`)
+
+type StorageCellService struct {
+	repo repository.StorageCell
+	cfg  *config.Config
+}
+
+func NewStorageCellService(repo repository.StorageCell, cfg *config.Config) *StorageCellService {
+	return &StorageCellService{repo: repo, cfg: cfg}
+}
+
+// List can return ErrPVZNotFound
+func (s *StorageCellService) List(ctx context.Context, pvzID uuid.UUID) ([]api.StorageCell, error) {
+	const op = "service.storage_cell.List"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	cells, err := s.repo.List(ctx, pvzID)
+	if err != nil {
+		if errors.Is(err, errs.ErrPVZNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+	return cells, nil
+}
+
+// Create can return ErrInvalidStorageCellCode, ErrInvalidStorageCellCapacity, ErrPVZNotFound and ErrStorageCellExists
+func (s *StorageCellService) Create(ctx context.Context, pvzID uuid.UUID, cell api.StorageCellInput) (api.StorageCell, error) {
+	const op = "service.storage_cell.Create"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	if !storageCellCodeRe.MatchString(cell.Code) {
+		return api.StorageCell{}, errs.ErrInvalidStorageCellCode
+	}
+	if cell.Capacity < 1 {
+		return api.StorageCell{}, errs.ErrInvalidStorageCellCapacity
+	}
+	res, err := s.repo.Create(ctx, pvzID, cell)
+	if err != nil {
+		if errors.Is(err, errs.ErrPVZNotFound) || errors.Is(err, errs.ErrStorageCellExists) {
+			return api.StorageCell{}, err
+		}
+		return api.StorageCell{}, fmt.Errorf("%s:%w", op, err)
+	}
+	return res, nil
+}
+
+// Update can return ErrInvalidStorageCellCapacity, ErrStorageCellNotFound and ErrCapacityBelowOccupied
+func (s *StorageCellService) Update(ctx context.Context, pvzID, cellID uuid.UUID, cell api.StorageCellUpdate) (api.StorageCell, error) {
+	const op = "service.storage_cell.Update"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	if cell.Capacity < 1 {
+		return api.StorageCell{}, errs.ErrInvalidStorageCellCapacity
+	}
+	res, err := s.repo.Update(ctx, pvzID, cellID, cell)
+	if err != nil {
+		if errors.Is(err, errs.ErrStorageCellNotFound) || errors.Is(err, errs.ErrCapacityBelowOccupied) {
+			return api.StorageCell{}, err
+		}
+		return api.StorageCell{}, fmt.Errorf("%s:%w", op, err)
+	}
+	return res, nil
+}
+
+// Delete can return ErrStorageCellNotFound and ErrStorageCellNotEmpty
+func (s *StorageCellService) Delete(ctx context.Context, pvzID, cellID uuid.UUID) error {
+	const op = "service.storage_cell.Delete"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	if err := s.repo.Delete(ctx, pvzID, cellID); err != nil {
+		if errors.Is(err, errs.ErrStorageCellNotFound) || errors.Is(err, errs.ErrStorageCellNotEmpty) {
+			return err
+		}
+		return fmt.Errorf("%s:%w", op, err)
+	}
+	return nil
+}
+
+// Suggest picks a cell for count products, count defaults to one. Can return ErrNoFreeStorageCell
+func (s *StorageCellService) Suggest(ctx context.Context, pvzID uuid.UUID, count *int) (api.StorageCell, error) {
+	const op = "service.storage_cell.Suggest"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	n := 1
+	if count != nil {
+		n = *count
+	}
+	if n < 1 {
+		return api.StorageCell{}, errs.ErrEmptyProductBatch
+	}
+	cell, err := s.repo.Suggest(ctx, pvzID, n)
+	if err != nil {
+		if errors.Is(err, errs.ErrNoFreeStorageCell) {
+			return api.StorageCell{}, err
+		}
+		return api.StorageCell{}, fmt.Errorf("%s:%w", op, err)
+	}
+	return cell, nil
+}
+
+// Place puts products into the cell or moves them there from other cells, either all of them or none.
+// Can return ErrEmptyProductBatch, ErrProductBatchTooLarge, ErrProductNotFound, ErrInvalidProductState
+// and storage cell errors
+func (s *StorageCellService) Place(ctx context.Context, pvzID, cellID uuid.UUID, ids []uuid.UUID) ([]api.Product, error) {
+	const op = "service.storage_cell.Place"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	unique, err := uniqueProductIDs(ids, s.cfg.ProductBatchMaxSize)
+	if err != nil {
+		return nil, err
+	}
+	prods, err := s.repo.Place(ctx, pvzID, cellID, unique)
+	if err != nil {
+		if errors.Is(err, errs.ErrProductNotFound) || errors.Is(err, errs.ErrInvalidProductState) || isStorageCellError(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+	return prods, nil
+}
+
+// isStorageCellError reports whether err means the cell can't take the products
+func isStorageCellError(err error) bool {
+	return errors.Is(err, errs.ErrStorageCellNotFound) ||
+		errors.Is(err, errs.ErrStorageCellInactive) ||
+		errors.Is(err, errs.ErrStorageCellFull)
+}
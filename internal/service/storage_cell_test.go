@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/config"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockStorageCellRepository is a mock implementation of repository.StorageCell
+type MockStorageCellRepository struct {
+	mock.Mock
+}
+
+func (m *MockStorageCellRepository) List(ctx context.Context, pvzID uuid.UUID) ([]api.StorageCell, error) {
+	args := m.Called(pvzID)
+	return args.Get(0).([]api.StorageCell), args.Error(1)
+}
+
+func (m *MockStorageCellRepository) Create(ctx context.Context, pvzID uuid.UUID, cell api.StorageCellInput) (api.StorageCell, error) {
+	args := m.Called(pvzID, cell)
+	return args.Get(0).(api.StorageCell), args.Error(1)
+}
+
+func (m *MockStorageCellRepository) Update(ctx context.Context, pvzID, cellID uuid.UUID, cell api.StorageCellUpdate) (api.StorageCell, error) {
+	args := m.Called(pvzID, cellID, cell)
+	return args.Get(0).(api.StorageCell), args.Error(1)
+}
+
+func (m *MockStorageCellRepository) Delete(ctx context.Context, pvzID, cellID uuid.UUID) error {
+	args := m.Called(pvzID, cellID)
+	return args.Error(0)
+}
+
+func (m *MockStorageCellRepository) Suggest(ctx context.Context, pvzID uuid.UUID, count int) (api.StorageCell, error) {
+	args := m.Called(pvzID, count)
+	return args.Get(0).(api.StorageCell), args.Error(1)
+}
+
+func (m *MockStorageCellRepository) Place(ctx context.Context, pvzID, cellID uuid.UUID, ids []uuid.UUID) ([]api.Product, error) {
+	args := m.Called(pvzID, cellID, ids)
+	return args.Get(0).([]api.Product), args.Error(1)
+}
+
+func TestStorageCellService_Create(t *testing.T) {
+	pvzID := uuid.New()
+
+	tests := []struct {
+		name        string
+		input       api.StorageCellInput
+		mockSetup   func(*MockStorageCellRepository)
+		expectedErr error
+	}{
+		{
+			name:  "created",
+			input: api.StorageCellInput{Code: "A-01.2", Capacity: 10},
+			mockSetup: func(m *MockStorageCellRepository) {
+				m.On("Create", pvzID, api.StorageCellInput{Code: "A-01.2", Capacity: 10}).
+					Return(api.StorageCell{Code: "A-01.2", Capacity: 10, Active: true}, nil)
+			},
+		},
+		{
+			name:        "code with spaces",
+			input:       api.StorageCellInput{Code: "A 01", Capacity: 10},
+			mockSetup:   func(m *MockStorageCellRepository) {},
+			expectedErr: errs.ErrInvalidStorageCellCode,
+		},
+		{
+			name:        "zero capacity",
+			input:       api.StorageCellInput{Code: "A-01", Capacity: 0},
+			mockSetup:   func(m *MockStorageCellRepository) {},
+			expectedErr: errs.ErrInvalidStorageCellCapacity,
+		},
+		{
+			name:  "code taken",
+			input: api.StorageCellInput{Code: "A-01", Capacity: 10},
+			mockSetup: func(m *MockStorageCellRepository) {
+				m.On("Create", pvzID, api.StorageCellInput{Code: "A-01", Capacity: 10}).
+					Return(api.StorageCell{}, errs.ErrStorageCellExists)
+			},
+			expectedErr: errs.ErrStorageCellExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockStorageCellRepository)
+			tt.mockSetup(mockRepo)
+
+			service := NewStorageCellService(mockRepo, &config.Config{})
+			_, err := service.Create(context.Background(), pvzID, tt.input)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStorageCellService_Suggest(t *testing.T) {
+	pvzID := uuid.New()
+	three := 3
+	zero := 0
+
+	tests := []struct {
+		name        string
+		count       *int
+		mockSetup   func(*MockStorageCellRepository)
+		expectedErr error
+	}{
+		{
+			name:  "defaults to one product",
+			count: nil,
+			mockSetup: func(m *MockStorageCellRepository) {
+				m.On("Suggest", pvzID, 1).Return(api.StorageCell{Code: "A-01"}, nil)
+			},
+		},
+		{
+			name:  "no free cell",
+			count: &three,
+			mockSetup: func(m *MockStorageCellRepository) {
+				m.On("Suggest", pvzID, 3).Return(api.StorageCell{}, errs.ErrNoFreeStorageCell)
+			},
+			expectedErr: errs.ErrNoFreeStorageCell,
+		},
+		{
+			name:        "zero count",
+			count:       &zero,
+			mockSetup:   func(m *MockStorageCellRepository) {},
+			expectedErr: errs.ErrEmptyProductBatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockStorageCellRepository)
+			tt.mockSetup(mockRepo)
+
+			service := NewStorageCellService(mockRepo, &config.Config{})
+			_, err := service.Suggest(context.Background(), pvzID, tt.count)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStorageCellService_Place(t *testing.T) {
+	pvzID := uuid.New()
+	cellID := uuid.New()
+	id1, id2, id3 := uuid.New(), uuid.New(), uuid.New()
+	dbErr := errors.New("connection lost")
+
+	tests := []struct {
+		name        string
+		ids         []uuid.UUID
+		mockSetup   func(*MockStorageCellRepository)
+		expectedErr error
+	}{
+		{
+			name: "duplicates collapsed",
+			ids:  []uuid.UUID{id1, id2, id1},
+			mockSetup: func(m *MockStorageCellRepository) {
+				m.On("Place", pvzID, cellID, []uuid.UUID{id1, id2}).
+					Return([]api.Product{{Id: &id1}, {Id: &id2}}, nil)
+			},
+		},
+		{
+			name:        "too many products",
+			ids:         []uuid.UUID{id1, id2, id3},
+			mockSetup:   func(m *MockStorageCellRepository) {},
+			expectedErr: errs.ErrProductBatchTooLarge,
+		},
+		{
+			name: "cell full",
+			ids:  []uuid.UUID{id1},
+			mockSetup: func(m *MockStorageCellRepository) {
+				m.On("Place", pvzID, cellID, []uuid.UUID{id1}).Return([]api.Product(nil), errs.ErrStorageCellFull)
+			},
+			expectedErr: errs.ErrStorageCellFull,
+		},
+		{
+			name: "repository failure",
+			ids:  []uuid.UUID{id1},
+			mockSetup: func(m *MockStorageCellRepository) {
+				m.On("Place", pvzID, cellID, []uuid.UUID{id1}).Return([]api.Product(nil), dbErr)
+			},
+			expectedErr: dbErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockStorageCellRepository)
+			tt.mockSetup(mockRepo)
+
+			service := NewStorageCellService(mockRepo, &config.Config{ProductBatchMaxSize: 2})
+			_, err := service.Place(context.Background(), pvzID, cellID, tt.ids)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
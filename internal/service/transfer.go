@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/config"
+	"github.com/ST359/pvz-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+type TransferService struct {
+	repo repository.Transfer
+	cfg  *config.Config
+}
+
+func NewTransferService(repo repository.Transfer, cfg *config.Config) *TransferService {
+	return &TransferService{repo: repo, cfg: cfg}
+}
+
+// Create starts a transfer of stored products between two PVZs, repeated products are included once.
+// Can return ErrSameTransferPVZ, ErrEmptyProductBatch, ErrProductBatchTooLarge, ErrPVZNotFound, ErrProductNotFound,
+// ErrProductInOpenReception, ErrInvalidProductState and ErrProductInTransfer
+func (t *TransferService) Create(ctx context.Context, transfer api.TransferInput, userID uuid.UUID) (api.Transfer, error) {
+	const op = "service.transfer.Create"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	if transfer.SourcePvzId == transfer.DestinationPvzId {
+		return api.Transfer{}, errs.ErrSameTransferPVZ
+	}
+	unique, err := uniqueProductIDs(transfer.ProductIds, t.cfg.ProductBatchMaxSize)
+	if err != nil {
+		return api.Transfer{}, err
+	}
+	transfer.ProductIds = unique
+
+	res, err := t.repo.Create(ctx, transfer, userID)
+	if err != nil {
+		if errors.Is(err, errs.ErrPVZNotFound) || errors.Is(err, errs.ErrProductInTransfer) || isProductStateError(err) {
+			return api.Transfer{}, err
+		}
+		return api.Transfer{}, fmt.Errorf("%s:%w", op, err)
+	}
+	return res, nil
+}
+
+// Dispatch sends products of the transfer from the source PVZ, can return ErrTransferNotFound,
+// ErrInvalidTransferStatus, ErrProductInOpenReception and ErrInvalidProductState
+func (t *TransferService) Dispatch(ctx context.Context, transferID, userID uuid.UUID) (api.Transfer, error) {
+	const op = "service.transfer.Dispatch"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	res, err := t.repo.Dispatch(ctx, transferID, userID)
+	if err != nil {
+		if isTransferError(err) || errors.Is(err, errs.ErrProductInOpenReception) || errors.Is(err, errs.ErrInvalidProductState) {
+			return api.Transfer{}, err
+		}
+		return api.Transfer{}, fmt.Errorf("%s:%w", op, err)
+	}
+	return res, nil
+}
+
+// Arrive receives products of the transfer at the destination PVZ, can return ErrTransferNotFound,
+// ErrInvalidTransferStatus, ErrNoReceptionsInProgress and ErrDuplicateBarcode
+func (t *TransferService) Arrive(ctx context.Context, transferID, userID uuid.UUID) (api.Transfer, error) {
+	const op = "service.transfer.Arrive"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	res, err := t.repo.Arrive(ctx, transferID, userID)
+	if err != nil {
+		if isTransferError(err) || errors.Is(err, errs.ErrNoReceptionsInProgress) || errors.Is(err, errs.ErrDuplicateBarcode) {
+			return api.Transfer{}, err
+		}
+		return api.Transfer{}, fmt.Errorf("%s:%w", op, err)
+	}
+	return res, nil
+}
+
+func (t *TransferService) List(ctx context.Context, params api.GetTransfersParams) ([]api.Transfer, error) {
+	const op = "service.transfer.List"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	res, err := t.repo.List(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+	return res, nil
+}
+
+// Get can return ErrTransferNotFound
+func (t *TransferService) Get(ctx context.Context, transferID uuid.UUID) (api.Transfer, error) {
+	const op = "service.transfer.Get"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	res, err := t.repo.GetByID(ctx, transferID)
+	if err != nil {
+		if errors.Is(err, errs.ErrTransferNotFound) {
+			return api.Transfer{}, err
+		}
+		return api.Transfer{}, fmt.Errorf("%s:%w", op, err)
+	}
+	return res, nil
+}
+
+// ProductHistory returns transfers of the product oldest first, can return ErrProductNotFound
+func (t *TransferService) ProductHistory(ctx context.Context, productID uuid.UUID) ([]api.Transfer, error) {
+	const op = "service.transfer.ProductHistory"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	res, err := t.repo.ProductHistory(ctx, productID)
+	if err != nil {
+		if errors.Is(err, errs.ErrProductNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s:%w", op, err)
+	}
+	return res, nil
+}
+
+func isTransferError(err error) bool {
+	return errors.Is(err, errs.ErrTransferNotFound) || errors.Is(err, errs.ErrInvalidTransferStatus)
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ST359/pvz-service/internal/api"
+	errs "github.com/ST359/pvz-service/internal/app_errors"
+	"github.com/ST359/pvz-service/internal/config"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTransferRepository is a mock implementation of repository.Transfer
+type MockTransferRepository struct {
+	mock.Mock
+}
+
+func (m *MockTransferRepository) Create(ctx context.Context, transfer api.TransferInput, userID uuid.UUID) (api.Transfer, error) {
+	args := m.Called(transfer, userID)
+	return args.Get(0).(api.Transfer), args.Error(1)
+}
+
+func (m *MockTransferRepository) Dispatch(ctx context.Context, transferID, userID uuid.UUID) (api.Transfer, error) {
+	args := m.Called(transferID, userID)
+	return args.Get(0).(api.Transfer), args.Error(1)
+}
+
+func (m *MockTransferRepository) Arrive(ctx context.Context, transferID, userID uuid.UUID) (api.Transfer, error) {
+	args := m.Called(transferID, userID)
+	return args.Get(0).(api.Transfer), args.Error(1)
+}
+
+func (m *MockTransferRepository) List(ctx context.Context, params api.GetTransfersParams) ([]api.Transfer, error) {
+	args := m.Called(params)
+	return args.Get(0).([]api.Transfer), args.Error(1)
+}
+
+func (m *MockTransferRepository) GetByID(ctx context.Context, transferID uuid.UUID) (api.Transfer, error) {
+	args := m.Called(transferID)
+	return args.Get(0).(api.Transfer), args.Error(1)
+}
+
+func (m *MockTransferRepository) ProductHistory(ctx context.Context, productID uuid.UUID) ([]api.Transfer, error) {
+	args := m.Called(productID)
+	return args.Get(0).([]api.Transfer), args.Error(1)
+}
+
+func TestTransferService_Create(t *testing.T) {
+	source, destination := uuid.New(), uuid.New()
+	userID := uuid.New()
+	id1, id2, id3 := uuid.New(), uuid.New(), uuid.New()
+
+	tests := []struct {
+		name        string
+		input       api.TransferInput
+		mockSetup   func(*MockTransferRepository)
+		expectedErr error
+	}{
+		{
+			name:  "duplicates collapsed",
+			input: api.TransferInput{SourcePvzId: source, DestinationPvzId: destination, ProductIds: []uuid.UUID{id1, id2, id1}},
+			mockSetup: func(m *MockTransferRepository) {
+				m.On("Create", api.TransferInput{SourcePvzId: source, DestinationPvzId: destination, ProductIds: []uuid.UUID{id1, id2}}, userID).
+					Return(api.Transfer{Status: api.TransferStatusCreated, ProductIds: []uuid.UUID{id1, id2}}, nil)
+			},
+		},
+		{
+			name:        "same pvz",
+			input:       api.TransferInput{SourcePvzId: source, DestinationPvzId: source, ProductIds: []uuid.UUID{id1}},
+			mockSetup:   func(m *MockTransferRepository) {},
+			expectedErr: errs.ErrSameTransferPVZ,
+		},
+		{
+			name:        "no products",
+			input:       api.TransferInput{SourcePvzId: source, DestinationPvzId: destination},
+			mockSetup:   func(m *MockTransferRepository) {},
+			expectedErr: errs.ErrEmptyProductBatch,
+		},
+		{
+			name:        "too many products",
+			input:       api.TransferInput{SourcePvzId: source, DestinationPvzId: destination, ProductIds: []uuid.UUID{id1, id2, id3}},
+			mockSetup:   func(m *MockTransferRepository) {},
+			expectedErr: errs.ErrProductBatchTooLarge,
+		},
+		{
+			name:  "product in another transfer",
+			input: api.TransferInput{SourcePvzId: source, DestinationPvzId: destination, ProductIds: []uuid.UUID{id1}},
+			mockSetup: func(m *MockTransferRepository) {
+				m.On("Create", api.TransferInput{SourcePvzId: source, DestinationPvzId: destination, ProductIds: []uuid.UUID{id1}}, userID).
+					Return(api.Transfer{}, errs.ErrProductInTransfer)
+			},
+			expectedErr: errs.ErrProductInTransfer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockTransferRepository)
+			tt.mockSetup(mockRepo)
+
+			service := NewTransferService(mockRepo, &config.Config{ProductBatchMaxSize: 2})
+			_, err := service.Create(context.Background(), tt.input, userID)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTransferService_Arrive(t *testing.T) {
+	transferID := uuid.New()
+	userID := uuid.New()
+	dbErr := errors.New("connection lost")
+
+	tests := []struct {
+		name        string
+		repoErr     error
+		expectedErr error
+	}{
+		{name: "arrived"},
+		{name: "not dispatched", repoErr: errs.ErrInvalidTransferStatus, expectedErr: errs.ErrInvalidTransferStatus},
+		{name: "no open reception", repoErr: errs.ErrNoReceptionsInProgress, expectedErr: errs.ErrNoReceptionsInProgress},
+		{name: "repository failure", repoErr: dbErr, expectedErr: dbErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockTransferRepository)
+			mockRepo.On("Arrive", transferID, userID).Return(api.Transfer{Status: api.TransferStatusArrived}, tt.repoErr)
+
+			service := NewTransferService(mockRepo, &config.Config{})
+			res, err := service.Arrive(context.Background(), transferID, userID)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, api.TransferStatusArrived, res.Status)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
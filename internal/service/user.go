@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -9,6 +10,7 @@ import (
 	errs "github.com/ST359/pvz-service/internal/app_errors"
 	"github.com/ST359/pvz-service/internal/repository"
 	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -19,7 +21,8 @@ const (
 
 type tokenClaims struct {
 	jwt.StandardClaims
-	UserRole string `json:"role"`
+	UserRole string    `json:"role"`
+	UserID   uuid.UUID `json:"user_id"`
 }
 type UserService struct {
 	repo repository.User
@@ -30,13 +33,15 @@ func NewUserService(repo repository.User) *UserService {
 }
 
 // CreateUser return an api.User on success
-func (u *UserService) CreateUser(usr api.PostRegisterJSONBody) (api.User, error) {
+func (u *UserService) CreateUser(ctx context.Context, usr api.PostRegisterJSONBody) (api.User, error) {
 	const op = "service.user.CreateUser"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
 
 	if len(usr.Password) >= 72 {
 		return api.User{}, errs.ErrPasswordTooLong
 	}
-	exists, err := u.repo.EmailExists(string(usr.Email))
+	exists, err := u.repo.EmailExists(ctx, string(usr.Email))
 	if err != nil {
 		return api.User{}, fmt.Errorf("%s: %w", op, err)
 	}
@@ -47,7 +52,7 @@ func (u *UserService) CreateUser(usr api.PostRegisterJSONBody) (api.User, error)
 	var createdUser api.User
 	passHash := generatePasswordHash(usr.Password)
 
-	id, err := u.repo.Create(string(usr.Email), passHash, string(usr.Role))
+	id, err := u.repo.Create(ctx, string(usr.Email), passHash, string(usr.Role))
 	if err != nil {
 		return api.User{}, fmt.Errorf("%s: %w", op, err)
 	}
@@ -58,25 +63,27 @@ func (u *UserService) CreateUser(usr api.PostRegisterJSONBody) (api.User, error)
 }
 
 // Login returns a JWT token on success
-func (u *UserService) Login(creds api.PostLoginJSONBody) (string, error) {
+func (u *UserService) Login(ctx context.Context, creds api.PostLoginJSONBody) (string, error) {
 	const op = "service.user.Login"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
 
-	passHash, role, err := u.repo.Login(string(creds.Email))
+	id, passHash, role, err := u.repo.Login(ctx, string(creds.Email))
 	if err != nil {
 		return "", err
 	}
 	if err := bcrypt.CompareHashAndPassword([]byte(passHash), []byte(creds.Password)); err != nil {
 		return "", errs.ErrWrongCreds
 	}
-	tok, err := u.GenerateToken(role)
+	tok, err := generateToken(id, role)
 	if err != nil {
 		return "", fmt.Errorf("%s: error generating jwt: %w", op, err)
 	}
 	return tok, nil
 }
 
-// ParseToken returns a role of a user on success
-func (u *UserService) ParseToken(tok string) (api.UserRole, error) {
+// ParseToken returns a role and an id of a user on success, id is uuid.Nil for dummy tokens
+func (u *UserService) ParseToken(tok string) (api.UserRole, uuid.UUID, error) {
 
 	token, err := jwt.ParseWithClaims(tok, &tokenClaims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -86,18 +93,23 @@ func (u *UserService) ParseToken(tok string) (api.UserRole, error) {
 		return []byte(secretKey), nil
 	})
 	if err != nil {
-		return "", err
+		return "", uuid.Nil, err
 	}
 
 	claims, ok := token.Claims.(*tokenClaims)
 	if !ok {
-		return "", errs.ErrWrongCreds
+		return "", uuid.Nil, errs.ErrWrongCreds
 	}
 
-	return api.UserRole(claims.UserRole), nil
+	return api.UserRole(claims.UserRole), claims.UserID, nil
 }
 
+// GenerateToken returns a token which is not bound to any registered user
 func (u *UserService) GenerateToken(role string) (string, error) {
+	return generateToken(uuid.Nil, role)
+}
+
+func generateToken(userID uuid.UUID, role string) (string, error) {
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &tokenClaims{
 		jwt.StandardClaims{
@@ -105,6 +117,7 @@ func (u *UserService) GenerateToken(role string) (string, error) {
 			IssuedAt:  time.Now().Unix(),
 		},
 		role,
+		userID,
 	})
 
 	return token.SignedString([]byte(secretKey))
@@ -1,6 +1,7 @@
 package service_test
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -21,19 +22,19 @@ type MockUserRepository struct {
 	mock.Mock
 }
 
-func (m *MockUserRepository) EmailExists(email string) (bool, error) {
+func (m *MockUserRepository) EmailExists(ctx context.Context, email string) (bool, error) {
 	args := m.Called(email)
 	return args.Bool(0), args.Error(1)
 }
 
-func (m *MockUserRepository) Create(email, passwordHash, role string) (uuid.UUID, error) {
+func (m *MockUserRepository) Create(ctx context.Context, email, passwordHash, role string) (uuid.UUID, error) {
 	args := m.Called(email, passwordHash, role)
 	return args.Get(0).(uuid.UUID), args.Error(1)
 }
 
-func (m *MockUserRepository) Login(email string) (string, string, error) {
+func (m *MockUserRepository) Login(ctx context.Context, email string) (uuid.UUID, string, string, error) {
 	args := m.Called(email)
-	return args.String(0), args.String(1), args.Error(2)
+	return args.Get(0).(uuid.UUID), args.String(1), args.String(2), args.Error(3)
 }
 
 func TestUserService_CreateUser(t *testing.T) {
@@ -148,7 +149,7 @@ func TestUserService_CreateUser(t *testing.T) {
 			tt.mockSetup(mockRepo)
 
 			userService := service.NewUserService(mockRepo)
-			result, err := userService.CreateUser(tt.input)
+			result, err := userService.CreateUser(context.Background(), tt.input)
 
 			if tt.expectedErr != nil {
 				assert.Error(t, err)
@@ -167,6 +168,9 @@ func TestUserService_CreateUser(t *testing.T) {
 }
 
 func TestUserService_Login(t *testing.T) {
+	moderatorID := uuid.New()
+	employeeID := uuid.New()
+
 	tests := []struct {
 		name        string
 		input       api.PostLoginJSONBody
@@ -182,14 +186,15 @@ func TestUserService_Login(t *testing.T) {
 			},
 			mockSetup: func(m *MockUserRepository) {
 				hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("correct_password"), bcrypt.DefaultCost)
-				m.On("Login", "moderator@example.com").Return(string(hashedPassword), "moderator", nil)
+				m.On("Login", "moderator@example.com").Return(moderatorID, string(hashedPassword), "moderator", nil)
 			},
 			expectedErr: nil,
 			checkToken: func(t *testing.T, token string) {
 				userService := service.NewUserService(nil)
-				role, err := userService.ParseToken(token)
+				role, id, err := userService.ParseToken(token)
 				assert.NoError(t, err)
 				assert.Equal(t, api.UserRoleModerator, role)
+				assert.Equal(t, moderatorID, id)
 			},
 		},
 		{
@@ -200,14 +205,15 @@ func TestUserService_Login(t *testing.T) {
 			},
 			mockSetup: func(m *MockUserRepository) {
 				hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("correct_password"), bcrypt.DefaultCost)
-				m.On("Login", "employee@example.com").Return(string(hashedPassword), "employee", nil)
+				m.On("Login", "employee@example.com").Return(employeeID, string(hashedPassword), "employee", nil)
 			},
 			expectedErr: nil,
 			checkToken: func(t *testing.T, token string) {
 				userService := service.NewUserService(nil)
-				role, err := userService.ParseToken(token)
+				role, id, err := userService.ParseToken(token)
 				assert.NoError(t, err)
 				assert.Equal(t, api.UserRoleEmployee, role)
+				assert.Equal(t, employeeID, id)
 			},
 		},
 		{
@@ -218,7 +224,7 @@ func TestUserService_Login(t *testing.T) {
 			},
 			mockSetup: func(m *MockUserRepository) {
 				hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("correct_password"), bcrypt.DefaultCost)
-				m.On("Login", "user@example.com").Return(string(hashedPassword), "employee", nil)
+				m.On("Login", "user@example.com").Return(employeeID, string(hashedPassword), "employee", nil)
 			},
 			expectedErr: errs.ErrWrongCreds,
 			checkToken:  nil,
@@ -230,7 +236,7 @@ func TestUserService_Login(t *testing.T) {
 				Password: "password123",
 			},
 			mockSetup: func(m *MockUserRepository) {
-				m.On("Login", "nonexistent@example.com").Return("", "", errs.ErrWrongCreds)
+				m.On("Login", "nonexistent@example.com").Return(uuid.Nil, "", "", errs.ErrWrongCreds)
 			},
 			expectedErr: errs.ErrWrongCreds,
 			checkToken:  nil,
@@ -242,7 +248,7 @@ func TestUserService_Login(t *testing.T) {
 				Password: "password123",
 			},
 			mockSetup: func(m *MockUserRepository) {
-				m.On("Login", "error@example.com").Return("", "", errors.New("database error"))
+				m.On("Login", "error@example.com").Return(uuid.Nil, "", "", errors.New("database error"))
 			},
 			expectedErr: errors.New("database error"),
 			checkToken:  nil,
@@ -255,7 +261,7 @@ func TestUserService_Login(t *testing.T) {
 			tt.mockSetup(mockRepo)
 
 			userService := service.NewUserService(mockRepo)
-			token, err := userService.Login(tt.input)
+			token, err := userService.Login(context.Background(), tt.input)
 
 			if tt.expectedErr != nil {
 				assert.Error(t, err)
@@ -317,7 +323,7 @@ func TestUserService_ParseToken(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			role, err := userService.ParseToken(tt.token)
+			role, _, err := userService.ParseToken(tt.token)
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -342,8 +348,9 @@ func TestUserService_GenerateToken(t *testing.T) {
 			name: "generate moderator token",
 			role: "moderator",
 			checkToken: func(t *testing.T, token string) {
-				role, err := userService.ParseToken(token)
+				role, id, err := userService.ParseToken(token)
 				assert.NoError(t, err)
+				assert.Equal(t, uuid.Nil, id)
 				assert.Equal(t, api.UserRoleModerator, role)
 			},
 		},
@@ -351,8 +358,9 @@ func TestUserService_GenerateToken(t *testing.T) {
 			name: "generate employee token",
 			role: "employee",
 			checkToken: func(t *testing.T, token string) {
-				role, err := userService.ParseToken(token)
+				role, id, err := userService.ParseToken(token)
 				assert.NoError(t, err)
+				assert.Equal(t, uuid.Nil, id)
 				assert.Equal(t, api.UserRoleEmployee, role)
 			},
 		},
@@ -360,8 +368,9 @@ func TestUserService_GenerateToken(t *testing.T) {
 			name: "empty role",
 			role: "",
 			checkToken: func(t *testing.T, token string) {
-				role, err := userService.ParseToken(token)
+				role, id, err := userService.ParseToken(token)
 				assert.NoError(t, err)
+				assert.Equal(t, uuid.Nil, id)
 				assert.Equal(t, api.UserRole(""), role)
 			},
 		},
@@ -0,0 +1,43 @@
+package spreadsheet
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// utf8BOM lets Excel detect the encoding of a CSV file, otherwise cyrillic text opens garbled
+const utf8BOM = "\ufeff"
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+// NewCSV writes a UTF-8 BOM and the header row to w
+func NewCSV(w io.Writer, header []string) (Writer, error) {
+	if _, err := io.WriteString(w, utf8BOM); err != nil {
+		return nil, err
+	}
+	cw := &csvWriter{w: csv.NewWriter(w)}
+	if err := cw.WriteRow(headerRow(header)); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+func (c *csvWriter) WriteRow(cells []any) error {
+	record := make([]string, len(cells))
+	for i, cell := range cells {
+		record[i] = cellText(cell)
+		// text cells starting like a formula are run by spreadsheet apps, so they are kept as text
+		if s, ok := cell.(string); ok && s != "" && strings.IndexByte("=+-@\t\r", s[0]) >= 0 {
+			record[i] = "'" + s
+		}
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
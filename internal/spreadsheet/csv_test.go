@@ -0,0 +1,27 @@
+package spreadsheet
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewCSV(&buf, []string{"id", "dateTime", "address", "products"})
+	require.NoError(t, err)
+
+	id := uuid.MustParse("6f1d3f5e-0d5b-4a7e-9a57-2a4f0c1d9b10")
+	moscow := time.FixedZone("MSK", 3*60*60)
+	require.NoError(t, w.WriteRow([]any{id, time.Date(2025, 3, 1, 12, 30, 0, 0, moscow), "ул. Ленина, 1", int64(3)}))
+	require.NoError(t, w.WriteRow([]any{id, nil, "=HYPERLINK(\"http://example.com\")", 0}))
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, "\ufeffid,dateTime,address,products\n"+
+		"6f1d3f5e-0d5b-4a7e-9a57-2a4f0c1d9b10,2025-03-01T09:30:00Z,\"ул. Ленина, 1\",3\n"+
+		"6f1d3f5e-0d5b-4a7e-9a57-2a4f0c1d9b10,,\"'=HYPERLINK(\"\"http://example.com\"\")\",0\n", buf.String())
+}
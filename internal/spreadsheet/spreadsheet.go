@@ -0,0 +1,42 @@
+package spreadsheet
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Writer streams rows of a single table. Cells can be nil(an empty cell), strings, integers, time.Time
+// or anything printable with fmt, times are written in UTC
+type Writer interface {
+	WriteRow(cells []any) error
+	// Close writes out buffered rows and finishes the file, the underlying writer is left open
+	Close() error
+}
+
+// cellText formats a cell for text formats, nil gives an empty string
+func cellText(cell any) string {
+	switch v := cell.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// headerRow turns column names into cells
+func headerRow(header []string) []any {
+	row := make([]any, len(header))
+	for i, name := range header {
+		row[i] = name
+	}
+	return row
+}
@@ -0,0 +1,176 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// maxXLSXRows is the row limit of an Excel sheet, longer tables continue on the next sheet
+const maxXLSXRows = 1 << 20
+
+// excelEpoch is the day 0 of Excel serial dates, it accounts for the nonexistent 29 February 1900
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+const (
+	xlsxContentTypesHead = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`
+	xlsxRootRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+	// the second cell format shows dates with time, the first one is the default
+	xlsxStyles = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy-mm-dd hh:mm:ss"/></numFmts>` +
+		`<fonts count="1"><font><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/></cellXfs>` +
+		`</styleSheet>`
+	xlsxSheetHead = xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetTail = `</sheetData></worksheet>`
+)
+
+// xlsxWriter writes sheets one after another straight into the zip archive, rows are never kept in memory.
+// Workbook parts listing the sheets are written on Close, when the number of sheets is known
+type xlsxWriter struct {
+	zw      *zip.Writer
+	name    string
+	header  []any
+	maxRows int
+
+	sheet  io.Writer
+	sheets int
+	rows   int
+	buf    bytes.Buffer
+}
+
+// NewXLSX starts a workbook with sheets called name, name 2 and so on, each one beginning with the header row
+func NewXLSX(w io.Writer, name string, header []string) (Writer, error) {
+	x := &xlsxWriter{zw: zip.NewWriter(w), name: name, header: headerRow(header), maxRows: maxXLSXRows}
+	if err := x.nextSheet(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+func (x *xlsxWriter) WriteRow(cells []any) error {
+	if x.rows == x.maxRows {
+		if err := x.nextSheet(); err != nil {
+			return err
+		}
+	}
+	return x.writeRow(cells)
+}
+
+func (x *xlsxWriter) nextSheet() error {
+	if x.sheet != nil {
+		if _, err := io.WriteString(x.sheet, xlsxSheetTail); err != nil {
+			return err
+		}
+	}
+	x.sheets++
+	x.rows = 0
+	sheet, err := x.zw.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", x.sheets))
+	if err != nil {
+		return err
+	}
+	x.sheet = sheet
+	if _, err := io.WriteString(x.sheet, xlsxSheetHead); err != nil {
+		return err
+	}
+	return x.writeRow(x.header)
+}
+
+func (x *xlsxWriter) writeRow(cells []any) error {
+	x.rows++
+	x.buf.Reset()
+	fmt.Fprintf(&x.buf, `<row r="%d">`, x.rows)
+	for i, cell := range cells {
+		ref := columnName(i) + strconv.Itoa(x.rows)
+		switch v := cell.(type) {
+		case nil:
+		case int:
+			fmt.Fprintf(&x.buf, `<c r="%s"><v>%d</v></c>`, ref, v)
+		case int64:
+			fmt.Fprintf(&x.buf, `<c r="%s"><v>%d</v></c>`, ref, v)
+		case time.Time:
+			serial := float64(v.UTC().Sub(excelEpoch)) / float64(24*time.Hour)
+			fmt.Fprintf(&x.buf, `<c r="%s" s="1"><v>%s</v></c>`, ref, strconv.FormatFloat(serial, 'f', -1, 64))
+		default:
+			fmt.Fprintf(&x.buf, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+			xml.EscapeText(&x.buf, []byte(cellText(v)))
+			x.buf.WriteString(`</t></is></c>`)
+		}
+	}
+	x.buf.WriteString(`</row>`)
+	_, err := x.sheet.Write(x.buf.Bytes())
+	return err
+}
+
+func (x *xlsxWriter) Close() error {
+	if _, err := io.WriteString(x.sheet, xlsxSheetTail); err != nil {
+		return err
+	}
+
+	var contentTypes, workbook, rels bytes.Buffer
+	contentTypes.WriteString(xlsxContentTypesHead)
+	workbook.WriteString(xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	rels.WriteString(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := 1; i <= x.sheets; i++ {
+		fmt.Fprintf(&contentTypes, `<Override PartName="/xl/worksheets/sheet%d.xml" `+
+			`ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i)
+		name := x.name
+		if i > 1 {
+			name = fmt.Sprintf("%s %d", x.name, i)
+		}
+		workbook.WriteString(`<sheet name="`)
+		xml.EscapeText(&workbook, []byte(name))
+		fmt.Fprintf(&workbook, `" sheetId="%d" r:id="rId%d"/>`, i, i)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" `+
+			`Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i, i)
+	}
+	contentTypes.WriteString(`</Types>`)
+	workbook.WriteString(`</sheets></workbook>`)
+	fmt.Fprintf(&rels, `<Relationship Id="rId%d" `+
+		`Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/></Relationships>`, x.sheets+1)
+
+	parts := []struct {
+		name string
+		body []byte
+	}{
+		{"xl/workbook.xml", workbook.Bytes()},
+		{"xl/_rels/workbook.xml.rels", rels.Bytes()},
+		{"xl/styles.xml", []byte(xlsxStyles)},
+		{"_rels/.rels", []byte(xlsxRootRels)},
+		{"[Content_Types].xml", contentTypes.Bytes()},
+	}
+	for _, part := range parts {
+		f, err := x.zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(part.body); err != nil {
+			return err
+		}
+	}
+	return x.zw.Close()
+}
+
+// columnName converts a zero based column index to A, B, ..., Z, AA and so on
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
@@ -0,0 +1,84 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readZip returns parts of the archive by name and checks each one is well-formed xml
+func readZip(t *testing.T, data []byte) map[string]string {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	parts := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		body, err := io.ReadAll(rc)
+		rc.Close()
+		require.NoError(t, err)
+
+		dec := xml.NewDecoder(bytes.NewReader(body))
+		for {
+			_, err := dec.Token()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err, f.Name)
+		}
+		parts[f.Name] = string(body)
+	}
+	return parts
+}
+
+func TestXLSXWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewXLSX(&buf, "products", []string{"type", "dateTime", "count"})
+	require.NoError(t, err)
+	require.NoError(t, w.WriteRow([]any{"обувь & <аксессуары>", time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC), int64(2)}))
+	require.NoError(t, w.WriteRow([]any{nil, nil, 0}))
+	require.NoError(t, w.Close())
+
+	parts := readZip(t, buf.Bytes())
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet1.xml"} {
+		assert.Contains(t, parts, name)
+	}
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheet, `<row r="1"><c r="A1" t="inlineStr"><is><t xml:space="preserve">type</t></is></c>`)
+	assert.Contains(t, sheet, `<c r="A2" t="inlineStr"><is><t xml:space="preserve">обувь &amp; &lt;аксессуары&gt;</t></is></c>`+
+		`<c r="B2" s="1"><v>45717.5</v></c><c r="C2"><v>2</v></c></row>`)
+	assert.Contains(t, sheet, `<row r="3"><c r="C3"><v>0</v></c></row>`)
+	assert.Contains(t, parts["xl/workbook.xml"], `<sheet name="products" sheetId="1" r:id="rId1"/>`)
+}
+
+func TestXLSXWriter_NextSheet(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewXLSX(&buf, "receptions", []string{"n"})
+	require.NoError(t, err)
+	w.(*xlsxWriter).maxRows = 3
+	for i := range 5 {
+		require.NoError(t, w.WriteRow([]any{i}))
+	}
+	require.NoError(t, w.Close())
+
+	// every sheet repeats the header and holds at most two data rows
+	parts := readZip(t, buf.Bytes())
+	assert.Contains(t, parts["xl/worksheets/sheet1.xml"], `<c r="A3"><v>1</v></c>`)
+	assert.Contains(t, parts["xl/worksheets/sheet2.xml"], `<row r="1"><c r="A1" t="inlineStr"><is><t xml:space="preserve">n</t></is></c></row>`)
+	assert.Contains(t, parts["xl/worksheets/sheet3.xml"], `<c r="A2"><v>4</v></c>`)
+	assert.NotContains(t, parts, "xl/worksheets/sheet4.xml")
+	assert.Contains(t, parts["xl/workbook.xml"], `<sheet name="receptions 3" sheetId="3" r:id="rId3"/>`)
+	assert.Contains(t, parts["xl/_rels/workbook.xml.rels"], `Id="rId4" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles"`)
+}
+
+func TestColumnName(t *testing.T) {
+	for i, name := range map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		assert.Equal(t, name, columnName(i))
+	}
+}
@@ -0,0 +1,62 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/ST359/pvz-service/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var (
+	testProvider     = sdktrace.NewTracerProvider()
+	testProviderOnce sync.Once
+)
+
+// recordSpans collects spans ended during the test, the global provider can only be replaced once
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	testProviderOnce.Do(func() { otel.SetTracerProvider(testProvider) })
+	rec := tracetest.NewSpanRecorder()
+	testProvider.RegisterSpanProcessor(rec)
+	t.Cleanup(func() { testProvider.UnregisterSpanProcessor(rec) })
+	return rec
+}
+
+func TestLogHandler(t *testing.T) {
+	recordSpans(t)
+	var buf bytes.Buffer
+	logger := slog.New(NewLogHandler(slog.NewJSONHandler(&buf, nil))).With(slog.String("op", "test"))
+
+	logger.InfoContext(context.Background(), "no span")
+	ctx, span := otel.Tracer("test").Start(context.Background(), "request")
+	logger.InfoContext(ctx, "in span")
+	span.End()
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+	var outside, inside map[string]any
+	require.NoError(t, json.Unmarshal(lines[0], &outside))
+	require.NoError(t, json.Unmarshal(lines[1], &inside))
+
+	assert.NotContains(t, outside, "trace_id")
+	assert.Equal(t, span.SpanContext().TraceID().String(), inside["trace_id"])
+	assert.Equal(t, span.SpanContext().SpanID().String(), inside["span_id"])
+	assert.Equal(t, "test", inside["op"])
+}
+
+func TestSetup(t *testing.T) {
+	shutdown, err := Setup(context.Background(), &config.Config{TracingExporter: ExporterNone})
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+
+	_, err = Setup(context.Background(), &config.Config{TracingExporter: "jaeger"})
+	assert.EqualError(t, err, `tracing.Setup: unknown exporter "jaeger", expected none, stdout or otlp`)
+}
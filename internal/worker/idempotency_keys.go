@@ -0,0 +1,53 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// ExpiredKeyCleaner is implemented by service.Idempotency
+type ExpiredKeyCleaner interface {
+	DeleteExpiredKeys(ctx context.Context) (int64, error)
+}
+
+// IdempotencyKeys periodically removes idempotency keys whose TTL has passed
+type IdempotencyKeys struct {
+	cleaner  ExpiredKeyCleaner
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+func NewIdempotencyKeys(cleaner ExpiredKeyCleaner, interval time.Duration, logger *slog.Logger) *IdempotencyKeys {
+	return &IdempotencyKeys{cleaner: cleaner, interval: interval, logger: logger}
+}
+
+// Run removes expired keys every interval until ctx is canceled
+func (w *IdempotencyKeys) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.clean(ctx)
+		}
+	}
+}
+
+func (w *IdempotencyKeys) clean(ctx context.Context) {
+	const op = "worker.idempotency_keys.clean"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	n, err := w.cleaner.DeleteExpiredKeys(ctx)
+	if err != nil {
+		w.logger.ErrorContext(ctx, "failed to delete expired idempotency keys", slog.String("op", op), slog.String("error", err.Error()))
+		return
+	}
+	if n > 0 {
+		w.logger.InfoContext(ctx, "expired idempotency keys deleted", slog.String("op", op), slog.Int64("count", n))
+	}
+}
@@ -0,0 +1,77 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCleaner is a mock implementation of ExpiredKeyCleaner
+type MockCleaner struct {
+	mock.Mock
+	mu    sync.Mutex
+	calls int
+}
+
+func (m *MockCleaner) DeleteExpiredKeys(ctx context.Context) (int64, error) {
+	m.mu.Lock()
+	m.calls++
+	m.mu.Unlock()
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockCleaner) Calls() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls
+}
+
+func TestIdempotencyKeys_Run(t *testing.T) {
+	tests := []struct {
+		name      string
+		mockSetup func(*MockCleaner)
+	}{
+		{
+			name: "cleans on every tick",
+			mockSetup: func(m *MockCleaner) {
+				m.On("DeleteExpiredKeys").Return(int64(3), nil)
+			},
+		},
+		{
+			name: "keeps running after an error",
+			mockSetup: func(m *MockCleaner) {
+				m.On("DeleteExpiredKeys").Return(int64(0), errors.New("db error"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cleaner := new(MockCleaner)
+			tt.mockSetup(cleaner)
+
+			w := NewIdempotencyKeys(cleaner, time.Millisecond, slog.Default())
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			go func() {
+				w.Run(ctx)
+				close(done)
+			}()
+
+			assert.Eventually(t, func() bool { return cleaner.Calls() >= 2 }, time.Second, time.Millisecond)
+			cancel()
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("worker did not stop after context cancel")
+			}
+		})
+	}
+}
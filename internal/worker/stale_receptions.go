@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/ST359/pvz-service/internal/api"
+	"go.opentelemetry.io/otel"
+)
+
+// StaleReceptionSweeper is implemented by service.Reception
+type StaleReceptionSweeper interface {
+	SweepStaleReceptions(ctx context.Context) ([]api.Reception, error)
+}
+
+// tracer starts a root span for every run of a worker, spans of services and queries it makes are its children
+var tracer = otel.Tracer("github.com/ST359/pvz-service/internal/worker")
+
+// StaleReceptions periodically closes or flags receptions nobody works with anymore
+type StaleReceptions struct {
+	sweeper  StaleReceptionSweeper
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+func NewStaleReceptions(sweeper StaleReceptionSweeper, interval time.Duration, logger *slog.Logger) *StaleReceptions {
+	return &StaleReceptions{sweeper: sweeper, interval: interval, logger: logger}
+}
+
+// Run sweeps stale receptions right away and then every interval until ctx is canceled,
+// so restarts don't postpone closing of stale receptions
+func (w *StaleReceptions) Run(ctx context.Context) {
+	w.sweep(ctx)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.sweep(ctx)
+		}
+	}
+}
+
+func (w *StaleReceptions) sweep(ctx context.Context) {
+	const op = "worker.stale_receptions.sweep"
+	ctx, span := tracer.Start(ctx, op)
+	defer span.End()
+
+	recs, err := w.sweeper.SweepStaleReceptions(ctx)
+	if err != nil {
+		w.logger.ErrorContext(ctx, "failed to sweep stale receptions", slog.String("op", op), slog.String("error", err.Error()))
+		return
+	}
+	for _, rec := range recs {
+		w.logger.InfoContext(ctx, "stale reception swept", slog.String("op", op),
+			slog.String("reception_id", rec.Id.String()), slog.String("pvz_id", rec.PvzId.String()),
+			slog.String("status", string(rec.Status)))
+	}
+}
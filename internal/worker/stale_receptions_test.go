@@ -0,0 +1,103 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ST359/pvz-service/internal/api"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockSweeper is a mock implementation of StaleReceptionSweeper
+type MockSweeper struct {
+	mock.Mock
+	mu    sync.Mutex
+	calls int
+}
+
+func (m *MockSweeper) SweepStaleReceptions(ctx context.Context) ([]api.Reception, error) {
+	m.mu.Lock()
+	m.calls++
+	m.mu.Unlock()
+	args := m.Called()
+	return args.Get(0).([]api.Reception), args.Error(1)
+}
+
+func (m *MockSweeper) Calls() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls
+}
+
+func TestStaleReceptions_Run(t *testing.T) {
+	recID := uuid.New()
+
+	tests := []struct {
+		name      string
+		mockSetup func(*MockSweeper)
+	}{
+		{
+			name: "sweeps on every tick",
+			mockSetup: func(m *MockSweeper) {
+				m.On("SweepStaleReceptions").
+					Return([]api.Reception{{Id: &recID, PvzId: uuid.New(), Status: api.Close}}, nil)
+			},
+		},
+		{
+			name: "keeps running after an error",
+			mockSetup: func(m *MockSweeper) {
+				m.On("SweepStaleReceptions").Return([]api.Reception(nil), errors.New("db error"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sweeper := new(MockSweeper)
+			tt.mockSetup(sweeper)
+
+			w := NewStaleReceptions(sweeper, time.Millisecond, slog.Default())
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			go func() {
+				w.Run(ctx)
+				close(done)
+			}()
+
+			assert.Eventually(t, func() bool { return sweeper.Calls() >= 2 }, time.Second, time.Millisecond)
+			cancel()
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("worker did not stop after context cancel")
+			}
+		})
+	}
+}
+
+func TestStaleReceptions_Run_SweepsOnStart(t *testing.T) {
+	sweeper := new(MockSweeper)
+	sweeper.On("SweepStaleReceptions").Return([]api.Reception{}, nil)
+
+	w := NewStaleReceptions(sweeper, time.Hour, slog.Default())
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool { return sweeper.Calls() == 1 }, time.Second, time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("worker did not stop after context cancel")
+	}
+}
@@ -0,0 +1,76 @@
+DELETE FROM products WHERE deleted_at IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION get_pvz_with_receptions_paginated(
+    start_date TIMESTAMP DEFAULT NULL,
+    end_date TIMESTAMP DEFAULT NULL,
+    page_limit INT DEFAULT 10,
+    page_offset INT DEFAULT 0
+) RETURNS TABLE (
+    pvz_id UUID,
+    city TEXT,
+    address TEXT,
+    registration_date TIMESTAMPTZ,
+    receptions JSON
+) AS $$
+BEGIN
+    RETURN QUERY
+    WITH filtered_pvzs AS (
+        SELECT p.id, p.city, p.address, p.registration_date
+        FROM pvzs p
+        ORDER BY p.registration_date DESC
+        LIMIT page_limit
+        OFFSET page_offset
+    )
+    SELECT 
+        p.id AS pvz_id,
+        p.city,
+        p.address,
+        p.registration_date,
+        CASE 
+            WHEN COUNT(r.id) = 0 THEN NULL
+            ELSE (
+                SELECT json_agg(
+                    json_build_object(
+                        'reception', json_build_object(
+                            'dateTime', r.date,
+                            'id', r.id,
+                            'pvzId', r.pvz_id,
+                            'status', r.status
+                        ),
+                        'products', (
+                            SELECT COALESCE(
+                                json_agg(
+                                    json_build_object(
+                                        'dateTime', pr.date,
+                                        'id', pr.id,
+                                        'receptionId', pr.reception_id,
+                                        'type', pr.type,
+                                        'barcode', pr.barcode,
+                                        'externalOrderId', pr.external_order_id
+                                    )
+                                ),
+                                '[]'::json
+                            )
+                            FROM products pr
+                            WHERE pr.reception_id = r.id
+                        )
+                    )
+                )
+                FROM receptions r
+                WHERE r.pvz_id = p.id
+                AND (start_date IS NULL OR r.date >= start_date)
+                AND (end_date IS NULL OR r.date <= end_date)
+            )
+        END AS receptions
+    FROM filtered_pvzs p
+    LEFT JOIN receptions r ON r.pvz_id = p.id
+        AND (start_date IS NULL OR r.date >= start_date)
+        AND (end_date IS NULL OR r.date <= end_date)
+    GROUP BY p.id, p.city, p.address, p.registration_date;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_delete_reason_required;
+ALTER TABLE products DROP COLUMN IF EXISTS delete_comment;
+ALTER TABLE products DROP COLUMN IF EXISTS delete_reason;
+ALTER TABLE products DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS reception_reopens;
+
+ALTER TABLE receptions DROP COLUMN IF EXISTS closed_at;
@@ -0,0 +1,12 @@
+ALTER TABLE receptions ADD COLUMN IF NOT EXISTS closed_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS reception_reopens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    reception_id UUID NOT NULL REFERENCES receptions(id) ON DELETE CASCADE,
+    reopened_by UUID,
+    reason TEXT NOT NULL,
+    previous_closed_at TIMESTAMPTZ NOT NULL,
+    reopened_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_reception_reopens_reception_id ON reception_reopens (reception_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_receptions_in_progress_date;
+
+ALTER TABLE receptions DROP COLUMN IF EXISTS stale_flagged_at;
+ALTER TABLE receptions DROP COLUMN IF EXISTS close_reason;
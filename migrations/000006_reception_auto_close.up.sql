@@ -0,0 +1,7 @@
+ALTER TABLE receptions ADD COLUMN IF NOT EXISTS close_reason VARCHAR(20)
+    CHECK (close_reason IN ('manual', 'auto_closed'));
+ALTER TABLE receptions ADD COLUMN IF NOT EXISTS stale_flagged_at TIMESTAMPTZ;
+
+UPDATE receptions SET close_reason = 'manual' WHERE status = 'close';
+
+CREATE INDEX IF NOT EXISTS idx_receptions_in_progress_date ON receptions (date) WHERE status = 'in_progress';
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_receptions_one_in_progress;
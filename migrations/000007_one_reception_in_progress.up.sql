@@ -0,0 +1,12 @@
+-- older duplicates could have been opened by concurrent requests, only the newest one stays in progress
+UPDATE receptions r
+SET status = 'close', closed_at = now(), close_reason = 'auto_closed'
+WHERE r.status = 'in_progress'
+  AND EXISTS (
+      SELECT 1 FROM receptions n
+      WHERE n.pvz_id = r.pvz_id
+        AND n.status = 'in_progress'
+        AND (n.date, n.id) > (r.date, r.id)
+  );
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_receptions_one_in_progress ON receptions (pvz_id) WHERE status = 'in_progress';
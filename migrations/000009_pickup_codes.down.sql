@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_products_pickup_code_id;
+ALTER TABLE products DROP COLUMN IF EXISTS pickup_code_id;
+
+DROP TABLE IF EXISTS pickup_codes;
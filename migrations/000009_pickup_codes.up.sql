@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS pickup_codes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    pvz_id UUID NOT NULL REFERENCES pvzs(id) ON DELETE CASCADE,
+    reception_id UUID NOT NULL REFERENCES receptions(id) ON DELETE CASCADE,
+    external_order_id TEXT,
+    code_hash TEXT NOT NULL,
+    failed_attempts INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_pickup_codes_active_order ON pickup_codes (pvz_id, external_order_id)
+    WHERE used_at IS NULL AND revoked_at IS NULL;
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS pickup_code_id UUID REFERENCES pickup_codes(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_products_pickup_code_id ON products (pickup_code_id);
@@ -0,0 +1,92 @@
+DROP INDEX IF EXISTS idx_receptions_returns_date;
+
+-- only the newest reception of a PVZ stays in progress
+UPDATE receptions r
+SET status = 'close', closed_at = now(), close_reason = 'auto_closed'
+WHERE r.status = 'in_progress'
+  AND EXISTS (
+      SELECT 1 FROM receptions n
+      WHERE n.pvz_id = r.pvz_id
+        AND n.status = 'in_progress'
+        AND (n.date, n.id) > (r.date, r.id)
+  );
+
+DROP INDEX IF EXISTS idx_receptions_one_in_progress_per_kind;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_receptions_one_in_progress ON receptions (pvz_id) WHERE status = 'in_progress';
+
+CREATE OR REPLACE FUNCTION get_pvz_with_receptions_paginated(
+    start_date TIMESTAMP DEFAULT NULL,
+    end_date TIMESTAMP DEFAULT NULL,
+    page_limit INT DEFAULT 10,
+    page_offset INT DEFAULT 0
+) RETURNS TABLE (
+    pvz_id UUID,
+    city TEXT,
+    address TEXT,
+    registration_date TIMESTAMPTZ,
+    receptions JSON
+) AS $$
+BEGIN
+    RETURN QUERY
+    WITH filtered_pvzs AS (
+        SELECT p.id, p.city, p.address, p.registration_date
+        FROM pvzs p
+        ORDER BY p.registration_date DESC
+        LIMIT page_limit
+        OFFSET page_offset
+    )
+    SELECT 
+        p.id AS pvz_id,
+        p.city,
+        p.address,
+        p.registration_date,
+        CASE 
+            WHEN COUNT(r.id) = 0 THEN NULL
+            ELSE (
+                SELECT json_agg(
+                    json_build_object(
+                        'reception', json_build_object(
+                            'dateTime', r.date,
+                            'id', r.id,
+                            'pvzId', r.pvz_id,
+                            'status', r.status
+                        ),
+                        'products', (
+                            SELECT COALESCE(
+                                json_agg(
+                                    json_build_object(
+                                        'dateTime', pr.date,
+                                        'id', pr.id,
+                                        'receptionId', pr.reception_id,
+                                        'type', pr.type,
+                                        'barcode', pr.barcode,
+                                        'externalOrderId', pr.external_order_id,
+                                        'state', pr.state,
+                                        'stateChangedAt', pr.state_changed_at
+                                    )
+                                ),
+                                '[]'::json
+                            )
+                            FROM products pr
+                            WHERE pr.reception_id = r.id
+                            AND pr.deleted_at IS NULL
+                        )
+                    )
+                )
+                FROM receptions r
+                WHERE r.pvz_id = p.id
+                AND (start_date IS NULL OR r.date >= start_date)
+                AND (end_date IS NULL OR r.date <= end_date)
+            )
+        END AS receptions
+    FROM filtered_pvzs p
+    LEFT JOIN receptions r ON r.pvz_id = p.id
+        AND (start_date IS NULL OR r.date >= start_date)
+        AND (end_date IS NULL OR r.date <= end_date)
+    GROUP BY p.id, p.city, p.address, p.registration_date;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE products DROP COLUMN IF EXISTS original_order_id;
+ALTER TABLE products DROP COLUMN IF EXISTS return_condition;
+ALTER TABLE receptions DROP COLUMN IF EXISTS kind;
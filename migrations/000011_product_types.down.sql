@@ -0,0 +1,8 @@
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_type_fkey;
+UPDATE products p SET type = pt.legacy_name
+FROM product_types pt
+WHERE pt.code = p.type AND pt.legacy_name IS NOT NULL;
+-- products of types added through the catalog have no legacy name, so the old check is not validated against them
+ALTER TABLE products ADD CONSTRAINT products_type_check CHECK (type IN ('электроника', 'одежда', 'обувь')) NOT VALID;
+
+DROP TABLE IF EXISTS product_types;
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS product_types (
+    code TEXT PRIMARY KEY CHECK (code ~ '^[a-z][a-z0-9_]{0,31}$'),
+    names JSONB NOT NULL CHECK (jsonb_typeof(names) = 'object'),
+    legacy_name TEXT UNIQUE,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    requires_signature BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO product_types (code, names, legacy_name) VALUES
+    ('electronics', '{"ru": "Электроника", "en": "Electronics"}', 'электроника'),
+    ('clothes', '{"ru": "Одежда", "en": "Clothes"}', 'одежда'),
+    ('shoes', '{"ru": "Обувь", "en": "Shoes"}', 'обувь')
+ON CONFLICT (code) DO NOTHING;
+
+-- products keep the stable code of their type instead of the russian name
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_type_check;
+UPDATE products p SET type = pt.code
+FROM product_types pt
+WHERE pt.legacy_name = p.type;
+ALTER TABLE products ADD CONSTRAINT products_type_fkey FOREIGN KEY (type) REFERENCES product_types (code);
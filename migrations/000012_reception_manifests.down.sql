@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS reception_discrepancies;
+DROP TABLE IF EXISTS reception_manifest_items;
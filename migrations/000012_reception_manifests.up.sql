@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS reception_manifest_items (
+    reception_id UUID NOT NULL REFERENCES receptions(id) ON DELETE CASCADE,
+    barcode TEXT NOT NULL,
+    type TEXT NOT NULL REFERENCES product_types(code),
+    PRIMARY KEY (reception_id, barcode)
+);
+
+-- filled when a reception with a manifest is closed, cleared when it is reopened
+CREATE TABLE IF NOT EXISTS reception_discrepancies (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    reception_id UUID NOT NULL REFERENCES receptions(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('missing', 'extra', 'wrong_type')),
+    barcode TEXT,
+    expected_type TEXT,
+    actual_type TEXT,
+    product_id UUID REFERENCES products(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_reception_discrepancies_reception_id ON reception_discrepancies (reception_id);
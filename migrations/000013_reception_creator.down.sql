@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_products_reception_date;
+DROP INDEX IF EXISTS idx_receptions_created_by;
+ALTER TABLE receptions DROP COLUMN IF EXISTS created_by;
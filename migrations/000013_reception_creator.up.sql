@@ -0,0 +1,5 @@
+-- receptions opened before this migration or with /dummyLogin tokens have no creator
+ALTER TABLE receptions ADD COLUMN IF NOT EXISTS created_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_receptions_created_by ON receptions (created_by) WHERE created_by IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_products_reception_date ON products (reception_id, date) WHERE deleted_at IS NULL;
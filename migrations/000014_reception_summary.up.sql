@@ -0,0 +1,97 @@
+-- totals computed once on close so reports don't re-aggregate products, cleared on reopen
+ALTER TABLE receptions ADD COLUMN IF NOT EXISTS summary JSONB;
+
+-- receptions closed before this migration
+UPDATE receptions r
+SET summary = json_build_object(
+    'productsByType', COALESCE((
+        SELECT json_object_agg(t.type, t.n)
+        FROM (
+            SELECT p.type, COUNT(*) AS n
+            FROM products p
+            WHERE p.reception_id = r.id AND p.deleted_at IS NULL
+            GROUP BY p.type
+        ) t
+    ), '{}'::json),
+    'productsTotal', (SELECT COUNT(*) FROM products p WHERE p.reception_id = r.id AND p.deleted_at IS NULL),
+    'deletions', (SELECT COUNT(*) FROM products p WHERE p.reception_id = r.id AND p.deleted_at IS NOT NULL),
+    'durationSeconds', GREATEST(EXTRACT(EPOCH FROM r.closed_at - r.date), 0)::BIGINT
+)
+WHERE r.status = 'close' AND r.closed_at IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION get_pvz_with_receptions_paginated(
+    start_date TIMESTAMP DEFAULT NULL,
+    end_date TIMESTAMP DEFAULT NULL,
+    page_limit INT DEFAULT 10,
+    page_offset INT DEFAULT 0
+) RETURNS TABLE (
+    pvz_id UUID,
+    city TEXT,
+    address TEXT,
+    registration_date TIMESTAMPTZ,
+    receptions JSON
+) AS $$
+BEGIN
+    RETURN QUERY
+    WITH filtered_pvzs AS (
+        SELECT p.id, p.city, p.address, p.registration_date
+        FROM pvzs p
+        ORDER BY p.registration_date DESC
+        LIMIT page_limit
+        OFFSET page_offset
+    )
+    SELECT 
+        p.id AS pvz_id,
+        p.city,
+        p.address,
+        p.registration_date,
+        CASE 
+            WHEN COUNT(r.id) = 0 THEN NULL
+            ELSE (
+                SELECT json_agg(
+                    json_build_object(
+                        'reception', json_build_object(
+                            'dateTime', r.date,
+                            'id', r.id,
+                            'pvzId', r.pvz_id,
+                            'status', r.status,
+                            'kind', r.kind,
+                            'summary', r.summary
+                        ),
+                        'products', (
+                            SELECT COALESCE(
+                                json_agg(
+                                    json_build_object(
+                                        'dateTime', pr.date,
+                                        'id', pr.id,
+                                        'receptionId', pr.reception_id,
+                                        'type', pr.type,
+                                        'barcode', pr.barcode,
+                                        'externalOrderId', pr.external_order_id,
+                                        'state', pr.state,
+                                        'stateChangedAt', pr.state_changed_at,
+                                        'returnCondition', pr.return_condition,
+                                        'originalOrderId', pr.original_order_id
+                                    )
+                                ),
+                                '[]'::json
+                            )
+                            FROM products pr
+                            WHERE pr.reception_id = r.id
+                            AND pr.deleted_at IS NULL
+                        )
+                    )
+                )
+                FROM receptions r
+                WHERE r.pvz_id = p.id
+                AND (start_date IS NULL OR r.date >= start_date)
+                AND (end_date IS NULL OR r.date <= end_date)
+            )
+        END AS receptions
+    FROM filtered_pvzs p
+    LEFT JOIN receptions r ON r.pvz_id = p.id
+        AND (start_date IS NULL OR r.date >= start_date)
+        AND (end_date IS NULL OR r.date <= end_date)
+    GROUP BY p.id, p.city, p.address, p.registration_date;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS transfer_items;
+DROP TABLE IF EXISTS transfers;
+
+-- products still on the way are put back to the source PVZ
+UPDATE products SET state = 'stored' WHERE state = 'in_transit';
+
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_state_check;
+ALTER TABLE products ADD CONSTRAINT products_state_check
+    CHECK (state IN ('received', 'stored', 'issued', 'returned_to_sender'));
@@ -0,0 +1,36 @@
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_state_check;
+ALTER TABLE products ADD CONSTRAINT products_state_check
+    CHECK (state IN ('received', 'stored', 'issued', 'returned_to_sender', 'in_transit'));
+
+CREATE TABLE IF NOT EXISTS transfers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    source_pvz_id UUID NOT NULL REFERENCES pvzs(id) ON DELETE CASCADE,
+    destination_pvz_id UUID NOT NULL REFERENCES pvzs(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'created' CHECK (status IN ('created', 'dispatched', 'arrived')),
+    -- reception of the destination PVZ the products were received into
+    reception_id UUID REFERENCES receptions(id) ON DELETE SET NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    dispatched_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    dispatched_at TIMESTAMPTZ,
+    arrived_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    arrived_at TIMESTAMPTZ,
+    CHECK (source_pvz_id <> destination_pvz_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_transfers_source ON transfers (source_pvz_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_transfers_destination ON transfers (destination_pvz_id, created_at);
+
+CREATE TABLE IF NOT EXISTS transfer_items (
+    transfer_id UUID NOT NULL REFERENCES transfers(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    -- reception the product was stored in at the source PVZ
+    source_reception_id UUID REFERENCES receptions(id) ON DELETE SET NULL,
+    -- cleared on arrival, a product can be in one unfinished transfer at a time
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    PRIMARY KEY (transfer_id, product_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transfer_items_one_active ON transfer_items (product_id) WHERE active;
+CREATE INDEX IF NOT EXISTS idx_transfer_items_product ON transfer_items (product_id);
@@ -0,0 +1,16 @@
+-- responses of POST requests sent with an Idempotency-Key header, replayed when a client retries the request
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    -- keys are scoped to the caller, so clients can't collide or read each other's responses
+    scope TEXT NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    fingerprint TEXT NOT NULL,
+    -- NULL while the first request with the key is being processed
+    status_code INT,
+    content_type TEXT,
+    response BYTEA,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_products_reception_scan_seq;
+ALTER TABLE products DROP COLUMN IF EXISTS scan_seq;
+ALTER TABLE receptions DROP COLUMN IF EXISTS last_scan_seq;
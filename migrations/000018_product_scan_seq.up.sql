@@ -0,0 +1,13 @@
+-- last scan number given out in the reception, products take the next ones on insert
+ALTER TABLE receptions ADD COLUMN last_scan_seq BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN scan_seq BIGINT;
+
+-- existing products are numbered in order of scanning
+UPDATE products p SET scan_seq = s.seq
+FROM (SELECT id, row_number() OVER (PARTITION BY reception_id ORDER BY date, id) AS seq FROM products) s
+WHERE s.id = p.id;
+
+UPDATE receptions r SET last_scan_seq = COALESCE((SELECT MAX(p.scan_seq) FROM products p WHERE p.reception_id = r.id), 0);
+
+ALTER TABLE products ALTER COLUMN scan_seq SET NOT NULL;
+CREATE UNIQUE INDEX idx_products_reception_scan_seq ON products (reception_id, scan_seq);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS attachments (
+    id UUID PRIMARY KEY,
+    reception_id UUID REFERENCES receptions(id) ON DELETE CASCADE,
+    product_id UUID REFERENCES products(id) ON DELETE CASCADE,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL CHECK (size > 0),
+    sha256 CHAR(64) NOT NULL,
+    -- key of the file in the blob store
+    storage_key VARCHAR(255) NOT NULL,
+    uploaded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    uploaded_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    -- an attachment belongs either to a reception or to a product
+    CHECK ((reception_id IS NULL) <> (product_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachments_reception_id ON attachments(reception_id) WHERE reception_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_attachments_product_id ON attachments(product_id) WHERE product_id IS NOT NULL;
@@ -0,0 +1,6 @@
+UPDATE receptions
+SET summary = summary - 'damagedByCondition' - 'damagedTotal'
+WHERE summary IS NOT NULL;
+
+DROP TABLE IF EXISTS damage_reports;
+ALTER TABLE products DROP COLUMN IF EXISTS condition;
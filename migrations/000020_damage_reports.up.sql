@@ -0,0 +1,25 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS condition VARCHAR(20) NOT NULL DEFAULT 'ok'
+    CHECK (condition IN ('ok', 'damaged', 'opened', 'wet'));
+
+CREATE TABLE IF NOT EXISTS damage_reports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    condition VARCHAR(20) NOT NULL CHECK (condition IN ('damaged', 'opened', 'wet')),
+    comment TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'rejected')),
+    reported_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reported_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMPTZ,
+    review_comment TEXT,
+    CHECK ((status = 'pending') = (reviewed_at IS NULL))
+);
+
+-- a product has at most one report waiting for a moderator, flagging it again updates that report
+CREATE UNIQUE INDEX IF NOT EXISTS idx_damage_reports_one_pending ON damage_reports (product_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_damage_reports_status ON damage_reports (status, reported_at);
+
+-- all products were ok before this migration
+UPDATE receptions
+SET summary = summary || '{"damagedByCondition": {}, "damagedTotal": 0}'::jsonb
+WHERE summary IS NOT NULL;
@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS products_volume_insert ON products;
+DROP TRIGGER IF EXISTS products_volume_update ON products;
+DROP TRIGGER IF EXISTS products_volume_delete ON products;
+DROP FUNCTION IF EXISTS product_volume_daily_apply();
+DROP TABLE IF EXISTS product_volume_daily;
@@ -0,0 +1,78 @@
+-- number of not deleted products by UTC scan day, PVZ of their current reception and type,
+-- kept up to date by a trigger on products so volume reports don't aggregate the products table
+CREATE TABLE IF NOT EXISTS product_volume_daily (
+    day DATE NOT NULL,
+    pvz_id UUID NOT NULL REFERENCES pvzs(id) ON DELETE CASCADE,
+    type TEXT NOT NULL,
+    products BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (day, pvz_id, type)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_volume_daily_pvz ON product_volume_daily (pvz_id, day);
+
+-- the statement level trigger sums changes of all affected rows and applies them in key order,
+-- so concurrent batches touching the same days and types can't deadlock on the counters
+CREATE OR REPLACE FUNCTION product_volume_daily_apply() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO product_volume_daily (day, pvz_id, type, products)
+        SELECT (n.date AT TIME ZONE 'UTC')::date, r.pvz_id, n.type, COUNT(*)
+        FROM new_rows n
+        JOIN receptions r ON r.id = n.reception_id
+        WHERE n.deleted_at IS NULL
+        GROUP BY 1, 2, 3
+        ORDER BY 1, 2, 3
+        ON CONFLICT (day, pvz_id, type) DO UPDATE SET products = product_volume_daily.products + EXCLUDED.products;
+    ELSIF TG_OP = 'UPDATE' THEN
+        INSERT INTO product_volume_daily (day, pvz_id, type, products)
+        SELECT d.day, d.pvz_id, d.type, SUM(d.delta)
+        FROM (
+            SELECT (n.date AT TIME ZONE 'UTC')::date AS day, r.pvz_id, n.type, 1 AS delta
+            FROM new_rows n
+            JOIN receptions r ON r.id = n.reception_id
+            WHERE n.deleted_at IS NULL
+            UNION ALL
+            SELECT (o.date AT TIME ZONE 'UTC')::date, r.pvz_id, o.type, -1
+            FROM old_rows o
+            JOIN receptions r ON r.id = o.reception_id
+            WHERE o.deleted_at IS NULL
+        ) d
+        GROUP BY 1, 2, 3
+        HAVING SUM(d.delta) <> 0
+        ORDER BY 1, 2, 3
+        ON CONFLICT (day, pvz_id, type) DO UPDATE SET products = product_volume_daily.products + EXCLUDED.products;
+    ELSE
+        -- products deleted together with their PVZ have no reception left, their counters go with the PVZ
+        INSERT INTO product_volume_daily (day, pvz_id, type, products)
+        SELECT (o.date AT TIME ZONE 'UTC')::date, r.pvz_id, o.type, -COUNT(*)
+        FROM old_rows o
+        JOIN receptions r ON r.id = o.reception_id
+        WHERE o.deleted_at IS NULL
+        GROUP BY 1, 2, 3
+        ORDER BY 1, 2, 3
+        ON CONFLICT (day, pvz_id, type) DO UPDATE SET products = product_volume_daily.products + EXCLUDED.products;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS products_volume_insert ON products;
+CREATE TRIGGER products_volume_insert AFTER INSERT ON products
+    REFERENCING NEW TABLE AS new_rows
+    FOR EACH STATEMENT EXECUTE FUNCTION product_volume_daily_apply();
+DROP TRIGGER IF EXISTS products_volume_update ON products;
+CREATE TRIGGER products_volume_update AFTER UPDATE ON products
+    REFERENCING OLD TABLE AS old_rows NEW TABLE AS new_rows
+    FOR EACH STATEMENT EXECUTE FUNCTION product_volume_daily_apply();
+DROP TRIGGER IF EXISTS products_volume_delete ON products;
+CREATE TRIGGER products_volume_delete AFTER DELETE ON products
+    REFERENCING OLD TABLE AS old_rows
+    FOR EACH STATEMENT EXECUTE FUNCTION product_volume_daily_apply();
+
+INSERT INTO product_volume_daily (day, pvz_id, type, products)
+SELECT (p.date AT TIME ZONE 'UTC')::date, r.pvz_id, p.type, COUNT(*)
+FROM products p
+JOIN receptions r ON r.id = p.reception_id
+WHERE p.deleted_at IS NULL
+GROUP BY 1, 2, 3
+ON CONFLICT (day, pvz_id, type) DO UPDATE SET products = EXCLUDED.products;
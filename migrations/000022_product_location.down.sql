@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_products_current_reception_state;
+ALTER TABLE products DROP COLUMN IF EXISTS current_reception_id;
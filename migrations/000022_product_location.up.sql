@@ -0,0 +1,31 @@
+-- reception the product is kept under right now, it differs from reception_id after a transfer
+-- arrives: reception_id stays the reception the product was scanned into and is kept for history
+ALTER TABLE products ADD COLUMN current_reception_id UUID REFERENCES receptions(id) ON DELETE CASCADE;
+
+UPDATE products SET current_reception_id = reception_id;
+
+-- arrivals used to move products into the destination reception, put them back into the reception
+-- they were scanned into; their original scan numbers are lost, they get the next ones of that reception
+WITH moved AS (
+    SELECT DISTINCT ON (ti.product_id) ti.product_id, ti.source_reception_id
+    FROM transfer_items ti
+    JOIN transfers t ON t.id = ti.transfer_id
+    WHERE t.status = 'arrived' AND ti.source_reception_id IS NOT NULL
+    ORDER BY ti.product_id, t.created_at, ti.transfer_id
+), numbered AS (
+    SELECT m.product_id, m.source_reception_id,
+        row_number() OVER (PARTITION BY m.source_reception_id ORDER BY p.scan_seq, p.id) AS position
+    FROM moved m
+    JOIN products p ON p.id = m.product_id
+    WHERE p.reception_id <> m.source_reception_id
+)
+UPDATE products p SET reception_id = n.source_reception_id, scan_seq = r.last_scan_seq + n.position
+FROM numbered n
+JOIN receptions r ON r.id = n.source_reception_id
+WHERE p.id = n.product_id;
+
+UPDATE receptions r SET last_scan_seq = COALESCE((SELECT MAX(p.scan_seq) FROM products p WHERE p.reception_id = r.id), 0)
+WHERE r.last_scan_seq < COALESCE((SELECT MAX(p.scan_seq) FROM products p WHERE p.reception_id = r.id), 0);
+
+ALTER TABLE products ALTER COLUMN current_reception_id SET NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_products_current_reception_state ON products (current_reception_id, state) WHERE deleted_at IS NULL;
@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS products_volume_update ON products;
+CREATE TRIGGER products_volume_update AFTER UPDATE ON products
+    REFERENCING OLD TABLE AS old_rows NEW TABLE AS new_rows
+    FOR EACH STATEMENT EXECUTE FUNCTION product_volume_daily_apply();
+DROP FUNCTION IF EXISTS product_volume_daily_move();
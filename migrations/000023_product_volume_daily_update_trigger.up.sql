@@ -0,0 +1,33 @@
+-- products are updated on every state change, cell placement and pickup code, the update trigger fires only
+-- when a column the counters depend on changes. Column lists and WHEN can't be used together with transition
+-- tables, so updates are applied row by row; such updates touch one product or products of one reception
+-- at a time, so they don't contend on the counters like scans do
+CREATE OR REPLACE FUNCTION product_volume_daily_move() RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO product_volume_daily (day, pvz_id, type, products)
+    SELECT d.day, d.pvz_id, d.type, SUM(d.delta)
+    FROM (
+        SELECT (NEW.date AT TIME ZONE 'UTC')::date AS day, r.pvz_id, NEW.type AS type, 1 AS delta
+        FROM receptions r
+        WHERE r.id = NEW.reception_id AND NEW.deleted_at IS NULL
+        UNION ALL
+        SELECT (OLD.date AT TIME ZONE 'UTC')::date, r.pvz_id, OLD.type, -1
+        FROM receptions r
+        WHERE r.id = OLD.reception_id AND OLD.deleted_at IS NULL
+    ) d
+    GROUP BY 1, 2, 3
+    HAVING SUM(d.delta) <> 0
+    ORDER BY 1, 2, 3
+    ON CONFLICT (day, pvz_id, type) DO UPDATE SET products = product_volume_daily.products + EXCLUDED.products;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS products_volume_update ON products;
+CREATE TRIGGER products_volume_update AFTER UPDATE OF date, reception_id, type, deleted_at ON products
+    FOR EACH ROW
+    WHEN (OLD.date IS DISTINCT FROM NEW.date
+        OR OLD.reception_id IS DISTINCT FROM NEW.reception_id
+        OR OLD.type IS DISTINCT FROM NEW.type
+        OR OLD.deleted_at IS DISTINCT FROM NEW.deleted_at)
+    EXECUTE FUNCTION product_volume_daily_move();